@@ -0,0 +1,236 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// bulkConcurrency is the maximum number of concurrent requests made by a bulk fetch
+const bulkConcurrency = 4
+
+// BulkError reports each item which failed during a bulk fetch
+type BulkError struct {
+	Items []BulkItemError
+}
+
+// BulkItemError represents a failure to fetch a single item during a bulk fetch
+type BulkItemError struct {
+	Index int
+	ID    ID
+	Err   error
+}
+
+// Error returns the number of failed items, and the first failure
+func (e *BulkError) Error() string {
+	first := e.Items[0]
+	return fmt.Sprintf("gosubsonic: %d of bulk fetch items failed, first: ID %s: %s",
+		len(e.Items), first.ID, first.Err.Error())
+}
+
+// GetAlbums concurrently fetches albums for each of the specified IDs.  Results are returned
+// in the same order as the input IDs.  If any album cannot be fetched, its result is nil, and
+// a *BulkError describing each failure is returned alongside the successful results.
+func (s Client) GetAlbums(ctx context.Context, ids []ID) ([]*AlbumID3, error) {
+	albums := make([]*AlbumID3, len(ids))
+	err := bulkFetch(ctx, ids, func(i int, id ID) error {
+		album, err := s.GetAlbum(ctx, id)
+		albums[i] = album
+		return err
+	})
+
+	return albums, err
+}
+
+// GetSongs concurrently fetches songs for each of the specified IDs.  Results are returned
+// in the same order as the input IDs.  If any song cannot be fetched, its result is nil, and
+// a *BulkError describing each failure is returned alongside the successful results.
+func (s Client) GetSongs(ctx context.Context, ids []ID) ([]*Audio, error) {
+	songs := make([]*Audio, len(ids))
+	err := bulkFetch(ctx, ids, func(i int, id ID) error {
+		song, err := s.GetSong(ctx, id)
+		songs[i] = song
+		return err
+	})
+
+	return songs, err
+}
+
+// GetMusicDirectories concurrently fetches the contents of each of the specified directories.
+// Results are keyed by directory ID.  If any directory cannot be fetched, it is omitted from
+// the results, and a *BulkError describing each failure is returned alongside the successful
+// results.
+func (s Client) GetMusicDirectories(ctx context.Context, ids []ID) (map[ID]*Content, error) {
+	contents := make([]*Content, len(ids))
+	err := bulkFetch(ctx, ids, func(i int, id ID) error {
+		content, err := s.GetMusicDirectory(ctx, id)
+		contents[i] = content
+		return err
+	})
+
+	out := make(map[ID]*Content, len(ids))
+	for i, c := range contents {
+		if c != nil {
+			out[ids[i]] = c
+		}
+	}
+
+	return out, err
+}
+
+// bulkFetch invokes fn for each ID with bounded concurrency, and aggregates per-item errors.
+// Once ctx is canceled, no further fetches are started, and the remaining items fail with
+// the context's error.
+func bulkFetch(ctx context.Context, ids []ID, fn func(i int, id ID) error) error {
+	return bulkFetchN(ctx, bulkConcurrency, ids, fn)
+}
+
+// bulkFetchN is like bulkFetch, but with the specified maximum number of concurrent fetches
+func bulkFetchN(ctx context.Context, concurrency int, ids []ID, fn func(i int, id ID) error) error {
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		// Do not start new fetches once the context is canceled
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		// Wait for a free slot, unless the context is canceled first
+		select {
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, id ID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = fn(i, id)
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	// Collect per-item errors in input order
+	var items []BulkItemError
+	for i, err := range errs {
+		if err != nil {
+			items = append(items, BulkItemError{
+				Index: i,
+				ID:    ids[i],
+				Err:   err,
+			})
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	return &BulkError{Items: items}
+}
+
+// DirectoryNode is a directory in a tree returned by GetMusicDirectoryTree
+type DirectoryNode struct {
+	// Directory describes the directory as listed by its parent.  Only the ID is set for the
+	// root of a tree.
+	Directory Directory
+
+	// Content is the contents of the directory
+	Content *Content
+
+	// Children are the expanded subdirectories, in the order they are listed in Content.
+	// Subdirectories beyond the depth limit, or which could not be fetched, are omitted.
+	Children []*DirectoryNode
+}
+
+// GetMusicDirectoryTree fetches a directory, and recursively expands its subdirectories up
+// to the specified depth, such as 1 for an artist's directory and the contents of each of
+// its albums.  A depth of 0 fetches only the directory itself, and a negative depth expands
+// every subdirectory.  Each level is fetched concurrently, as with GetMusicDirectories.
+//
+// If the directory itself cannot be fetched, an error is returned.  If any subdirectory
+// cannot be fetched, it is omitted from the tree, and a *BulkError describing each failure
+// is returned alongside the tree.  Each failure's Index is the order in which its
+// subdirectory was fetched, breadth first.
+func (s Client) GetMusicDirectoryTree(ctx context.Context, id ID, depth int) (*DirectoryNode, error) {
+	content, err := s.GetMusicDirectory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &DirectoryNode{Directory: Directory{ID: id}, Content: content}
+
+	// Directories are only expanded once, in case the server reports a cycle
+	seen := map[ID]bool{id: true}
+
+	var items []BulkItemError
+	var fetched int
+	level := []*DirectoryNode{root}
+	for d := 0; (depth < 0 || d < depth) && len(level) > 0; d++ {
+		// Collect the subdirectories of every directory in this level
+		var nodes []*DirectoryNode
+		var ids []ID
+		for _, n := range level {
+			for _, dir := range n.Content.Directories {
+				if seen[dir.ID] {
+					continue
+				}
+				seen[dir.ID] = true
+
+				nodes = append(nodes, &DirectoryNode{Directory: dir})
+				ids = append(ids, dir.ID)
+			}
+		}
+
+		err := bulkFetch(ctx, ids, func(i int, id ID) error {
+			content, err := s.GetMusicDirectory(ctx, id)
+			nodes[i].Content = content
+			return err
+		})
+
+		var berr *BulkError
+		if errors.As(err, &berr) {
+			for _, item := range berr.Items {
+				item.Index += fetched
+				items = append(items, item)
+			}
+		}
+		fetched += len(ids)
+
+		// Attach fetched directories to their parents, in listing order
+		byID := make(map[ID]*DirectoryNode, len(nodes))
+		next := level[:0:0]
+		for _, n := range nodes {
+			if n.Content != nil {
+				byID[n.Directory.ID] = n
+				next = append(next, n)
+			}
+		}
+		for _, n := range level {
+			for _, dir := range n.Content.Directories {
+				if c, ok := byID[dir.ID]; ok {
+					n.Children = append(n.Children, c)
+					delete(byID, dir.ID)
+				}
+			}
+		}
+
+		level = next
+	}
+
+	if len(items) > 0 {
+		return root, &BulkError{Items: items}
+	}
+
+	return root, nil
+}
@@ -0,0 +1,142 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"testing"
+)
+
+// TestGetAlbums verifies that client.GetAlbums() preserves order and reports per-item errors
+func TestGetAlbums(t *testing.T) {
+	log.Println("TestGetAlbums()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Fetch albums, where only ID 1 has mock data
+	albums, err := s.GetAlbums(context.Background(), []ID{"1", "2", "1"})
+	if err == nil {
+		t.Fatalf("GetAlbums returned no error for missing album")
+	}
+
+	// Check for a single failed item
+	bulkErr, ok := err.(*BulkError)
+	if !ok || len(bulkErr.Items) != 1 || bulkErr.Items[0].Index != 1 || bulkErr.Items[0].ID != "2" {
+		t.Fatalf("GetAlbums returned invalid error: %#v", err)
+	}
+
+	// Check for results in input order
+	if albums[0] == nil || albums[1] != nil || albums[2] == nil {
+		t.Fatalf("GetAlbums returned results out of order: %v", albums)
+	}
+}
+
+// TestGetSongsCanceled verifies that client.GetSongs() starts no fetches once canceled
+func TestGetSongsCanceled(t *testing.T) {
+	log.Println("TestGetSongsCanceled()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Fetch songs with an already canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	songs, err := s.GetSongs(ctx, []ID{"1", "1"})
+	bulkErr, ok := err.(*BulkError)
+	if !ok || len(bulkErr.Items) != 2 || bulkErr.Items[0].Err != context.Canceled {
+		t.Fatalf("GetSongs returned invalid error: %#v", err)
+	}
+
+	if songs[0] != nil || songs[1] != nil {
+		t.Fatalf("GetSongs fetched songs after cancellation")
+	}
+}
+
+// TestGetMusicDirectories verifies that client.GetMusicDirectories() keys results by ID
+func TestGetMusicDirectories(t *testing.T) {
+	log.Println("TestGetMusicDirectories()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Fetch directories, where only ID 1 has mock data
+	contents, err := s.GetMusicDirectories(context.Background(), []ID{"1", "2"})
+	bulkErr, ok := err.(*BulkError)
+	if !ok || len(bulkErr.Items) != 1 || bulkErr.Items[0].ID != "2" || !IsNotFound(bulkErr.Items[0].Err) {
+		t.Fatalf("GetMusicDirectories returned invalid error: %#v", err)
+	}
+
+	if len(contents) != 1 || contents["1"] == nil || len(contents["1"].Directories) == 0 {
+		t.Fatalf("GetMusicDirectories returned invalid results: %v", contents)
+	}
+}
+
+// TestGetMusicDirectoryTree verifies that client.GetMusicDirectoryTree() expands
+// subdirectories up to a depth, and reports subdirectories which cannot be fetched
+func TestGetMusicDirectoryTree(t *testing.T) {
+	log.Println("TestGetMusicDirectoryTree()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	dir := func(id string, children string) {
+		f.SetFixture("getMusicDirectory", url.Values{"id": {id}}, []byte(`{"subsonic-response": {"status": "ok",
+			"version": "1.9.0", "directory": {"id": `+id+`, "child": [`+children+`]}}}`))
+	}
+
+	// Directory 12 is missing, and directory 13 lists its ancestor as a child
+	dir("10", `{"id": 11, "isDir": true, "title": "Adventure"}, {"id": 12, "isDir": true, "title": "Missing"},
+		{"id": 1, "isDir": false, "title": "Intro"}`)
+	dir("11", `{"id": 13, "isDir": true, "title": "Disc 1"}, {"id": 2, "isDir": false, "title": "Wonderland"}`)
+	dir("13", `{"id": 10, "isDir": true, "title": "Loop"}, {"id": 3, "isDir": false, "title": "311"}`)
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	tree, err := s.GetMusicDirectoryTree(ctx, "10", 0)
+	if err != nil {
+		t.Fatalf("GetMusicDirectoryTree returned error: %s", err.Error())
+	}
+	if tree.Directory.ID != "10" || len(tree.Content.Children) != 3 || tree.Children != nil || f.Requests("getMusicDirectory") != 1 {
+		t.Fatalf("GetMusicDirectoryTree expanded beyond depth 0: %+v", tree)
+	}
+
+	// Only the first level is expanded, and the missing directory is reported
+	tree, err = s.GetMusicDirectoryTree(ctx, "10", 1)
+	bulkErr, ok := err.(*BulkError)
+	if !ok || len(bulkErr.Items) != 1 || bulkErr.Items[0].ID != "12" || bulkErr.Items[0].Index != 1 || !IsNotFound(bulkErr.Items[0].Err) {
+		t.Fatalf("GetMusicDirectoryTree returned invalid error: %#v", err)
+	}
+	if len(tree.Children) != 1 || tree.Children[0].Directory.Title != "Adventure" || tree.Children[0].Children != nil {
+		t.Fatalf("GetMusicDirectoryTree returned invalid tree: %+v", tree)
+	}
+
+	// Every level is expanded, without following the cycle
+	tree, err = s.GetMusicDirectoryTree(ctx, "10", -1)
+	if _, ok := err.(*BulkError); !ok {
+		t.Fatalf("GetMusicDirectoryTree returned invalid error: %#v", err)
+	}
+
+	disc := tree.Children[0].Children
+	if len(disc) != 1 || disc[0].Directory.ID != "13" || disc[0].Content.Audio[0].Title != "311" || disc[0].Children != nil {
+		t.Fatalf("GetMusicDirectoryTree returned invalid tree: %+v", tree.Children[0])
+	}
+
+	if _, err := s.GetMusicDirectoryTree(ctx, "12", -1); !IsNotFound(err) {
+		t.Fatalf("GetMusicDirectoryTree returned invalid error for missing root: %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/binary"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheRevalidateTTL is the duration for which expired getIndexes responses are retained,
+// so they may be revalidated cheaply using ifModifiedSince
+const cacheRevalidateTTL = 24 * time.Hour
+
+// DefaultCacheTTL maps each API method whose responses are cached to its default time-to-live.
+// Methods which modify data or return frequently changing data are never cached, and neither
+// are methods whose responses include per-user state, such as starred items, ratings, and
+// play counts, which would be stale after this Client's own writes.
+var DefaultCacheTTL = map[string]time.Duration{
+	"getLicense":      time.Hour,
+	"getMusicFolders": time.Hour,
+	"getGenres":       10 * time.Minute,
+	"getArtistInfo":   time.Hour,
+	"getArtistInfo2":  time.Hour,
+	"getAlbumInfo":    time.Hour,
+	"getAlbumInfo2":   time.Hour,
+}
+
+// BrowseCacheTTL maps the browsing methods, which UI clients frequently repeat, to suggested
+// time-to-lives, and may be set as Client.CacheTTL to cache them.  Their responses include
+// per-user state, so after methods such as Star, SetRating, or Scrobble, cached responses
+// are stale until they expire or are removed using InvalidateCache.
+var BrowseCacheTTL = map[string]time.Duration{
+	"getIndexes":        5 * time.Minute,
+	"getMusicDirectory": 5 * time.Minute,
+	"getArtists":        5 * time.Minute,
+	"getArtist":         5 * time.Minute,
+	"getAlbum":          5 * time.Minute,
+	"getSong":           5 * time.Minute,
+	"getVideos":         5 * time.Minute,
+}
+
+// Cache stores raw Subsonic responses, keyed by request URL.  Implementations must be safe
+// for concurrent use, and may be backed by memory, disk, or an external store.
+type Cache interface {
+	// Get returns the value stored for key, and whether it was found.  Values need not be
+	// returned once their time-to-live has passed.
+	Get(key string) ([]byte, bool)
+
+	// Set stores a value for key, which may be discarded after ttl
+	Set(key string, value []byte, ttl time.Duration)
+
+	// Delete removes the value stored for key, if any
+	Delete(key string)
+}
+
+// MemoryCache is an in-memory Cache, which discards values after their time-to-live
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+// memoryCacheEntry represents a value stored in a MemoryCache
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a new, empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get implements Cache
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return e.value, true
+}
+
+// Set implements Cache
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = memoryCacheEntry{
+		value:   value,
+		expires: c.now().Add(ttl),
+	}
+}
+
+// Delete implements Cache
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// Clear removes all values from the cache
+func (c *MemoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]memoryCacheEntry)
+}
+
+// InvalidateCache removes the cached response for an API method with the specified parameters,
+// such as after modifying data on the server outside of this Client
+func (s Client) InvalidateCache(method string, params Params) {
+	if s.Cache == nil {
+		return
+	}
+
+	s.Cache.Delete(cacheKey(s.makeURL(method, params)))
+}
+
+// cacheTTL returns the time-to-live for cached responses of an API method, or 0 if the
+// method's responses should not be cached
+func (s Client) cacheTTL(method string) time.Duration {
+	if ttl, ok := s.CacheTTL[method]; ok {
+		return ttl
+	}
+
+	return DefaultCacheTTL[method]
+}
+
+// get retrieves and parses a response, using the cache if one is configured
+func (s Client) get(ctx context.Context, url string) (*apiContainer, error) {
+	method := urlMethod(url)
+	ttl := s.cacheTTL(method)
+	if s.Cache == nil || ttl <= 0 {
+		_, res, err := s.call(ctx, url, false)
+		return res, err
+	}
+
+	// Cached entries record when they must be revalidated, as they may be retained longer
+	key := cacheKey(url)
+	now := time.Now()
+	var stale *apiContainer
+	var staleBody []byte
+	if entry, ok := s.Cache.Get(key); ok && len(entry) >= 8 {
+		fresh := time.Unix(0, int64(binary.BigEndian.Uint64(entry[:8])))
+		body := entry[8:]
+		if now.Before(fresh) {
+			res, warnings, err := s.parse(body)
+			reportParseWarnings(ctx, method, warnings)
+			return res, err
+		}
+
+		if res, _, err := s.parse(body); err == nil {
+			stale, staleBody = res, body
+		}
+	}
+
+	// Expired indexes are only retrieved again if they were modified
+	revalidate := method == "getIndexes" && stale != nil && stale.Response.Indexes.lastModified() > 0 &&
+		!strings.Contains(url, "ifModifiedSince=")
+	fetchURL := url
+	if revalidate {
+		fetchURL = withParam(url, "ifModifiedSince", stale.Response.Indexes.lastModified())
+	}
+
+	body, res, err := s.call(ctx, fetchURL, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if revalidate && len(res.Response.Indexes.Index) == 0 &&
+		res.Response.Indexes.lastModified() <= stale.Response.Indexes.lastModified() {
+		res, body = stale, staleBody
+	}
+
+	retain := ttl
+	if method == "getIndexes" && retain < cacheRevalidateTTL {
+		retain = cacheRevalidateTTL
+	}
+
+	entry := make([]byte, 8+len(body))
+	binary.BigEndian.PutUint64(entry[:8], uint64(now.Add(ttl).UnixNano()))
+	copy(entry[8:], body)
+	s.Cache.Set(key, entry, retain)
+
+	return res, nil
+}
+
+// cacheKey generates a cache key for a request URL, which omits the password and token
+// authentication parameters, as the token changes with every request
+func cacheKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	q := u.Query()
+	for _, p := range []string{"p", "t", "s"} {
+		q.Del(p)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// urlMethod returns the API method name from a request URL, such as getIndexes
+func urlMethod(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	name := u.Path[strings.LastIndex(u.Path, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// withParam returns a request URL with an additional integer parameter
+func withParam(rawURL string, key string, value int64) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	q := Params(u.Query())
+	q.SetInt(key, value)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
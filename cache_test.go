@@ -0,0 +1,192 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMemoryCache verifies that MemoryCache stores values until they expire
+func TestMemoryCache(t *testing.T) {
+	log.Println("TestMemoryCache()")
+
+	now := time.Unix(0, 0)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Hour)
+
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Fatalf("MemoryCache returned invalid value: %q, %v", v, ok)
+	}
+
+	// Expired values are not returned
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("MemoryCache returned expired value")
+	}
+
+	c.Delete("b")
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("MemoryCache returned deleted value")
+	}
+}
+
+// cacheTestClient generates a client for a test server which records each request made
+// after ping, and serves indexes which were last modified at the specified time
+func cacheTestClient(t *testing.T, lastModified *int64) (*Client, func() []string, func()) {
+	var mu sync.Mutex
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/rest/ping.view" {
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, strings.TrimPrefix(r.URL.Path, "/rest/")+"?"+r.URL.Query().Get("ifModifiedSince"))
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/rest/getIndexes.view":
+			mu.Lock()
+			modified := *lastModified
+			mu.Unlock()
+
+			// Indexes are omitted when they were not modified
+			since, err := strconv.ParseInt(r.URL.Query().Get("ifModifiedSince"), 10, 64)
+			if err == nil && since >= modified {
+				fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.9.0",
+					"indexes": {"lastModified": %d}}}`, modified)
+				return
+			}
+
+			fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.9.0",
+				"indexes": {"lastModified": %d,
+				"index": {"name": "A", "artist": {"id": 1, "name": "Adventure"}}}}}`, modified)
+		default:
+			w.Write([]byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+				"musicFolders": {"musicFolder": {"id": 1, "name": "Music"}}}}`))
+		}
+	}))
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		srv.Close()
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+	s.Cache = NewMemoryCache()
+
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}, srv.Close
+}
+
+// TestClientCache verifies that cached responses are reused until invalidated
+func TestClientCache(t *testing.T) {
+	log.Println("TestClientCache()")
+
+	modified := int64(1000)
+	s, requests, done := cacheTestClient(t, &modified)
+	defer done()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		folders, err := s.GetMusicFolders(ctx)
+		if err != nil {
+			t.Fatalf("GetMusicFolders returned error: %s", err.Error())
+		}
+
+		if len(folders) != 1 || folders[0].Name != "Music" {
+			t.Fatalf("GetMusicFolders returned invalid folders: %+v", folders)
+		}
+	}
+
+	if r := requests(); len(r) != 1 {
+		t.Fatalf("GetMusicFolders made %d requests with cache", len(r))
+	}
+
+	// Explicit invalidation requires a new request
+	s.InvalidateCache("getMusicFolders", nil)
+	if _, err := s.GetMusicFolders(ctx); err != nil {
+		t.Fatalf("GetMusicFolders returned error: %s", err.Error())
+	}
+
+	if r := requests(); len(r) != 2 {
+		t.Fatalf("GetMusicFolders made %d requests after invalidation", len(r))
+	}
+
+	// Methods whose responses include per-user state are only cached if enabled
+	if s.cacheTTL("getSong") != 0 || s.cacheTTL("getMusicDirectory") != 0 {
+		t.Fatalf("Methods with per-user state are cached by default")
+	}
+	s.CacheTTL = BrowseCacheTTL
+	if s.cacheTTL("getSong") <= 0 {
+		t.Fatalf("BrowseCacheTTL does not enable caching of getSong")
+	}
+
+	// Disabling caching for a method makes a request each time
+	s.CacheTTL = map[string]time.Duration{"getMusicFolders": 0}
+	if _, err := s.GetMusicFolders(ctx); err != nil {
+		t.Fatalf("GetMusicFolders returned error: %s", err.Error())
+	}
+
+	if r := requests(); len(r) != 3 {
+		t.Fatalf("GetMusicFolders made %d requests with caching disabled", len(r))
+	}
+}
+
+// TestClientCacheIfModifiedSince verifies that expired indexes are revalidated using
+// ifModifiedSince, and reused when they were not modified
+func TestClientCacheIfModifiedSince(t *testing.T) {
+	log.Println("TestClientCacheIfModifiedSince()")
+
+	modified := int64(1000)
+	s, requests, done := cacheTestClient(t, &modified)
+	defer done()
+
+	// Expire cached indexes immediately, so each call revalidates
+	s.CacheTTL = map[string]time.Duration{"getIndexes": time.Nanosecond}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		indexes, err := s.GetIndexes(ctx, -1, time.Time{})
+		if err != nil {
+			t.Fatalf("GetIndexes returned error: %s", err.Error())
+		}
+
+		if len(indexes.Index) != 1 || indexes.Index[0].Artist[0].Name != "Adventure" {
+			t.Fatalf("GetIndexes returned invalid indexes: %+v", indexes)
+		}
+	}
+
+	// After modification, the new indexes are retrieved
+	modified = 2000
+	if _, err := s.GetIndexes(ctx, -1, time.Time{}); err != nil {
+		t.Fatalf("GetIndexes returned error: %s", err.Error())
+	}
+
+	expected := []string{"getIndexes.view?", "getIndexes.view?1000", "getIndexes.view?1000"}
+	r := requests()
+	if len(r) != len(expected) {
+		t.Fatalf("GetIndexes made invalid requests: %v", r)
+	}
+
+	for i := range expected {
+		if r[i] != expected[i] {
+			t.Fatalf("GetIndexes made invalid requests: %v", r)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// REST API versions which introduced optional features
+const (
+	id3APIVersion       = "1.8.0"
+	bookmarksAPIVersion = "1.9.0"
+)
+
+// Capabilities describes the optional features supported by a server, so applications may
+// hide features which are not available, instead of reporting errors when they are used
+type Capabilities struct {
+	// APIVersion is the REST API version reported by the server, such as "1.16.1"
+	APIVersion string
+
+	// Type and ServerVersion identify the server implementation, if it implements
+	// OpenSubsonic
+	Type          string
+	ServerVersion string
+	OpenSubsonic  bool
+
+	// Features determined by the API version
+	ID3       bool
+	Bookmarks bool
+	TokenAuth bool
+
+	// Features determined by probing their methods, which are unavailable if the server
+	// reports an error, such as an unknown method or a user without permission
+	ScanStatus bool
+	PlayQueue  bool
+	Podcasts   bool
+}
+
+// Capabilities pings the server, and probes a few optional methods to determine which
+// features it supports.  Errors reported by the server for a probe mark its feature as
+// unavailable, while other errors, such as network errors, are returned.
+func (s Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	status, err := s.Ping(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Capabilities{
+		APIVersion:    status.Version,
+		Type:          status.Type,
+		ServerVersion: status.ServerVersion,
+		OpenSubsonic:  status.OpenSubsonic,
+
+		ID3:       versionAtLeast(status.Version, id3APIVersion),
+		Bookmarks: versionAtLeast(status.Version, bookmarksAPIVersion),
+		TokenAuth: versionAtLeast(status.Version, tokenAuthAPIVersion),
+	}
+
+	probes := []struct {
+		ok    *bool
+		probe func() error
+	}{
+		{&c.ScanStatus, func() error { _, err := s.GetScanStatus(ctx); return err }},
+		{&c.PlayQueue, func() error { _, err := s.GetPlayQueue(ctx); return err }},
+		{&c.Podcasts, func() error { _, err := s.GetPodcasts(ctx, false, ""); return err }},
+	}
+
+	for _, p := range probes {
+		err := p.probe()
+
+		var e *Error
+		if err != nil && !errors.As(err, &e) {
+			return nil, err
+		}
+
+		*p.ok = err == nil
+	}
+
+	return c, nil
+}
+
+// versionAtLeast determines if a REST API version, such as "1.13.0", is at least the
+// minimum version.  Missing or invalid components are treated as 0.
+func versionAtLeast(version string, min string) bool {
+	v, m := strings.Split(version, "."), strings.Split(min, ".")
+	for i := 0; i < len(v) || i < len(m); i++ {
+		var a, b int
+		if i < len(v) {
+			a, _ = strconv.Atoi(v[i])
+		}
+		if i < len(m) {
+			b, _ = strconv.Atoi(m[i])
+		}
+
+		if a != b {
+			return a > b
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,81 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// TestCapabilities verifies that client.Capabilities() reports the features supported by the
+// server, and returns errors which are not reported by the server
+func TestCapabilities(t *testing.T) {
+	log.Println("TestCapabilities()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	// Podcasts are probed without their episodes
+	f.SetFixture("getPodcasts", url.Values{"includeEpisodes": {"false"}},
+		[]byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "podcasts": {}}}`))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	c, err := s.Capabilities(ctx)
+	if err != nil {
+		t.Fatalf("Capabilities returned error: %s", err.Error())
+	}
+
+	want := Capabilities{APIVersion: "1.9.0", ID3: true, Bookmarks: true, ScanStatus: true, PlayQueue: true, Podcasts: true}
+	if *c != want {
+		t.Fatalf("Capabilities returned %+v, expected %+v", *c, want)
+	}
+
+	// Methods the server rejects are unavailable
+	f.SetError("getScanStatus", ErrCodeServerTooOld, "Incompatible Subsonic REST protocol version")
+	f.SetError("getPlayQueue", ErrCodeNotFound, "Unknown method")
+
+	c, err = s.Capabilities(ctx)
+	if err != nil {
+		t.Fatalf("Capabilities returned error: %s", err.Error())
+	}
+	if c.ScanStatus || c.PlayQueue || !c.Podcasts {
+		t.Fatalf("Capabilities returned invalid probes: %+v", *c)
+	}
+
+	// Other errors are returned
+	f.SetStatus("getPodcasts", http.StatusInternalServerError)
+	if _, err := s.Capabilities(ctx); err == nil {
+		t.Fatalf("Capabilities ignored server failure")
+	}
+}
+
+// TestVersionAtLeast verifies that versionAtLeast() compares REST API versions
+func TestVersionAtLeast(t *testing.T) {
+	log.Println("TestVersionAtLeast()")
+
+	var tests = []struct {
+		version string
+		min     string
+		ok      bool
+	}{
+		{"1.13.0", "1.13.0", true},
+		{"1.16.1", "1.13.0", true},
+		{"1.9.0", "1.13.0", false},
+		{"2.0", "1.13.0", true},
+		{"1.13", "1.13.0", true},
+		{"1.8.0", "1.8.1", false},
+		{"", "1.8.0", false},
+	}
+
+	for _, test := range tests {
+		if ok := versionAtLeast(test.version, test.min); ok != test.ok {
+			t.Fatalf("versionAtLeast(%q, %q) returned %v, expected %v", test.version, test.min, ok, test.ok)
+		}
+	}
+}
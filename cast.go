@@ -0,0 +1,96 @@
+package gosubsonic
+
+import (
+	"strings"
+)
+
+// Defaults used when generating URLs for cast devices
+const (
+	castDefaultFormat     = "mp3"
+	castFallbackMediaType = "application/octet-stream"
+)
+
+// formatContentTypes maps a transcoding format to the content type of the media it produces
+var formatContentTypes = map[string]string{
+	"aac":  "audio/aac",
+	"flac": "audio/flac",
+	"m4a":  "audio/mp4",
+	"mp3":  "audio/mpeg",
+	"oga":  "audio/ogg",
+	"ogg":  "audio/ogg",
+	"opus": "audio/ogg",
+	"wav":  "audio/wav",
+	"flv":  "video/x-flv",
+	"mp4":  "video/mp4",
+	"webm": "video/webm",
+}
+
+// CastOptions represents additional options for the StreamCastURL() method
+type CastOptions struct {
+	Format     string
+	MaxBitRate int64
+}
+
+// CastMedia represents an absolute media URL, and a content type hint, which may be handed
+// to a Chromecast or DLNA renderer.  The hint is empty if the content type is not known in
+// advance.
+type CastMedia struct {
+	URL         string
+	ContentType string
+}
+
+// StreamCastURL returns a CastMedia struct which contains a stream URL for the specified ID.
+// Cast devices cannot negotiate formats with Subsonic, so the stream is always forced to a
+// known format (mp3 by default), and optionally capped at a maximum bitrate.  As with all cast
+// URLs, token authentication is always used, so the password is never exposed to the device.
+func (s Client) StreamCastURL(id ID, options *CastOptions) CastMedia {
+	s = s.castClient()
+
+	// Force a format, so the content type hint is always accurate
+	format := castDefaultFormat
+	var maxBitRate int64
+	if options != nil {
+		if options.Format != "" {
+			format = strings.ToLower(options.Format)
+		}
+
+		maxBitRate = options.MaxBitRate
+	}
+
+	return CastMedia{
+		URL: s.streamURL(id, &StreamOptions{
+			Format:     format,
+			MaxBitRate: maxBitRate,
+		}),
+		ContentType: formatContentType(format),
+	}
+}
+
+// CoverArtCastURL returns a CastMedia struct which contains a cover art URL for the specified ID,
+// scaled to the specified size, using token authentication.  Cover art may be stored in any
+// image format, so no content type hint is given.
+func (s Client) CoverArtCastURL(id ID, size int64) CastMedia {
+	s = s.castClient()
+
+	return CastMedia{URL: s.coverArtURL(id, size)}
+}
+
+// castClient returns a copy of the client which uses token authentication, with an API
+// version new enough to support it, even if an older version was configured
+func (s Client) castClient() Client {
+	s.TokenAuth = true
+	if s.APIVersion != "" && !versionAtLeast(s.APIVersion, tokenAuthAPIVersion) {
+		s.APIVersion = tokenAuthAPIVersion
+	}
+
+	return s
+}
+
+// formatContentType returns the content type of media in a transcoding format
+func formatContentType(format string) string {
+	if c, ok := formatContentTypes[format]; ok {
+		return c
+	}
+
+	return castFallbackMediaType
+}
@@ -0,0 +1,103 @@
+package gosubsonic
+
+import (
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestStreamCastURL verifies that client.StreamCastURL() is working properly
+func TestStreamCastURL(t *testing.T) {
+	log.Println("TestStreamCastURL()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Generate a cast URL with no options, which should force mp3
+	media := s.StreamCastURL("1", nil)
+	if !strings.Contains(media.URL, "&format=mp3") {
+		t.Fatalf("StreamCastURL returned URL without forced format: %s", media.URL)
+	}
+
+	if media.ContentType != "audio/mpeg" {
+		t.Fatalf("StreamCastURL returned invalid content type: %s", media.ContentType)
+	}
+
+	// Generate a cast URL with format and bitrate
+	media = s.StreamCastURL("1", &CastOptions{
+		Format:     "OGG",
+		MaxBitRate: 128,
+	})
+	if !strings.Contains(media.URL, "&format=ogg&") || !strings.Contains(media.URL, "&maxBitRate=128&") {
+		t.Fatalf("StreamCastURL returned URL without options: %s", media.URL)
+	}
+
+	if media.ContentType != "audio/ogg" {
+		t.Fatalf("StreamCastURL returned invalid content type: %s", media.ContentType)
+	}
+}
+
+// TestCoverArtCastURL verifies that client.CoverArtCastURL() is working properly
+func TestCoverArtCastURL(t *testing.T) {
+	log.Println("TestCoverArtCastURL()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Generate a cover art cast URL
+	media := s.CoverArtCastURL("1", 300)
+	if !strings.HasPrefix(media.URL, "http://") || !strings.Contains(media.URL, "&id=1&") || !strings.Contains(media.URL, "&size=300&") {
+		t.Fatalf("CoverArtCastURL returned invalid URL: %s", media.URL)
+	}
+
+	// The image format is unknown, so no content type is claimed
+	if media.ContentType != "" {
+		t.Fatalf("CoverArtCastURL returned invalid content type: %s", media.ContentType)
+	}
+}
+
+// TestCastURLTokenAuth verifies that cast URLs never contain the password, even for clients
+// which use password authentication
+func TestCastURLTokenAuth(t *testing.T) {
+	log.Println("TestCastURLTokenAuth()")
+
+	// Generate mock client, which uses password authentication
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+	if s.TokenAuth {
+		t.Fatalf("Mock client unexpectedly uses token authentication")
+	}
+
+	// Older API versions do not support token authentication, so they are raised
+	old := *s
+	old.APIVersion = "1.8.0"
+
+	for _, rawURL := range []string{
+		s.StreamCastURL("1", nil).URL,
+		s.CoverArtCastURL("1", 300).URL,
+		old.StreamCastURL("1", nil).URL,
+		old.CoverArtCastURL("1", 300).URL,
+	} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatalf("Cast URL is invalid: %s", err.Error())
+		}
+
+		q := u.Query()
+		if q.Has("p") || q.Get("t") == "" || q.Get("s") == "" {
+			t.Fatalf("Cast URL does not use token authentication: %s", rawURL)
+		}
+		if !versionAtLeast(q.Get("v"), tokenAuthAPIVersion) {
+			t.Fatalf("Cast URL uses API version without token authentication: %s", rawURL)
+		}
+	}
+}
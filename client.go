@@ -1,78 +1,189 @@
 package gosubsonic
 
 import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"html"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Constants to pass with each API request
+// Defaults for the client name and REST API version passed with each API request, which may
+// be overridden for each Client
+const (
+	DefaultClientName = "gosubsonic"
+	DefaultAPIVersion = "1.8.0"
+
+	// tokenAuthAPIVersion is the first REST API version which supports token authentication
+	tokenAuthAPIVersion = "1.13.0"
+)
+
+// DefaultMaxResponseSize is the maximum size of an API response body, unless overridden by
+// Client.MaxResponseSize.  It is large enough for the indexes and starred items of very
+// large libraries.
+const DefaultMaxResponseSize = 128 << 20
+
+// Deprecated: use DefaultClientName and DefaultAPIVersion, or Client.ClientName and
+// Client.APIVersion to override them.  CLIENT keeps its original value, but is no longer
+// sent with requests.
 const (
 	CLIENT     = "gosubsonic-git-master"
-	APIVERSION = "1.8.0"
+	APIVERSION = DefaultAPIVersion
 )
 
-// dataSource represents a data source for a Subsonic client (could be HTTP, mock, etc)
-type dataSource interface {
-	Get(string) (*apiContainer, error)
+// DataSource retrieves raw API response bodies for a Client, such as over HTTP.  Get is
+// called with the full URL of each request, and returns the response body.
+type DataSource interface {
+	Get(ctx context.Context, url string) ([]byte, error)
 }
 
-// Client represents the required parameters to connect to a Subsonic server
-type Client struct {
-	Host     string
-	Username string
-	Password string
-	source   dataSource
+// StreamingDataSource is a DataSource which can also return response bodies as they are
+// read, so large responses are decoded without first being read entirely into memory.  The
+// HTTP data source is a StreamingDataSource.
+type StreamingDataSource interface {
+	DataSource
+	Open(ctx context.Context, url string) (io.ReadCloser, error)
 }
 
-// New creates a new Client using the specified parameters
-func New(host string, username string, password string) (*Client, error) {
-	// Generate a new Subsonic client
-	client := Client{
-		Host:     host,
-		Username: username,
-		Password: password,
+// BinaryDataSource is a DataSource which can also retrieve binary responses, such as media
+// streams and cover art, so they may be served by the same source as API responses.
+// GetBinary is called with the full URL and optional request headers, such as Range, and
+// returns the response, whose status and headers are checked like any HTTP response.  The
+// HTTP data source is a BinaryDataSource.  If a Client's data source is not, binary
+// responses are retrieved over HTTP.
+type BinaryDataSource interface {
+	DataSource
+	GetBinary(ctx context.Context, url string, header http.Header) (*http.Response, error)
+}
 
-		// Use HTTP as the data source
-		source: httpDataSource{},
-	}
+// Client represents the required parameters to connect to a Subsonic server.
+//
+// A Client is safe for concurrent use by multiple goroutines, as long as its fields are not
+// modified while it is in use.  Methods use a copy of the Client, and any shared state, such
+// as a Cache, Limiter, or Credentials, must itself be safe for concurrent use, as are the
+// implementations in this package.  Clients share pooled HTTP connections to the server
+// unless WithHTTPClient or non-default timeouts are used.
+type Client struct {
+	// Host is either a host with optional port, which is accessed over HTTP, or a full
+	// base URL including scheme and path prefix, such as https://example.com/subsonic
+	Host     string
+	Username string
 
-	// Attempt to ping the Subsonic server
-	_, err := client.Ping()
-	return &client, err
+	// Credentials supplies the password for each request.  The password is never included
+	// in errors or printed as part of the Client.
+	Credentials Credentials
+
+	// TokenAuth enables token authentication (Subsonic 1.13.0+), which sends a salted
+	// MD5 hash of the password with each request, instead of the password itself
+	TokenAuth bool
+
+	// AutoScrobble submits a "Now Playing" scrobble each time a Stream is opened,
+	// unless StreamOptions.SkipScrobble is set
+	AutoScrobble bool
+
+	// XML requests responses in XML format, rather than JSON.  Older Subsonic servers
+	// produce malformed or inconsistent JSON in some cases, such as numeric names or single
+	// item lists, while their XML responses are reliable.  Responses are decoded into the
+	// same types regardless of format.
+	XML bool
+
+	// Retry configures retries of requests which fail due to transient errors.  If nil,
+	// failed requests are not retried.
+	Retry *RetryPolicy
+
+	// Cache stores responses for rarely changing methods, such as GetMusicFolders and
+	// GetGenres, and for browsing methods, such as GetIndexes and GetMusicDirectory, if they
+	// are enabled using CacheTTL.  If nil, responses are not cached.
+	Cache Cache
+
+	// CacheTTL overrides DefaultCacheTTL for the specified API methods, such as "getIndexes",
+	// or BrowseCacheTTL to cache browsing methods.  A zero duration disables caching for a
+	// method.
+	CacheTTL map[string]time.Duration
+
+	// Interceptors wrap each request, such as for logging, metrics, or tracing.  The first
+	// interceptor is the outermost.
+	Interceptors []Interceptor
+
+	// Profile corrects the quirks of the server implementation in each response.  New selects
+	// a profile automatically using DetectProfile, unless WithoutPing is set.  If nil,
+	// responses are not corrected.
+	Profile *Profile
+
+	// Limiter limits the rate and concurrency of requests, and may be shared by multiple
+	// clients for the same server.  If nil, requests are not limited.
+	Limiter *Limiter
+
+	// ClientName is the client name reported to the server, which identifies the application
+	// in its logs and player list.  If empty, DefaultClientName is used.
+	ClientName string
+
+	// APIVersion is the REST API version reported to the server, which may be lowered to
+	// request the behavior of older API versions.  If empty, DefaultAPIVersion is used, or
+	// 1.13.0 with token authentication, which older versions do not support.
+	APIVersion string
+
+	// Timeout limits the duration of each API request, including any retries.  Binary
+	// requests, such as streams, are not limited.  If zero, requests have no timeout.  New
+	// sets DefaultTimeout unless WithTimeout is used, and WithCallTimeout overrides it for a
+	// single call.
+	Timeout time.Duration
+
+	// MaxResponseSize limits the size of each API response body in bytes, so a misbehaving
+	// server cannot exhaust memory.  Larger responses fail with ErrResponseTooLarge.  If
+	// zero, DefaultMaxResponseSize is used, and if negative, responses are not limited.
+	MaxResponseSize int64
+
+	// Transfers aggregates the binary transfers made by the client, such as streams and
+	// downloads, and may be shared by multiple clients.  If nil, transfers are not recorded.
+	// The progress of individual transfers is reported using WithProgress.
+	Transfers *TransferStats
+
+	// Logger logs each request, with its duration and outcome, such as to diagnose malformed
+	// responses.  Credentials are always redacted, and LogRedaction may redact more.  If nil,
+	// requests are not logged.
+	Logger       *slog.Logger
+	LogRedaction LogRedaction
+
+	// Lenient skips malformed items in lists, such as a single bad song in a directory,
+	// instead of failing the entire call.  Skipped items are reported to the function set by
+	// WithParseWarnings, and logged.  Lenient responses are read entirely before decoding.
+	Lenient bool
+
+	source     DataSource
+	httpClient *http.Client
 }
 
-// NewMock creates a new Client which receives mock data instead of connecting to a Subsonic server
+// NewMock creates a new Client which receives mock data from a FakeServer, which is served
+// in-process instead of connecting to a Subsonic server
 func NewMock() (*Client, error) {
-	// Generate a new mock client
-	client := Client{
-		Host: "__MOCK__",
-
-		// Use mock data as the data source
-		source: mockDataSource{},
-	}
-
-	// Initialize mock data
-	if err := mockInit(client); err != nil {
-		return nil, errors.New("gosubsonic: failed to initialize mock client")
-	}
-
-	return &client, nil
+	return New("__MOCK__",
+		WithPassword(fakeUsername, fakePassword),
+		WithHTTPClient(&http.Client{Transport: fakeTransport{server: newFakeServer()}}),
+		WithoutPing(),
+	)
 }
 
 // -- System --
 
 // Ping checks the connectivity of a Subsonic server
-func (s Client) Ping() (*APIStatus, error) {
+func (s Client) Ping(ctx context.Context) (*APIStatus, error) {
 	// Nil error means that ping is successful
-	res, err := s.source.Get(s.makeURL("ping"))
+	res, err := s.get(ctx, s.makeURL("ping", nil))
 	if err != nil {
 		return nil, err
 	}
@@ -80,767 +191,2099 @@ func (s Client) Ping() (*APIStatus, error) {
 	return &res.Response, nil
 }
 
-// GetLicense retrieves details about the Subsonic server license
-func (s Client) GetLicense() (*License, error) {
+// GetLicense retrieves details about the Subsonic server license.  Free servers, such as
+// Navidrome and Gonic, report a valid license without a date or email, or no license at all,
+// in which case ErrNotSupported is returned.
+func (s Client) GetLicense(ctx context.Context) (*License, error) {
 	// Retrieve license information from Subsonic
-	res, err := s.source.Get(s.makeURL("getLicense"))
+	res, err := s.get(ctx, s.makeURL("getLicense", nil))
 	if err != nil {
 		return nil, err
 	}
 
-	// Check for a license in the response
-	if &res.Response.License == nil {
-		return nil, errors.New("gosubsonic: no license found")
+	// Check for a license in the response, which is distinct from an empty license
+	if !res.Response.License.present {
+		return nil, ErrNotSupported
 	}
 
-	// Parse raw date into a time.Time struct, using the special Go date for parsing
-	// reference: http://golang.org/pkg/time/#Parse
-	t, err := time.Parse("2006-01-02T15:04:05", res.Response.License.DateRaw)
-	if err != nil {
-		return nil, err
-	}
-	res.Response.License.Date = t
+	// Parse raw date into a time.Time struct, which is zero if the date is missing
+	res.Response.License.Date = subsonicTime(res.Response.License.DateRaw)
 
 	return &res.Response.License, nil
 }
 
-// -- Browsing --
-
-// GetMusicFolders returns the configured top-level music folders
-func (s Client) GetMusicFolders() ([]MusicFolder, error) {
-	// Retrieve top-level music folders from Subsonic
-	res, err := s.source.Get(s.makeURL("getMusicFolders"))
+// GetOpenSubsonicExtensions returns the OpenSubsonic extensions supported by the server.
+// Servers which do not implement OpenSubsonic return an error.
+func (s Client) GetOpenSubsonicExtensions(ctx context.Context) ([]OpenSubsonicExtension, error) {
+	res, err := s.get(ctx, s.makeURL("getOpenSubsonicExtensions", nil))
 	if err != nil {
 		return nil, err
 	}
 
-	// Slice of MusicFolders to return
-	folders := make([]MusicFolder, 0)
+	return res.Response.OpenSubsonicExtensions, nil
+}
 
-	// Slice of interfaces to parse out response
-	iface := make([]interface{}, 0)
+// SupportsExtension determines if the server supports the specified version of an
+// OpenSubsonic extension.  Servers which do not implement OpenSubsonic support no extensions.
+func (s Client) SupportsExtension(ctx context.Context, name string, version int64) (bool, error) {
+	extensions, err := s.GetOpenSubsonicExtensions(ctx)
+	if err != nil {
+		// Subsonic reports an error for unknown methods
+		var e *Error
+		if errors.As(err, &e) {
+			return false, nil
+		}
 
-	// Parse response from interface{}, which may be one or more items
-	mf := res.Response.MusicFolders.MusicFolder
-	switch mf.(type) {
-	// Single item
-	case map[string]interface{}:
-		iface = append(iface, mf.(interface{}))
-	// Multiple items
-	case []interface{}:
-		iface = mf.([]interface{})
-	// Unknown case
-	default:
-		return nil, errors.New("gosubsonic: failed to parse getMusicFolders response")
-	}
-
-	// Iterate each item
-	for _, i := range iface {
-		// Type hint to appropriate type
-		if m, ok := i.(map[string]interface{}); ok {
-			// Create a music folder from the map
-			f := MusicFolder{
-				// Note: ID is always an int64, so we can safely convert the float64
-				ID:   int64(m["id"].(float64)),
-				Name: m["name"].(string),
-			}
+		return false, err
+	}
 
-			// Add folder to collection
-			folders = append(folders, f)
+	for _, ext := range extensions {
+		if ext.Name == name {
+			return ext.Supports(version), nil
 		}
 	}
 
-	// Return output folders
-	return folders, nil
+	return false, nil
+}
+
+// -- Browsing --
+
+// GetMusicFolders returns the configured top-level music folders
+func (s Client) GetMusicFolders(ctx context.Context) ([]MusicFolder, error) {
+	// Retrieve top-level music folders from Subsonic
+	res, err := s.get(ctx, s.makeURL("getMusicFolders", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.MusicFolders.MusicFolder, nil
 }
 
-// GetIndexes returns an indexed structure of all artists from Subsonic
-func (s Client) GetIndexes(folderID int64, modified int64) ([]Index, error) {
+// GetIndexes returns an indexed structure of all artists from Subsonic.  If folderID is set
+// (>= 0), only artists in that music folder are returned.  If modifiedSince is set, such as to
+// the LastModified time of previously retrieved indexes, the index is empty unless the indexes
+// were modified since then.
+func (s Client) GetIndexes(ctx context.Context, folderID int64, modifiedSince time.Time) (*Indexes, error) {
 	// Additional parameters for query
-	query := ""
+	params := Params{}
 
 	// Check for a set folder ID (ID >= 0)
 	if folderID >= 0 {
-		query = query + "&musicFolderId=" + strconv.FormatInt(folderID, 10)
+		params.SetInt("musicFolderId", folderID)
 	}
 
-	// Check for a modify time (modified >= 0)
-	if modified >= 0 {
-		query = query + "&ifModifiedSince=" + strconv.FormatInt(modified, 10)
+	// Check for a modify time, in milliseconds
+	if !modifiedSince.IsZero() {
+		params.SetInt("ifModifiedSince", modifiedSince.UnixMilli())
 	}
 
 	// Retrieve indexes from Subsonic, with query parameters
-	res, err := s.source.Get(s.makeURL("getIndexes") + query)
+	res, err := s.get(ctx, s.makeURL("getIndexes", params))
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate new index with proper information
-	outIndex := make([]Index, 0)
-
-	// Slice of interfaces to parse out response
-	iface := make([]interface{}, 0)
+	return &res.Response.Indexes, nil
+}
 
-	// Parse response from interface{}, which may be one or more items
-	idx := res.Response.Indexes.Index
-	switch idx.(type) {
-	// Single item
-	case map[string]interface{}:
-		iface = append(iface, idx.(interface{}))
-	// Multiple items
-	case []interface{}:
-		iface = idx.([]interface{})
-	// Unknown case
-	default:
-		return nil, errors.New("gosubsonic: failed to parse getIndexes response")
+// GetMusicDirectory returns a list of all content in a music directory
+func (s Client) GetMusicDirectory(ctx context.Context, id ID) (*Content, error) {
+	// Retrieve a list of files in a given directory from Subsonic
+	res, err := s.get(ctx, s.makeURL("getMusicDirectory", idParams(id)))
+	if err != nil {
+		return nil, err
 	}
 
-	// Iterate each index item
-	for _, i := range iface {
-		m, ok := i.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		// Create an index
-		index := Index{
-			Name: m["name"].(string),
-			ArtistRaw: m["artist"],
-		}
-
-		// Slice of IndexArtist structs to output
-		artists := make([]IndexArtist, 0)
-
-		// Slice of interfaces to parse out response
-		ifaceArtists := make([]interface{}, 0)
-
-		// Parse response from interface{}, which may be one or more items
-		switch index.ArtistRaw.(type) {
-		// Single item
-		case map[string]interface{}:
-			ifaceArtists = append(ifaceArtists, index.ArtistRaw.(interface{}))
-		// Multiple items
-		case []interface{}:
-			ifaceArtists = index.ArtistRaw.([]interface{})
-		// Unknown case
-		default:
-			return nil, errors.New("gosubsonic: failed to parse getIndexes response")
-		}
+	return &res.Response.Directory.Child, nil
+}
 
-		// Iterate each item
-		for _, ia := range ifaceArtists {
-			// Type hint to appropriate type
-			ma, ok := ia.(map[string]interface{})
-			if !ok {
-				continue
-			}
+// GetArtists returns all artists in the library, grouped into indexes and organized by ID3 tags.
+// If folderID is set (>= 0), only artists in that music folder are returned.
+func (s Client) GetArtists(ctx context.Context, folderID int64) ([]IndexID3, error) {
+	// Check for a set folder ID (ID >= 0)
+	params := Params{}
+	if folderID >= 0 {
+		params.SetInt("musicFolderId", folderID)
+	}
 
-			// Name
-			name, err := ifaceToString(ma["name"])
-			if err != nil {
-				return nil, err
-			}
+	// Retrieve artists from Subsonic
+	res, err := s.get(ctx, s.makeURL("getArtists", params))
+	if err != nil {
+		return nil, err
+	}
 
-			// Create a IndexArtist from map
-			a := IndexArtist{
-				// Note: ID is always an int64, so we can safely convert the float64
-				ID:   int64(ma["id"].(float64)),
-				Name: name,
-			}
+	return res.Response.Artists.Index, nil
+}
 
-			// Add artist to collection
-			artists = append(artists, a)
-		}
+// GetArtist returns details and albums for an artist, organized by ID3 tags
+func (s Client) GetArtist(ctx context.Context, id ID) (*ArtistID3, error) {
+	// Retrieve an artist from Subsonic
+	res, err := s.get(ctx, s.makeURL("getArtist", idParams(id)))
+	if err != nil {
+		return nil, err
+	}
 
-		// Store artists collection in out index, nullify raw values
-		index.ArtistRaw = nil
-		index.Artist = artists
-		outIndex = append(outIndex, index)
+	// Check for an artist in the response
+	if res.Response.Artist == nil {
+		return nil, errors.New("gosubsonic: failed to parse getArtist response")
 	}
 
-	// Return output
-	return outIndex, nil
+	return res.Response.Artist, nil
 }
 
-// GetMusicDirectory returns a list of all content in a music directory
-func (s Client) GetMusicDirectory(folderID int64) (*Content, error) {
-	// Retrieve a list of files in a given directory from Subsonic
-	res, err := s.source.Get(s.makeURL("getMusicDirectory") + "&id=" + strconv.FormatInt(folderID, 10))
+// GetAlbum returns details and songs for an album, organized by ID3 tags
+func (s Client) GetAlbum(ctx context.Context, id ID) (*AlbumID3, error) {
+	// Retrieve an album from Subsonic
+	res, err := s.get(ctx, s.makeURL("getAlbum", idParams(id)))
 	if err != nil {
 		return nil, err
 	}
 
-	// Slice of Audio, Directory, Video structs to return
-	audio := make([]Audio, 0)
-	directories := make([]Directory, 0)
-	video := make([]Video, 0)
-
-	// Slice of interfaces to parse out response
-	iface := make([]interface{}, 0)
-
-	// Parse response from interface{}, which may be one or more items
-	ch := res.Response.Directory.Child
-	switch ch.(type) {
-	// No items
-	case nil:
-		break
-	// Single item
-	case map[string]interface{}:
-		iface = append(iface, ch.(interface{}))
-	// Multiple items
-	case []interface{}:
-		iface = ch.([]interface{})
-	// Unknown case
-	default:
-		return nil, errors.New("gosubsonic: failed to parse getMusicDirectory response")
+	// Check for an album in the response
+	if res.Response.Album == nil {
+		return nil, errors.New("gosubsonic: failed to parse getAlbum response")
 	}
 
-	// Iterate each item
-	for _, i := range iface {
-		// Type hint to appropriate type
-		if m, ok := i.(map[string]interface{}); ok {
-			// First, we have to work out some shared fields between directories and media
+	return res.Response.Album, nil
+}
 
-			// Artist
-			artist, err := ifaceToString(m["artist"])
-			if err != nil {
-				return nil, err
-			}
+// GetSong returns details for a single song
+func (s Client) GetSong(ctx context.Context, id ID) (*Audio, error) {
+	// Retrieve a song from Subsonic
+	res, err := s.get(ctx, s.makeURL("getSong", idParams(id)))
+	if err != nil {
+		return nil, err
+	}
 
-			// Album
-			album, err := ifaceToString(m["album"])
-			if err != nil {
-				return nil, err
-			}
+	if res.Response.Song == nil {
+		return nil, errors.New("gosubsonic: no song found in getSong response")
+	}
 
-			// Title
-			title, err := ifaceToString(m["title"])
-			if err != nil {
-				return nil, err
-			}
+	return res.Response.Song, nil
+}
 
-			// Some albums may not have cover art, so we check individually for it
-			var coverArt int64
-			if c, ok := m["coverArt"].(float64); ok {
-				coverArt = int64(c)
-			}
+// GetGenres returns all genres, with song and album counts for each
+func (s Client) GetGenres(ctx context.Context) ([]Genre, error) {
+	// Retrieve genres from Subsonic
+	res, err := s.get(ctx, s.makeURL("getGenres", nil))
+	if err != nil {
+		return nil, err
+	}
 
-			// Parse CreatedRaw into a time.Time struct
-			created, err := time.Parse("2006-01-02T15:04:05", m["created"].(string))
-			if err != nil {
-				return nil, err
-			}
+	return res.Response.Genres.Genre, nil
+}
 
-			// Is this a directory?
-			if b, ok := m["isDir"].(bool); b && ok {
-				// Create a directory from the map
-				d := Directory{
-					// Note: ID is always an int64, so we can safely convert the float64
-					ID:         int64(m["id"].(float64)),
-					Album:      album,
-					Artist:     artist,
-					CoverArt:   coverArt,
-					Created:    created,
-					CreatedRaw: m["created"].(string),
-					Parent:     int64(m["parent"].(float64)),
-					Title:      title,
-				}
-
-				// Add directory to collection
-				directories = append(directories, d)
-			} else {
-				// If not a directory, this is a media item
-				// Parse shared media field items
-				var id int64
-				if i, ok := m["id"].(float64); ok {
-					id = int64(i)
-				}
-
-				var bitRate int64
-				if b, ok := m["bitRate"].(float64); ok {
-					bitRate = int64(b)
-				}
-
-				var contentType string
-				if c, ok := m["contentType"].(string); ok {
-					contentType = c
-				}
-
-				var createdRaw string
-				if c, ok := m["created"].(string); ok {
-					createdRaw = c
-				}
-
-				var durationRaw int64
-				if d, ok := m["duration"].(float64); ok {
-					durationRaw = int64(d)
-				}
-
-				// Parse DurationRaw into a time.Duration struct
-				duration, err := time.ParseDuration(strconv.FormatInt(durationRaw, 10) + "s")
-				if err != nil {
-					return nil, err
-				}
-
-				var parent int64
-				if p, ok := m["parent"].(float64); ok {
-					parent = int64(p)
-				}
-
-				var path string
-				if p, ok := m["path"].(string); ok {
-					path = html.UnescapeString(p)
-				}
-
-				var size int64
-				if s, ok := m["size"].(float64); ok {
-					size = int64(s)
-				}
-
-				var suffix string
-				if s, ok := m["suffix"].(string); ok {
-					suffix = s
-				}
-
-				var mType string
-				if t, ok := m["type"].(string); ok {
-					mType = t
-				}
-
-				// Returned only in transcodes
-				var transcodedContentType string
-				if t, ok := m["transcodedContentType"].(string); ok {
-					transcodedContentType = t
-				}
-
-				var transcodedSuffix string
-				if t, ok := m["transcodedSuffix"].(string); ok {
-					transcodedSuffix = t
-				}
-
-				// Check if this item is a video
-				if b, ok := m["isVideo"].(bool); b && ok {
-					med := Video{
-						ID:          id,
-						BitRate:     bitRate,
-						ContentType: contentType,
-						CoverArt:    coverArt,
-						Created:     created,
-						CreatedRaw:  createdRaw,
-						Duration:    duration,
-						DurationRaw: durationRaw,
-						Parent:      parent,
-						Path:        path,
-						Size:        size,
-						Suffix:      suffix,
-						Title:       title,
-						TranscodedContentType: transcodedContentType,
-						TranscodedSuffix:      transcodedSuffix,
-					}
-
-					// Add video to collection
-					video = append(video, med)
-				} else {
-					// Else, this is an audio item
-					med := Audio{
-						// Note: ID is always an int64, so we can safely convert the float64
-						ID:          id,
-						Album:       album,
-						Artist:      artist,
-						BitRate:     bitRate,
-						ContentType: contentType,
-						CoverArt:    coverArt,
-						Created:     created,
-						CreatedRaw:  createdRaw,
-						Duration:    duration,
-						DurationRaw: durationRaw,
-						Parent:      parent,
-						Path:        path,
-						Size:        size,
-						Suffix:      suffix,
-						Title:       title,
-						Type:        mType,
-						TranscodedContentType: transcodedContentType,
-						TranscodedSuffix:      transcodedSuffix,
-					}
-
-					// Subsonic is very inconsistent, so we have to check for optional items
-					if a, ok := m["albumId"].(float64); ok {
-						med.AlbumID = int64(a)
-					}
-					if a, ok := m["artistId"].(float64); ok {
-						med.ArtistID = int64(a)
-					}
-					if d, ok := m["discNumber"].(float64); ok {
-						med.DiscNumber = int64(d)
-					}
-					if g, ok := m["genre"].(string); ok {
-						med.Genre = g
-					}
-					if t, ok := m["track"].(float64); ok {
-						med.Track = int64(t)
-					}
-					if y, ok := m["year"].(float64); ok {
-						med.Year = int64(y)
-					}
-
-					// Add audio to collection
-					audio = append(audio, med)
-				}
-			}
-		}
+// GetVideos returns all video files
+func (s Client) GetVideos(ctx context.Context) ([]Video, error) {
+	// Retrieve videos from Subsonic
+	res, err := s.get(ctx, s.makeURL("getVideos", nil))
+	if err != nil {
+		return nil, err
 	}
 
-	// Return output content
-	return &Content{
-		Audio:       audio,
-		Directories: directories,
-		Video:       video,
-	}, nil
+	return res.Response.Videos.Video, nil
 }
 
-// -- Album/song lists --
-
-// GetNowPlaying returns a list of tracks which are currently being played
-func (s Client) GetNowPlaying() ([]NowPlaying, error) {
-	// Retreive all tracks currently playing from Subsonic
-	res, err := s.source.Get(s.makeURL("getNowPlaying"))
+// GetVideoInfo returns the captions, audio tracks, and conversions available for a video
+func (s Client) GetVideoInfo(ctx context.Context, id ID) (*VideoInfo, error) {
+	// Retrieve video info from Subsonic
+	res, err := s.get(ctx, s.makeURL("getVideoInfo", idParams(id)))
 	if err != nil {
 		return nil, err
 	}
 
-	// Subsonic problem: when no songs are playing, the apiNowPlayingContainer will be an empty string
-	// To work around this, we have to check if it's a string and bail out if so
-	if _, ok := res.Response.NowPlaying.(string); ok {
-		return nil, nil
+	// Check for video info in the response
+	if res.Response.VideoInfo == nil {
+		return nil, errors.New("gosubsonic: failed to parse getVideoInfo response")
 	}
 
-	// Slice of NowPlaying structs to return
-	nowPlaying := make([]NowPlaying, 0)
+	return res.Response.VideoInfo, nil
+}
 
-	// Slice of interfaces to parse out response
-	iface := make([]interface{}, 0)
+// GetArtistInfo returns biography, Last.fm, and image details for an artist, organized by file
+// structure, with up to count similar artists.  If includeNotPresent is true, similar artists
+// which are not in the library are included.
+func (s Client) GetArtistInfo(ctx context.Context, id ID, count int64, includeNotPresent bool) (*ArtistInfo, error) {
+	// Retrieve artist info from Subsonic
+	res, err := s.get(ctx, s.makeURL("getArtistInfo", artistInfoParams(id, count, includeNotPresent)))
+	if err != nil {
+		return nil, err
+	}
 
-	// Parse response from interface{}, which may be one or more items
-	en := res.Response.NowPlaying.(map[string]interface{})["entry"]
-	switch en.(type) {
-	// Single item
-	case map[string]interface{}:
-		iface = append(iface, en.(interface{}))
-	// Multiple items
-	case []interface{}:
-		iface = en.([]interface{})
-	// Unknown case
-	default:
-		return nil, errors.New("gosubsonic: failed to parse getNowPlaying response")
+	// Check for artist info in the response
+	if res.Response.ArtistInfo == nil {
+		return nil, errors.New("gosubsonic: failed to parse getArtistInfo response")
 	}
 
-	// Iterate each item
-	for _, i := range iface {
-		// Type hint to appropriate type
-		if m, ok := i.(map[string]interface{}); ok {
-			// Artist
-			artist, err := ifaceToString(m["artist"])
-			if err != nil {
-				return nil, err
-			}
+	return res.Response.ArtistInfo, nil
+}
 
-			// Album
-			album, err := ifaceToString(m["album"])
-			if err != nil {
-				return nil, err
-			}
+// GetArtistInfo2 returns biography, Last.fm, and image details for an artist, organized by ID3
+// tags, with up to count similar artists.  If includeNotPresent is true, similar artists which
+// are not in the library are included.
+func (s Client) GetArtistInfo2(ctx context.Context, id ID, count int64, includeNotPresent bool) (*ArtistInfo2, error) {
+	// Retrieve artist info from Subsonic
+	res, err := s.get(ctx, s.makeURL("getArtistInfo2", artistInfoParams(id, count, includeNotPresent)))
+	if err != nil {
+		return nil, err
+	}
 
-			// Title
-			title, err := ifaceToString(m["title"])
-			if err != nil {
-				return nil, err
-			}
+	// Check for artist info in the response
+	if res.Response.ArtistInfo2 == nil {
+		return nil, errors.New("gosubsonic: failed to parse getArtistInfo2 response")
+	}
 
-			// MusicID
-			_musicID, err := strconv.Atoi(m["id"].(string))
-			if err != nil {
-				return nil, err
-			}
-			musicID := int64(_musicID)
+	return res.Response.ArtistInfo2, nil
+}
 
-			// AlbumID
-			_albumID, err := strconv.Atoi(m["albumId"].(string))
-			if err != nil {
-				return nil, err
-			}
-			albumID := int64(_albumID)
+// artistInfoParams generates parameters for an artist info request
+func artistInfoParams(id ID, count int64, includeNotPresent bool) Params {
+	params := idParams(id)
+	if count > 0 {
+		params.SetInt("count", count)
+	}
+	if includeNotPresent {
+		params.SetBool("includeNotPresent", true)
+	}
 
-			// Parent
-			_parent, err := strconv.Atoi(m["parent"].(string))
-			if err != nil {
-				return nil, err
-			}
-			parent := int64(_parent)
-
-			// Create a now playing entry from the map
-			n := NowPlaying{
-				ID:          musicID,
-				AlbumID:     albumID,
-				Album:       album,
-				Artist:      artist,
-				BitRate:     int64(m["bitRate"].(float64)),
-				ContentType: m["contentType"].(string),
-				CreatedRaw:  m["created"].(string),
-				DiscNumber:  int64(m["discNumber"].(float64)),
-				DurationRaw: int64(m["duration"].(float64)),
-				Genre:       m["genre"].(string),
-				IsDir:       m["isDir"].(bool),
-				MinutesAgo:  int64(m["minutesAgo"].(float64)),
-				Parent:      parent,
-				Path:        m["path"].(string),
-				PlayerID:    int64(m["playerId"].(float64)),
-				Size:        int64(m["size"].(float64)),
-				Suffix:      m["suffix"].(string),
-				Title:       title,
-				Track:       int64(m["track"].(float64)),
-				Year:        int64(m["year"].(float64)),
-			}
+	return params
+}
 
-			// Some albums may not have cover art, so we check individually for it
-			if c, ok := m["coverArt"].(float64); ok {
-				n.CoverArt = int64(c)
-			}
+// GetAlbumInfo returns notes, Last.fm, and image details for an album, organized by file structure
+func (s Client) GetAlbumInfo(ctx context.Context, id ID) (*AlbumInfo, error) {
+	return s.getAlbumInfo(ctx, "getAlbumInfo", id)
+}
 
-			// Parse CreatedRaw into a time.Time struct
-			t, err := time.Parse("2006-01-02T15:04:05Z", n.CreatedRaw)
-			if err != nil {
-				return nil, err
-			}
-			n.Created = t
+// GetAlbumInfo2 returns notes, Last.fm, and image details for an album, organized by ID3 tags
+func (s Client) GetAlbumInfo2(ctx context.Context, id ID) (*AlbumInfo, error) {
+	return s.getAlbumInfo(ctx, "getAlbumInfo2", id)
+}
 
-			// Parse DurationRaw into a time.Duration struct
-			d, err := time.ParseDuration(strconv.FormatInt(n.DurationRaw, 10) + "s")
-			if err != nil {
-				return nil, err
-			}
-			n.Duration = d
+// getAlbumInfo retrieves album info using the specified method
+func (s Client) getAlbumInfo(ctx context.Context, method string, id ID) (*AlbumInfo, error) {
+	// Retrieve album info from Subsonic
+	res, err := s.get(ctx, s.makeURL(method, idParams(id)))
+	if err != nil {
+		return nil, err
+	}
 
-			// Add now playing to collection
-			nowPlaying = append(nowPlaying, n)
-		}
+	// Check for album info in the response
+	if res.Response.AlbumInfo == nil {
+		return nil, fmt.Errorf("gosubsonic: failed to parse %s response", method)
 	}
 
-	// Return output entries
-	return nowPlaying, nil
+	return res.Response.AlbumInfo, nil
 }
 
-// -- Media retrieval --
+// GetSimilarSongs returns up to count random songs by an artist and similar artists, organized
+// by file structure.  The ID may be a song, album, or artist.
+func (s Client) GetSimilarSongs(ctx context.Context, id ID, count int64) ([]Audio, error) {
+	// Retrieve similar songs from Subsonic
+	res, err := s.get(ctx, s.makeURL("getSimilarSongs", similarSongsParams(id, count)))
+	if err != nil {
+		return nil, err
+	}
 
-// StreamOptions represents additional options for the Stream() method
-type StreamOptions struct {
-	MaxBitRate            int64
-	Format                string
-	TimeOffset            int64
-	Size                  string
-	EstimateContentLength bool
+	return res.Response.SimilarSongs.Song, nil
 }
 
-// Stream returns a io.ReadCloser which contains a processed media file stream, with an optional StreamOptions struct
-func (s Client) Stream(id int64, options *StreamOptions) (io.ReadCloser, error) {
-	// Check for no options, which will do a simple stream
-	if options == nil {
-		return fetchBinary(s.makeURL("stream") + "&id=" + strconv.FormatInt(id, 10))
+// GetSimilarSongs2 returns up to count random songs by an artist and similar artists, organized
+// by ID3 tags.  The ID must be an artist.
+func (s Client) GetSimilarSongs2(ctx context.Context, id ID, count int64) ([]Audio, error) {
+	// Retrieve similar songs from Subsonic
+	res, err := s.get(ctx, s.makeURL("getSimilarSongs2", similarSongsParams(id, count)))
+	if err != nil {
+		return nil, err
 	}
 
-	// Check for additional options
-	optStr := ""
+	return res.Response.SimilarSongs2.Song, nil
+}
 
-	// maxBitRate
-	if options.MaxBitRate > 0 {
-		optStr = optStr + "&maxBitRate=" + strconv.FormatInt(options.MaxBitRate, 10)
+// similarSongsParams generates parameters for a similar songs request
+func similarSongsParams(id ID, count int64) Params {
+	params := idParams(id)
+	if count > 0 {
+		params.SetInt("count", count)
 	}
 
-	// format
-	if options.Format != "" {
-		optStr = optStr + "&format=" + options.Format
-	}
+	return params
+}
 
-	// timeOffset
-	if options.TimeOffset > 0 {
-		optStr = optStr + "&timeOffset=" + strconv.FormatInt(options.TimeOffset, 10)
+// GetTopSongs returns up to count of the top songs for an artist, by name, using Last.fm
+func (s Client) GetTopSongs(ctx context.Context, artist string, count int64) ([]Audio, error) {
+	// Check for an artist
+	if artist == "" {
+		return nil, errors.New("gosubsonic: artist is required")
 	}
 
-	// size
-	if options.Size != "" {
-		optStr = optStr + "&size=" + options.Size
+	params := Params{}
+	params.Set("artist", artist)
+	if count > 0 {
+		params.SetInt("count", count)
 	}
 
-	// estimateContentLength
-	if options.EstimateContentLength {
-		optStr = optStr + "&estimateContentLength=true"
+	// Retrieve top songs from Subsonic
+	res, err := s.get(ctx, s.makeURL("getTopSongs", params))
+	if err != nil {
+		return nil, err
 	}
 
-	// Stream with options
-	return fetchBinary(s.makeURL("stream") + "&id=" + strconv.FormatInt(id, 10) + optStr)
+	return res.Response.TopSongs.Song, nil
 }
 
-// Download returns a io.ReadCloser which contains a raw, non-transcoded media file stream
-func (s Client) Download(id int64) (io.ReadCloser, error) {
-	return fetchBinary(s.makeURL("download") + "&id=" + strconv.FormatInt(id, 10))
-}
+// -- Album/song lists --
 
-// GetCoverArt returns a io.ReadCloser which contains a cover art stream, scaled to the specified size
-func (s Client) GetCoverArt(id int64, size int64) (io.ReadCloser, error) {
-	// Check for a non-negative size for image scaling
-	optStr := ""
-	if size > 0 {
-		optStr = optStr + "&size=" + strconv.FormatInt(size, 10)
+// GetNowPlaying returns a list of tracks which are currently being played
+func (s Client) GetNowPlaying(ctx context.Context) ([]NowPlaying, error) {
+	// Retreive all tracks currently playing from Subsonic
+	res, err := s.get(ctx, s.makeURL("getNowPlaying", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	// Subsonic problem: when no songs are playing, the container will be an empty string, and
+	// no entries are returned
+	if len(res.Response.NowPlaying.Entry) == 0 {
+		return nil, nil
 	}
 
-	return fetchBinary(s.makeURL("getCoverArt") + "&id=" + strconv.FormatInt(id, 10) + optStr)
+	return res.Response.NowPlaying.Entry, nil
 }
 
-// -- Media annotation --
+// Album list types for the GetAlbumList() and GetAlbumList2() methods
+const (
+	AlbumListRandom               = "random"
+	AlbumListNewest               = "newest"
+	AlbumListHighest              = "highest"
+	AlbumListFrequent             = "frequent"
+	AlbumListRecent               = "recent"
+	AlbumListStarred              = "starred"
+	AlbumListAlphabeticalByName   = "alphabeticalByName"
+	AlbumListAlphabeticalByArtist = "alphabeticalByArtist"
+	AlbumListByYear               = "byYear"
+	AlbumListByGenre              = "byGenre"
+)
 
-// Scrobble triggers a "Now Playing" or "Submission" request to Last.fm, if configured
-func (s Client) Scrobble(id int64, time int64, submission bool) error {
-	// Build query string
-	optStr := ""
+// albumListTypes is the set of list types accepted by Subsonic
+var albumListTypes = map[string]bool{
+	AlbumListRandom:               true,
+	AlbumListNewest:               true,
+	AlbumListHighest:              true,
+	AlbumListFrequent:             true,
+	AlbumListRecent:               true,
+	AlbumListStarred:              true,
+	AlbumListAlphabeticalByName:   true,
+	AlbumListAlphabeticalByArtist: true,
+	AlbumListByYear:               true,
+	AlbumListByGenre:              true,
+}
 
-	// time (time < 0 means no time)
-	if time > 0 {
-		optStr = optStr + "&time=" + strconv.FormatInt(time, 10)
-	}
+// AlbumListOptions represents additional options for the GetAlbumList() and GetAlbumList2() methods
+type AlbumListOptions struct {
+	// Size is the number of albums to return, default 10, maximum 500
+	Size int64
 
-	// submission (true: Submission, false: NowPlaying)
-	if submission {
-		optStr = optStr + "&submission=true"
-	} else {
-		optStr = optStr + "&submission=false"
-	}
+	// Offset is the number of albums to skip, for pagination
+	Offset int64
 
-	// Send a scrobble request to Subsonic
-	_, err := s.source.Get(s.makeURL("scrobble") + "&id=" + strconv.FormatInt(id, 10) + optStr)
-	return err
-}
+	// FromYear and ToYear are required for the byYear list type
+	FromYear int64
+	ToYear   int64
 
-// -- Functions --
+	// Genre is required for the byGenre list type
+	Genre string
 
-// makeURL Generates a URL for an API call using given parameters and method
-func (s Client) makeURL(method string) string {
-	return fmt.Sprintf("http://%s/rest/%s.view?u=%s&p=%s&c=%s&v=%s&f=json",
-		s.Host, method, s.Username, s.Password, CLIENT, APIVERSION)
+	// MusicFolderID limits the list to a music folder, if set (>= 1)
+	MusicFolderID int64
 }
 
-// fetchBinary retrieves a binary stream from a specified URL and returns a io.ReadCloser on the stream
-func fetchBinary(url string) (io.ReadCloser, error) {
-	// Perform HTTP GET request
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("gosubsonic: HTTP request failed: %s - %s", err.Error(), url)
+// query validates a list type, and generates parameters for an album list with any
+// additional options
+func (o *AlbumListOptions) query(listType string) (Params, error) {
+	if !albumListTypes[listType] {
+		return nil, fmt.Errorf("gosubsonic: invalid album list type: %s", listType)
 	}
 
-	// Check for JSON content type, meaning file is not binary
-	if strings.Contains(res.Header.Get("Content-Type"), "application/json") {
-		// Read the entire response body, and defer it to be closed
-		body, err := ioutil.ReadAll(res.Body)
-		defer res.Body.Close()
+	params := Params{}
+	params.Set("type", listType)
 
-		// Unmarshal response JSON from API container
-		var subRes apiContainer
-		err = json.Unmarshal(body, &subRes)
-		if err != nil {
-			return nil, fmt.Errorf("gosubsonic: failed to parse response JSON: %s - %s", err.Error(), url)
+	// Use empty options if none are set, so required options are still checked
+	opts := AlbumListOptions{}
+	if o != nil {
+		opts = *o
+	}
+
+	// byYear requires a range of years
+	if listType == AlbumListByYear {
+		if opts.FromYear <= 0 || opts.ToYear <= 0 {
+			return nil, errors.New("gosubsonic: byYear album list requires FromYear and ToYear")
 		}
 
-		// Return the error
-		return nil, fmt.Errorf("gosubsonic: %d: %s", subRes.Response.Error.Code, subRes.Response.Error.Message)
+		params.SetInt("fromYear", opts.FromYear)
+		params.SetInt("toYear", opts.ToYear)
 	}
 
-	// Return response reader for body
-	return res.Body, nil
-}
+	// byGenre requires a genre
+	if listType == AlbumListByGenre {
+		if opts.Genre == "" {
+			return nil, errors.New("gosubsonic: byGenre album list requires Genre")
+		}
 
-// httpDataSource represents a HTTP data source for a Subsonic client
-type httpDataSource struct {
+		params.Set("genre", opts.Genre)
+	}
+
+	if opts.Size > 0 {
+		params.SetInt("size", opts.Size)
+	}
+	if opts.Offset > 0 {
+		params.SetInt("offset", opts.Offset)
+	}
+	if opts.MusicFolderID > 0 {
+		params.SetInt("musicFolderId", opts.MusicFolderID)
+	}
+
+	return params, nil
 }
 
-// Get retrieves JSON from HTTP with a specified URL, and parses it into an apiContainer
-func (s httpDataSource) Get(url string) (*apiContainer, error) {
-	res, err := http.Get(url)
+// GetAlbumList returns a list of albums organized by file structure, of the specified list
+// type, with an optional AlbumListOptions struct
+func (s Client) GetAlbumList(ctx context.Context, listType string, options *AlbumListOptions) ([]Directory, error) {
+	params, err := options.query(listType)
 	if err != nil {
-		return nil, fmt.Errorf("gosubsonic: HTTP request failed: %s - %s", err.Error(), url)
+		return nil, err
 	}
 
-	// Read the entire response body
-	out, err := ioutil.ReadAll(res.Body)
+	// Retrieve an album list from Subsonic
+	res, err := s.get(ctx, s.makeURL("getAlbumList", params))
 	if err != nil {
 		return nil, err
 	}
 
-	// Close response body
-	if err := res.Body.Close(); err != nil {
+	return res.Response.AlbumList.Album, nil
+}
+
+// GetAlbumList2 returns a list of albums organized by ID3 tags, of the specified list type,
+// with an optional AlbumListOptions struct
+func (s Client) GetAlbumList2(ctx context.Context, listType string, options *AlbumListOptions) ([]AlbumID3, error) {
+	params, err := options.query(listType)
+	if err != nil {
 		return nil, err
 	}
 
-	// Return apiContainer
-	return processJSON(out)
-}
+	// Retrieve an album list from Subsonic
+	res, err := s.get(ctx, s.makeURL("getAlbumList2", params))
+	if err != nil {
+		return nil, err
+	}
 
-// mockDataSource represents a mock data source for a Subsonic client
-type mockDataSource struct {
+	return res.Response.AlbumList2.Album, nil
 }
 
-// Get retrieves JSON from mock data with a specified URL, and parses it into an apiContainer
-func (s mockDataSource) Get(url string) (*apiContainer, error) {
-	// Get mock data from map
-	res, ok := mockData[url]
-	if !ok {
-		return nil, fmt.Errorf("gosubsonic: No mock data: %s", url)
-	}
+// RandomSongsOptions represents additional options for the GetRandomSongs() method
+type RandomSongsOptions struct {
+	// Size is the number of songs to return, default 10, maximum 500
+	Size int64
+
+	// Genre limits songs to a genre, if set
+	Genre string
 
-	// Return apiContainer
-	return processJSON(res)
+	// FromYear and ToYear limit songs to a range of years, if set
+	FromYear int64
+	ToYear   int64
+
+	// MusicFolderID limits songs to a music folder, if set (>= 1)
+	MusicFolderID int64
 }
 
-// processJSON parses raw JSON into an apiContainer
-func processJSON(body []byte) (*apiContainer, error) {
-	// Unmarshal response JSON from API container
-	var subRes apiContainer
-	if err := json.Unmarshal(body, &subRes); err != nil {
-		return nil, fmt.Errorf("gosubsonic: failed to parse response JSON: %s", err.Error())
+// GetRandomSongs returns random songs, with an optional RandomSongsOptions struct
+func (s Client) GetRandomSongs(ctx context.Context, options *RandomSongsOptions) ([]Audio, error) {
+	// Add any additional options
+	params := Params{}
+	if options != nil {
+		if options.Size > 0 {
+			params.SetInt("size", options.Size)
+		}
+		if options.Genre != "" {
+			params.Set("genre", options.Genre)
+		}
+		if options.FromYear > 0 {
+			params.SetInt("fromYear", options.FromYear)
+		}
+		if options.ToYear > 0 {
+			params.SetInt("toYear", options.ToYear)
+		}
+		if options.MusicFolderID > 0 {
+			params.SetInt("musicFolderId", options.MusicFolderID)
+		}
 	}
 
-	// Check for any errors in response object
-	if subRes.Response.Error != (APIError{}) {
-		// Report error and code
-		return nil, fmt.Errorf("gosubsonic: %d: %s", subRes.Response.Error.Code, subRes.Response.Error.Message)
+	// Retrieve random songs from Subsonic
+	res, err := s.get(ctx, s.makeURL("getRandomSongs", params))
+	if err != nil {
+		return nil, err
 	}
 
-	// Return the response container
-	return &subRes, nil
+	return res.Response.RandomSongs.Song, nil
 }
 
-// ifaceToString attempts to convert an interface type to its string representation
-func ifaceToString(data interface{}) (string, error) {
-	// There are many cases in Subsonic's XML-to-JSON converter fails to properly
-	// handle certain conversions properly.  To account for this, this function will
-	// turn types into their string representation.
-
-	// Some issues found so far:
-	//   - Numeric artist/title (311, etc) will return as a float64
-	//   - Boolean artist/title (True, false), etc will return as a boolean
-	//   - String artist/title, etc must have HTML unescaped
-	switch data.(type) {
-	case nil:
-		return "", nil
-	case bool:
-		if data.(bool) {
-			return "True", nil
+// SongsByGenreOptions represents additional options for the GetSongsByGenre() method
+type SongsByGenreOptions struct {
+	// Count is the number of songs to return, default 10, maximum 500
+	Count int64
+
+	// Offset is the number of songs to skip, for pagination
+	Offset int64
+
+	// MusicFolderID limits songs to a music folder, if set (>= 1)
+	MusicFolderID int64
+}
+
+// GetSongsByGenre returns songs in a genre, with an optional SongsByGenreOptions struct
+func (s Client) GetSongsByGenre(ctx context.Context, genre string, options *SongsByGenreOptions) ([]Audio, error) {
+	// Genre is required
+	if genre == "" {
+		return nil, errors.New("gosubsonic: genre is required")
+	}
+
+	// Add any additional options
+	params := Params{}
+	params.Set("genre", genre)
+	if options != nil {
+		if options.Count > 0 {
+			params.SetInt("count", options.Count)
+		}
+		if options.Offset > 0 {
+			params.SetInt("offset", options.Offset)
+		}
+		if options.MusicFolderID > 0 {
+			params.SetInt("musicFolderId", options.MusicFolderID)
+		}
+	}
+
+	// Retrieve songs from Subsonic
+	res, err := s.get(ctx, s.makeURL("getSongsByGenre", params))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.SongsByGenre.Song, nil
+}
+
+// -- Searching --
+
+// SearchOptions represents additional options for the Search2() and Search3() methods.
+// Zero counts use the server's default of 20 results per category, and negative counts
+// omit a category from the results.
+type SearchOptions struct {
+	ArtistCount  int64
+	ArtistOffset int64
+	AlbumCount   int64
+	AlbumOffset  int64
+	SongCount    int64
+	SongOffset   int64
+
+	// MusicFolderID limits results to a music folder, if set (>= 1).  Requires Subsonic
+	// 1.12.0+.
+	MusicFolderID int64
+}
+
+// query generates parameters for a search, with any additional options
+func (o *SearchOptions) query(query string) Params {
+	params := Params{}
+	params.Set("query", query)
+	if o == nil {
+		return params
+	}
+
+	// Counts and offsets for each category
+	counts := []struct {
+		name  string
+		value int64
+	}{
+		{"artistCount", o.ArtistCount},
+		{"artistOffset", o.ArtistOffset},
+		{"albumCount", o.AlbumCount},
+		{"albumOffset", o.AlbumOffset},
+		{"songCount", o.SongCount},
+		{"songOffset", o.SongOffset},
+	}
+
+	for _, c := range counts {
+		switch {
+		case c.value > 0:
+			params.SetInt(c.name, c.value)
+		case c.value < 0 && strings.HasSuffix(c.name, "Count"):
+			params.SetInt(c.name, 0)
+		}
+	}
+
+	if o.MusicFolderID > 0 {
+		params.SetInt("musicFolderId", o.MusicFolderID)
+	}
+
+	return params
+}
+
+// Search2 returns artists, albums, and songs matching a query, organized by file structure,
+// with an optional SearchOptions struct
+func (s Client) Search2(ctx context.Context, query string, options *SearchOptions) (*SearchResult2, error) {
+	// Retrieve search results from Subsonic
+	res, err := s.get(ctx, s.makeURL("search2", options.query(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.SearchResult2, nil
+}
+
+// Search3 returns artists, albums, and songs matching a query, organized by ID3 tags,
+// with an optional SearchOptions struct
+func (s Client) Search3(ctx context.Context, query string, options *SearchOptions) (*SearchResult3, error) {
+	// Retrieve search results from Subsonic
+	res, err := s.get(ctx, s.makeURL("search3", options.query(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.SearchResult3, nil
+}
+
+// -- Playlists --
+
+// GetPlaylists returns all playlists a user is allowed to play.  If username is empty,
+// playlists for the current user are returned.
+func (s Client) GetPlaylists(ctx context.Context, username string) ([]Playlist, error) {
+	// Check for a specified username, which requires admin privileges
+	params := Params{}
+	if username != "" {
+		params.Set("username", username)
+	}
+
+	// Retrieve playlists from Subsonic
+	res, err := s.get(ctx, s.makeURL("getPlaylists", params))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.Playlists.Playlist, nil
+}
+
+// GetPlaylist returns a playlist, including all of its entries
+func (s Client) GetPlaylist(ctx context.Context, id ID) (*Playlist, error) {
+	// Retrieve a playlist from Subsonic
+	res, err := s.get(ctx, s.makeURL("getPlaylist", idParams(id)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for a playlist in the response
+	if res.Response.Playlist == nil {
+		return nil, errors.New("gosubsonic: failed to parse getPlaylist response")
+	}
+
+	return res.Response.Playlist, nil
+}
+
+// CreatePlaylist creates a playlist with the specified name, containing the specified songs.
+// Servers implementing API version 1.14.0 and newer return the new playlist; for older
+// servers, the returned playlist is nil.
+func (s Client) CreatePlaylist(ctx context.Context, name string, songIDs []ID) (*Playlist, error) {
+	// Build query parameters
+	params := Params{}
+	params.Set("name", name)
+	params.AddIDs("songId", songIDs)
+
+	// Send a playlist creation request to Subsonic
+	res, err := s.get(ctx, s.makeURL("createPlaylist", params))
+	if err != nil {
+		return nil, err
+	}
+
+	// Older servers do not return the new playlist, so this may be nil
+	return res.Response.Playlist, nil
+}
+
+// UpdatePlaylistOptions represents additional options for the UpdatePlaylist() method
+type UpdatePlaylistOptions struct {
+	Name    string
+	Comment string
+	Public  *bool
+
+	// Songs to append to the end of the playlist
+	SongIDToAdd []ID
+
+	// Zero-based indices of entries to remove, relative to the playlist before any changes
+	SongIndexToRemove []int64
+}
+
+// UpdatePlaylist updates a playlist's details, and adds or removes entries
+func (s Client) UpdatePlaylist(ctx context.Context, id ID, options UpdatePlaylistOptions) error {
+	// Build query parameters
+	params := Params{}
+	params.Set("playlistId", string(id))
+
+	// name
+	if options.Name != "" {
+		params.Set("name", options.Name)
+	}
+
+	// comment
+	if options.Comment != "" {
+		params.Set("comment", options.Comment)
+	}
+
+	// public
+	if options.Public != nil {
+		params.SetBool("public", *options.Public)
+	}
+
+	// songIdToAdd
+	params.AddIDs("songIdToAdd", options.SongIDToAdd)
+
+	// songIndexToRemove
+	params.AddInts("songIndexToRemove", options.SongIndexToRemove)
+
+	// Send a playlist update request to Subsonic
+	_, err := s.get(ctx, s.makeURL("updatePlaylist", params))
+	return err
+}
+
+// DeletePlaylist deletes a playlist
+func (s Client) DeletePlaylist(ctx context.Context, id ID) error {
+	_, err := s.get(ctx, s.makeURL("deletePlaylist", idParams(id)))
+	return err
+}
+
+// -- Media retrieval --
+
+// StreamOptions represents additional options for the Stream() method
+type StreamOptions struct {
+	MaxBitRate            int64
+	Format                string
+	TimeOffset            int64
+	Size                  string
+	EstimateContentLength bool
+
+	// SkipScrobble opts out of the "Now Playing" scrobble submitted when Client.AutoScrobble is set
+	SkipScrobble bool
+}
+
+// StreamResponse represents a processed media file stream, along with metadata about the
+// stream reported by Subsonic.  It may be read and closed directly.
+type StreamResponse struct {
+	io.ReadCloser
+
+	// ContentType is the MIME type of the stream, which differs from the original file's
+	// when the stream is transcoded
+	ContentType string
+
+	// ContentLength is the length of the stream in bytes, or -1 if unknown.  Transcoded
+	// streams only report a length when StreamOptions.EstimateContentLength is set.
+	ContentLength int64
+
+	// Duration is the estimated duration of the stream, or 0 if unknown
+	Duration time.Duration
+}
+
+// Stream returns a StreamResponse which contains a processed media file stream, with an optional StreamOptions struct
+func (s Client) Stream(ctx context.Context, id ID, options *StreamOptions) (*StreamResponse, error) {
+	res, err := s.fetchBinaryResponse(ctx, s.streamURL(id, options), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.autoScrobble(ctx, id, options)
+	return &StreamResponse{
+		ReadCloser:    res.Body,
+		ContentType:   res.Header.Get("Content-Type"),
+		ContentLength: res.ContentLength,
+		Duration:      streamDuration(res, options),
+	}, nil
+}
+
+// streamDuration estimates the duration of a stream, using the duration reported by Subsonic
+// for transcoded streams, or else the content length and maximum bit rate
+func streamDuration(res *http.Response, options *StreamOptions) time.Duration {
+	// Subsonic reports the duration in seconds, with fractional seconds
+	if d, err := strconv.ParseFloat(res.Header.Get("X-Content-Duration"), 64); err == nil && d > 0 {
+		return time.Duration(d * float64(time.Second))
+	}
+
+	if options == nil || options.MaxBitRate <= 0 || res.ContentLength <= 0 {
+		return 0
+	}
+
+	// Bit rate is specified in Kbps
+	bytesPerSecond := options.MaxBitRate * 1000 / 8
+	return time.Duration(res.ContentLength) * time.Second / time.Duration(bytesPerSecond)
+}
+
+// autoScrobble submits a "Now Playing" scrobble for a stream, if enabled and not skipped
+// for this stream
+func (s Client) autoScrobble(ctx context.Context, id ID, options *StreamOptions) {
+	if s.AutoScrobble && (options == nil || !options.SkipScrobble) {
+		// Now playing status is best effort, and should never prevent playback
+		_ = s.Scrobble(ctx, id, -1, false)
+	}
+}
+
+// streamURL generates a stream URL for the specified ID, with an optional StreamOptions struct
+func (s Client) streamURL(id ID, options *StreamOptions) string {
+	// Check for no options, which will do a simple stream
+	params := idParams(id)
+	if options == nil {
+		return s.makeURL("stream", params)
+	}
+
+	// maxBitRate
+	if options.MaxBitRate > 0 {
+		params.SetInt("maxBitRate", options.MaxBitRate)
+	}
+
+	// format
+	if options.Format != "" {
+		params.Set("format", options.Format)
+	}
+
+	// timeOffset
+	if options.TimeOffset > 0 {
+		params.SetInt("timeOffset", options.TimeOffset)
+	}
+
+	// size
+	if options.Size != "" {
+		params.Set("size", options.Size)
+	}
+
+	// estimateContentLength
+	if options.EstimateContentLength {
+		params.SetBool("estimateContentLength", true)
+	}
+
+	// Stream with options
+	return s.makeURL("stream", params)
+}
+
+// Download returns a io.ReadCloser which contains a raw, non-transcoded media file stream
+func (s Client) Download(ctx context.Context, id ID) (io.ReadCloser, error) {
+	return s.fetchBinary(ctx, s.makeURL("download", idParams(id)))
+}
+
+// HLSOptions represents additional options for the HLS() method
+type HLSOptions struct {
+	// BitRate lists the bitrates in Kbps to include in the playlist.  If more than one is
+	// specified, a variant playlist is returned for adaptive streaming.
+	BitRate []int64
+
+	// AudioTrack is the ID of the audio track to use, from GetVideoInfo
+	AudioTrack ID
+}
+
+// HLS returns a io.ReadCloser which contains an HTTP Live Streaming (m3u8) playlist for a video,
+// with an optional HLSOptions struct
+func (s Client) HLS(ctx context.Context, id ID, options *HLSOptions) (io.ReadCloser, error) {
+	return s.fetchBinary(ctx, s.hlsURL(id, options))
+}
+
+// hlsURL generates an HLS playlist URL for the specified ID, with an optional HLSOptions struct
+func (s Client) hlsURL(id ID, options *HLSOptions) string {
+	// HLS playlists are served with an m3u8 extension, instead of the usual view extension
+	params := idParams(id)
+	if options != nil {
+		params.AddInts("bitRate", options.BitRate)
+
+		if options.AudioTrack != "" {
+			params.Set("audioTrack", string(options.AudioTrack))
+		}
+	}
+
+	return strings.Replace(s.makeURL("hls", params), "/hls.view?", "/hls.m3u8?", 1)
+}
+
+// GetCaptions returns a io.ReadCloser which contains captions for a video.  Format may be
+// "srt" or "vtt", or empty for the captions' original format.
+func (s Client) GetCaptions(ctx context.Context, id ID, format string) (io.ReadCloser, error) {
+	params := idParams(id)
+	if format != "" {
+		params.Set("format", format)
+	}
+
+	return s.fetchBinary(ctx, s.makeURL("getCaptions", params))
+}
+
+// GetLyrics searches for and returns lyrics for a song, by artist and title.  If no lyrics
+// are found, a Lyrics struct with empty text is returned.
+func (s Client) GetLyrics(ctx context.Context, artist string, title string) (*Lyrics, error) {
+	// Build query parameters
+	params := Params{}
+	if artist != "" {
+		params.Set("artist", artist)
+	}
+	if title != "" {
+		params.Set("title", title)
+	}
+
+	// Retrieve lyrics from Subsonic
+	res, err := s.get(ctx, s.makeURL("getLyrics", params))
+	if err != nil {
+		return nil, err
+	}
+
+	// Subsonic problem: when no lyrics are found, lyrics may be an empty string or object
+	if res.Response.Lyrics == nil {
+		return &Lyrics{}, nil
+	}
+
+	return res.Response.Lyrics, nil
+}
+
+// GetAvatar returns a io.ReadCloser which contains the avatar image for a user
+func (s Client) GetAvatar(ctx context.Context, username string) (io.ReadCloser, error) {
+	return s.fetchBinary(ctx, s.makeURL("getAvatar", usernameParams(username)))
+}
+
+// GetCoverArt returns a io.ReadCloser which contains a cover art stream, scaled to the specified size
+func (s Client) GetCoverArt(ctx context.Context, id ID, size int64) (io.ReadCloser, error) {
+	return s.fetchBinary(ctx, s.coverArtURL(id, size))
+}
+
+// coverArtURL generates a cover art URL for the specified ID, scaled to the specified size
+func (s Client) coverArtURL(id ID, size int64) string {
+	// Check for a non-negative size for image scaling
+	params := idParams(id)
+	if size > 0 {
+		params.SetInt("size", size)
+	}
+
+	return s.makeURL("getCoverArt", params)
+}
+
+// -- Media annotation --
+
+// Scrobble triggers a "Now Playing" or "Submission" request to Last.fm, if configured
+func (s Client) Scrobble(ctx context.Context, id ID, time int64, submission bool) error {
+	// Build query parameters
+	params := idParams(id)
+
+	// time (time < 0 means no time)
+	if time > 0 {
+		params.SetInt("time", time)
+	}
+
+	// submission (true: Submission, false: NowPlaying)
+	params.SetBool("submission", submission)
+
+	// Send a scrobble request to Subsonic
+	_, err := s.get(ctx, s.makeURL("scrobble", params))
+	return err
+}
+
+// StarOptions represents the media items to star or unstar with the Star() and Unstar()
+// methods.  Songs, folders, albums, and artists may be mixed in a single request.
+type StarOptions struct {
+	// IDs of songs or folders
+	IDs []ID
+
+	// AlbumIDs of albums, organized by ID3 tags
+	AlbumIDs []ID
+
+	// ArtistIDs of artists, organized by ID3 tags
+	ArtistIDs []ID
+}
+
+// query generates parameters for a star or unstar request
+func (o StarOptions) query() (Params, error) {
+	// Check for at least one ID
+	if len(o.IDs)+len(o.AlbumIDs)+len(o.ArtistIDs) == 0 {
+		return nil, errors.New("gosubsonic: at least one ID is required to star or unstar")
+	}
+
+	params := Params{}
+	params.AddIDs("id", o.IDs)
+	params.AddIDs("albumId", o.AlbumIDs)
+	params.AddIDs("artistId", o.ArtistIDs)
+
+	return params, nil
+}
+
+// Star attaches a star to one or more songs, folders, albums, or artists
+func (s Client) Star(ctx context.Context, options StarOptions) error {
+	params, err := options.query()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.get(ctx, s.makeURL("star", params))
+	return err
+}
+
+// Unstar removes a star from one or more songs, folders, albums, or artists
+func (s Client) Unstar(ctx context.Context, options StarOptions) error {
+	params, err := options.query()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.get(ctx, s.makeURL("unstar", params))
+	return err
+}
+
+// SetRating sets the rating of a media item, from 1 to 5.  A rating of 0 removes the rating.
+func (s Client) SetRating(ctx context.Context, id ID, rating int) error {
+	// Check for a valid rating
+	if rating < 0 || rating > 5 {
+		return errors.New("gosubsonic: rating must be between 0 and 5")
+	}
+
+	params := idParams(id)
+	params.SetInt("rating", int64(rating))
+
+	_, err := s.get(ctx, s.makeURL("setRating", params))
+	return err
+}
+
+// StarredOptions represents additional options for the GetStarred() and GetStarred2() methods
+type StarredOptions struct {
+	// MusicFolderID limits starred items to a music folder, if set (>= 1).  Requires
+	// Subsonic 1.12.0+.
+	MusicFolderID int64
+}
+
+// query generates parameters for starred items, with any additional options
+func (o *StarredOptions) query() Params {
+	params := Params{}
+	if o != nil && o.MusicFolderID > 0 {
+		params.SetInt("musicFolderId", o.MusicFolderID)
+	}
+
+	return params
+}
+
+// GetStarred returns all starred artists, albums, and songs, organized by file structure,
+// with an optional StarredOptions struct
+func (s Client) GetStarred(ctx context.Context, options *StarredOptions) (*Starred, error) {
+	// Retrieve starred items from Subsonic
+	res, err := s.get(ctx, s.makeURL("getStarred", options.query()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.Starred, nil
+}
+
+// GetStarred2 returns all starred artists, albums, and songs, organized by ID3 tags, with an
+// optional StarredOptions struct
+func (s Client) GetStarred2(ctx context.Context, options *StarredOptions) (*Starred2, error) {
+	// Retrieve starred items from Subsonic
+	res, err := s.get(ctx, s.makeURL("getStarred2", options.query()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.Starred2, nil
+}
+
+// -- Sharing --
+
+// GetShares returns all shares created by the current user
+func (s Client) GetShares(ctx context.Context) ([]Share, error) {
+	// Retrieve shares from Subsonic
+	res, err := s.get(ctx, s.makeURL("getShares", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.Shares.Share, nil
+}
+
+// CreateShare creates a public share for one or more media IDs, with an optional description
+// and expiration time.  A zero expiration time creates a share which never expires.
+func (s Client) CreateShare(ctx context.Context, ids []ID, description string, expires time.Time) (*Share, error) {
+	// Check for at least one ID to share
+	if len(ids) == 0 {
+		return nil, errors.New("gosubsonic: at least one ID is required to create a share")
+	}
+
+	// Build query parameters
+	params := Params{}
+	params.AddIDs("id", ids)
+
+	// description
+	if description != "" {
+		params.Set("description", description)
+	}
+
+	// expires, in milliseconds since the Unix epoch
+	if !expires.IsZero() {
+		params.SetInt("expires", expires.UnixMilli())
+	}
+
+	// Send a share creation request to Subsonic
+	res, err := s.get(ctx, s.makeURL("createShare", params))
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for the newly created share
+	shares := res.Response.Shares.Share
+	if len(shares) == 0 {
+		return nil, errors.New("gosubsonic: no share found in createShare response")
+	}
+
+	return &shares[0], nil
+}
+
+// UpdateShareOptions represents the details to change in the UpdateShare() method.  Fields
+// which are not set are left unchanged.
+type UpdateShareOptions struct {
+	Description string
+
+	// Expires is the new expiration time of the share.  A zero time removes any expiration,
+	// so the share never expires.
+	Expires *time.Time
+}
+
+// UpdateShare updates the description and expiration time of a share
+func (s Client) UpdateShare(ctx context.Context, id ID, options UpdateShareOptions) error {
+	// Build query parameters
+	params := Params{}
+	params.Set("id", string(id))
+
+	// description
+	if options.Description != "" {
+		params.Set("description", options.Description)
+	}
+
+	// expires, in milliseconds since the Unix epoch, where 0 means no expiration
+	if options.Expires != nil {
+		var ms int64
+		if !options.Expires.IsZero() {
+			ms = options.Expires.UnixMilli()
+		}
+		params.SetInt("expires", ms)
+	}
+
+	_, err := s.get(ctx, s.makeURL("updateShare", params))
+	return err
+}
+
+// DeleteShare deletes a share
+func (s Client) DeleteShare(ctx context.Context, id ID) error {
+	params := Params{}
+	params.Set("id", string(id))
+
+	_, err := s.get(ctx, s.makeURL("deleteShare", params))
+	return err
+}
+
+// -- Podcast --
+
+// GetPodcasts returns all podcast channels the server subscribes to.  If id is set,
+// only that channel is returned.  Episodes are included only if includeEpisodes is true.
+func (s Client) GetPodcasts(ctx context.Context, includeEpisodes bool, id ID) ([]PodcastChannel, error) {
+	// Build query parameters
+	params := Params{}
+	params.SetBool("includeEpisodes", includeEpisodes)
+	if id != "" {
+		params.Set("id", string(id))
+	}
+
+	// Retrieve podcasts from Subsonic
+	res, err := s.get(ctx, s.makeURL("getPodcasts", params))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.Podcasts.Channel, nil
+}
+
+// RefreshPodcasts requests the server to check for new podcast episodes
+func (s Client) RefreshPodcasts(ctx context.Context) error {
+	_, err := s.get(ctx, s.makeURL("refreshPodcasts", nil))
+	return err
+}
+
+// CreatePodcastChannel adds a new podcast channel, using its feed URL
+func (s Client) CreatePodcastChannel(ctx context.Context, feedURL string) error {
+	// Check for a feed URL
+	if feedURL == "" {
+		return errors.New("gosubsonic: a feed URL is required to create a podcast channel")
+	}
+
+	params := Params{}
+	params.Set("url", feedURL)
+
+	_, err := s.get(ctx, s.makeURL("createPodcastChannel", params))
+	return err
+}
+
+// DeletePodcastChannel deletes a podcast channel
+func (s Client) DeletePodcastChannel(ctx context.Context, id ID) error {
+	_, err := s.get(ctx, s.makeURL("deletePodcastChannel", idParams(id)))
+	return err
+}
+
+// DeletePodcastEpisode deletes a podcast episode
+func (s Client) DeletePodcastEpisode(ctx context.Context, id ID) error {
+	_, err := s.get(ctx, s.makeURL("deletePodcastEpisode", idParams(id)))
+	return err
+}
+
+// DownloadPodcastEpisode requests the server to start downloading a podcast episode
+func (s Client) DownloadPodcastEpisode(ctx context.Context, id ID) error {
+	_, err := s.get(ctx, s.makeURL("downloadPodcastEpisode", idParams(id)))
+	return err
+}
+
+// -- Jukebox --
+
+// JukeboxGet returns the current jukebox playlist, along with the jukebox status
+func (s Client) JukeboxGet(ctx context.Context) (*JukeboxPlaylist, error) {
+	// Retrieve the jukebox playlist from Subsonic
+	params := Params{}
+	params.Set("action", "get")
+
+	res, err := s.get(ctx, s.makeURL("jukeboxControl", params))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.JukeboxPlaylist, nil
+}
+
+// JukeboxStatus returns the current jukebox status
+func (s Client) JukeboxStatus(ctx context.Context) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "status", nil)
+}
+
+// JukeboxSet replaces the jukebox playlist with the specified media IDs
+func (s Client) JukeboxSet(ctx context.Context, ids []ID) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "set", idsParams(ids))
+}
+
+// JukeboxStart starts jukebox playback
+func (s Client) JukeboxStart(ctx context.Context) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "start", nil)
+}
+
+// JukeboxStop stops jukebox playback
+func (s Client) JukeboxStop(ctx context.Context) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "stop", nil)
+}
+
+// JukeboxSkip skips to the specified playlist index, at the specified offset in seconds
+func (s Client) JukeboxSkip(ctx context.Context, index int64, offset int64) (*JukeboxStatus, error) {
+	// Build query parameters
+	params := Params{}
+	params.SetInt("index", index)
+
+	// offset (offset <= 0 means start of track)
+	if offset > 0 {
+		params.SetInt("offset", offset)
+	}
+
+	return s.jukeboxControl(ctx, "skip", params)
+}
+
+// JukeboxAdd appends the specified media IDs to the jukebox playlist
+func (s Client) JukeboxAdd(ctx context.Context, ids []ID) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "add", idsParams(ids))
+}
+
+// JukeboxClear removes all items from the jukebox playlist
+func (s Client) JukeboxClear(ctx context.Context) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "clear", nil)
+}
+
+// JukeboxRemove removes the item at the specified index from the jukebox playlist
+func (s Client) JukeboxRemove(ctx context.Context, index int64) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "remove", Params{"index": {strconv.FormatInt(index, 10)}})
+}
+
+// JukeboxShuffle randomly shuffles the jukebox playlist
+func (s Client) JukeboxShuffle(ctx context.Context) (*JukeboxStatus, error) {
+	return s.jukeboxControl(ctx, "shuffle", nil)
+}
+
+// JukeboxSetGain sets the jukebox volume, between 0.0 and 1.0
+func (s Client) JukeboxSetGain(ctx context.Context, gain float64) (*JukeboxStatus, error) {
+	// Check for a gain within the valid range
+	if gain < 0 || gain > 1 {
+		return nil, errors.New("gosubsonic: jukebox gain must be between 0.0 and 1.0")
+	}
+
+	return s.jukeboxControl(ctx, "setGain", Params{"gain": {strconv.FormatFloat(gain, 'f', 2, 64)}})
+}
+
+// jukeboxControl performs a jukebox action which returns the jukebox status
+func (s Client) jukeboxControl(ctx context.Context, action string, params Params) (*JukeboxStatus, error) {
+	params = params.clone()
+	params.Set("action", action)
+
+	// Send a jukebox control request to Subsonic
+	res, err := s.get(ctx, s.makeURL("jukeboxControl", params))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.JukeboxStatus, nil
+}
+
+// -- Internet radio --
+
+// GetInternetRadioStations returns all internet radio stations
+func (s Client) GetInternetRadioStations(ctx context.Context) ([]InternetRadioStation, error) {
+	// Retrieve internet radio stations from Subsonic
+	res, err := s.get(ctx, s.makeURL("getInternetRadioStations", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.InternetRadioStations.InternetRadioStation, nil
+}
+
+// radioParams generates parameters for an internet radio station's stream URL, name, and
+// optional home page URL
+func radioParams(streamURL string, name string, homePageURL string) (Params, error) {
+	// Check for required fields
+	if streamURL == "" || name == "" {
+		return nil, errors.New("gosubsonic: stream URL and name are required for an internet radio station")
+	}
+
+	params := Params{}
+	params.Set("streamUrl", streamURL)
+	params.Set("name", name)
+	if homePageURL != "" {
+		params.Set("homepageUrl", homePageURL)
+	}
+
+	return params, nil
+}
+
+// CreateInternetRadioStation adds a new internet radio station, with an optional home page URL
+func (s Client) CreateInternetRadioStation(ctx context.Context, streamURL string, name string, homePageURL string) error {
+	params, err := radioParams(streamURL, name, homePageURL)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.get(ctx, s.makeURL("createInternetRadioStation", params))
+	return err
+}
+
+// UpdateInternetRadioStation updates an existing internet radio station, with an optional
+// home page URL
+func (s Client) UpdateInternetRadioStation(ctx context.Context, id ID, streamURL string, name string, homePageURL string) error {
+	params, err := radioParams(streamURL, name, homePageURL)
+	if err != nil {
+		return err
+	}
+
+	params.Set("id", string(id))
+
+	_, err = s.get(ctx, s.makeURL("updateInternetRadioStation", params))
+	return err
+}
+
+// DeleteInternetRadioStation deletes an internet radio station
+func (s Client) DeleteInternetRadioStation(ctx context.Context, id ID) error {
+	_, err := s.get(ctx, s.makeURL("deleteInternetRadioStation", idParams(id)))
+	return err
+}
+
+// -- Chat --
+
+// GetChatMessages returns chat messages posted since a specified time.  A zero time returns
+// all chat messages.
+func (s Client) GetChatMessages(ctx context.Context, since time.Time) ([]ChatMessage, error) {
+	// since, in milliseconds since the Unix epoch
+	params := Params{}
+	if !since.IsZero() {
+		params.SetInt("since", since.UnixMilli())
+	}
+
+	// Retrieve chat messages from Subsonic
+	res, err := s.get(ctx, s.makeURL("getChatMessages", params))
+	if err != nil {
+		return nil, err
+	}
+
+	// Subsonic problem: when there are no messages, the container will be an empty string, and
+	// no messages are returned
+	if len(res.Response.ChatMessages.ChatMessage) == 0 {
+		return nil, nil
+	}
+
+	return res.Response.ChatMessages.ChatMessage, nil
+}
+
+// AddChatMessage posts a message to the chat
+func (s Client) AddChatMessage(ctx context.Context, message string) error {
+	// Check for a message
+	if message == "" {
+		return errors.New("gosubsonic: a message is required")
+	}
+
+	params := Params{}
+	params.Set("message", message)
+
+	_, err := s.get(ctx, s.makeURL("addChatMessage", params))
+	return err
+}
+
+// -- User management --
+
+// GetUser returns details and roles for a user.  Details for other users require admin privileges.
+func (s Client) GetUser(ctx context.Context, username string) (*User, error) {
+	// Retrieve a user from Subsonic
+	res, err := s.get(ctx, s.makeURL("getUser", usernameParams(username)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for a user in the response
+	if res.Response.User == nil {
+		return nil, errors.New("gosubsonic: failed to parse getUser response")
+	}
+
+	return res.Response.User, nil
+}
+
+// GetUsers returns details and roles for all users, which requires admin privileges
+func (s Client) GetUsers(ctx context.Context) ([]User, error) {
+	// Retrieve users from Subsonic
+	res, err := s.get(ctx, s.makeURL("getUsers", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.Users.User, nil
+}
+
+// userParams generates parameters containing a user's email, settings, roles, and
+// music folders
+func userParams(u User) Params {
+	params := usernameParams(u.Username)
+	if u.Email != "" {
+		params.Set("email", u.Email)
+	}
+	if u.MaxBitRate > 0 {
+		params.SetInt("maxBitRate", u.MaxBitRate)
+	}
+
+	for _, r := range u.roles() {
+		params.SetBool(r.name, *r.value)
+	}
+
+	params.AddInts("musicFolderId", u.Folder)
+
+	return params
+}
+
+// usernameParams generates parameters containing a single username
+func usernameParams(username string) Params {
+	params := Params{}
+	params.Set("username", username)
+	return params
+}
+
+// encodePassword hex-encodes a password, so it is not sent in plain text in a query string
+func encodePassword(password string) string {
+	return "enc:" + hex.EncodeToString([]byte(password))
+}
+
+// CreateUser creates a new user with a password, and the email, settings, roles, and music
+// folders from a User struct.  This requires admin privileges.
+func (s Client) CreateUser(ctx context.Context, user User, password string) error {
+	// Check for required fields
+	if user.Username == "" || password == "" || user.Email == "" {
+		return errors.New("gosubsonic: username, password, and email are required to create a user")
+	}
+
+	params := userParams(user)
+	params.Set("password", encodePassword(password))
+
+	_, err := s.get(ctx, s.makeURL("createUser", params))
+	return err
+}
+
+// UpdateUserOptions represents the details to change in the UpdateUser() method.  Fields
+// which are not set are left unchanged, so only the settings and roles which are set are
+// granted or revoked.
+type UpdateUserOptions struct {
+	Email      string
+	MaxBitRate int64
+
+	// Settings and roles
+	ScrobblingEnabled   *bool
+	LdapAuthenticated   *bool
+	AdminRole           *bool
+	SettingsRole        *bool
+	DownloadRole        *bool
+	UploadRole          *bool
+	PlaylistRole        *bool
+	CoverArtRole        *bool
+	CommentRole         *bool
+	PodcastRole         *bool
+	StreamRole          *bool
+	JukeboxRole         *bool
+	ShareRole           *bool
+	VideoConversionRole *bool
+
+	// Folder replaces the IDs of music folders this user may access, if set
+	Folder []int64
+}
+
+// UpdateUser updates an existing user's email, settings, roles, and music folders.  This
+// requires admin privileges.
+func (s Client) UpdateUser(ctx context.Context, username string, options UpdateUserOptions) error {
+	// Check for required fields
+	if username == "" {
+		return errors.New("gosubsonic: username is required to update a user")
+	}
+
+	_, err := s.get(ctx, s.makeURL("updateUser", updateUserParams(username, options)))
+	return err
+}
+
+// updateUserParams generates parameters containing only the user details which are set
+func updateUserParams(username string, o UpdateUserOptions) Params {
+	params := usernameParams(username)
+	if o.Email != "" {
+		params.Set("email", o.Email)
+	}
+	if o.MaxBitRate > 0 {
+		params.SetInt("maxBitRate", o.MaxBitRate)
+	}
+
+	for _, r := range []struct {
+		name  string
+		value *bool
+	}{
+		{"scrobblingEnabled", o.ScrobblingEnabled},
+		{"ldapAuthenticated", o.LdapAuthenticated},
+		{"adminRole", o.AdminRole},
+		{"settingsRole", o.SettingsRole},
+		{"downloadRole", o.DownloadRole},
+		{"uploadRole", o.UploadRole},
+		{"playlistRole", o.PlaylistRole},
+		{"coverArtRole", o.CoverArtRole},
+		{"commentRole", o.CommentRole},
+		{"podcastRole", o.PodcastRole},
+		{"streamRole", o.StreamRole},
+		{"jukeboxRole", o.JukeboxRole},
+		{"shareRole", o.ShareRole},
+		{"videoConversionRole", o.VideoConversionRole},
+	} {
+		if r.value != nil {
+			params.SetBool(r.name, *r.value)
+		}
+	}
+
+	params.AddInts("musicFolderId", o.Folder)
+
+	return params
+}
+
+// DeleteUser deletes a user, which requires admin privileges
+func (s Client) DeleteUser(ctx context.Context, username string) error {
+	_, err := s.get(ctx, s.makeURL("deleteUser", usernameParams(username)))
+	return err
+}
+
+// ChangePassword changes the password of a user.  Changing the password of another user
+// requires admin privileges.
+func (s Client) ChangePassword(ctx context.Context, username string, password string) error {
+	// Check for a new password
+	if password == "" {
+		return errors.New("gosubsonic: a new password is required")
+	}
+
+	params := usernameParams(username)
+	params.Set("password", encodePassword(password))
+
+	_, err := s.get(ctx, s.makeURL("changePassword", params))
+	return err
+}
+
+// -- Bookmarks --
+
+// GetBookmarks returns all bookmarks for the current user
+func (s Client) GetBookmarks(ctx context.Context) ([]Bookmark, error) {
+	// Retrieve bookmarks from Subsonic
+	res, err := s.get(ctx, s.makeURL("getBookmarks", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Response.Bookmarks.Bookmark, nil
+}
+
+// CreateBookmark creates or updates a bookmark at a position within a media item, with an
+// optional comment
+func (s Client) CreateBookmark(ctx context.Context, id ID, position time.Duration, comment string) error {
+	// Build query parameters, with position in milliseconds
+	params := idParams(id)
+	params.SetInt("position", int64(position/time.Millisecond))
+
+	// comment
+	if comment != "" {
+		params.Set("comment", comment)
+	}
+
+	_, err := s.get(ctx, s.makeURL("createBookmark", params))
+	return err
+}
+
+// DeleteBookmark deletes the bookmark for a media item
+func (s Client) DeleteBookmark(ctx context.Context, id ID) error {
+	_, err := s.get(ctx, s.makeURL("deleteBookmark", idParams(id)))
+	return err
+}
+
+// GetPlayQueue returns the play queue saved by the current user, so playback may be resumed
+// on another client.  If no play queue has been saved, nil is returned.
+func (s Client) GetPlayQueue(ctx context.Context) (*PlayQueue, error) {
+	// Retrieve the play queue from Subsonic
+	res, err := s.get(ctx, s.makeURL("getPlayQueue", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	// If no play queue has been saved, this is nil
+	return res.Response.PlayQueue, nil
+}
+
+// SavePlayQueue saves the play queue for the current user, with the currently playing media ID
+// and the position within it.  An empty list of IDs clears the play queue.
+func (s Client) SavePlayQueue(ctx context.Context, ids []ID, current ID, position time.Duration) error {
+	// Build query parameters
+	params := idsParams(ids)
+	if len(ids) > 0 {
+		params.Set("current", string(current))
+		params.SetInt("position", int64(position/time.Millisecond))
+	}
+
+	_, err := s.get(ctx, s.makeURL("savePlayQueue", params))
+	return err
+}
+
+// -- Media library scanning --
+
+// GetScanStatus returns the status of a media library scan
+func (s Client) GetScanStatus(ctx context.Context) (*ScanStatus, error) {
+	// Retrieve scan status from Subsonic
+	res, err := s.get(ctx, s.makeURL("getScanStatus", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.ScanStatus, nil
+}
+
+// StartScan starts a media library scan, and returns the status of the new scan
+func (s Client) StartScan(ctx context.Context) (*ScanStatus, error) {
+	// Start a scan on Subsonic
+	res, err := s.get(ctx, s.makeURL("startScan", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Response.ScanStatus, nil
+}
+
+// -- Functions --
+
+// makeURL Generates a URL for an API call using given parameters and method.  The parameters
+// are not modified, and may be nil.
+func (s Client) makeURL(method string, params Params) string {
+	// Add client, version, format, and authentication parameters
+	q := params.clone()
+	q.Set("u", s.Username)
+	q.Set("c", s.clientName())
+	q.Set("v", s.apiVersion())
+	q.Set("f", s.format())
+	s.authParams(q)
+
+	return s.baseURL() + "/rest/" + method + ".view?" + q.Encode()
+}
+
+// clientName returns the client name reported to Subsonic
+func (s Client) clientName() string {
+	if s.ClientName == "" {
+		return DefaultClientName
+	}
+
+	return s.ClientName
+}
+
+// apiVersion returns the REST API version reported to Subsonic
+func (s Client) apiVersion() string {
+	switch {
+	case s.APIVersion != "":
+		return s.APIVersion
+	case s.TokenAuth:
+		return tokenAuthAPIVersion
+	default:
+		return DefaultAPIVersion
+	}
+}
+
+// format returns the response format requested from Subsonic
+func (s Client) format() string {
+	if s.XML {
+		return "xml"
+	}
+
+	return "json"
+}
+
+// baseURL returns the base URL of the Subsonic server, including scheme and any path prefix
+func (s Client) baseURL() string {
+	// A bare host (and port) defaults to HTTP
+	base := s.Host
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+
+	return strings.TrimSuffix(base, "/")
+}
+
+// authParams adds the authentication parameters for a request.  With token authentication,
+// a random salt is generated for every request, and the password is never sent.
+func (s Client) authParams(params Params) {
+	if !s.TokenAuth {
+		params.Set("p", s.password())
+		return
+	}
+
+	// Generate a random salt
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		// The system's secure random source should never fail, but if it does, fall
+		// back to a timestamp which is still unique per request
+		binary.BigEndian.PutUint64(salt, uint64(time.Now().UnixNano()))
+	}
+	saltHex := hex.EncodeToString(salt)
+
+	// Token is md5(password + salt)
+	token := md5.Sum([]byte(s.password() + saltHex))
+	params.Set("t", hex.EncodeToString(token[:]))
+	params.Set("s", saltHex)
+}
+
+// fetchBinary retrieves a binary stream from a specified URL and returns a io.ReadCloser on the stream
+func (s Client) fetchBinary(ctx context.Context, url string) (io.ReadCloser, error) {
+	res, err := s.fetchBinaryResponse(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return response reader for body
+	return res.Body, nil
+}
+
+// fetchBinaryResponse retrieves a binary stream from a specified URL with optional request
+// headers, through the client's interceptors, and returns the HTTP response if it contains
+// binary data
+func (s Client) fetchBinaryResponse(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	var res *http.Response
+	err := s.intercept(ctx, &Request{Method: urlMethod(url), URL: redactURL(url), Binary: true}, func(ctx context.Context, req *Request) error {
+		var err error
+		res, err = s.fetchBinaryAttempts(ctx, url, header)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = s.trackProgress(ctx, url, res.Body, res.ContentLength)
+	return res, nil
+}
+
+// fetchBinaryAttempts retrieves a binary stream, retrying according to the retry policy, and
+// returns the HTTP response if it contains binary data
+func (s Client) fetchBinaryAttempts(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	// Perform HTTP GET request
+	var res *http.Response
+	err := s.retry(ctx, func() error {
+		// Binary requests remain in flight until their body is closed
+		release, err := s.limit(ctx, url)
+		if err != nil {
+			return err
+		}
+
+		res, err = s.binaryDataSource().GetBinary(ctx, url, header)
+		if err != nil {
+			release()
+
+			// Report cancellation by the caller directly
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return fmt.Errorf("gosubsonic: HTTP request failed: %w - %s", err, redactURL(url))
+		}
+		res.Body = releaseReadCloser{res.Body, release}
+		recordHTTPStatus(ctx, res.StatusCode)
+
+		if err := statusError(res, url); err != nil {
+			res.Body.Close()
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for JSON or XML content type, meaning file is not binary
+	contentType := res.Header.Get("Content-Type")
+	if strings.Contains(contentType, "application/json") || isXMLContentType(contentType) {
+		// Read the entire response body, and defer it to be closed
+		body, err := ioutil.ReadAll(s.limitResponse(res.Body, url))
+		defer res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gosubsonic: failed to read response: %s - %s", err.Error(), redactURL(url))
+		}
+
+		// Return the error, or report a response which contains no binary data
+		if _, err := processResponse(body); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("gosubsonic: unexpected %s response in place of binary data - %s", contentType, redactURL(url))
+	}
+
+	// Other unsuccessful responses, such as error pages from a reverse proxy, are not media.
+	// Unsatisfiable ranges are reported by fetchRange.
+	if (res.StatusCode < 200 || res.StatusCode > 299) && res.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		res.Body.Close()
+		return nil, &StatusError{StatusCode: res.StatusCode, URL: redactURL(url)}
+	}
+
+	return res, nil
+}
+
+// httpGet performs a HTTP GET request for the specified URL and optional headers using client,
+// which is canceled along with ctx
+func httpGet(ctx context.Context, client *http.Client, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	// Fall back to the shared client, for clients which were not constructed using New
+	if client == nil {
+		client = sharedHTTPClient
+	}
+
+	// The request URL, which contains credentials, is included in the HTTP client's errors
+	res, err := client.Do(req)
+	return res, redactError(err)
+}
+
+// maxResponseSize returns the maximum size of an API response body, or 0 for no limit
+func (s Client) maxResponseSize() int64 {
+	switch {
+	case s.MaxResponseSize == 0:
+		return DefaultMaxResponseSize
+	case s.MaxResponseSize < 0:
+		return 0
+	default:
+		return s.MaxResponseSize
+	}
+}
+
+// limitResponse limits a response body to the maximum response size, reporting
+// ErrResponseTooLarge if it is exceeded
+func (s Client) limitResponse(r io.Reader, url string) io.Reader {
+	max := s.maxResponseSize()
+	if max <= 0 {
+		return r
+	}
+
+	return &limitedReader{r: r, n: max, max: max, url: url}
+}
+
+// responseTooLarge returns an error reporting a response which exceeded the maximum size
+func responseTooLarge(url string, max int64) error {
+	return fmt.Errorf("%w of %d bytes - %s", ErrResponseTooLarge, max, redactURL(url))
+}
+
+// limitedReader is an io.Reader which fails once more than a maximum number of bytes are
+// read, unlike io.LimitReader, which silently truncates
+type limitedReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+	url string
+}
+
+// Read implements io.Reader
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, responseTooLarge(l.url, l.max)
+	}
+
+	// Read one byte past the limit, to detect when it is exceeded
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n - 1, responseTooLarge(l.url, l.max)
+	}
+
+	return n, err
+}
+
+// httpDataSource represents a HTTP data source for a Subsonic client
+type httpDataSource struct {
+	client *http.Client
+
+	// uncompressed disables compression of responses
+	uncompressed bool
+}
+
+// Get retrieves a raw response from HTTP with a specified URL
+func (s httpDataSource) Get(ctx context.Context, url string) ([]byte, error) {
+	body, err := s.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the entire response body
+	out, err := ioutil.ReadAll(body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	// Close response body
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetBinary retrieves a binary response from HTTP with a specified URL and optional request
+// headers.  Its body must be closed by the caller.
+func (s httpDataSource) GetBinary(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	return httpGet(ctx, s.client, url, header)
+}
+
+// Open retrieves a response body from HTTP with a specified URL, which must be closed by
+// the caller
+func (s httpDataSource) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	// Request compression explicitly, rather than relying on the transport, which does not
+	// offer deflate, and may be replaced by one which does not compress at all
+	header := http.Header{"Accept-Encoding": {"gzip, deflate"}}
+	if s.uncompressed {
+		header.Set("Accept-Encoding", "identity")
+	}
+
+	res, err := httpGet(ctx, s.client, url, header)
+	if err != nil {
+		// Report cancellation by the caller directly
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
 
-		return "False", nil
-	case string:
-		return html.UnescapeString(data.(string)), nil
-	case float64:
-		return strconv.FormatInt(int64(data.(float64)), 10), nil
+		return nil, fmt.Errorf("gosubsonic: HTTP request failed: %w - %s", err, redactURL(url))
+	}
+
+	if err := statusError(res, url); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	body, err := decompress(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, fmt.Errorf("gosubsonic: failed to decompress response: %s - %s", err.Error(), redactURL(url))
+	}
+
+	return body, nil
+}
+
+// decompress returns the decompressed body of a HTTP response, according to its
+// Content-Encoding
+func decompress(res *http.Response) (io.ReadCloser, error) {
+	var r io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return res.Body, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	case "deflate":
+		// Deflate should be wrapped in zlib, but some servers send raw deflate data
+		br := bufio.NewReader(res.Body)
+		if head, err := br.Peek(2); err == nil && isZlibHeader(head) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		} else {
+			r = flate.NewReader(br)
+		}
 	default:
-		return "", fmt.Errorf("gosubsonic: unknown data type %T for ifaceToString", data)
+		return nil, fmt.Errorf("unsupported content encoding: %q", res.Header.Get("Content-Encoding"))
+	}
+
+	return decompressReadCloser{r, res.Body}, nil
+}
+
+// isZlibHeader determines if two bytes are a valid zlib header, using deflate compression
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// decompressReadCloser is an io.ReadCloser which reads decompressed data, and closes both
+// the decompressor and the compressed body
+type decompressReadCloser struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+// Close closes the decompressor and the compressed body
+func (d decompressReadCloser) Close() error {
+	err := d.ReadCloser.Close()
+	if berr := d.body.Close(); err == nil {
+		err = berr
+	}
+
+	return err
+}
+
+// processJSON decodes JSON into an apiContainer as it is read
+func processJSON(r io.Reader) (*apiContainer, error) {
+	// Decode response JSON from API container
+	var subRes apiContainer
+	if err := json.NewDecoder(r).Decode(&subRes); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to parse response JSON: %w", err)
+	}
+
+	// Check for any errors in response object, which may have a generic error code of 0
+	if subRes.Response.Status == "failed" || subRes.Response.Error != (Error{}) {
+		// Report error and code
+		apiErr := subRes.Response.Error
+		return nil, &apiErr
 	}
+
+	// Return the response container
+	return &subRes, nil
 }
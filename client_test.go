@@ -1,8 +1,24 @@
 package gosubsonic
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestPing verifies that client.Ping() is working properly
@@ -16,7 +32,7 @@ func TestPing(t *testing.T) {
 	}
 
 	// Ping mock data and get current status
-	stat, err := s.Ping()
+	stat, err := s.Ping(context.Background())
 	if err != nil {
 		t.Fatalf("Ping returned error: %s", err.Error())
 	}
@@ -32,6 +48,50 @@ func TestPing(t *testing.T) {
 	}
 }
 
+// TestGetOpenSubsonicExtensions verifies that client.GetOpenSubsonicExtensions() and
+// client.SupportsExtension() are working properly
+func TestGetOpenSubsonicExtensions(t *testing.T) {
+	log.Println("TestGetOpenSubsonicExtensions()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get extensions mock data
+	extensions, err := s.GetOpenSubsonicExtensions(context.Background())
+	if err != nil {
+		t.Fatalf("GetOpenSubsonicExtensions returned error: %s", err.Error())
+	}
+
+	if len(extensions) != 2 || extensions[1].Name != "formPost" || len(extensions[1].Versions) != 2 {
+		t.Fatalf("GetOpenSubsonicExtensions returned invalid extensions: %+v", extensions)
+	}
+
+	var tests = []struct {
+		name      string
+		version   int64
+		supported bool
+	}{
+		{"transcodeOffset", 1, true},
+		{"formPost", 2, true},
+		{"formPost", 3, false},
+		{"songLyrics", 1, false},
+	}
+
+	for _, test := range tests {
+		ok, err := s.SupportsExtension(context.Background(), test.name, test.version)
+		if err != nil {
+			t.Fatalf("SupportsExtension returned error: %s", err.Error())
+		}
+
+		if ok != test.supported {
+			t.Fatalf("SupportsExtension returned %v for %s version %d", ok, test.name, test.version)
+		}
+	}
+}
+
 // TestGetLicense verifies that client.GetLicense() is working properly
 func TestGetLicense(t *testing.T) {
 	log.Println("TestGetLicense()")
@@ -43,7 +103,7 @@ func TestGetLicense(t *testing.T) {
 	}
 
 	// Get license mock data
-	license, err := s.GetLicense()
+	license, err := s.GetLicense(context.Background())
 	if err != nil {
 		t.Fatalf("GetLicense returned error: %s", err.Error())
 	}
@@ -59,6 +119,49 @@ func TestGetLicense(t *testing.T) {
 	}
 }
 
+// TestGetLicenseFreeServers verifies that client.GetLicense() tolerates licenses without a
+// date or email, and reports servers which return no license as not supported
+func TestGetLicenseFreeServers(t *testing.T) {
+	log.Println("TestGetLicenseFreeServers()")
+
+	var tests = []struct {
+		body  string
+		valid bool
+		err   error
+	}{
+		// Navidrome
+		{`{"subsonic-response": {"status": "ok", "version": "1.16.1", "license": {"valid": true}}}`, true, nil},
+		{`<subsonic-response status="ok" version="1.16.1"><license valid="true"/></subsonic-response>`, true, nil},
+		// No license, or an empty license element
+		{`{"subsonic-response": {"status": "ok", "version": "1.16.1"}}`, false, ErrNotSupported},
+		{`<subsonic-response status="ok" version="1.16.1"><license/></subsonic-response>`, false, ErrNotSupported},
+	}
+
+	for i, test := range tests {
+		f := NewFakeServer()
+		f.SetFixture("getLicense", nil, []byte(test.body))
+
+		s, err := f.Client()
+		if err != nil {
+			t.Fatalf("Client returned error: %s", err.Error())
+		}
+
+		license, err := s.GetLicense(context.Background())
+		f.Close()
+
+		if !errors.Is(err, test.err) {
+			t.Fatalf("[%02d] GetLicense returned error %v, expected %v", i, err, test.err)
+		}
+		if err != nil {
+			continue
+		}
+
+		if license.Valid != test.valid || !license.Date.IsZero() || license.Email != "" {
+			t.Fatalf("[%02d] GetLicense returned invalid license: %+v", i, license)
+		}
+	}
+}
+
 // TestGetMusicFolders verifies that client.GetMusicFolders() is working properly
 func TestGetMusicFolders(t *testing.T) {
 	log.Println("TestGetMusicFolders()")
@@ -70,7 +173,7 @@ func TestGetMusicFolders(t *testing.T) {
 	}
 
 	// Get music folders mock data
-	folders, err := s.GetMusicFolders()
+	folders, err := s.GetMusicFolders(context.Background())
 	if err != nil {
 		t.Fatalf("GetMusicFolders returned error: %s", err.Error())
 	}
@@ -97,24 +200,37 @@ func TestGetIndexes(t *testing.T) {
 	}
 
 	// Get indexes mock data
-	indexes, err := s.GetIndexes(-1, -1)
+	indexes, err := s.GetIndexes(context.Background(), -1, time.Time{})
 	if err != nil {
 		t.Fatalf("GetIndexes returned error: %s", err.Error())
 	}
 
 	// Check for proper index
-	if indexes[0].Name != "A" {
-		t.Fatalf("GetIndexes returned invalid index name: %s", indexes[0].Name)
+	if indexes.Index[0].Name != "A" {
+		t.Fatalf("GetIndexes returned invalid index name: %s", indexes.Index[0].Name)
 	}
 
 	// Check for known ID
-	if indexes[0].Artist[0].ID != 1 {
-		t.Fatalf("GetIndexes returned invalid ID: %d", indexes[0].Artist[0].ID)
+	if indexes.Index[0].Artist[0].ID != "1" {
+		t.Fatalf("GetIndexes returned invalid ID: %s", indexes.Index[0].Artist[0].ID)
 	}
 
 	// Check for known name
-	if indexes[1].Artist[0].Name != "Boston" {
-		t.Fatalf("GetIndexes returned invalid name: %s", indexes[1].Artist[0].Name)
+	if indexes.Index[1].Artist[0].Name != "Boston" {
+		t.Fatalf("GetIndexes returned invalid name: %s", indexes.Index[1].Artist[0].Name)
+	}
+
+	// Check for shortcuts and children
+	if len(indexes.Shortcuts) != 1 || indexes.Shortcuts[0].Name != "New Music" {
+		t.Fatalf("GetIndexes returned invalid shortcuts: %+v", indexes.Shortcuts)
+	}
+	if len(indexes.Children.Audio) != 1 || indexes.Children.Audio[0].Title != "Intro" {
+		t.Fatalf("GetIndexes returned invalid children: %+v", indexes.Children)
+	}
+
+	// Check for last modified time, which must be sent back to the server unchanged
+	if indexes.LastModified.UnixMilli() != 1395014311154 || indexes.IgnoredArticles != "The El La" {
+		t.Fatalf("GetIndexes returned invalid metadata: %s %q", indexes.LastModified, indexes.IgnoredArticles)
 	}
 }
 
@@ -129,14 +245,14 @@ func TestGetMusicDirectory(t *testing.T) {
 	}
 
 	// Get music directory mock data
-	content, err := s.GetMusicDirectory(1)
+	content, err := s.GetMusicDirectory(context.Background(), "1")
 	if err != nil {
 		t.Fatalf("GetMusicDirectory returned error: %s", err.Error())
 	}
 
 	// Check for mock directory ID
-	if content.Directories[0].ID != 405 {
-		t.Fatalf("GetMusicDirectory returned invalid ID: %d", content.Directories[0].ID)
+	if content.Directories[0].ID != "405" {
+		t.Fatalf("GetMusicDirectory returned invalid ID: %s", content.Directories[0].ID)
 	}
 
 	// Check for mock artist
@@ -156,7 +272,1701 @@ func TestScrobble(t *testing.T) {
 	}
 
 	// Get scrobble mock data
-	if err := s.Scrobble(1, -1, false); err != nil {
+	if err := s.Scrobble(context.Background(), "1", -1, false); err != nil {
 		t.Fatalf("Scrobble returned error: %s", err.Error())
 	}
 }
+
+// TestGetArtists verifies that client.GetArtists() is working properly
+func TestGetArtists(t *testing.T) {
+	log.Println("TestGetArtists()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get artists mock data
+	indexes, err := s.GetArtists(context.Background(), -1)
+	if err != nil {
+		t.Fatalf("GetArtists returned error: %s", err.Error())
+	}
+
+	// Check for known number of indexes, with single and multiple artists
+	if len(indexes) != 2 || len(indexes[0].Artist) != 1 || len(indexes[1].Artist) != 2 {
+		t.Fatalf("GetArtists returned invalid indexes: %+v", indexes)
+	}
+
+	// Check for numeric artist name, converted to string
+	if indexes[0].Artist[0].Name != "311" {
+		t.Fatalf("GetArtists returned invalid artist name: %s", indexes[0].Artist[0].Name)
+	}
+}
+
+// TestGetArtist verifies that client.GetArtist() is working properly
+func TestGetArtist(t *testing.T) {
+	log.Println("TestGetArtist()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get artist mock data
+	artist, err := s.GetArtist(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetArtist returned error: %s", err.Error())
+	}
+
+	// Check for a single album, parsed into a slice
+	if len(artist.Album) != 1 || artist.Album[0].SongCount != 2 {
+		t.Fatalf("GetArtist returned invalid albums: %+v", artist.Album)
+	}
+}
+
+// TestGetAlbum verifies that client.GetAlbum() is working properly
+func TestGetAlbum(t *testing.T) {
+	log.Println("TestGetAlbum()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get album mock data
+	album, err := s.GetAlbum(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetAlbum returned error: %s", err.Error())
+	}
+
+	// Check for known name
+	if album.Name != "Adventure" {
+		t.Fatalf("GetAlbum returned invalid name: %s", album.Name)
+	}
+
+	// Check for known songs
+	if len(album.Song) != 2 || album.Song[1].Title != "Another Day" {
+		t.Fatalf("GetAlbum returned invalid songs: %+v", album.Song)
+	}
+}
+
+// TestGetSong verifies that client.GetSong() is working properly
+func TestGetSong(t *testing.T) {
+	log.Println("TestGetSong()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get song mock data
+	song, err := s.GetSong(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetSong returned error: %s", err.Error())
+	}
+
+	// Check for numeric title, converted to string
+	if song.Title != "311" {
+		t.Fatalf("GetSong returned invalid title: %s", song.Title)
+	}
+
+	// Check for OpenSubsonic fields
+	if song.SortName != "three eleven" || song.MusicBrainzID == "" {
+		t.Fatalf("GetSong returned invalid OpenSubsonic fields: %+v", song)
+	}
+
+	if song.ReplayGain == nil || song.ReplayGain.TrackGain != -6.5 || song.ReplayGain.AlbumGain != -7.25 {
+		t.Fatalf("GetSong returned invalid ReplayGain: %+v", song.ReplayGain)
+	}
+}
+
+// TestGetGenres verifies that client.GetGenres() is working properly
+func TestGetGenres(t *testing.T) {
+	log.Println("TestGetGenres()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get genres mock data
+	genres, err := s.GetGenres(context.Background())
+	if err != nil {
+		t.Fatalf("GetGenres returned error: %s", err.Error())
+	}
+
+	// Check for each style of genre name
+	expected := []Genre{
+		{Name: "Electronic", SongCount: 28, AlbumCount: 6},
+		{Name: "Hip-Hop", SongCount: 6, AlbumCount: 2},
+		{Name: "Rock"},
+	}
+
+	if len(genres) != len(expected) {
+		t.Fatalf("GetGenres returned invalid number of genres: %d", len(genres))
+	}
+
+	for i := range expected {
+		if genres[i] != expected[i] {
+			t.Fatalf("GetGenres returned invalid genre: %+v != %+v", genres[i], expected[i])
+		}
+	}
+}
+
+// TestGetVideos verifies that client.GetVideos() is working properly
+func TestGetVideos(t *testing.T) {
+	log.Println("TestGetVideos()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get videos mock data, with a single video
+	videos, err := s.GetVideos(context.Background())
+	if err != nil {
+		t.Fatalf("GetVideos returned error: %s", err.Error())
+	}
+
+	if len(videos) != 1 || videos[0].ID != "460" || videos[0].Duration != time.Hour {
+		t.Fatalf("GetVideos returned invalid videos: %+v", videos)
+	}
+}
+
+// TestGetVideoInfo verifies that client.GetVideoInfo() is working properly
+func TestGetVideoInfo(t *testing.T) {
+	log.Println("TestGetVideoInfo()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get video info mock data
+	info, err := s.GetVideoInfo(context.Background(), "460")
+	if err != nil {
+		t.Fatalf("GetVideoInfo returned error: %s", err.Error())
+	}
+
+	// Check for single and multiple items in each list
+	if len(info.Captions) != 1 || len(info.AudioTracks) != 2 || len(info.Conversions) != 1 {
+		t.Fatalf("GetVideoInfo returned invalid info: %+v", info)
+	}
+
+	if info.AudioTracks[1].LanguageCode != "nor" || info.Conversions[0].BitRate != 1000 {
+		t.Fatalf("GetVideoInfo returned invalid info: %+v", info)
+	}
+}
+
+// TestHLSURL verifies that HLS playlist URLs are generated properly
+func TestHLSURL(t *testing.T) {
+	log.Println("TestHLSURL()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Generate an adaptive playlist URL with an audio track
+	u := s.hlsURL("460", &HLSOptions{
+		BitRate:    []int64{1000, 2000},
+		AudioTrack: "3",
+	})
+
+	if !strings.Contains(u, "/rest/hls.m3u8?audioTrack=3&bitRate=1000&bitRate=2000&") || !strings.Contains(u, "&id=460&") {
+		t.Fatalf("hlsURL returned invalid URL: %s", u)
+	}
+}
+
+// TestGetArtistInfo verifies that client.GetArtistInfo() and client.GetArtistInfo2() are working properly
+func TestGetArtistInfo(t *testing.T) {
+	log.Println("TestGetArtistInfo()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get artist info mock data
+	info, err := s.GetArtistInfo(context.Background(), "1", 0, false)
+	if err != nil {
+		t.Fatalf("GetArtistInfo returned error: %s", err.Error())
+	}
+
+	// Check for unescaped biography and image URLs
+	if info.Biography != "Adventure is an 8-bit band from Baltimore & elsewhere" || info.LargeImageURL == "" {
+		t.Fatalf("GetArtistInfo returned invalid info: %+v", info.ArtistBio)
+	}
+
+	// Check for similar artists, with numeric name converted to string
+	if len(info.SimilarArtist) != 2 || info.SimilarArtist[0].Name != "311" {
+		t.Fatalf("GetArtistInfo returned invalid similar artists: %+v", info.SimilarArtist)
+	}
+
+	// Get ID3 artist info mock data, with a single similar artist
+	info2, err := s.GetArtistInfo2(context.Background(), "1", 0, false)
+	if err != nil {
+		t.Fatalf("GetArtistInfo2 returned error: %s", err.Error())
+	}
+
+	if len(info2.SimilarArtist) != 1 || info2.SimilarArtist[0].AlbumCount != 2 || info2.Biography != "" {
+		t.Fatalf("GetArtistInfo2 returned invalid info: %+v", info2)
+	}
+}
+
+// TestGetAlbumInfo verifies that client.GetAlbumInfo() is working properly
+func TestGetAlbumInfo(t *testing.T) {
+	log.Println("TestGetAlbumInfo()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get album info mock data
+	info, err := s.GetAlbumInfo(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetAlbumInfo returned error: %s", err.Error())
+	}
+
+	if info.Notes != "The debut album & more" || info.LargeImageURL != "http://example.com/album.png" {
+		t.Fatalf("GetAlbumInfo returned invalid info: %+v", info)
+	}
+}
+
+// TestGetSimilarSongs2 verifies that client.GetSimilarSongs2() is working properly
+func TestGetSimilarSongs2(t *testing.T) {
+	log.Println("TestGetSimilarSongs2()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get similar songs mock data, which is an empty string
+	songs, err := s.GetSimilarSongs2(context.Background(), "1", 0)
+	if err != nil {
+		t.Fatalf("GetSimilarSongs2 returned error: %s", err.Error())
+	}
+
+	if len(songs) != 0 {
+		t.Fatalf("GetSimilarSongs2 returned invalid songs: %+v", songs)
+	}
+}
+
+// TestGetTopSongs verifies that client.GetTopSongs() is working properly
+func TestGetTopSongs(t *testing.T) {
+	log.Println("TestGetTopSongs()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get top songs mock data, with a single song
+	songs, err := s.GetTopSongs(context.Background(), "Adventure", 5)
+	if err != nil {
+		t.Fatalf("GetTopSongs returned error: %s", err.Error())
+	}
+
+	if len(songs) != 1 || songs[0].ID != "1" {
+		t.Fatalf("GetTopSongs returned invalid songs: %+v", songs)
+	}
+}
+
+// TestGetNowPlaying verifies that client.GetNowPlaying() is working properly
+func TestGetNowPlaying(t *testing.T) {
+	log.Println("TestGetNowPlaying()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get now playing mock data
+	entries, err := s.GetNowPlaying(context.Background())
+	if err != nil {
+		t.Fatalf("GetNowPlaying returned error: %s", err.Error())
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("GetNowPlaying returned invalid entries: %+v", entries)
+	}
+
+	// Check for complete entry, including the player
+	e := entries[0]
+	if e.ID != "406" || e.AlbumID != "405" || e.DiscNumber != 1 || e.Genre != "Electronic" || e.Track != 1 ||
+		e.Year != 2011 || e.Duration != 214*time.Second || e.Username != "mock" || e.MinutesAgo != 2 ||
+		e.PlayerID != 3 || e.PlayerName != "gosubsonic-player" {
+		t.Fatalf("GetNowPlaying returned invalid entry: %+v", e)
+	}
+
+	// Check for sparse entry, with all optional fields missing
+	e = entries[1]
+	if e.ID != "407" || e.Title != "Another Day" || e.Username != "guest" || e.AlbumID != "" ||
+		e.PlayerID != 0 || e.PlayerName != "" || !e.Created.IsZero() || e.Duration != 0 {
+		t.Fatalf("GetNowPlaying returned invalid sparse entry: %+v", e)
+	}
+}
+
+// TestGetAlbumList verifies that client.GetAlbumList() is working properly
+func TestGetAlbumList(t *testing.T) {
+	log.Println("TestGetAlbumList()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get album list mock data
+	albums, err := s.GetAlbumList(context.Background(), AlbumListNewest, nil)
+	if err != nil {
+		t.Fatalf("GetAlbumList returned error: %s", err.Error())
+	}
+
+	// Check for known number of albums, with numeric title converted to string
+	if len(albums) != 2 || albums[1].Title != "311" {
+		t.Fatalf("GetAlbumList returned invalid albums: %+v", albums)
+	}
+}
+
+// TestGetAlbumList2 verifies that client.GetAlbumList2() is working properly
+func TestGetAlbumList2(t *testing.T) {
+	log.Println("TestGetAlbumList2()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get album list mock data, which is an empty string
+	albums, err := s.GetAlbumList2(context.Background(), AlbumListNewest, nil)
+	if err != nil {
+		t.Fatalf("GetAlbumList2 returned error: %s", err.Error())
+	}
+
+	if len(albums) != 0 {
+		t.Fatalf("GetAlbumList2 returned invalid albums: %+v", albums)
+	}
+}
+
+// TestAlbumListOptions verifies that album list types and options are validated
+func TestAlbumListOptions(t *testing.T) {
+	log.Println("TestAlbumListOptions()")
+
+	var tests = []struct {
+		listType string
+		options  *AlbumListOptions
+		query    string
+		ok       bool
+	}{
+		{AlbumListRandom, nil, "type=random", true},
+		{AlbumListRandom, &AlbumListOptions{Size: 50, Offset: 100}, "offset=100&size=50&type=random", true},
+		{AlbumListByYear, &AlbumListOptions{FromYear: 1990, ToYear: 2000}, "fromYear=1990&toYear=2000&type=byYear", true},
+		{AlbumListByYear, nil, "", false},
+		{AlbumListByGenre, &AlbumListOptions{Genre: "Hip-Hop & Rap"}, "genre=Hip-Hop+%26+Rap&type=byGenre", true},
+		{AlbumListByGenre, &AlbumListOptions{}, "", false},
+		{"bogus", nil, "", false},
+	}
+
+	for _, test := range tests {
+		q, err := test.options.query(test.listType)
+		if (err == nil) != test.ok {
+			t.Fatalf("query returned unexpected error for %s: %v", test.listType, err)
+		}
+
+		if q.Encode() != test.query {
+			t.Fatalf("query returned invalid query string: %s != %s", q.Encode(), test.query)
+		}
+	}
+}
+
+// TestGetRandomSongs verifies that client.GetRandomSongs() is working properly
+func TestGetRandomSongs(t *testing.T) {
+	log.Println("TestGetRandomSongs()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get random songs mock data
+	songs, err := s.GetRandomSongs(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetRandomSongs returned error: %s", err.Error())
+	}
+
+	// Check for known number of songs, with numeric title converted to string
+	if len(songs) != 2 || songs[1].Title != "311" {
+		t.Fatalf("GetRandomSongs returned invalid songs: %+v", songs)
+	}
+}
+
+// TestGetSongsByGenre verifies that client.GetSongsByGenre() is working properly
+func TestGetSongsByGenre(t *testing.T) {
+	log.Println("TestGetSongsByGenre()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get songs by genre mock data, with a single song
+	songs, err := s.GetSongsByGenre(context.Background(), "Electronic", nil)
+	if err != nil {
+		t.Fatalf("GetSongsByGenre returned error: %s", err.Error())
+	}
+
+	if len(songs) != 1 || songs[0].Genre != "Electronic" {
+		t.Fatalf("GetSongsByGenre returned invalid songs: %+v", songs)
+	}
+
+	// An empty genre should be rejected
+	if _, err := s.GetSongsByGenre(context.Background(), "", nil); err == nil {
+		t.Fatalf("GetSongsByGenre accepted empty genre")
+	}
+}
+
+// TestSearch2 verifies that client.Search2() is working properly
+func TestSearch2(t *testing.T) {
+	log.Println("TestSearch2()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get search mock data
+	res, err := s.Search2(context.Background(), "mock", nil)
+	if err != nil {
+		t.Fatalf("Search2 returned error: %s", err.Error())
+	}
+
+	// Check for known number of results in each category
+	if len(res.Artists) != 1 || len(res.Albums) != 1 || len(res.Songs) != 2 {
+		t.Fatalf("Search2 returned invalid results: %d artists, %d albums, %d songs",
+			len(res.Artists), len(res.Albums), len(res.Songs))
+	}
+
+	// Check for numeric title, converted to string
+	if res.Songs[1].Title != "311" {
+		t.Fatalf("Search2 returned invalid song title: %s", res.Songs[1].Title)
+	}
+}
+
+// TestSearch3 verifies that client.Search3() is working properly
+func TestSearch3(t *testing.T) {
+	log.Println("TestSearch3()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get search mock data
+	res, err := s.Search3(context.Background(), "mock", nil)
+	if err != nil {
+		t.Fatalf("Search3 returned error: %s", err.Error())
+	}
+
+	// Check for known number of results in each category, with no songs
+	if len(res.Artists) != 2 || len(res.Albums) != 1 || len(res.Songs) != 0 {
+		t.Fatalf("Search3 returned invalid results: %d artists, %d albums, %d songs",
+			len(res.Artists), len(res.Albums), len(res.Songs))
+	}
+
+	// Check for numeric artist name, converted to string
+	if res.Artists[1].Name != "311" || res.Artists[1].AlbumCount != 3 {
+		t.Fatalf("Search3 returned invalid artist: %+v", res.Artists[1])
+	}
+}
+
+// TestSearchOptions verifies that search options are added to the query string
+func TestSearchOptions(t *testing.T) {
+	log.Println("TestSearchOptions()")
+
+	var tests = []struct {
+		options *SearchOptions
+		query   string
+	}{
+		{nil, "query=a+b"},
+		{&SearchOptions{}, "query=a+b"},
+		{&SearchOptions{ArtistCount: 0, AlbumCount: 5, SongOffset: 10}, "albumCount=5&query=a+b&songOffset=10"},
+		{&SearchOptions{SongCount: -1, MusicFolderID: 2}, "musicFolderId=2&query=a+b&songCount=0"},
+	}
+
+	for _, test := range tests {
+		if q := test.options.query("a b").Encode(); q != test.query {
+			t.Fatalf("query returned invalid query string: %s != %s", q, test.query)
+		}
+	}
+}
+
+// TestGetPlaylists verifies that client.GetPlaylists() is working properly
+func TestGetPlaylists(t *testing.T) {
+	log.Println("TestGetPlaylists()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get playlists mock data
+	playlists, err := s.GetPlaylists(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetPlaylists returned error: %s", err.Error())
+	}
+
+	// Check for known number of playlists
+	if len(playlists) != 2 {
+		t.Fatalf("GetPlaylists returned invalid number of playlists: %d", len(playlists))
+	}
+
+	// Check for string ID, converted to integer
+	if playlists[0].ID != "1" {
+		t.Fatalf("GetPlaylists returned invalid ID: %s", playlists[0].ID)
+	}
+
+	// Check for numeric name, converted to string
+	if playlists[1].Name != "311" {
+		t.Fatalf("GetPlaylists returned invalid name: %s", playlists[1].Name)
+	}
+
+	// Check for parsed date with fractional seconds
+	if playlists[0].Created.IsZero() || playlists[0].Changed.Before(playlists[0].Created) {
+		t.Fatalf("GetPlaylists returned invalid dates: %s, %s", playlists[0].Created, playlists[0].Changed)
+	}
+}
+
+// TestGetPlaylist verifies that client.GetPlaylist() is working properly
+func TestGetPlaylist(t *testing.T) {
+	log.Println("TestGetPlaylist()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get playlist mock data
+	playlist, err := s.GetPlaylist(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetPlaylist returned error: %s", err.Error())
+	}
+
+	// Check for a single entry
+	if len(playlist.Entry) != 1 {
+		t.Fatalf("GetPlaylist returned invalid number of entries: %d", len(playlist.Entry))
+	}
+
+	// Check for known title
+	if playlist.Entry[0].Title != "Wonderland" || playlist.Entry[0].Index != 0 {
+		t.Fatalf("GetPlaylist returned invalid entry: %+v", playlist.Entry[0])
+	}
+}
+
+// TestUpdatePlaylist verifies that client.UpdatePlaylist() is working properly
+func TestUpdatePlaylist(t *testing.T) {
+	log.Println("TestUpdatePlaylist()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Add and remove a song using mock data
+	err = s.UpdatePlaylist(context.Background(), "1", UpdatePlaylistOptions{
+		SongIDToAdd:       []ID{"407"},
+		SongIndexToRemove: []int64{0},
+	})
+	if err != nil {
+		t.Fatalf("UpdatePlaylist returned error: %s", err.Error())
+	}
+}
+
+// TestStar verifies that client.Star() is working properly
+func TestStar(t *testing.T) {
+	log.Println("TestStar()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Star a song, album, and artist
+	if err := s.Star(context.Background(), StarOptions{
+		IDs:       []ID{"1"},
+		AlbumIDs:  []ID{"2"},
+		ArtistIDs: []ID{"3"},
+	}); err != nil {
+		t.Fatalf("Star returned error: %s", err.Error())
+	}
+
+	// Starring nothing should fail
+	if err := s.Star(context.Background(), StarOptions{}); err == nil {
+		t.Fatalf("Star accepted empty options")
+	}
+}
+
+// TestSetRating verifies that client.SetRating() is working properly
+func TestSetRating(t *testing.T) {
+	log.Println("TestSetRating()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	if err := s.SetRating(context.Background(), "1", 5); err != nil {
+		t.Fatalf("SetRating returned error: %s", err.Error())
+	}
+
+	// Ratings out of range should fail
+	if err := s.SetRating(context.Background(), "1", 6); err == nil {
+		t.Fatalf("SetRating accepted invalid rating")
+	}
+}
+
+// TestGetStarred verifies that client.GetStarred() is working properly
+func TestGetStarred(t *testing.T) {
+	log.Println("TestGetStarred()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get starred mock data
+	starred, err := s.GetStarred(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetStarred returned error: %s", err.Error())
+	}
+
+	// Check for known number of items in each category
+	if len(starred.Artists) != 2 || len(starred.Albums) != 0 || len(starred.Songs) != 1 {
+		t.Fatalf("GetStarred returned invalid results: %d artists, %d albums, %d songs",
+			len(starred.Artists), len(starred.Albums), len(starred.Songs))
+	}
+
+	// Check for numeric artist name, converted to string
+	if starred.Artists[1].Name != "311" {
+		t.Fatalf("GetStarred returned invalid artist name: %s", starred.Artists[1].Name)
+	}
+
+	// Check for music folder option
+	if q := (&StarredOptions{MusicFolderID: 2}).query().Encode(); q != "musicFolderId=2" {
+		t.Fatalf("StarredOptions returned invalid query string: %s", q)
+	}
+}
+
+// TestGetStarred2 verifies that client.GetStarred2() is working properly
+func TestGetStarred2(t *testing.T) {
+	log.Println("TestGetStarred2()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get starred mock data
+	starred, err := s.GetStarred2(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetStarred2 returned error: %s", err.Error())
+	}
+
+	// Check for a single album, parsed into a slice
+	if len(starred.Artists) != 0 || len(starred.Albums) != 1 || starred.Albums[0].Name != "Adventure" {
+		t.Fatalf("GetStarred2 returned invalid results: %+v", starred)
+	}
+}
+
+// TestGetScanStatus verifies that client.GetScanStatus() is working properly
+func TestGetScanStatus(t *testing.T) {
+	log.Println("TestGetScanStatus()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get scan status mock data
+	status, err := s.GetScanStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetScanStatus returned error: %s", err.Error())
+	}
+
+	if !status.Scanning || status.Count != 4271 {
+		t.Fatalf("GetScanStatus returned invalid status: %+v", status)
+	}
+}
+
+// TestCreateShare verifies that client.CreateShare() is working properly
+func TestCreateShare(t *testing.T) {
+	log.Println("TestCreateShare()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get share creation mock data
+	share, err := s.CreateShare(context.Background(), []ID{"1"}, "", time.Time{})
+	if err != nil {
+		t.Fatalf("CreateShare returned error: %s", err.Error())
+	}
+
+	// Check for known ID
+	if share.ID != "12" {
+		t.Fatalf("CreateShare returned invalid ID: %s", share.ID)
+	}
+
+	// Check for known URL
+	if share.URL != "http://mock.example.com/share/abc" {
+		t.Fatalf("CreateShare returned invalid URL: %s", share.URL)
+	}
+
+	// Check for invalid "zero" date
+	if share.Created.IsZero() {
+		t.Fatalf("CreateShare returned zero date")
+	}
+}
+
+// TestGetShares verifies that client.GetShares() is working properly
+func TestGetShares(t *testing.T) {
+	log.Println("TestGetShares()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get shares mock data
+	shares, err := s.GetShares(context.Background())
+	if err != nil {
+		t.Fatalf("GetShares returned error: %s", err.Error())
+	}
+
+	if len(shares) != 2 {
+		t.Fatalf("GetShares returned invalid number of shares: %d", len(shares))
+	}
+
+	// Check for visits, expiration, and shared entries
+	sh := shares[0]
+	if sh.VisitCount != 3 || sh.Expires.IsZero() || sh.LastVisited.IsZero() || len(sh.Entry) != 2 {
+		t.Fatalf("GetShares returned invalid share: %+v", sh)
+	}
+
+	// Check for a share which never expires, with a numeric ID converted to string
+	sh = shares[1]
+	if sh.ID != "13" || !sh.Expires.IsZero() || len(sh.Entry) != 0 {
+		t.Fatalf("GetShares returned invalid share: %+v", sh)
+	}
+}
+
+// TestUpdateShare verifies that client.UpdateShare() is working properly
+func TestUpdateShare(t *testing.T) {
+	log.Println("TestUpdateShare()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Update a share, removing its expiration
+	never := time.Time{}
+	if err := s.UpdateShare(context.Background(), "12", UpdateShareOptions{Description: "Updated", Expires: &never}); err != nil {
+		t.Fatalf("UpdateShare returned error: %s", err.Error())
+	}
+
+	// Partial updates only send the fields which are set
+	f := NewFakeServer()
+	defer f.Close()
+
+	expires := time.UnixMilli(1375306344000)
+	f.SetFixture("updateShare", url.Values{"id": {"12"}, "expires": {"1375306344000"}}, mockEmptyResponse)
+	f.SetFixture("updateShare", url.Values{"id": {"12"}, "description": {"Renamed"}}, mockEmptyResponse)
+	s, err = f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	for _, options := range []UpdateShareOptions{{Expires: &expires}, {Description: "Renamed"}} {
+		if err := s.UpdateShare(context.Background(), "12", options); err != nil {
+			t.Fatalf("UpdateShare(%+v) returned error: %s", options, err.Error())
+		}
+	}
+}
+
+// TestGetPodcasts verifies that client.GetPodcasts() is working properly
+func TestGetPodcasts(t *testing.T) {
+	log.Println("TestGetPodcasts()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get podcasts mock data
+	channels, err := s.GetPodcasts(context.Background(), true, "")
+	if err != nil {
+		t.Fatalf("GetPodcasts returned error: %s", err.Error())
+	}
+
+	// Check for known number of channels and episodes
+	if len(channels) != 2 || len(channels[0].Episode) != 2 || len(channels[1].Episode) != 0 {
+		t.Fatalf("GetPodcasts returned invalid channels: %+v", channels)
+	}
+
+	// Check for channel error status
+	if channels[1].Status != PodcastStatusError || channels[1].ErrorMessage == "" {
+		t.Fatalf("GetPodcasts returned invalid channel status: %+v", channels[1])
+	}
+
+	// Check for a downloaded episode, with string IDs converted to integers
+	e := channels[0].Episode[0]
+	if e.ID != "34" || e.StreamID != "523" || e.Status != PodcastStatusCompleted || e.Duration != 3146*time.Second {
+		t.Fatalf("GetPodcasts returned invalid episode: %+v", e)
+	}
+
+	// Check for an episode which is not yet downloaded, with numeric title converted to string
+	e = channels[0].Episode[1]
+	if e.StreamID != "" || e.Status != PodcastStatusNew || e.Title != "311" {
+		t.Fatalf("GetPodcasts returned invalid episode: %+v", e)
+	}
+}
+
+// TestGetLyrics verifies that client.GetLyrics() is working properly
+func TestGetLyrics(t *testing.T) {
+	log.Println("TestGetLyrics()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get lyrics mock data
+	lyrics, err := s.GetLyrics(context.Background(), "311", "Amber")
+	if err != nil {
+		t.Fatalf("GetLyrics returned error: %s", err.Error())
+	}
+
+	// Check for numeric artist converted to string, and unescaped text
+	if lyrics.Artist != "311" || lyrics.Text != "Whoa, now you're amber\nStay this way" {
+		t.Fatalf("GetLyrics returned invalid lyrics: %+v", lyrics)
+	}
+}
+
+// TestGetInternetRadioStations verifies that client.GetInternetRadioStations() is working properly
+func TestGetInternetRadioStations(t *testing.T) {
+	log.Println("TestGetInternetRadioStations()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get internet radio stations mock data
+	stations, err := s.GetInternetRadioStations(context.Background())
+	if err != nil {
+		t.Fatalf("GetInternetRadioStations returned error: %s", err.Error())
+	}
+
+	if len(stations) != 2 {
+		t.Fatalf("GetInternetRadioStations returned invalid number of stations: %d", len(stations))
+	}
+
+	// Check for string ID converted to integer
+	if stations[0].ID != "1" || stations[0].HomePageURL != "http://radio.example.com/" {
+		t.Fatalf("GetInternetRadioStations returned invalid station: %+v", stations[0])
+	}
+
+	// Check for numeric name converted to string, and no home page
+	if stations[1].Name != "311" || stations[1].HomePageURL != "" {
+		t.Fatalf("GetInternetRadioStations returned invalid station: %+v", stations[1])
+	}
+
+	// Creating a station without a stream URL should fail
+	if err := s.CreateInternetRadioStation(context.Background(), "", "Mock", ""); err == nil {
+		t.Fatalf("CreateInternetRadioStation accepted empty stream URL")
+	}
+}
+
+// TestGetChatMessages verifies that client.GetChatMessages() is working properly
+func TestGetChatMessages(t *testing.T) {
+	log.Println("TestGetChatMessages()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get chat messages mock data
+	messages, err := s.GetChatMessages(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("GetChatMessages returned error: %s", err.Error())
+	}
+
+	if len(messages) != 2 {
+		t.Fatalf("GetChatMessages returned invalid number of messages: %d", len(messages))
+	}
+
+	// Check for time converted from milliseconds
+	if !messages[0].Time.Equal(time.Unix(1395014311, 0)) {
+		t.Fatalf("GetChatMessages returned invalid time: %s", messages[0].Time)
+	}
+
+	// Check for numeric message, converted to string
+	if messages[1].Message != "311" {
+		t.Fatalf("GetChatMessages returned invalid message: %s", messages[1].Message)
+	}
+}
+
+// TestGetUser verifies that client.GetUser() is working properly
+func TestGetUser(t *testing.T) {
+	log.Println("TestGetUser()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get user mock data
+	user, err := s.GetUser(context.Background(), "mock")
+	if err != nil {
+		t.Fatalf("GetUser returned error: %s", err.Error())
+	}
+
+	// Check for known roles and settings
+	if user.AdminRole || !user.StreamRole || !user.JukeboxRole || !user.ScrobblingEnabled || user.MaxBitRate != 320 {
+		t.Fatalf("GetUser returned invalid roles: %+v", user)
+	}
+
+	// Check for multiple music folders
+	if len(user.Folder) != 2 || user.Folder[1] != 1 {
+		t.Fatalf("GetUser returned invalid folders: %v", user.Folder)
+	}
+}
+
+// TestGetUsers verifies that client.GetUsers() is working properly
+func TestGetUsers(t *testing.T) {
+	log.Println("TestGetUsers()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get users mock data
+	users, err := s.GetUsers(context.Background())
+	if err != nil {
+		t.Fatalf("GetUsers returned error: %s", err.Error())
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("GetUsers returned invalid number of users: %d", len(users))
+	}
+
+	// Check for a single music folder
+	if !users[0].AdminRole || len(users[0].Folder) != 1 {
+		t.Fatalf("GetUsers returned invalid user: %+v", users[0])
+	}
+
+	// Check for numeric username, converted to string
+	if users[1].Username != "311" || users[1].StreamRole {
+		t.Fatalf("GetUsers returned invalid user: %+v", users[1])
+	}
+}
+
+// TestUserParams verifies that user details are added to the query parameters
+func TestUserParams(t *testing.T) {
+	log.Println("TestUserParams()")
+
+	q := userParams(User{
+		Username:   "mock user",
+		StreamRole: true,
+		Folder:     []int64{0, 2},
+	})
+
+	// All roles are always sent, so that roles may be revoked
+	for _, p := range []string{
+		"username=mock+user",
+		"adminRole=false",
+		"streamRole=true",
+		"musicFolderId=0&musicFolderId=2",
+	} {
+		if !strings.Contains(q.Encode(), p) {
+			t.Fatalf("userParams returned parameters without %s: %s", p, q.Encode())
+		}
+	}
+}
+
+// TestUpdateUserParams verifies that only the user details which are set are added to the
+// query parameters, so partial updates do not revoke roles
+func TestUpdateUserParams(t *testing.T) {
+	log.Println("TestUpdateUserParams()")
+
+	q := updateUserParams("mock", UpdateUserOptions{Email: "mock@example.com"})
+	if q.Encode() != "email=mock%40example.com&username=mock" {
+		t.Fatalf("updateUserParams returned unexpected parameters: %s", q.Encode())
+	}
+
+	revoke := false
+	q = updateUserParams("mock", UpdateUserOptions{AdminRole: &revoke})
+	if q.Encode() != "adminRole=false&username=mock" {
+		t.Fatalf("updateUserParams returned unexpected parameters: %s", q.Encode())
+	}
+}
+
+// TestChangePassword verifies that client.ChangePassword() is working properly
+func TestChangePassword(t *testing.T) {
+	log.Println("TestChangePassword()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Password should be hex encoded
+	if err := s.ChangePassword(context.Background(), "mock", "mock2"); err != nil {
+		t.Fatalf("ChangePassword returned error: %s", err.Error())
+	}
+
+	// An empty password should be rejected
+	if err := s.ChangePassword(context.Background(), "mock", ""); err == nil {
+		t.Fatalf("ChangePassword accepted empty password")
+	}
+}
+
+// TestGetBookmarks verifies that client.GetBookmarks() is working properly
+func TestGetBookmarks(t *testing.T) {
+	log.Println("TestGetBookmarks()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get bookmarks mock data, with a single bookmark
+	bookmarks, err := s.GetBookmarks(context.Background())
+	if err != nil {
+		t.Fatalf("GetBookmarks returned error: %s", err.Error())
+	}
+
+	if len(bookmarks) != 1 {
+		t.Fatalf("GetBookmarks returned invalid number of bookmarks: %d", len(bookmarks))
+	}
+
+	// Check for position in milliseconds, numeric comment, and entry
+	b := bookmarks[0]
+	if b.Position != 90500*time.Millisecond || b.Comment != "311" || b.Entry.ID != "1" {
+		t.Fatalf("GetBookmarks returned invalid bookmark: %+v", b)
+	}
+}
+
+// TestGetPlayQueue verifies that client.GetPlayQueue() is working properly
+func TestGetPlayQueue(t *testing.T) {
+	log.Println("TestGetPlayQueue()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get play queue mock data
+	q, err := s.GetPlayQueue(context.Background())
+	if err != nil {
+		t.Fatalf("GetPlayQueue returned error: %s", err.Error())
+	}
+
+	if q.Current != "2" || q.Position != 30*time.Second || len(q.Entry) != 2 || q.ChangedBy != "gosubsonic" {
+		t.Fatalf("GetPlayQueue returned invalid play queue: %+v", q)
+	}
+}
+
+// TestSavePlayQueue verifies that client.SavePlayQueue() is working properly
+func TestSavePlayQueue(t *testing.T) {
+	log.Println("TestSavePlayQueue()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Save a play queue, resuming the second song 30 seconds in
+	if err := s.SavePlayQueue(context.Background(), []ID{"1", "2"}, "2", 30*time.Second); err != nil {
+		t.Fatalf("SavePlayQueue returned error: %s", err.Error())
+	}
+}
+
+// TestJukeboxGet verifies that client.JukeboxGet() is working properly
+func TestJukeboxGet(t *testing.T) {
+	log.Println("TestJukeboxGet()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Get jukebox playlist mock data
+	playlist, err := s.JukeboxGet(context.Background())
+	if err != nil {
+		t.Fatalf("JukeboxGet returned error: %s", err.Error())
+	}
+
+	// Check for known status
+	if !playlist.Playing || playlist.Gain != 0.75 || playlist.Position != 42 {
+		t.Fatalf("JukeboxGet returned invalid status: %+v", playlist.JukeboxStatus)
+	}
+
+	// Check for known entries
+	if len(playlist.Entry) != 2 {
+		t.Fatalf("JukeboxGet returned invalid number of entries: %d", len(playlist.Entry))
+	}
+
+	// Check for known title
+	if playlist.Entry[1].Title != "Another Day" {
+		t.Fatalf("JukeboxGet returned invalid title: %s", playlist.Entry[1].Title)
+	}
+}
+
+// TestStreamAutoScrobble verifies that client.Stream() submits a "Now Playing" scrobble when enabled
+func TestStreamAutoScrobble(t *testing.T) {
+	log.Println("TestStreamAutoScrobble()")
+
+	// Record scrobble requests made to a test server
+	scrobbles := make([]string, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/stream.view":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("mock audio"))
+		case "/rest/scrobble.view":
+			scrobbles = append(scrobbles, r.URL.RawQuery)
+			fallthrough
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+		}
+	}))
+	defer srv.Close()
+
+	// Generate client for test server
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+	s.AutoScrobble = true
+
+	// Stream with and without opting out of scrobbling
+	for _, options := range []*StreamOptions{nil, {SkipScrobble: true}} {
+		stream, err := s.Stream(context.Background(), "1", options)
+		if err != nil {
+			t.Fatalf("Stream returned error: %s", err.Error())
+		}
+
+		if _, err := ioutil.ReadAll(stream); err != nil {
+			t.Fatalf("Stream returned unreadable stream: %s", err.Error())
+		}
+		stream.Close()
+	}
+
+	// Only the first stream should scrobble
+	if len(scrobbles) != 1 {
+		t.Fatalf("Stream submitted invalid number of scrobbles: %d", len(scrobbles))
+	}
+
+	if !strings.Contains(scrobbles[0], "&id=1&") || !strings.Contains(scrobbles[0], "&submission=false&") {
+		t.Fatalf("Stream submitted invalid scrobble: %s", scrobbles[0])
+	}
+}
+
+// TestStreamResponse verifies that client.Stream() returns metadata about the stream
+func TestStreamResponse(t *testing.T) {
+	log.Println("TestStreamResponse()")
+
+	// Serve a transcoded stream, reporting its duration only when requested
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/stream.view" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "ogg" {
+			w.Header().Set("X-Content-Duration", "214.5")
+		}
+		w.Header().Set("Content-Type", "audio/ogg")
+		w.Header().Set("Content-Length", "16000")
+		w.Write(make([]byte, 16000))
+	}))
+	defer srv.Close()
+
+	// Generate client for test server
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	var tests = []struct {
+		options  *StreamOptions
+		duration time.Duration
+	}{
+		{nil, 0},
+		{&StreamOptions{Format: "ogg"}, 214500 * time.Millisecond},
+		{&StreamOptions{MaxBitRate: 128}, time.Second},
+	}
+
+	for _, test := range tests {
+		stream, err := s.Stream(context.Background(), "1", test.options)
+		if err != nil {
+			t.Fatalf("Stream returned error: %s", err.Error())
+		}
+		stream.Close()
+
+		if stream.ContentType != "audio/ogg" || stream.ContentLength != 16000 {
+			t.Fatalf("Stream returned invalid metadata: %s, %d", stream.ContentType, stream.ContentLength)
+		}
+
+		if stream.Duration != test.duration {
+			t.Fatalf("Stream returned invalid duration: %s != %s", stream.Duration, test.duration)
+		}
+	}
+}
+
+// TestClientNameAPIVersion verifies that client.makeURL() sends the client name and API version
+func TestClientNameAPIVersion(t *testing.T) {
+	log.Println("TestClientNameAPIVersion()")
+
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	var tests = []struct {
+		name      string
+		version   string
+		tokenAuth bool
+		c         string
+		v         string
+	}{
+		{"", "", false, DefaultClientName, DefaultAPIVersion},
+		{"", "", true, DefaultClientName, "1.13.0"},
+		{"myplayer", "1.4.0", false, "myplayer", "1.4.0"},
+		{"myplayer", "1.16.1", true, "myplayer", "1.16.1"},
+	}
+
+	for _, test := range tests {
+		s.ClientName = test.name
+		s.APIVersion = test.version
+		s.TokenAuth = test.tokenAuth
+
+		u, err := url.Parse(s.makeURL("ping", nil))
+		if err != nil {
+			t.Fatalf("makeURL returned invalid URL: %s", err.Error())
+		}
+
+		if q := u.Query(); q.Get("c") != test.c || q.Get("v") != test.v {
+			t.Fatalf("makeURL returned c=%q, v=%q, expected c=%q, v=%q", q.Get("c"), q.Get("v"), test.c, test.v)
+		}
+	}
+}
+
+// TestTokenAuth verifies that client.makeURL() sends a salted token instead of a password
+func TestTokenAuth(t *testing.T) {
+	log.Println("TestTokenAuth()")
+
+	// Generate mock client with token authentication
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+	s.Credentials = Secret(fakePassword)
+	s.TokenAuth = true
+
+	// Parse authentication parameters from two URLs
+	u1, err := url.Parse(s.makeURL("ping", nil))
+	if err != nil {
+		t.Fatalf("makeURL returned invalid URL: %s", err.Error())
+	}
+	u2, err := url.Parse(s.makeURL("ping", nil))
+	if err != nil {
+		t.Fatalf("makeURL returned invalid URL: %s", err.Error())
+	}
+	q1, q2 := u1.Query(), u2.Query()
+
+	// Check that password is never sent
+	if q1.Get("p") != "" {
+		t.Fatalf("makeURL sent password with token authentication: %s", q1.Get("p"))
+	}
+
+	// Check for per-request salt
+	if len(q1.Get("s")) < 6 || q1.Get("s") == q2.Get("s") {
+		t.Fatalf("makeURL returned invalid salts: %s, %s", q1.Get("s"), q2.Get("s"))
+	}
+
+	// Check for token = md5(password + salt)
+	token := md5.Sum([]byte(fakePassword + q1.Get("s")))
+	if q1.Get("t") != hex.EncodeToString(token[:]) {
+		t.Fatalf("makeURL returned invalid token: %s", q1.Get("t"))
+	}
+
+	// Mock data should still be found with token authentication
+	if _, err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping returned error: %s", err.Error())
+	}
+}
+
+// TestMakeURLHost verifies that client.makeURL() handles hosts, schemes, ports, and path prefixes
+func TestMakeURLHost(t *testing.T) {
+	log.Println("TestMakeURLHost()")
+
+	// Table of hosts and expected URL prefixes
+	var tests = []struct {
+		host   string
+		prefix string
+	}{
+		{"example.com", "http://example.com/rest/ping.view?"},
+		{"example.com:4040", "http://example.com:4040/rest/ping.view?"},
+		{"https://example.com", "https://example.com/rest/ping.view?"},
+		{"https://example.com:8443/subsonic", "https://example.com:8443/subsonic/rest/ping.view?"},
+		{"https://example.com/subsonic/", "https://example.com/subsonic/rest/ping.view?"},
+	}
+
+	for _, test := range tests {
+		s := Client{Host: test.host}
+		if u := s.makeURL("ping", nil); !strings.HasPrefix(u, test.prefix) {
+			t.Fatalf("makeURL returned invalid URL for host %s: %s", test.host, u)
+		}
+	}
+}
+
+// TestContextCanceled verifies that client methods honor context cancellation
+func TestContextCanceled(t *testing.T) {
+	log.Println("TestContextCanceled()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Ping with an already canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.Ping(ctx); err != context.Canceled {
+		t.Fatalf("Ping returned invalid error for canceled context: %v", err)
+	}
+}
+
+// roundTripFunc is a http.RoundTripper implemented by a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip invokes the function
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// TestWithHTTPClient verifies that all requests are sent using an injected *http.Client
+func TestWithHTTPClient(t *testing.T) {
+	log.Println("TestWithHTTPClient()")
+
+	// Serve requests from a transport which never touches the network
+	paths := make([]string, 0)
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			paths = append(paths, r.URL.Path)
+
+			// Stream returns audio, everything else returns a JSON status
+			header := http.Header{}
+			body := mockTable[0].data
+			if r.URL.Path == "/subsonic/rest/stream.view" {
+				header.Set("Content-Type", "audio/mpeg")
+				body = []byte("mock audio")
+			} else {
+				header.Set("Content-Type", "application/json")
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       ioutil.NopCloser(strings.NewReader(string(body))),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	// Generate client, which pings using the injected client
+	s, err := New("https://mock.example.com/subsonic", WithPassword("mock", "mock"), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	// Binary requests should also use the injected client
+	stream, err := s.Stream(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	stream.Close()
+
+	if len(paths) != 2 || paths[0] != "/subsonic/rest/ping.view" || paths[1] != "/subsonic/rest/stream.view" {
+		t.Fatalf("Injected client received invalid requests: %v", paths)
+	}
+}
+
+// TestMaxResponseSize verifies that responses larger than the maximum response size are
+// rejected, whether they are decoded as they are read, buffered, or served by a DataSource
+func TestMaxResponseSize(t *testing.T) {
+	log.Println("TestMaxResponseSize()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	// Large JSON and XML responses
+	jsonBody := `{"subsonic-response": {"status": "ok", "version": "1.8.0", "genres": {"genre": [` +
+		strings.Repeat(`{"value": "Rock", "songCount": 1, "albumCount": 1}, `, 100) +
+		`{"value": "Jazz", "songCount": 2, "albumCount": 1}]}}}`
+	xmlBody := `<subsonic-response status="ok" version="1.8.0"><genres>` +
+		strings.Repeat(`<genre songCount="1" albumCount="1">Rock</genre>`, 100) + `</genres></subsonic-response>`
+	f.SetFixture("getGenres", nil, []byte(jsonBody))
+	f.SetFixture("getPlaylists", nil, []byte(xmlBody))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	source := *s
+	source.source = fakeDataSource(jsonBody)
+
+	var tests = []struct {
+		name   string
+		client Client
+		call   func(Client) error
+	}{
+		{"json", *s, func(s Client) error {
+			genres, err := s.GetGenres(context.Background())
+			if err == nil && (len(genres) != 101 || genres[100].Name != "Jazz") {
+				t.Fatalf("GetGenres returned invalid genres: %d", len(genres))
+			}
+			return err
+		}},
+		{"xml", *s, func(s Client) error {
+			_, err := s.GetPlaylists(context.Background(), "")
+			return err
+		}},
+		{"cache", *s, func(s Client) error {
+			// Cached responses are buffered, so they may be stored
+			s.Cache = NewMemoryCache()
+			_, err := s.GetGenres(context.Background())
+			return err
+		}},
+		{"source", source, func(s Client) error {
+			_, err := s.GetGenres(context.Background())
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		// Responses within the limit are decoded
+		test.client.MaxResponseSize = int64(len(jsonBody) + len(xmlBody))
+		if err := test.call(test.client); err != nil {
+			t.Fatalf("%s: request returned error: %s", test.name, err.Error())
+		}
+
+		test.client.MaxResponseSize = 1024
+		if err := test.call(test.client); !errors.Is(err, ErrResponseTooLarge) {
+			t.Fatalf("%s: expected ErrResponseTooLarge, got: %v", test.name, err)
+		}
+
+		// Negative sizes disable the limit
+		test.client.MaxResponseSize = -1
+		if err := test.call(test.client); err != nil {
+			t.Fatalf("%s: unlimited request returned error: %s", test.name, err.Error())
+		}
+	}
+}
+
+// TestCompression verifies that API responses are requested compressed and decompressed
+// transparently, unless compression is disabled
+func TestCompression(t *testing.T) {
+	log.Println("TestCompression()")
+
+	var encoding, accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept-Encoding")
+
+		var zw io.WriteCloser
+		switch encoding {
+		case "gzip":
+			zw = gzip.NewWriter(w)
+		case "deflate":
+			zw = zlib.NewWriter(w)
+		case "raw":
+			zw, _ = flate.NewWriter(w, flate.DefaultCompression)
+		default:
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		// Raw deflate data is also sent as deflate by some servers
+		w.Header().Set("Content-Encoding", strings.Replace(encoding, "raw", "deflate", 1))
+		zw.Write(mockTable[0].data)
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	var tests = []struct {
+		encoding string
+		opts     []Option
+		accept   string
+	}{
+		{"gzip", nil, "gzip, deflate"},
+		{"deflate", nil, "gzip, deflate"},
+		{"raw", nil, "gzip, deflate"},
+		{"", []Option{WithoutCompression()}, "identity"},
+	}
+
+	for _, test := range tests {
+		encoding = test.encoding
+
+		opts := append([]Option{WithPassword("mock", "mock")}, test.opts...)
+		if _, err := New(strings.TrimPrefix(srv.URL, "http://"), opts...); err != nil {
+			t.Fatalf("%q: Ping returned error: %s", test.encoding, err.Error())
+		}
+
+		if accept != test.accept {
+			t.Fatalf("%q: invalid Accept-Encoding: %q != %q", test.encoding, accept, test.accept)
+		}
+	}
+}
+
+// TestConcurrentUse verifies that a Client may be used by many goroutines at once, including
+// its cache, limiter, and interceptors, when run with the race detector
+func TestConcurrentUse(t *testing.T) {
+	log.Println("TestConcurrentUse()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	media := []byte(strings.Repeat("audio", 1024))
+	f.SetMedia("1", "audio/mpeg", media)
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	var mu sync.Mutex
+	var calls int
+	s.Cache = NewMemoryCache()
+	s.Limiter = NewLimiter(RateLimit{MaxInFlight: 8}, map[string]RateLimit{"stream": {MaxInFlight: 4}})
+	s.Interceptors = []Interceptor{func(ctx context.Context, req *Request, next Invoker) error {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+
+		return next(ctx, req)
+	}}
+
+	const workers, iterations = 16, 10
+
+	ctx := context.Background()
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < iterations; j++ {
+				if err := concurrentCall(ctx, s, i+j, media); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent call returned error: %s", err.Error())
+	}
+
+	// Every stream reaches the server, while cached browsing calls may not
+	if n := f.Requests("stream"); n != workers*iterations/4 || calls == 0 {
+		t.Fatalf("Client made %d streams and %d intercepted calls, expected %d streams", n, calls, workers*iterations/4)
+	}
+}
+
+// concurrentCall performs one of several streaming and browsing calls
+func concurrentCall(ctx context.Context, s *Client, n int, media []byte) error {
+	switch n % 4 {
+	case 0:
+		stream, err := s.Stream(ctx, "1", nil)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		b, err := io.ReadAll(stream)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(b, media) {
+			return errors.New("stream returned invalid data")
+		}
+
+		return nil
+	case 1:
+		_, err := s.GetIndexes(ctx, -1, time.Time{})
+		return err
+	case 2:
+		_, err := s.GetMusicDirectory(ctx, "1")
+		return err
+	default:
+		_, err := s.GetSong(ctx, "1")
+		return err
+	}
+}
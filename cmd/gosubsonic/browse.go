@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// browseCommand lists all artists in the index, or the contents of a music directory
+func browseCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	switch len(args) {
+	case 0:
+		return browseIndex(ctx, s)
+	case 1:
+		return browseDirectory(ctx, s, gosubsonic.ID(args[0]))
+	default:
+		return errors.New("usage: browse [id]")
+	}
+}
+
+// browseIndex lists the shortcuts and artists in the index of all music folders
+func browseIndex(ctx context.Context, s *gosubsonic.Client) error {
+	indexes, err := s.GetIndexes(ctx, -1, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "ID\tTYPE\tNAME")
+	for _, a := range indexes.Shortcuts {
+		fmt.Fprintf(w, "%s\tshortcut\t%s\n", a.ID, a.Name)
+	}
+	for _, i := range indexes.Index {
+		for _, a := range i.Artist {
+			fmt.Fprintf(w, "%s\tartist\t%s\n", a.ID, a.Name)
+		}
+	}
+
+	return w.Flush()
+}
+
+// browseDirectory lists the items in a music directory, in the order returned by the server
+func browseDirectory(ctx context.Context, s *gosubsonic.Client, id gosubsonic.ID) error {
+	content, err := s.GetMusicDirectory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "ID\tTYPE\tTITLE\tDURATION")
+	for _, c := range content.Children {
+		switch c := c.(type) {
+		case *gosubsonic.Directory:
+			fmt.Fprintf(w, "%s\tdirectory\t%s\t\n", c.ID, c.Title)
+		case *gosubsonic.Audio:
+			fmt.Fprintf(w, "%s\taudio\t%s\t%s\n", c.ID, c.Title, c.Duration)
+		case *gosubsonic.Video:
+			fmt.Fprintf(w, "%s\tvideo\t%s\t%s\n", c.ID, c.Title, c.Duration)
+		}
+	}
+
+	return w.Flush()
+}
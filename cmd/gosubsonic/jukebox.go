@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// jukeboxRefresh is the interval at which the jukebox playlist is redrawn
+const jukeboxRefresh = 2 * time.Second
+
+// jukeboxGainStep is the amount the volume changes with each keypress
+const jukeboxGainStep = 0.1
+
+// jukeboxHelp describes the keyboard controls for the jukebox command
+const jukeboxHelp = `controls (press enter after each):
+  p          play/pause        n / b      next/previous track
+  + / -      volume up/down    j <index>  jump to track
+  a <id...>  add media         r <index>  remove track
+  s          shuffle           c          clear playlist
+  l          redraw            q          quit`
+
+// jukeboxCommand maps keyboard controls to jukebox actions, and displays the jukebox playlist live
+func jukeboxCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	// Read controls from stdin in the background
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+
+		close(lines)
+	}()
+
+	// Draw the initial playlist
+	playlist, err := s.JukeboxGet(ctx)
+	if err != nil {
+		return err
+	}
+	drawJukebox(playlist, "")
+
+	ticker := time.NewTicker(jukeboxRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			// Stop on end of input or quit
+			if !ok || strings.TrimSpace(line) == "q" {
+				return nil
+			}
+
+			// Apply the control, reporting any errors in the status line
+			status := ""
+			if err := jukeboxControl(ctx, s, playlist, line); err != nil {
+				status = err.Error()
+			}
+
+			// Always redraw after a control
+			if p, err := s.JukeboxGet(ctx); err == nil {
+				playlist = p
+			}
+			drawJukebox(playlist, status)
+		case <-ticker.C:
+			// Redraw only when the playlist changes
+			p, err := s.JukeboxGet(ctx)
+			if err != nil {
+				drawJukebox(playlist, err.Error())
+				continue
+			}
+
+			if !reflect.DeepEqual(p, playlist) {
+				playlist = p
+				drawJukebox(playlist, "")
+			}
+		}
+	}
+}
+
+// jukeboxControl applies a single line of keyboard input to the jukebox
+func jukeboxControl(ctx context.Context, s *gosubsonic.Client, playlist *gosubsonic.JukeboxPlaylist, line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var err error
+	switch fields[0] {
+	case "p":
+		if playlist.Playing {
+			_, err = s.JukeboxStop(ctx)
+		} else {
+			_, err = s.JukeboxStart(ctx)
+		}
+	case "n":
+		_, err = s.JukeboxSkip(ctx, playlist.CurrentIndex+1, 0)
+	case "b":
+		if playlist.CurrentIndex > 0 {
+			_, err = s.JukeboxSkip(ctx, playlist.CurrentIndex-1, 0)
+		}
+	case "+":
+		_, err = s.JukeboxSetGain(ctx, clampGain(playlist.Gain+jukeboxGainStep))
+	case "-":
+		_, err = s.JukeboxSetGain(ctx, clampGain(playlist.Gain-jukeboxGainStep))
+	case "j", "r":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: %s <index>", fields[0])
+		}
+
+		index, perr := strconv.ParseInt(fields[1], 10, 64)
+		if perr != nil {
+			return fmt.Errorf("invalid index: %s", fields[1])
+		}
+
+		if fields[0] == "j" {
+			_, err = s.JukeboxSkip(ctx, index, 0)
+		} else {
+			_, err = s.JukeboxRemove(ctx, index)
+		}
+	case "a":
+		ids, perr := parseIDs(fields[1:])
+		if perr != nil {
+			return perr
+		}
+
+		_, err = s.JukeboxAdd(ctx, ids)
+	case "s":
+		_, err = s.JukeboxShuffle(ctx)
+	case "c":
+		_, err = s.JukeboxClear(ctx)
+	case "l":
+		return nil
+	default:
+		return fmt.Errorf("unknown control: %s", fields[0])
+	}
+
+	return err
+}
+
+// drawJukebox clears the terminal and draws the jukebox status and playlist
+func drawJukebox(playlist *gosubsonic.JukeboxPlaylist, status string) {
+	// Clear screen and move cursor to top left
+	fmt.Print("\033[H\033[2J")
+
+	state := "stopped"
+	if playlist.Playing {
+		state = "playing"
+	}
+
+	fmt.Printf("jukebox: %s, volume %d%%, position %s\n\n",
+		state, int(playlist.Gain*100), time.Duration(playlist.Position)*time.Second)
+
+	if len(playlist.Entry) == 0 {
+		fmt.Println("  (playlist is empty)")
+	}
+
+	for i, a := range playlist.Entry {
+		marker := " "
+		if int64(i) == playlist.CurrentIndex {
+			marker = ">"
+		}
+
+		fmt.Printf("%s %3d. %s - %s [%s]\n", marker, i, a.Artist, a.Title, a.Duration)
+	}
+
+	fmt.Printf("\n%s\n", jukeboxHelp)
+	if status != "" {
+		fmt.Printf("\nerror: %s\n", status)
+	}
+}
+
+// clampGain keeps a gain value within the range accepted by Subsonic
+func clampGain(gain float64) float64 {
+	if gain < 0 {
+		return 0
+	}
+	if gain > 1 {
+		return 1
+	}
+
+	return gain
+}
+
+// parseIDs parses a list of media IDs from command arguments
+func parseIDs(args []string) ([]gosubsonic.ID, error) {
+	if len(args) == 0 {
+		return nil, errors.New("at least one ID is required")
+	}
+
+	ids := make([]gosubsonic.ID, 0, len(args))
+	for _, a := range args {
+		ids = append(ids, gosubsonic.ID(a))
+	}
+
+	return ids, nil
+}
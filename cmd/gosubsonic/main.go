@@ -0,0 +1,125 @@
+// Command gosubsonic is a command-line client for Subsonic servers, built on the gosubsonic package.
+// Each subcommand accepts its own flags after its name, such as:
+//
+//	gosubsonic -host music.example.com -user alice -pass secret -token search -n 5 wonderland
+//	gosubsonic -host music.example.com -user alice -pass secret stream -format mp3 123 | mpv -
+//
+// Listings are written to stdout as aligned columns, with IDs in the first column so they may
+// be used in scripts.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// Flags used to connect to a Subsonic server
+var (
+	host     = flag.String("host", "", "Subsonic server host with optional port, or base URL such as https://example.com/subsonic")
+	username = flag.String("user", "", "Subsonic username")
+	password = flag.String("pass", "", "Subsonic password")
+	token    = flag.Bool("token", false, "use token authentication (Subsonic 1.13.0+), so the password is never sent")
+	verbose  = flag.Bool("v", false, "log each API call to stderr")
+	lenient  = flag.Bool("lenient", false, "skip malformed items in responses, instead of failing")
+)
+
+// command represents a subcommand of the CLI
+type command struct {
+	Name  string
+	Usage string
+	Run   func(context.Context, *gosubsonic.Client, []string) error
+}
+
+// commands is the list of all available subcommands
+var commands = []command{
+	{"browse", "list artists, or the contents of a directory", browseCommand},
+	{"search", "search for artists, albums, and songs", searchCommand},
+	{"stream", "write a media stream to stdout", streamCommand},
+	{"download", "download original media files", downloadCommand},
+	{"playlists", "list playlists, or the songs in a playlist", playlistsCommand},
+	{"scrobble", "submit songs as played or now playing", scrobbleCommand},
+	{"now-playing", "list songs currently being played", nowPlayingCommand},
+	{"jukebox", "control the server jukebox interactively", jukeboxCommand},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	// Check for a subcommand
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	// Find the requested subcommand
+	var cmd *command
+	for i := range commands {
+		if commands[i].Name == flag.Arg(0) {
+			cmd = &commands[i]
+			break
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "gosubsonic: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	// Cancel requests on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Connect to Subsonic
+	opts := []gosubsonic.Option{
+		gosubsonic.WithPassword(*username, *password),
+		gosubsonic.WithClientName("gosubsonic-cli"),
+	}
+	if *token {
+		opts = append(opts, gosubsonic.WithTokenAuth())
+	}
+	if *verbose {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, gosubsonic.WithLogger(logger, gosubsonic.RedactCredentials))
+	}
+	if *lenient {
+		opts = append(opts, gosubsonic.WithLenientParsing())
+	}
+
+	s, err := gosubsonic.New(*host, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Run the subcommand
+	if err := cmd.Run(ctx, s, flag.Args()[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// usage prints the available flags and subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gosubsonic [flags] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.Name, c.Usage)
+	}
+
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flag.PrintDefaults()
+}
+
+// newTable returns a writer which aligns tab-separated columns of output on stdout, and must
+// be flushed
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// streamCommand writes a media file stream to stdout, so it may be piped into a player
+func streamCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	fs := flag.NewFlagSet("stream", flag.ContinueOnError)
+	format := fs.String("format", "", "transcode to a format, such as mp3, or \"raw\" for the original file")
+	bitRate := fs.Int64("bitrate", 0, "maximum bit rate in kbps")
+	offset := fs.Int64("offset", 0, "start position in seconds, for transcoded streams")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: stream [-format format] [-bitrate kbps] [-offset seconds] <id>")
+	}
+
+	stream, err := s.Stream(ctx, gosubsonic.ID(fs.Arg(0)), &gosubsonic.StreamOptions{
+		Format:     *format,
+		MaxBitRate: *bitRate,
+		TimeOffset: *offset,
+	})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = io.Copy(os.Stdout, stream)
+	return err
+}
+
+// downloadCommand downloads original media files into a directory, named from their metadata
+func downloadCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to download files into")
+	template := fs.String("template", gosubsonic.DefaultDownloadTemplate, "template used to name files")
+	tags := fs.Bool("tags", false, "write the server's metadata into MP3 and FLAC files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return errors.New("usage: download [-dir dir] [-template template] [-tags] <id...>")
+	}
+
+	options := &gosubsonic.DownloadOptions{
+		Template: *template,
+		Tags:     *tags,
+		CoverArt: *tags,
+	}
+	for _, id := range ids {
+		path, err := s.DownloadTo(ctx, id, *dir, options)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// playlistsCommand lists the playlists visible to the user, or the songs in a playlist
+func playlistsCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	switch len(args) {
+	case 0:
+		playlists, err := s.GetPlaylists(ctx, "")
+		if err != nil {
+			return err
+		}
+
+		w := newTable()
+		fmt.Fprintln(w, "ID\tNAME\tOWNER\tSONGS\tDURATION")
+		for _, p := range playlists {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", p.ID, p.Name, p.Owner, p.SongCount, p.Duration)
+		}
+
+		return w.Flush()
+	case 1:
+		playlist, err := s.GetPlaylist(ctx, gosubsonic.ID(args[0]))
+		if err != nil {
+			return err
+		}
+
+		w := newTable()
+		fmt.Fprintln(w, "#\tID\tARTIST\tTITLE\tDURATION")
+		for i, a := range playlist.Entry {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, a.ID, a.Artist, a.Title, a.Duration)
+		}
+
+		return w.Flush()
+	default:
+		return errors.New("usage: playlists [id]")
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// scrobbleCommand submits songs as played, or as now playing
+func scrobbleCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	fs := flag.NewFlagSet("scrobble", flag.ContinueOnError)
+	now := fs.Bool("now", false, "submit as now playing, rather than played")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return errors.New("usage: scrobble [-now] <id...>")
+	}
+
+	// Played songs are submitted with the current time, in milliseconds
+	var at int64
+	if !*now {
+		at = time.Now().UnixMilli()
+	}
+
+	for _, id := range ids {
+		if err := s.Scrobble(ctx, id, at, !*now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nowPlayingCommand lists the songs currently being played by all users
+func nowPlayingCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: now-playing")
+	}
+
+	entries, err := s.GetNowPlaying(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "USER\tPLAYER\tMINUTES AGO\tID\tARTIST\tTITLE")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", e.Username, e.PlayerName, e.MinutesAgo, e.ID, e.Artist, e.Title)
+	}
+
+	return w.Flush()
+}
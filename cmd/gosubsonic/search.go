@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// searchCommand searches the library for artists, albums, and songs organized by ID3 tags
+func searchCommand(ctx context.Context, s *gosubsonic.Client, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	count := fs.Int64("n", 20, "maximum number of results of each type")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		return errors.New("usage: search [-n count] <query>")
+	}
+
+	res, err := s.Search3(ctx, query, &gosubsonic.SearchOptions{
+		ArtistCount: *count,
+		AlbumCount:  *count,
+		SongCount:   *count,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "ID\tTYPE\tARTIST\tTITLE\tDURATION")
+	for _, a := range res.Artists {
+		fmt.Fprintf(w, "%s\tartist\t%s\t\t\n", a.ID, a.Name)
+	}
+	for _, a := range res.Albums {
+		fmt.Fprintf(w, "%s\talbum\t%s\t%s\t%s\n", a.ID, a.Artist, a.Name, a.Duration)
+	}
+	for _, a := range res.Songs {
+		fmt.Fprintf(w, "%s\tsong\t%s\t%s\t%s\n", a.ID, a.Artist, a.Title, a.Duration)
+	}
+
+	return w.Flush()
+}
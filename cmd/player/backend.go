@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Backend plays an audio stream, blocking until playback completes or ctx is canceled
+type Backend interface {
+	Play(ctx context.Context, r io.Reader) error
+}
+
+// newBackend creates a Backend from a command line, or "null" to discard audio
+func newBackend(command string) (Backend, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("no audio backend specified")
+	}
+
+	if fields[0] == "null" {
+		return nullBackend{}, nil
+	}
+
+	return commandBackend{
+		name: fields[0],
+		args: fields[1:],
+	}, nil
+}
+
+// commandBackend plays audio by piping the stream to an external command, such as mpv or ffplay
+type commandBackend struct {
+	name string
+	args []string
+}
+
+// Play runs the command with the stream as its standard input
+func (b commandBackend) Play(ctx context.Context, r io.Reader) error {
+	cmd := exec.CommandContext(ctx, b.name, b.args...)
+	cmd.Stdin = r
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		// Stopped by the player, not a playback failure
+		return nil
+	}
+
+	return err
+}
+
+// nullBackend discards audio, which is useful for testing against a server
+type nullBackend struct{}
+
+// Play reads the stream to completion, or until ctx is canceled
+func (nullBackend) Play(ctx context.Context, r io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if _, err := r.Read(buf); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
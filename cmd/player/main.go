@@ -0,0 +1,315 @@
+// Command player is a small terminal music player built on the gosubsonic package.  It
+// browses a Subsonic library, queues songs, and plays them through a pluggable audio
+// backend, scrobbling as it goes.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// Flags used to connect to a Subsonic server and play audio
+var (
+	host     = flag.String("host", "", "Subsonic server host with optional port, or base URL such as https://example.com/subsonic")
+	username = flag.String("user", "", "Subsonic username")
+	password = flag.String("pass", "", "Subsonic password")
+	token    = flag.Bool("token", false, "use token authentication (Subsonic 1.13.0+), so the password is never sent")
+	backend  = flag.String("backend", "mpv --no-video --really-quiet -", `audio backend command which reads a stream from stdin, or "null" to discard audio`)
+)
+
+// help describes the player's commands
+const help = `commands:
+  ls [id]      list artists, or the contents of a directory
+  search <q>   search for artists, albums, and songs
+  add <id...>  queue a song, or all songs in a directory
+  queue        show the queue
+  star [id]    star a song, or the current song
+  unstar [id]  unstar a song, or the current song
+  starred      list starred songs
+  play [n]     play the queue, or the song at queue index n
+  next, prev   skip forward or back in the queue
+  stop         stop playback
+  clear        stop playback and clear the queue
+  help         show this help
+  quit         exit the player`
+
+func main() {
+	flag.Parse()
+
+	b, err := newBackend(*backend)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Connect to Subsonic, submitting "Now Playing" scrobbles as songs start
+	opts := []gosubsonic.Option{
+		gosubsonic.WithPassword(*username, *password),
+		gosubsonic.WithClientName("gosubsonic-player"),
+	}
+	if *token {
+		opts = append(opts, gosubsonic.WithTokenAuth())
+	}
+
+	s, err := gosubsonic.New(*host, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.AutoScrobble = true
+
+	// Read commands from stdin in the background
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+
+		close(lines)
+	}()
+
+	// Cancel requests on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	p := newPlayer(s, b)
+	fmt.Println(help)
+	prompt(p)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.stop()
+			return
+		case line, ok := <-lines:
+			if !ok || strings.TrimSpace(line) == "quit" {
+				p.stop()
+				return
+			}
+
+			if err := command(ctx, s, p, strings.Fields(line)); err != nil {
+				fmt.Println("error:", err)
+			}
+		case pb := <-p.finished:
+			if err := p.done(pb); err != nil {
+				fmt.Println("error:", err)
+			}
+		}
+
+		prompt(p)
+	}
+}
+
+// command runs a single player command
+func command(ctx context.Context, s *gosubsonic.Client, p *player, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	args := fields[1:]
+	switch fields[0] {
+	case "ls":
+		if len(args) == 0 {
+			return listIndexes(ctx, s)
+		}
+
+		return listDirectory(ctx, s, gosubsonic.ID(args[0]))
+	case "search":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: search <query>")
+		}
+
+		return search(ctx, s, strings.Join(args, " "))
+	case "add":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: add <id...>")
+		}
+
+		for _, a := range args {
+			songs, err := resolveSongs(ctx, s, gosubsonic.ID(a))
+			if err != nil {
+				return err
+			}
+
+			p.add(songs...)
+			fmt.Printf("queued %d song(s)\n", len(songs))
+		}
+	case "queue":
+		if len(p.queue) == 0 {
+			fmt.Println("(queue is empty)")
+		}
+
+		for i, a := range p.queue {
+			marker := " "
+			if i == p.current {
+				marker = ">"
+			}
+
+			fmt.Printf("%s %3d. %s - %s [%s]\n", marker, i, a.Artist, a.Title, a.Duration)
+		}
+	case "star", "unstar":
+		id, err := songID(p, args)
+		if err != nil {
+			return err
+		}
+
+		if fields[0] == "star" {
+			return s.Star(ctx, gosubsonic.StarOptions{IDs: []gosubsonic.ID{id}})
+		}
+
+		return s.Unstar(ctx, gosubsonic.StarOptions{IDs: []gosubsonic.ID{id}})
+	case "starred":
+		starred, err := s.GetStarred(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if len(starred.Songs) == 0 {
+			fmt.Println("(no starred songs)")
+		}
+
+		for _, a := range starred.Songs {
+			fmt.Printf("  %6s  %s - %s [%s]\n", a.ID, a.Artist, a.Title, a.Duration)
+		}
+	case "play":
+		index := p.current
+		if index < 0 {
+			index = 0
+		}
+
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid queue index: %s", args[0])
+			}
+
+			index = n
+		}
+
+		return p.play(index)
+	case "next":
+		return p.next()
+	case "prev":
+		return p.previous()
+	case "stop":
+		p.stop()
+	case "clear":
+		p.clear()
+	case "help":
+		fmt.Println(help)
+	default:
+		return fmt.Errorf("unknown command: %s", fields[0])
+	}
+
+	return nil
+}
+
+// listIndexes prints all artists in the library
+func listIndexes(ctx context.Context, s *gosubsonic.Client) error {
+	indexes, err := s.GetIndexes(ctx, -1, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	for _, i := range indexes.Index {
+		fmt.Printf("[%s]\n", i.Name)
+		for _, a := range i.Artist {
+			fmt.Printf("  %6s  %s\n", a.ID, a.Name)
+		}
+	}
+
+	return nil
+}
+
+// listDirectory prints the directories, songs, and videos in a music directory, in the
+// server's order
+func listDirectory(ctx context.Context, s *gosubsonic.Client, id gosubsonic.ID) error {
+	content, err := s.GetMusicDirectory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range content.Children {
+		switch ch := ch.(type) {
+		case *gosubsonic.Directory:
+			fmt.Printf("  %6s  %s/\n", ch.ID, ch.Title)
+		case *gosubsonic.Audio:
+			fmt.Printf("  %6s  %02d. %s [%s]\n", ch.ID, ch.Track, ch.Title, ch.Duration)
+		case *gosubsonic.Video:
+			fmt.Printf("  %6s  %s [video]\n", ch.ID, ch.Title)
+		}
+	}
+
+	return nil
+}
+
+// search prints the artists, albums, and songs matching a query
+func search(ctx context.Context, s *gosubsonic.Client, query string) error {
+	res, err := s.Search2(ctx, query, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Artists)+len(res.Albums)+len(res.Songs) == 0 {
+		fmt.Println("(no results)")
+	}
+
+	for _, a := range res.Artists {
+		fmt.Printf("  %6s  %s/\n", a.ID, a.Name)
+	}
+	for _, d := range res.Albums {
+		fmt.Printf("  %6s  %s - %s/\n", d.ID, d.Artist, d.Title)
+	}
+	for _, a := range res.Songs {
+		fmt.Printf("  %6s  %s - %s [%s]\n", a.ID, a.Artist, a.Title, a.Duration)
+	}
+
+	return nil
+}
+
+// songID returns the song ID given in command arguments, or the currently playing song
+func songID(p *player, args []string) (gosubsonic.ID, error) {
+	if len(args) > 0 {
+		return gosubsonic.ID(args[0]), nil
+	}
+
+	song, ok := p.playing()
+	if !ok {
+		return "", fmt.Errorf("nothing is playing")
+	}
+
+	return song.ID, nil
+}
+
+// resolveSongs returns all songs in a directory, or a single song, for an ID
+func resolveSongs(ctx context.Context, s *gosubsonic.Client, id gosubsonic.ID) ([]gosubsonic.Audio, error) {
+	// Directory IDs queue all of their songs
+	if content, err := s.GetMusicDirectory(ctx, id); err == nil && len(content.Audio) > 0 {
+		return content.Audio, nil
+	}
+
+	song, err := s.GetSong(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return []gosubsonic.Audio{*song}, nil
+}
+
+// prompt prints the currently playing song and an input prompt
+func prompt(p *player) {
+	if song, ok := p.playing(); ok {
+		fmt.Printf("now playing: %s - %s\n", song.Artist, song.Title)
+	}
+
+	fmt.Print("> ")
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/gosubsonic"
+)
+
+// progressInterval is the interval at which playback progress is reported for scrobbling
+const progressInterval = time.Second
+
+// player manages a queue of songs, and plays them one at a time using a Backend.
+// All methods must be called from the same goroutine; playback goroutines report back
+// over the finished channel.
+type player struct {
+	client  *gosubsonic.Client
+	backend Backend
+
+	queue   []gosubsonic.Audio
+	current int
+
+	// generation identifies the active playback, so stale completions are ignored
+	generation int
+	cancel     context.CancelFunc
+	finished   chan playback
+}
+
+// playback reports the completion of a song's playback
+type playback struct {
+	generation int
+	err        error
+}
+
+// newPlayer creates a player with an empty queue
+func newPlayer(client *gosubsonic.Client, backend Backend) *player {
+	return &player{
+		client:   client,
+		backend:  backend,
+		current:  -1,
+		finished: make(chan playback),
+	}
+}
+
+// add appends songs to the end of the queue
+func (p *player) add(songs ...gosubsonic.Audio) {
+	p.queue = append(p.queue, songs...)
+}
+
+// clear stops playback and empties the queue
+func (p *player) clear() {
+	p.stop()
+	p.queue = nil
+	p.current = -1
+}
+
+// play stops any current playback, and starts playing the song at index in the queue
+func (p *player) play(index int) error {
+	if index < 0 || index >= len(p.queue) {
+		return fmt.Errorf("no song at queue index %d", index)
+	}
+
+	p.stop()
+	p.current = index
+	p.generation++
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	go p.run(ctx, p.generation, p.queue[index])
+	return nil
+}
+
+// next plays the next song in the queue, if there is one
+func (p *player) next() error {
+	return p.play(p.current + 1)
+}
+
+// previous plays the previous song in the queue, if there is one
+func (p *player) previous() error {
+	return p.play(p.current - 1)
+}
+
+// stop stops the current playback, if any
+func (p *player) stop() {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// playing returns the currently playing song, if any
+func (p *player) playing() (gosubsonic.Audio, bool) {
+	if p.cancel == nil || p.current < 0 || p.current >= len(p.queue) {
+		return gosubsonic.Audio{}, false
+	}
+
+	return p.queue[p.current], true
+}
+
+// done handles the completion of a playback, advancing the queue when a song ends naturally
+func (p *player) done(pb playback) error {
+	// Ignore playbacks which were replaced or stopped
+	if pb.generation != p.generation || p.cancel == nil {
+		return nil
+	}
+
+	p.stop()
+	if pb.err != nil {
+		return pb.err
+	}
+
+	// Stop at the end of the queue
+	if p.current+1 >= len(p.queue) {
+		return nil
+	}
+
+	return p.next()
+}
+
+// run streams a song to the backend, reporting progress for scrobbling until playback ends
+func (p *player) run(ctx context.Context, generation int, song gosubsonic.Audio) {
+	err := p.stream(ctx, song)
+
+	// Report completion, unless the player has moved on
+	select {
+	case p.finished <- playback{generation: generation, err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// stream opens a stream for a song, and plays it until completion or cancellation
+func (p *player) stream(ctx context.Context, song gosubsonic.Audio) error {
+	// Opening the stream also submits a "Now Playing" scrobble
+	stream, err := p.client.Stream(ctx, song.ID, nil)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// Report wall clock progress, which submits the scrobble once enough is played
+	session := p.client.NewSession(song.ID, song.Duration, nil)
+	start := time.Now()
+	stopProgress := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				session.Progress(ctx, time.Since(start))
+			case <-stopProgress:
+				return
+			}
+		}
+	}()
+
+	err = p.backend.Play(ctx, stream)
+	close(stopProgress)
+
+	// Report final progress even when stopped, since a stop may complete the play
+	session.Progress(context.Background(), time.Since(start))
+
+	return err
+}
@@ -0,0 +1,233 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Image formats which cover art may be decoded from, or encoded to
+const (
+	ImageFormatJPEG = "jpeg"
+	ImageFormatPNG  = "png"
+	ImageFormatGIF  = "gif"
+)
+
+// imageContentTypes maps an image content type to its format
+var imageContentTypes = map[string]string{
+	"image/gif":  ImageFormatGIF,
+	"image/jpeg": ImageFormatJPEG,
+	"image/jpg":  ImageFormatJPEG,
+	"image/png":  ImageFormatPNG,
+}
+
+// CoverArtImage is a decoded cover art image
+type CoverArtImage struct {
+	image.Image
+
+	// Format is the format of the image, such as "jpeg" or "png".  It is detected from the
+	// response's Content-Type, or from the image data if the server did not report one.
+	Format string
+
+	// ContentType is the MIME type reported by the server
+	ContentType string
+}
+
+// CoverArtCache stores cover art on disk, keyed by ID and size, so that views such as album
+// grids do not repeatedly fetch the same images.  Concurrent requests for the same image
+// share a single fetch.  A CoverArtCache is safe for concurrent use.
+type CoverArtCache struct {
+	client Client
+	dir    string
+
+	mu       sync.Mutex
+	inflight map[string]*coverArtCall
+}
+
+// coverArtCall represents an in-progress fetch of a single image, which other requests for
+// the same image wait on
+type coverArtCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewCoverArtCache creates a new CoverArtCache which stores images in the specified
+// directory, creating it if needed
+func (s Client) NewCoverArtCache(dir string) (*CoverArtCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to create cover art cache: %s", err.Error())
+	}
+
+	return &CoverArtCache{
+		client:   s,
+		dir:      dir,
+		inflight: make(map[string]*coverArtCall),
+	}, nil
+}
+
+// Get returns a io.ReadCloser which contains a cover art image, scaled to the specified size.
+// The image is read from disk if cached, or else fetched and cached first.
+func (c *CoverArtCache) Get(ctx context.Context, id ID, size int64) (io.ReadCloser, error) {
+	path, err := c.Path(ctx, id, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+// Path returns the path to a cached cover art image on disk, scaled to the specified size,
+// fetching and caching it first if needed
+func (c *CoverArtCache) Path(ctx context.Context, id ID, size int64) (string, error) {
+	name := c.name(id, size)
+	path := filepath.Join(c.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	// Join an in-progress fetch for the same image, or start a new one
+	c.mu.Lock()
+	call, ok := c.inflight[name]
+	if !ok {
+		call = &coverArtCall{done: make(chan struct{})}
+		c.inflight[name] = call
+	}
+	c.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+			return path, call.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	call.err = c.fetch(ctx, id, size, path)
+
+	c.mu.Lock()
+	delete(c.inflight, name)
+	c.mu.Unlock()
+	close(call.done)
+
+	return path, call.err
+}
+
+// Prefetch fetches and caches cover art for each of the specified IDs, scaled to the specified
+// size, with bounded concurrency.  Images which are already cached are not fetched again.  If
+// any image cannot be fetched, a *BulkError describing each failure is returned.
+func (c *CoverArtCache) Prefetch(ctx context.Context, ids []ID, size int64) error {
+	return bulkFetch(ctx, ids, func(i int, id ID) error {
+		_, err := c.Path(ctx, id, size)
+		return err
+	})
+}
+
+// Remove removes a cached cover art image of the specified size, if it exists
+func (c *CoverArtCache) Remove(id ID, size int64) error {
+	err := os.Remove(filepath.Join(c.dir, c.name(id, size)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// name returns the file name of a cached cover art image.  IDs are escaped, since servers
+// may use IDs which are not valid file names.
+func (c *CoverArtCache) name(id ID, size int64) string {
+	return fmt.Sprintf("%s-%d", url.PathEscape(string(id)), size)
+}
+
+// fetch retrieves a cover art image, and writes it to path.  The image is written to a
+// temporary file first, so partial images are never visible in the cache.
+func (c *CoverArtCache) fetch(ctx context.Context, id ID, size int64, path string) error {
+	art, err := c.client.GetCoverArt(ctx, id, size)
+	if err != nil {
+		return err
+	}
+	defer art.Close()
+
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, art); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// GetCoverArtImage retrieves a cover art image, scaled to the specified size, and decodes it.
+// JPEG, PNG, and GIF images are supported.
+func (s Client) GetCoverArtImage(ctx context.Context, id ID, size int64) (*CoverArtImage, error) {
+	res, err := s.fetchBinaryResponse(ctx, s.coverArtURL(id, size), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	img, format, err := image.Decode(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to decode cover art %s: %s", id, err.Error())
+	}
+
+	// Prefer the format reported by the server, falling back to the decoder's
+	contentType := res.Header.Get("Content-Type")
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		if f, ok := imageContentTypes[mt]; ok {
+			format = f
+		}
+	}
+
+	return &CoverArtImage{
+		Image:       img,
+		Format:      format,
+		ContentType: contentType,
+	}, nil
+}
+
+// Encode writes the image to w in the specified format, such as "jpeg" or "png".  quality is
+// the JPEG quality from 1 to 100, or 0 for the default, and is ignored for other formats.
+func (c *CoverArtImage) Encode(w io.Writer, format string, quality int) error {
+	switch format {
+	case ImageFormatJPEG, "jpg":
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		if quality > 100 {
+			quality = 100
+		}
+
+		return jpeg.Encode(w, c.Image, &jpeg.Options{Quality: quality})
+	case ImageFormatPNG:
+		return png.Encode(w, c.Image)
+	case ImageFormatGIF:
+		return gif.Encode(w, c.Image, nil)
+	default:
+		return fmt.Errorf("gosubsonic: unsupported image format: %q", format)
+	}
+}
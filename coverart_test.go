@@ -0,0 +1,191 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCoverArtCache verifies that CoverArtCache fetches each image once, including for
+// concurrent requests, and reports failures from Prefetch
+func TestCoverArtCache(t *testing.T) {
+	log.Println("TestCoverArtCache()")
+
+	var fetches int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/ping.view" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		atomic.AddInt32(&fetches, 1)
+		<-release
+
+		// Cover art 3 does not exist
+		id := r.URL.Query().Get("id")
+		if id == "3" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"subsonic-response": {"status": "failed", "version": "1.9.0",
+				"error": {"code": 70, "message": "Cover art not found"}}}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("image " + id + " " + r.URL.Query().Get("size")))
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	c, err := s.NewCoverArtCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCoverArtCache returned error: %s", err.Error())
+	}
+
+	// Concurrent requests for the same image share a single fetch
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Path(context.Background(), "1", 300); err != nil {
+				t.Errorf("Path returned error: %s", err.Error())
+			}
+		}()
+	}
+
+	// Complete the fetch once it begins, so waiting requests either join it or find the
+	// cached image afterward
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Fatalf("CoverArtCache made %d fetches for concurrent requests", n)
+	}
+
+	// Cached images are read from disk
+	art, err := c.Get(context.Background(), "1", 300)
+	if err != nil {
+		t.Fatalf("Get returned error: %s", err.Error())
+	}
+	out, _ := io.ReadAll(art)
+	art.Close()
+
+	if string(out) != "image 1 300" || atomic.LoadInt32(&fetches) != 1 {
+		t.Fatalf("Get returned %q after %d fetches", out, atomic.LoadInt32(&fetches))
+	}
+
+	// Prefetch only fetches uncached images, and reports failures
+	err = c.Prefetch(context.Background(), []ID{"1", "2", "3"}, 300)
+
+	var be *BulkError
+	if !errors.As(err, &be) || len(be.Items) != 1 || be.Items[0].ID != "3" || !IsNotFound(be.Items[0].Err) {
+		t.Fatalf("Prefetch returned unexpected error: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&fetches); n != 3 {
+		t.Fatalf("Prefetch made %d total fetches", n)
+	}
+
+	// Removed images are fetched again
+	if err := c.Remove("1", 300); err != nil {
+		t.Fatalf("Remove returned error: %s", err.Error())
+	}
+	if _, err := c.Path(context.Background(), "1", 300); err != nil || atomic.LoadInt32(&fetches) != 4 {
+		t.Fatalf("Path after Remove returned %v after %d fetches", err, atomic.LoadInt32(&fetches))
+	}
+}
+
+// TestGetCoverArtImage verifies that client.GetCoverArtImage() decodes cover art, detects
+// its format, and re-encodes it
+func TestGetCoverArtImage(t *testing.T) {
+	log.Println("TestGetCoverArtImage()")
+
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.Set(1, 1, color.RGBA{R: 255, A: 255})
+
+	buf := bytes.NewBuffer(nil)
+	if err := png.Encode(buf, src); err != nil {
+		t.Fatalf("Could not encode image: %s", err.Error())
+	}
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	// The format is detected from the data when the server does not report it
+	f.SetMedia("1", "image/png", buf.Bytes())
+	f.SetMedia("2", "application/octet-stream", buf.Bytes())
+	f.SetMedia("3", "image/png", []byte("not an image"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	for _, id := range []ID{"1", "2"} {
+		img, err := s.GetCoverArtImage(context.Background(), id, 0)
+		if err != nil {
+			t.Fatalf("GetCoverArtImage returned error: %s", err.Error())
+		}
+
+		if img.Format != ImageFormatPNG || img.Bounds().Dx() != 4 || img.Bounds().Dy() != 2 {
+			t.Fatalf("GetCoverArtImage returned invalid image: %s %v", img.Format, img.Bounds())
+		}
+	}
+
+	if _, err := s.GetCoverArtImage(context.Background(), "3", 0); err == nil {
+		t.Fatalf("GetCoverArtImage decoded invalid image")
+	}
+
+	img, err := s.GetCoverArtImage(context.Background(), "1", 0)
+	if err != nil {
+		t.Fatalf("GetCoverArtImage returned error: %s", err.Error())
+	}
+
+	// Re-encoded images decode to the same format
+	var tests = []struct {
+		format string
+		out    string
+		err    bool
+	}{
+		{ImageFormatJPEG, "jpeg", false},
+		{"jpg", "jpeg", false},
+		{ImageFormatPNG, "png", false},
+		{"webp", "", true},
+	}
+
+	for _, test := range tests {
+		buf.Reset()
+		err := img.Encode(buf, test.format, 90)
+		if (err != nil) != test.err {
+			t.Fatalf("Encode(%q) returned error: %v", test.format, err)
+		}
+		if test.err {
+			continue
+		}
+
+		if _, format, err := image.Decode(buf); err != nil || format != test.out {
+			t.Fatalf("Encode(%q) wrote invalid image: %s %v", test.format, format, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package gosubsonic
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// redacted replaces credentials in request URLs which appear in errors
+const redacted = "REDACTED"
+
+// Credentials supplies the password used to authenticate each request, so that it may be
+// rotated or retrieved from a secret store, rather than held by the Client.  Implementations
+// must be safe for concurrent use.
+type Credentials interface {
+	Password() string
+}
+
+// Secret is a fixed password which implements Credentials, and is never printed
+type Secret string
+
+// Password returns the password
+func (s Secret) Password() string {
+	return string(s)
+}
+
+// String implements fmt.Stringer, and hides the password
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer, and hides the password
+func (s Secret) GoString() string {
+	return `gosubsonic.Secret("` + redacted + `")`
+}
+
+// password returns the client's current password, if any
+func (s Client) password() string {
+	if s.Credentials == nil {
+		return ""
+	}
+
+	return s.Credentials.Password()
+}
+
+// redactURL replaces the password and token authentication parameters in a request URL, so
+// it may be safely included in errors and logs
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// Never risk returning an unparsed query
+		return strings.SplitN(rawURL, "?", 2)[0]
+	}
+
+	q := u.Query()
+	for _, p := range []string{"p", "t", "s"} {
+		if q.Has(p) {
+			q.Set(p, redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// redactError replaces credentials in the request URL of an error returned by a HTTP client
+func redactError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = redactURL(ue.URL)
+	}
+
+	return err
+}
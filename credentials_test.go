@@ -0,0 +1,119 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// rotatingCredentials is a Credentials which may be changed between requests
+type rotatingCredentials struct {
+	mu       sync.Mutex
+	password string
+}
+
+// Password returns the current password
+func (c *rotatingCredentials) Password() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.password
+}
+
+// TestCredentialsRedacted verifies that passwords and tokens never appear in errors or
+// printed clients
+func TestCredentialsRedacted(t *testing.T) {
+	log.Println("TestCredentialsRedacted()")
+
+	const password = "sesame-1234"
+
+	// A closed listener produces a HTTP client error which includes the request URL
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer busy.Close()
+
+	var tests = []struct {
+		description string
+		host        string
+		tokenAuth   bool
+	}{
+		{"connection refused", addr, false},
+		{"connection refused, token", addr, true},
+		{"HTTP status", strings.TrimPrefix(busy.URL, "http://"), false},
+	}
+
+	for _, test := range tests {
+		s := Client{
+			Host:        test.host,
+			Username:    "mock",
+			Credentials: Secret(password),
+			TokenAuth:   test.tokenAuth,
+		}
+
+		_, err := s.GetMusicFolders(context.Background())
+		if err == nil {
+			t.Fatalf("%s: expected error", test.description)
+		}
+
+		for _, out := range []string{err.Error(), fmt.Sprintf("%v", s), fmt.Sprintf("%+v", s), fmt.Sprintf("%#v", s)} {
+			if strings.Contains(out, password) || strings.Contains(out, "t=") && !strings.Contains(out, "t="+redacted) {
+				t.Fatalf("%s: credentials not redacted: %s", test.description, out)
+			}
+		}
+
+		if !strings.Contains(err.Error(), "u=mock") {
+			t.Fatalf("%s: error does not identify request: %s", test.description, err.Error())
+		}
+	}
+}
+
+// TestCredentialsRotation verifies that Credentials are consulted for every request
+func TestCredentialsRotation(t *testing.T) {
+	log.Println("TestCredentialsRotation()")
+
+	var mu sync.Mutex
+	var passwords []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		passwords = append(passwords, r.URL.Query().Get("p"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(mockTable[0].data)
+	}))
+	defer srv.Close()
+
+	creds := &rotatingCredentials{password: "one"}
+	s := Client{
+		Host:        strings.TrimPrefix(srv.URL, "http://"),
+		Username:    "mock",
+		Credentials: creds,
+	}
+
+	for _, p := range []string{"one", "two"} {
+		creds.mu.Lock()
+		creds.password = p
+		creds.mu.Unlock()
+
+		if _, err := s.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping returned error: %s", err.Error())
+		}
+	}
+
+	if strings.Join(passwords, ",") != "one,two" {
+		t.Fatalf("unexpected passwords sent: %v", passwords)
+	}
+}
@@ -0,0 +1,1436 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"time"
+)
+
+// Subsonic generates its JSON responses by converting its XML responses, which causes a
+// number of quirks that the types in this file smooth over, so that response structs may
+// be decoded directly:
+//   - A list with a single item is returned as a bare object, instead of an array
+//   - An empty list or object may be returned as an empty string
+//   - Numeric or boolean names and titles (311, True, etc) are returned as numbers or booleans
+//   - IDs may be returned as numbers or numeric strings, depending on the server
+//   - Strings may contain HTML escape sequences
+
+// oneOrMany decodes a JSON value which may be a single item, an array of items, or an
+// empty string, into a slice of items.  A malformed item is reported as an *itemError, and
+// items replaced by lenient parsing are skipped.
+type oneOrMany[T any] []T
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *oneOrMany[T]) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+
+	switch {
+	// No items
+	case isEmptyJSON(b) || bytes.Equal(b, skippedItem):
+		*o = nil
+	// Multiple items
+	case b[0] == '[':
+		var items []T
+		if err := json.Unmarshal(b, &items); err != nil {
+			// Decode each item individually, to find the malformed item or skip those
+			// replaced by lenient parsing
+			return o.unmarshalItems(b)
+		}
+
+		*o = items
+	// Single item
+	default:
+		var item T
+		if err := json.Unmarshal(b, &item); err != nil {
+			return &itemError{raw: b, err: err}
+		}
+
+		*o = oneOrMany[T]{item}
+	}
+
+	return nil
+}
+
+// unmarshalItems decodes an array of items one at a time
+func (o *oneOrMany[T]) unmarshalItems(b []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		return err
+	}
+
+	items := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		if bytes.Equal(raw, skippedItem) {
+			continue
+		}
+
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return &itemError{raw: raw, err: err}
+		}
+
+		items = append(items, item)
+	}
+
+	*o = items
+	return nil
+}
+
+// itemError is an error which occurred while decoding an item in a list, along with the
+// item's raw JSON, so lenient parsing may skip it
+type itemError struct {
+	raw []byte
+	err error
+}
+
+// Error returns the error which occurred while decoding the item
+func (e *itemError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error which occurred while decoding the item
+func (e *itemError) Unwrap() error {
+	return e.err
+}
+
+// flexString decodes a JSON string, number, or boolean into a string, unescaping any HTML
+type flexString string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexString) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case nil:
+		*f = ""
+	case bool:
+		if v {
+			*f = "True"
+		} else {
+			*f = "False"
+		}
+	case string:
+		*f = flexString(html.UnescapeString(v))
+	case json.Number:
+		// Numeric names are always whole numbers, such as 311
+		n, err := v.Float64()
+		if err != nil {
+			return err
+		}
+
+		*f = flexString(strconv.FormatInt(int64(n), 10))
+	default:
+		return fmt.Errorf("gosubsonic: unknown data type %T for string value", v)
+	}
+
+	return nil
+}
+
+// flexInt decodes a JSON number or numeric string into an int64.  Any other value decodes
+// to 0, because Subsonic omits or mangles many optional numeric values.
+type flexInt int64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexInt) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+
+	*f = 0
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			*f = flexInt(i)
+		} else if n, err := v.Float64(); err == nil {
+			*f = flexInt(n)
+		}
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		*f = flexInt(i)
+	}
+
+	return nil
+}
+
+// flexBool decodes a JSON boolean or boolean string into a bool.  Any other value decodes
+// to false.
+type flexBool bool
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexBool) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+	}
+
+	*f = false
+	switch v := v.(type) {
+	case bool:
+		*f = flexBool(v)
+	case string:
+		t, _ := strconv.ParseBool(v)
+		*f = flexBool(t)
+	}
+
+	return nil
+}
+
+// flexFloat decodes a JSON number or numeric string into a float64.  Any other value
+// decodes to 0.
+type flexFloat float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexFloat) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	*f = 0
+	switch v := v.(type) {
+	case float64:
+		*f = flexFloat(v)
+	case string:
+		n, _ := strconv.ParseFloat(v, 64)
+		*f = flexFloat(n)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, ignoring any values which Subsonic returns as
+// empty strings or empty arrays in place of empty objects or lists
+func (a *APIStatus) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	for k, v := range raw {
+		if isEmptyJSON(v) || string(bytes.TrimSpace(v)) == "[]" {
+			delete(raw, k)
+		}
+	}
+
+	// The OpenSubsonic flag is a string in XML responses
+	var openSubsonic flexBool
+	if v, ok := raw["openSubsonic"]; ok {
+		if err := openSubsonic.UnmarshalJSON(v); err != nil {
+			return err
+		}
+
+		delete(raw, "openSubsonic")
+	}
+
+	cleaned, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	// Decode using a type without this method, to avoid recursion
+	type apiStatus APIStatus
+	if err := json.Unmarshal(cleaned, (*apiStatus)(a)); err != nil {
+		return err
+	}
+
+	a.OpenSubsonic = bool(openSubsonic)
+	return nil
+}
+
+// isEmptyJSON determines if a raw JSON value is null or an empty string
+func isEmptyJSON(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) == 0 || string(b) == "null" || string(b) == `""`
+}
+
+// subsonicTimeLayouts are the layouts of timestamps produced by Subsonic and compatible servers,
+// in the order they are tried.  When parsing, Go accepts fractional seconds after the seconds
+// field even if a layout omits them.
+var subsonicTimeLayouts = []string{
+	// 2014-03-20T21:55:32.468Z or 2014-03-20T21:55:32+02:00
+	time.RFC3339,
+	// 2014-03-20T21:55:32.468+0200
+	"2006-01-02T15:04:05Z0700",
+	// 2014-03-20T21:55:32, in UTC
+	"2006-01-02T15:04:05",
+	// 2014-03-20 21:55:32+02:00 or 2014-03-20 21:55:32
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	// 2014-03-20
+	"2006-01-02",
+}
+
+// parseSubsonicTime parses a timestamp from Subsonic, which may use any of a number of layouts,
+// with or without fractional seconds and a time zone, or may be a number of milliseconds since
+// the Unix epoch.  An empty timestamp parses to the zero time.
+func parseSubsonicTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range subsonicTimeLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil && ms > 0 {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("gosubsonic: unrecognized timestamp: %q", raw)
+}
+
+// subsonicTime parses a timestamp from Subsonic using parseSubsonicTime.  A timestamp which
+// cannot be parsed does not fail the entire response, but parses to the zero time, and may be
+// inspected using the raw value stored alongside it.
+func subsonicTime(raw string) time.Time {
+	t, _ := parseSubsonicTime(raw)
+	return t
+}
+
+// apiChild represents a raw child item from Subsonic, which may be a directory, audio, or
+// video item.  Many other responses use this format for their songs.
+type apiChild struct {
+	ID                    ID
+	Parent                ID
+	Title                 flexString
+	Album                 flexString
+	Artist                flexString
+	IsDir                 flexBool
+	IsVideo               flexBool
+	CoverArt              ID
+	Created               flexString
+	AlbumID               ID `json:"albumId"`
+	ArtistID              ID `json:"artistId"`
+	BitRate               flexInt
+	ContentType           string
+	DiscNumber            flexInt
+	Duration              flexInt
+	Genre                 flexString
+	Path                  flexString
+	Size                  flexInt
+	Suffix                string
+	Track                 flexInt
+	TranscodedContentType string
+	TranscodedSuffix      string
+	Type                  string
+	Year                  flexInt
+
+	// OpenSubsonic fields
+	MusicBrainzID flexString `json:"musicBrainzId"`
+	SortName      flexString
+	BPM           flexInt `json:"bpm"`
+	Comment       flexString
+	ReplayGain    *ReplayGain
+}
+
+// directory converts a raw child item into a Directory
+func (c apiChild) directory() Directory {
+	created := subsonicTime(string(c.Created))
+
+	return Directory{
+		ID:         c.ID,
+		Album:      string(c.Album),
+		Artist:     string(c.Artist),
+		CoverArt:   c.CoverArt,
+		CreatedRaw: string(c.Created),
+		Parent:     c.Parent,
+		Title:      string(c.Title),
+		Created:    created,
+	}
+}
+
+// audio converts a raw child item into an Audio item
+func (c apiChild) audio() Audio {
+	created := subsonicTime(string(c.Created))
+
+	return Audio{
+		ID:                    c.ID,
+		Album:                 string(c.Album),
+		AlbumID:               c.AlbumID,
+		Artist:                string(c.Artist),
+		ArtistID:              c.ArtistID,
+		BitRate:               int64(c.BitRate),
+		ContentType:           c.ContentType,
+		CoverArt:              c.CoverArt,
+		CreatedRaw:            string(c.Created),
+		DiscNumber:            int64(c.DiscNumber),
+		DurationRaw:           int64(c.Duration),
+		Genre:                 string(c.Genre),
+		Parent:                c.Parent,
+		Path:                  string(c.Path),
+		Size:                  int64(c.Size),
+		Suffix:                c.Suffix,
+		Title:                 string(c.Title),
+		Track:                 int64(c.Track),
+		TranscodedContentType: c.TranscodedContentType,
+		TranscodedSuffix:      c.TranscodedSuffix,
+		Type:                  c.Type,
+		Year:                  int64(c.Year),
+		MusicBrainzID:         string(c.MusicBrainzID),
+		SortName:              string(c.SortName),
+		BPM:                   int64(c.BPM),
+		Comment:               string(c.Comment),
+		ReplayGain:            c.ReplayGain,
+		Created:               created,
+		Duration:              time.Duration(c.Duration) * time.Second,
+	}
+}
+
+// video converts a raw child item into a Video item
+func (c apiChild) video() Video {
+	created := subsonicTime(string(c.Created))
+
+	return Video{
+		ID:                    c.ID,
+		BitRate:               int64(c.BitRate),
+		ContentType:           c.ContentType,
+		CoverArt:              c.CoverArt,
+		CreatedRaw:            string(c.Created),
+		DurationRaw:           int64(c.Duration),
+		Parent:                c.Parent,
+		Path:                  string(c.Path),
+		Size:                  int64(c.Size),
+		Suffix:                c.Suffix,
+		Title:                 string(c.Title),
+		TranscodedContentType: c.TranscodedContentType,
+		TranscodedSuffix:      c.TranscodedSuffix,
+		Created:               created,
+		Duration:              time.Duration(c.Duration) * time.Second,
+	}
+}
+
+// apiArtistBio represents the raw biography, Last.fm, and image details shared by artist
+// and album info responses
+type apiArtistBio struct {
+	Biography      flexString
+	Notes          flexString
+	MusicBrainzID  string `json:"musicBrainzId"`
+	LastFMURL      string `json:"lastFmUrl"`
+	SmallImageURL  string `json:"smallImageUrl"`
+	MediumImageURL string `json:"mediumImageUrl"`
+	LargeImageURL  string `json:"largeImageUrl"`
+}
+
+// bio converts raw artist info into an ArtistBio
+func (b apiArtistBio) bio() ArtistBio {
+	return ArtistBio{
+		Biography:      string(b.Biography),
+		MusicBrainzID:  b.MusicBrainzID,
+		LastFMURL:      b.LastFMURL,
+		SmallImageURL:  b.SmallImageURL,
+		MediumImageURL: b.MediumImageURL,
+		LargeImageURL:  b.LargeImageURL,
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *MusicFolder) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID   flexInt
+		Name flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*f = MusicFolder{
+		ID:   int64(raw.ID),
+		Name: string(raw.Name),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *Indexes) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Index           oneOrMany[Index]
+		Shortcut        oneOrMany[IndexArtist]
+		Child           Content
+		IgnoredArticles flexString
+		LastModified    flexInt
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = Indexes{
+		Index:           raw.Index,
+		Shortcuts:       raw.Shortcut,
+		Children:        raw.Child,
+		IgnoredArticles: string(raw.IgnoredArticles),
+	}
+	if raw.LastModified > 0 {
+		i.LastModified = time.UnixMilli(int64(raw.LastModified))
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *Index) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Name   flexString
+		Artist oneOrMany[IndexArtist]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = Index{
+		Name:   string(raw.Name),
+		Artist: raw.Artist,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *IndexArtist) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID   ID
+		Name flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*a = IndexArtist{
+		ID:   raw.ID,
+		Name: string(raw.Name),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, sorting each child item into directories,
+// audio, or video, while retaining the server's order in Children
+func (c *Content) UnmarshalJSON(b []byte) error {
+	var children oneOrMany[apiChild]
+	if err := json.Unmarshal(b, &children); err != nil {
+		return err
+	}
+
+	out := Content{
+		Audio:       make([]Audio, 0),
+		Directories: make([]Directory, 0),
+		Video:       make([]Video, 0),
+		Children:    make([]Child, 0, len(children)),
+	}
+
+	for _, ch := range children {
+		switch {
+		case bool(ch.IsDir):
+			out.Directories = append(out.Directories, ch.directory())
+		case bool(ch.IsVideo):
+			out.Video = append(out.Video, ch.video())
+		default:
+			out.Audio = append(out.Audio, ch.audio())
+		}
+	}
+
+	// Children point into the typed slices, which are complete once every child is decoded
+	var d, v, a int
+	for _, ch := range children {
+		switch {
+		case bool(ch.IsDir):
+			out.Children = append(out.Children, &out.Directories[d])
+			d++
+		case bool(ch.IsVideo):
+			out.Children = append(out.Children, &out.Video[v])
+			v++
+		default:
+			out.Children = append(out.Children, &out.Audio[a])
+			a++
+		}
+	}
+
+	*c = out
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Directory) UnmarshalJSON(b []byte) error {
+	var raw apiChild
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*d = raw.directory()
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Audio) UnmarshalJSON(b []byte) error {
+	var raw apiChild
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*a = raw.audio()
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *Video) UnmarshalJSON(b []byte) error {
+	var raw apiChild
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*v = raw.video()
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  A genre may be a bare name, or an object with
+// counts, which stores its name as either "value" or "content" depending on the server version.
+func (g *Genre) UnmarshalJSON(b []byte) error {
+	// Bare genre name, without counts
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] != '{' {
+		var name flexString
+		if err := json.Unmarshal(b, &name); err != nil {
+			return err
+		}
+
+		*g = Genre{Name: string(name)}
+		return nil
+	}
+
+	var raw struct {
+		Value      flexString
+		Content    flexString
+		SongCount  flexInt
+		AlbumCount flexInt
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	name := raw.Value
+	if name == "" {
+		name = raw.Content
+	}
+
+	*g = Genre{
+		Name:       string(name),
+		SongCount:  int64(raw.SongCount),
+		AlbumCount: int64(raw.AlbumCount),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *ArtistInfo) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		apiArtistBio
+		SimilarArtist oneOrMany[IndexArtist]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = ArtistInfo{
+		ArtistBio:     raw.bio(),
+		SimilarArtist: raw.SimilarArtist,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *ArtistInfo2) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		apiArtistBio
+		SimilarArtist oneOrMany[ArtistID3]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = ArtistInfo2{
+		ArtistBio:     raw.bio(),
+		SimilarArtist: raw.SimilarArtist,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Album info shares its format with artist info,
+// with notes instead of a biography.
+func (i *AlbumInfo) UnmarshalJSON(b []byte) error {
+	var raw apiArtistBio
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = AlbumInfo{
+		Notes:          string(raw.Notes),
+		MusicBrainzID:  raw.MusicBrainzID,
+		LastFMURL:      raw.LastFMURL,
+		SmallImageURL:  raw.SmallImageURL,
+		MediumImageURL: raw.MediumImageURL,
+		LargeImageURL:  raw.LargeImageURL,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *VideoInfo) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID         ID
+		Captions   oneOrMany[VideoCaptions]
+		AudioTrack oneOrMany[VideoAudioTrack]
+		Conversion oneOrMany[VideoConversion]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = VideoInfo{
+		ID:          raw.ID,
+		Captions:    raw.Captions,
+		AudioTracks: raw.AudioTrack,
+		Conversions: raw.Conversion,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *VideoCaptions) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID   ID
+		Name flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = VideoCaptions{
+		ID:   raw.ID,
+		Name: string(raw.Name),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *VideoAudioTrack) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID           ID
+		Name         flexString
+		LanguageCode string
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*t = VideoAudioTrack{
+		ID:           raw.ID,
+		Name:         string(raw.Name),
+		LanguageCode: raw.LanguageCode,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *VideoConversion) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID      ID
+		BitRate flexInt
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = VideoConversion{
+		ID:      raw.ID,
+		BitRate: int64(raw.BitRate),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *IndexID3) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Name   flexString
+		Artist oneOrMany[ArtistID3]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*i = IndexID3{
+		Name:   string(raw.Name),
+		Artist: raw.Artist,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *ArtistID3) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID            ID
+		Name          flexString
+		CoverArt      ID
+		AlbumCount    flexInt
+		MusicBrainzID flexString `json:"musicBrainzId"`
+		SortName      flexString
+		Album         oneOrMany[AlbumID3]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*a = ArtistID3{
+		ID:            raw.ID,
+		Name:          string(raw.Name),
+		CoverArt:      raw.CoverArt,
+		AlbumCount:    int64(raw.AlbumCount),
+		MusicBrainzID: string(raw.MusicBrainzID),
+		SortName:      string(raw.SortName),
+		Album:         raw.Album,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *AlbumID3) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID        ID
+		Name      flexString
+		Artist    flexString
+		ArtistID  ID `json:"artistId"`
+		CoverArt  ID
+		SongCount flexInt
+		Created   flexString
+		Duration  flexInt
+		Genre     flexString
+		Year      flexInt
+		Song      oneOrMany[Audio]
+
+		// OpenSubsonic fields
+		MusicBrainzID flexString `json:"musicBrainzId"`
+		SortName      flexString
+		IsCompilation flexBool
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	created := subsonicTime(string(raw.Created))
+
+	*a = AlbumID3{
+		ID:            raw.ID,
+		Name:          string(raw.Name),
+		Artist:        string(raw.Artist),
+		ArtistID:      raw.ArtistID,
+		CoverArt:      raw.CoverArt,
+		SongCount:     int64(raw.SongCount),
+		CreatedRaw:    string(raw.Created),
+		DurationRaw:   int64(raw.Duration),
+		Genre:         string(raw.Genre),
+		Year:          int64(raw.Year),
+		Song:          raw.Song,
+		Created:       created,
+		Duration:      time.Duration(raw.Duration) * time.Second,
+		MusicBrainzID: string(raw.MusicBrainzID),
+		SortName:      string(raw.SortName),
+		IsCompilation: bool(raw.IsCompilation),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *ReplayGain) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		TrackGain    flexFloat
+		AlbumGain    flexFloat
+		TrackPeak    flexFloat
+		AlbumPeak    flexFloat
+		BaseGain     flexFloat
+		FallbackGain flexFloat
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*r = ReplayGain{
+		TrackGain:    float64(raw.TrackGain),
+		AlbumGain:    float64(raw.AlbumGain),
+		TrackPeak:    float64(raw.TrackPeak),
+		AlbumPeak:    float64(raw.AlbumPeak),
+		BaseGain:     float64(raw.BaseGain),
+		FallbackGain: float64(raw.FallbackGain),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (e *OpenSubsonicExtension) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Name     flexString
+		Versions oneOrMany[flexInt]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*e = OpenSubsonicExtension{
+		Name: string(raw.Name),
+	}
+	for _, v := range raw.Versions {
+		e.Versions = append(e.Versions, int64(v))
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  The lyrics text is stored as the element's value.
+func (l *Lyrics) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Artist flexString
+		Title  flexString
+		Value  flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*l = Lyrics{
+		Artist: string(raw.Artist),
+		Title:  string(raw.Title),
+		Text:   string(raw.Value),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *NowPlaying) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		apiChild
+		Username   flexString
+		MinutesAgo flexInt
+		PlayerID   flexInt    `json:"playerId"`
+		PlayerName flexString `json:"playerName"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	a := raw.audio()
+	*n = NowPlaying{
+		ID:          a.ID,
+		Album:       a.Album,
+		AlbumID:     a.AlbumID,
+		Artist:      a.Artist,
+		ArtistID:    a.ArtistID,
+		BitRate:     a.BitRate,
+		ContentType: a.ContentType,
+		CoverArt:    a.CoverArt,
+		CreatedRaw:  a.CreatedRaw,
+		DiscNumber:  a.DiscNumber,
+		DurationRaw: a.DurationRaw,
+		Genre:       a.Genre,
+		IsDir:       bool(raw.IsDir),
+		IsVideo:     bool(raw.IsVideo),
+		MinutesAgo:  int64(raw.MinutesAgo),
+		Parent:      a.Parent,
+		Path:        a.Path,
+		PlayerID:    int64(raw.PlayerID),
+		PlayerName:  string(raw.PlayerName),
+		Size:        a.Size,
+		Suffix:      a.Suffix,
+		Title:       a.Title,
+		Track:       a.Track,
+		Username:    string(raw.Username),
+		Year:        a.Year,
+		Created:     a.Created,
+		Duration:    a.Duration,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *SearchResult2) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Artist oneOrMany[IndexArtist]
+		Album  oneOrMany[Directory]
+		Song   oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*r = SearchResult2{
+		Artists: raw.Artist,
+		Albums:  raw.Album,
+		Songs:   raw.Song,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *SearchResult3) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Artist oneOrMany[ArtistID3]
+		Album  oneOrMany[AlbumID3]
+		Song   oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*r = SearchResult3{
+		Artists: raw.Artist,
+		Albums:  raw.Album,
+		Songs:   raw.Song,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Starred items use the same format as search results.
+func (s *Starred) UnmarshalJSON(b []byte) error {
+	var r SearchResult2
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+
+	*s = Starred(r)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Starred items use the same format as search results.
+func (s *Starred2) UnmarshalJSON(b []byte) error {
+	var r SearchResult3
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+
+	*s = Starred2(r)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *Playlist) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID        ID
+		Name      flexString
+		Comment   flexString
+		Owner     flexString
+		Public    flexBool
+		SongCount flexInt
+		Duration  flexInt
+		Created   flexString
+		Changed   flexString
+		Entry     oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	created := subsonicTime(string(raw.Created))
+	changed := subsonicTime(string(raw.Changed))
+
+	*p = Playlist{
+		ID:          raw.ID,
+		Name:        string(raw.Name),
+		Comment:     string(raw.Comment),
+		Owner:       string(raw.Owner),
+		Public:      bool(raw.Public),
+		SongCount:   int64(raw.SongCount),
+		DurationRaw: int64(raw.Duration),
+		CreatedRaw:  string(raw.Created),
+		ChangedRaw:  string(raw.Changed),
+		Entry:       make([]PlaylistEntry, 0, len(raw.Entry)),
+		Created:     created,
+		Changed:     changed,
+		Duration:    time.Duration(raw.Duration) * time.Second,
+	}
+
+	for i, a := range raw.Entry {
+		p.Entry = append(p.Entry, PlaylistEntry{
+			Audio: a,
+			Index: int64(i),
+		})
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Share) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID          ID
+		URL         string
+		Description flexString
+		Username    flexString
+		VisitCount  flexInt
+		Created     flexString
+		Expires     flexString
+		LastVisited flexString
+		Entry       oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	created := subsonicTime(string(raw.Created))
+	expires := subsonicTime(string(raw.Expires))
+	lastVisited := subsonicTime(string(raw.LastVisited))
+
+	*s = Share{
+		ID:             raw.ID,
+		URL:            raw.URL,
+		Description:    string(raw.Description),
+		Username:       string(raw.Username),
+		VisitCount:     int64(raw.VisitCount),
+		CreatedRaw:     string(raw.Created),
+		ExpiresRaw:     string(raw.Expires),
+		LastVisitedRaw: string(raw.LastVisited),
+		Entry:          raw.Entry,
+		Created:        created,
+		Expires:        expires,
+		LastVisited:    lastVisited,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *PodcastChannel) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID               ID
+		URL              string
+		Title            flexString
+		Description      flexString
+		CoverArt         ID
+		OriginalImageURL string `json:"originalImageUrl"`
+		Status           PodcastStatus
+		ErrorMessage     flexString
+		Episode          oneOrMany[PodcastEpisode]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = PodcastChannel{
+		ID:               raw.ID,
+		URL:              raw.URL,
+		Title:            string(raw.Title),
+		Description:      string(raw.Description),
+		CoverArt:         raw.CoverArt,
+		OriginalImageURL: raw.OriginalImageURL,
+		Status:           raw.Status,
+		ErrorMessage:     string(raw.ErrorMessage),
+		Episode:          raw.Episode,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Episodes which have not been downloaded have
+// no stream ID or media information.
+func (e *PodcastEpisode) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID          ID
+		StreamID    ID `json:"streamId"`
+		ChannelID   ID `json:"channelId"`
+		Title       flexString
+		Description flexString
+		Status      PodcastStatus
+		CoverArt    ID
+		BitRate     flexInt
+		ContentType string
+		Size        flexInt
+		Suffix      string
+		Duration    flexInt
+		PublishDate flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	publishDate := subsonicTime(string(raw.PublishDate))
+
+	*e = PodcastEpisode{
+		ID:             raw.ID,
+		StreamID:       raw.StreamID,
+		ChannelID:      raw.ChannelID,
+		Title:          string(raw.Title),
+		Description:    string(raw.Description),
+		Status:         raw.Status,
+		CoverArt:       raw.CoverArt,
+		BitRate:        int64(raw.BitRate),
+		ContentType:    raw.ContentType,
+		Size:           int64(raw.Size),
+		Suffix:         raw.Suffix,
+		DurationRaw:    int64(raw.Duration),
+		PublishDateRaw: string(raw.PublishDate),
+		Duration:       time.Duration(raw.Duration) * time.Second,
+		PublishDate:    publishDate,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *JukeboxPlaylist) UnmarshalJSON(b []byte) error {
+	// The status and entries are decoded separately, because the embedded status has its
+	// own UnmarshalJSON method, which would otherwise be promoted
+	var status JukeboxStatus
+	if err := json.Unmarshal(b, &status); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Entry oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*p = JukeboxPlaylist{
+		JukeboxStatus: status,
+		Entry:         raw.Entry,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (j *JukeboxStatus) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		CurrentIndex flexInt
+		Playing      flexBool
+		Gain         flexFloat
+		Position     flexInt
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*j = JukeboxStatus{
+		CurrentIndex: int64(raw.CurrentIndex),
+		Playing:      bool(raw.Playing),
+		Gain:         float64(raw.Gain),
+		Position:     int64(raw.Position),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *ScanStatus) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Scanning flexBool
+		Count    flexInt
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*s = ScanStatus{
+		Scanning: bool(raw.Scanning),
+		Count:    int64(raw.Count),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  An empty license element, as returned by some
+// free servers, is treated as a missing license.
+func (l *License) UnmarshalJSON(b []byte) error {
+	if isEmptyJSON(b) {
+		*l = License{}
+		return nil
+	}
+
+	var raw struct {
+		Date  flexString
+		Email flexString
+		Key   flexString
+		Valid flexBool
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*l = License{
+		DateRaw: string(raw.Date),
+		Email:   string(raw.Email),
+		Key:     string(raw.Key),
+		Valid:   bool(raw.Valid),
+		present: true,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (e *Error) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Code    flexInt
+		Message flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*e = Error{
+		Code:    ErrorCode(raw.Code),
+		Message: string(raw.Message),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *InternetRadioStation) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID          ID
+		Name        flexString
+		StreamURL   string `json:"streamUrl"`
+		HomePageURL string `json:"homePageUrl"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*s = InternetRadioStation{
+		ID:          raw.ID,
+		Name:        string(raw.Name),
+		StreamURL:   raw.StreamURL,
+		HomePageURL: raw.HomePageURL,
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Message times are in milliseconds since the
+// Unix epoch.
+func (c *ChatMessage) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Username flexString
+		Time     flexInt
+		Message  flexString
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = ChatMessage{
+		Username: string(raw.Username),
+		TimeRaw:  int64(raw.Time),
+		Message:  string(raw.Message),
+		Time:     time.UnixMilli(int64(raw.Time)),
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (u *User) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Username          flexString
+		Email             flexString
+		MaxBitRate        flexInt
+		AvatarLastChanged flexString
+		Folder            oneOrMany[flexInt]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	// Boolean settings and roles are decoded by name
+	var roles map[string]json.RawMessage
+	if err := json.Unmarshal(b, &roles); err != nil {
+		return err
+	}
+
+	avatarLastChanged := subsonicTime(string(raw.AvatarLastChanged))
+
+	*u = User{
+		Username:             string(raw.Username),
+		Email:                string(raw.Email),
+		MaxBitRate:           int64(raw.MaxBitRate),
+		AvatarLastChangedRaw: string(raw.AvatarLastChanged),
+		AvatarLastChanged:    avatarLastChanged,
+	}
+
+	for _, r := range u.roles() {
+		var v flexBool
+		if err := v.UnmarshalJSON(roles[r.name]); err != nil {
+			return err
+		}
+
+		*r.value = bool(v)
+	}
+
+	for _, id := range raw.Folder {
+		u.Folder = append(u.Folder, int64(id))
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Positions are in milliseconds.
+func (bm *Bookmark) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Position flexInt
+		Username flexString
+		Comment  flexString
+		Created  flexString
+		Changed  flexString
+		Entry    oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	created := subsonicTime(string(raw.Created))
+	changed := subsonicTime(string(raw.Changed))
+
+	*bm = Bookmark{
+		PositionRaw: int64(raw.Position),
+		Username:    string(raw.Username),
+		Comment:     string(raw.Comment),
+		CreatedRaw:  string(raw.Created),
+		ChangedRaw:  string(raw.Changed),
+		Position:    time.Duration(raw.Position) * time.Millisecond,
+		Created:     created,
+		Changed:     changed,
+	}
+
+	if len(raw.Entry) > 0 {
+		bm.Entry = raw.Entry[0]
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Positions are in milliseconds.
+func (q *PlayQueue) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Current   ID
+		Position  flexInt
+		Username  flexString
+		Changed   flexString
+		ChangedBy flexString
+		Entry     oneOrMany[Audio]
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	changed := subsonicTime(string(raw.Changed))
+
+	*q = PlayQueue{
+		Current:     raw.Current,
+		PositionRaw: int64(raw.Position),
+		Username:    string(raw.Username),
+		ChangedRaw:  string(raw.Changed),
+		ChangedBy:   string(raw.ChangedBy),
+		Entry:       raw.Entry,
+		Position:    time.Duration(raw.Position) * time.Millisecond,
+		Changed:     changed,
+	}
+	return nil
+}
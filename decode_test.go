@@ -0,0 +1,226 @@
+package gosubsonic
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOneOrMany verifies that oneOrMany decodes single items, arrays, and empty values
+func TestOneOrMany(t *testing.T) {
+	log.Println("TestOneOrMany()")
+
+	var tests = []struct {
+		input string
+		count int
+	}{
+		{`""`, 0},
+		{`null`, 0},
+		{`[]`, 0},
+		{`{"id": 1, "name": "Music"}`, 1},
+		{`[{"id": 1, "name": "Music"}, {"id": 2, "name": "Video"}]`, 2},
+	}
+
+	for _, test := range tests {
+		var folders oneOrMany[MusicFolder]
+		if err := json.Unmarshal([]byte(test.input), &folders); err != nil {
+			t.Fatalf("oneOrMany: unexpected error for %s: %s", test.input, err.Error())
+		}
+
+		if len(folders) != test.count {
+			t.Fatalf("oneOrMany: expected %d items for %s, got %d", test.count, test.input, len(folders))
+		}
+	}
+}
+
+// TestFlexString verifies that flexString decodes strings, numbers, and booleans
+func TestFlexString(t *testing.T) {
+	log.Println("TestFlexString()")
+
+	var tests = []struct {
+		input  string
+		output string
+	}{
+		{`"Tom &amp; Jerry"`, "Tom & Jerry"},
+		{`311`, "311"},
+		{`true`, "True"},
+		{`false`, "False"},
+		{`null`, ""},
+	}
+
+	for _, test := range tests {
+		var f flexString
+		if err := json.Unmarshal([]byte(test.input), &f); err != nil {
+			t.Fatalf("flexString: unexpected error for %s: %s", test.input, err.Error())
+		}
+
+		if string(f) != test.output {
+			t.Fatalf("flexString: expected %q for %s, got %q", test.output, test.input, f)
+		}
+	}
+
+	// Objects are not valid strings
+	var f flexString
+	if err := json.Unmarshal([]byte(`{}`), &f); err == nil {
+		t.Fatalf("flexString: expected error for object")
+	}
+}
+
+// TestFlexInt verifies that flexInt decodes numbers and numeric strings
+func TestFlexInt(t *testing.T) {
+	log.Println("TestFlexInt()")
+
+	var tests = []struct {
+		input  string
+		output int64
+	}{
+		{`42`, 42},
+		{`"42"`, 42},
+		{`"al-42"`, 0},
+		{`null`, 0},
+	}
+
+	for _, test := range tests {
+		var f flexInt
+		if err := json.Unmarshal([]byte(test.input), &f); err != nil {
+			t.Fatalf("flexInt: unexpected error for %s: %s", test.input, err.Error())
+		}
+
+		if int64(f) != test.output {
+			t.Fatalf("flexInt: expected %d for %s, got %d", test.output, test.input, f)
+		}
+	}
+}
+
+// TestParseSubsonicTime verifies that parseSubsonicTime parses each timestamp layout used by
+// Subsonic and compatible servers
+func TestParseSubsonicTime(t *testing.T) {
+	log.Println("TestParseSubsonicTime()")
+
+	utc := time.Date(2014, 3, 20, 21, 55, 32, 0, time.UTC)
+	var tests = []struct {
+		input  string
+		output time.Time
+		ok     bool
+	}{
+		{"", time.Time{}, true},
+		{"2014-03-20T21:55:32", utc, true},
+		{"2014-03-20T21:55:32Z", utc, true},
+		{"2014-03-20T21:55:32.468Z", utc.Add(468 * time.Millisecond), true},
+		{"2014-03-20T23:55:32+02:00", utc, true},
+		{"2014-03-20T23:55:32.000+0200", utc, true},
+		{"2014-03-20 21:55:32", utc, true},
+		{"2014-03-20", time.Date(2014, 3, 20, 0, 0, 0, 0, time.UTC), true},
+		{"1395352532000", utc, true},
+		{"last tuesday", time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		out, err := parseSubsonicTime(test.input)
+		if (err == nil) != test.ok {
+			t.Fatalf("parseSubsonicTime returned unexpected error for %q: %v", test.input, err)
+		}
+
+		if !out.Equal(test.output) {
+			t.Fatalf("parseSubsonicTime returned %s for %q, expected %s", out, test.input, test.output)
+		}
+	}
+
+	// Unrecognized timestamps do not fail the entire response
+	var c Content
+	if err := json.Unmarshal([]byte(`[{"id": 1, "isDir": true, "created": "last tuesday"}]`), &c); err != nil {
+		t.Fatalf("Content: unexpected error for unrecognized timestamp: %s", err.Error())
+	}
+
+	if d := c.Directories[0]; !d.Created.IsZero() || d.CreatedRaw != "last tuesday" {
+		t.Fatalf("Content: unexpected directory for unrecognized timestamp: %+v", d)
+	}
+}
+
+// TestContentUnmarshal verifies that Content sorts children into directories, audio, and video,
+// and retains their original order
+func TestContentUnmarshal(t *testing.T) {
+	log.Println("TestContentUnmarshal()")
+
+	input := `[
+		{"id": 2, "isDir": false, "title": "Song", "duration": 90, "created": "2014-03-20T21:55:32.468Z"},
+		{"id": "1", "isDir": true, "title": 311, "created": "2014-03-20T21:55:32"},
+		{"id": 3, "isDir": false, "isVideo": true, "title": "Video", "created": "2014-03-20T21:55:32"}
+	]`
+
+	var c Content
+	if err := json.Unmarshal([]byte(input), &c); err != nil {
+		t.Fatalf("Content: unexpected error: %s", err.Error())
+	}
+
+	if len(c.Directories) != 1 || len(c.Audio) != 1 || len(c.Video) != 1 {
+		t.Fatalf("Content: unexpected item counts: %d directories, %d audio, %d video",
+			len(c.Directories), len(c.Audio), len(c.Video))
+	}
+
+	if c.Directories[0].ID != "1" || c.Directories[0].Title != "311" {
+		t.Fatalf("Content: unexpected directory: %+v", c.Directories[0])
+	}
+
+	if c.Audio[0].Duration.Seconds() != 90 || c.Audio[0].Created.IsZero() {
+		t.Fatalf("Content: unexpected audio: %+v", c.Audio[0])
+	}
+
+	// Children share items with the typed slices
+	if len(c.Children) != 3 || c.Children[0] != Child(&c.Audio[0]) || c.Children[1] != Child(&c.Directories[0]) ||
+		c.Children[2] != Child(&c.Video[0]) {
+		t.Fatalf("Content: unexpected children: %v", c.Children)
+	}
+
+	var titles []string
+	for _, ch := range c.Children {
+		titles = append(titles, string(ch.ChildID())+":"+ch.ChildTitle())
+	}
+	if strings.Join(titles, ",") != "2:Song,1:311,3:Video" {
+		t.Fatalf("Content: unexpected children order: %v", titles)
+	}
+}
+
+// TestAPIStatusEmptyContainers verifies that empty string containers are ignored
+func TestAPIStatusEmptyContainers(t *testing.T) {
+	log.Println("TestAPIStatusEmptyContainers()")
+
+	input := `{"status": "ok", "version": "1.10.2", "nowPlaying": "", "playlist": ""}`
+
+	var a APIStatus
+	if err := json.Unmarshal([]byte(input), &a); err != nil {
+		t.Fatalf("APIStatus: unexpected error: %s", err.Error())
+	}
+
+	if a.Status != "ok" || a.Version != "1.10.2" {
+		t.Fatalf("APIStatus: unexpected status: %+v", a)
+	}
+
+	if len(a.NowPlaying.Entry) != 0 || a.Playlist != nil {
+		t.Fatalf("APIStatus: empty containers were not ignored")
+	}
+}
+
+// TestAPIStatusOpenSubsonic verifies that OpenSubsonic server fields are decoded from both
+// JSON booleans and XML attribute strings
+func TestAPIStatusOpenSubsonic(t *testing.T) {
+	log.Println("TestAPIStatusOpenSubsonic()")
+
+	var tests = []string{
+		`{"status": "ok", "version": "1.16.1", "type": "gonic", "serverVersion": "0.16.4", "openSubsonic": true}`,
+		`{"status": "ok", "version": "1.16.1", "type": "gonic", "serverVersion": "0.16.4", "openSubsonic": "true"}`,
+	}
+
+	for _, test := range tests {
+		var a APIStatus
+		if err := json.Unmarshal([]byte(test), &a); err != nil {
+			t.Fatalf("APIStatus: unexpected error: %s", err.Error())
+		}
+
+		if !a.OpenSubsonic || a.Type != "gonic" || a.ServerVersion != "0.16.4" {
+			t.Fatalf("APIStatus: unexpected OpenSubsonic fields: %+v", a)
+		}
+	}
+}
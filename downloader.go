@@ -0,0 +1,393 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// partSuffix is appended to the name of a file while it is being downloaded
+const partSuffix = ".part"
+
+// DefaultDownloadTemplate is the template used by DownloadTo to name files, such as
+// "Adventure/Adventure/01 - Wonderland"
+const DefaultDownloadTemplate = `{{.Artist}}/{{.Album}}/{{if .Track}}{{printf "%02d" .Track}} - {{end}}{{.Title}}`
+
+// downloadNameReplacer replaces characters which are not valid in file names on common
+// file systems
+var downloadNameReplacer = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_",
+)
+
+// DownloadOptions represents additional options for the DownloadTo() method
+type DownloadOptions struct {
+	// Template is a text/template which generates the path of the file from the song's Audio
+	// metadata, relative to the download directory and without an extension, using slashes
+	// to separate directories.  Default DefaultDownloadTemplate.
+	Template string
+
+	// Tags writes the song's metadata from the server into the file, as an ID3v2.4 tag for
+	// MP3 files, or Vorbis comments for FLAC files, replacing any existing tags of the same
+	// kind.  Files in other formats are not modified.
+	Tags bool
+
+	// CoverArt embeds the song's cover art in the file's tags, if Tags is set
+	CoverArt bool
+
+	// CoverArtSize scales the embedded cover art to the specified size, if set
+	CoverArtSize int64
+}
+
+// DownloadTo downloads the original file for a song into a directory, naming it from the
+// song's metadata using a template, with an optional DownloadOptions struct.  The file's
+// modification time is set to the time the song was added to the library.  The path of the
+// file is returned.
+func (s Client) DownloadTo(ctx context.Context, id ID, dir string, options *DownloadOptions) (string, error) {
+	opts := DownloadOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultDownloadTemplate
+	}
+
+	tmpl, err := template.New("download").Parse(opts.Template)
+	if err != nil {
+		return "", fmt.Errorf("gosubsonic: invalid download template: %s", err.Error())
+	}
+
+	song, err := s.GetSong(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := downloadName(song, tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", fmt.Errorf("gosubsonic: failed to create download directory: %s", err.Error())
+	}
+
+	var pic *tagPicture
+	if opts.Tags && opts.CoverArt && song.CoverArt != "" {
+		if pic, err = s.tagPicture(ctx, song.CoverArt, opts.CoverArtSize); err != nil {
+			return "", err
+		}
+	}
+
+	body, err := s.Download(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	// Write to a temporary file first, so a partial file is never left at the final path
+	f, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+
+	if opts.Tags {
+		err = writeTags(f, body, song, pic)
+	} else {
+		_, err = io.Copy(f, body)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), p)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	if !song.Created.IsZero() {
+		if err := os.Chtimes(p, song.Created, song.Created); err != nil {
+			return "", err
+		}
+	}
+
+	return p, nil
+}
+
+// downloadName generates the relative path of a downloaded song using a template.  Values are
+// sanitized before the template is executed, so they cannot add directories, and the result
+// may never escape the download directory.
+func downloadName(song *Audio, tmpl *template.Template) (string, error) {
+	data := *song
+	for _, v := range []*string{&data.Artist, &data.Album, &data.Title, &data.Genre} {
+		*v = downloadNameReplacer.Replace(*v)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("gosubsonic: failed to execute download template: %s", err.Error())
+	}
+
+	// Leading and trailing dots and spaces are removed from each element, which also removes
+	// any parent directory elements
+	var elems []string
+	for _, e := range strings.Split(b.String(), "/") {
+		if e = strings.Trim(e, ". "); e != "" {
+			elems = append(elems, e)
+		}
+	}
+	if len(elems) == 0 {
+		elems = []string{string(song.ID)}
+	}
+
+	name := filepath.Join(elems...)
+	if song.Suffix != "" {
+		name += "." + song.Suffix
+	}
+
+	return name, nil
+}
+
+// tagPicture retrieves cover art to embed in a file's tags
+func (s Client) tagPicture(ctx context.Context, id ID, size int64) (*tagPicture, error) {
+	res, err := s.fetchBinaryResponse(ctx, s.coverArtURL(id, size), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+
+	return &tagPicture{contentType: contentType, data: data}, nil
+}
+
+// Downloader downloads songs to a local directory, preserving the paths reported by the
+// server.  Interrupted downloads are kept as partial files, and are resumed using range
+// requests the next time they are downloaded.  Songs which were already downloaded are
+// skipped.
+type Downloader struct {
+	// Dir is the directory which songs are downloaded into
+	Dir string
+
+	// Concurrency is the maximum number of concurrent downloads, default 4
+	Concurrency int
+
+	// Progress is called as each song is written, and once more when it is finished or fails.
+	// Calls are never concurrent.
+	Progress func(DownloadProgress)
+
+	// Verify is called with the complete contents of each downloaded song before it is moved
+	// into place, if set, such as to compare a checksum recorded elsewhere, since Subsonic
+	// does not report checksums.  If it returns an error, the partial file is deleted, so the
+	// song is downloaded again from the start next time.  Calls may be concurrent.
+	Verify func(song *Audio, r io.Reader) error
+
+	client Client
+	mu     sync.Mutex
+}
+
+// DownloadProgress reports the progress of a single song downloaded by a Downloader
+type DownloadProgress struct {
+	// ID is the ID of the song
+	ID ID
+
+	// Path is the path the song is written to, once it is known
+	Path string
+
+	// Written is the number of bytes on disk, including those from a resumed partial file
+	Written int64
+
+	// Size is the size of the song reported by the server, or 0 if unknown
+	Size int64
+
+	// Done is set once the download is finished, successfully or not
+	Done bool
+
+	// Err is the error which caused the download to fail, if any
+	Err error
+}
+
+// NewDownloader creates a new Downloader which downloads songs into the specified directory
+func (s Client) NewDownloader(dir string) *Downloader {
+	return &Downloader{
+		Dir:    dir,
+		client: s,
+	}
+}
+
+// Download downloads each of the specified songs with bounded concurrency.  If any song
+// cannot be downloaded, a *BulkError describing each failure is returned.
+func (d *Downloader) Download(ctx context.Context, ids []ID) error {
+	n := d.Concurrency
+	if n <= 0 {
+		n = bulkConcurrency
+	}
+
+	return bulkFetchN(ctx, n, ids, func(i int, id ID) error {
+		p := DownloadProgress{ID: id}
+		err := d.download(ctx, &p)
+
+		p.Done = true
+		p.Err = err
+		d.report(p)
+
+		return err
+	})
+}
+
+// download downloads a single song, resuming a partial file if one exists
+func (d *Downloader) download(ctx context.Context, p *DownloadProgress) error {
+	song, err := d.client.GetSong(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+
+	p.Path = filepath.Join(d.Dir, downloadPath(song))
+	p.Size = song.Size
+
+	// Skip songs which were already downloaded
+	if fi, err := os.Stat(p.Path); err == nil && (p.Size == 0 || fi.Size() == p.Size) {
+		p.Written = fi.Size()
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.Path), 0755); err != nil {
+		return fmt.Errorf("gosubsonic: failed to create download directory: %s", err.Error())
+	}
+
+	part := p.Path + partSuffix
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Resume from the end of a partial file, unless it cannot belong to this song
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err == nil && p.Size > 0 && offset > p.Size {
+		if err = f.Truncate(0); err == nil {
+			offset, err = f.Seek(0, io.SeekStart)
+		}
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	p.Written = offset
+
+	if p.Size == 0 || offset < p.Size {
+		if err := d.copy(ctx, f, p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if p.Size > 0 && p.Written != p.Size {
+		return fmt.Errorf("gosubsonic: downloaded %d of %d bytes of song %s", p.Written, p.Size, p.ID)
+	}
+
+	// A resumed partial file may be corrupt, so it is discarded entirely if it fails
+	if err := d.verify(song, part); err != nil {
+		os.Remove(part)
+		return err
+	}
+
+	return os.Rename(part, p.Path)
+}
+
+// verify verifies the contents of a downloaded song, if a Verify callback is set
+func (d *Downloader) verify(song *Audio, part string) error {
+	if d.Verify == nil {
+		return nil
+	}
+
+	f, err := os.Open(part)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := d.Verify(song, f); err != nil {
+		return fmt.Errorf("gosubsonic: verification of song %s failed: %w", song.ID, err)
+	}
+
+	return nil
+}
+
+// copy appends the remainder of a song to a partial file, reporting progress as it is written
+func (d *Downloader) copy(ctx context.Context, f *os.File, p *DownloadProgress) error {
+	body, _, err := d.client.DownloadRange(ctx, p.ID, p.Written, 0)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(f, &progressReader{r: body, fn: func(n int) {
+		p.Written += int64(n)
+		d.report(*p)
+	}})
+	return err
+}
+
+// report reports download progress, if a callback is set
+func (d *Downloader) report(p DownloadProgress) {
+	if d.Progress == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Progress(p)
+}
+
+// progressReader is an io.Reader which invokes a callback with the number of bytes read
+type progressReader struct {
+	r  io.Reader
+	fn func(int)
+}
+
+// Read reads from the underlying io.Reader, and reports the number of bytes read
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.fn(n)
+	}
+
+	return n, err
+}
+
+// downloadPath returns the relative path a song is downloaded to.  The server's path is used
+// if set, but may never escape the download directory.  Otherwise, the song's ID and suffix
+// are used.
+func downloadPath(song *Audio) string {
+	p := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(song.Path, "\\", "/")), "/")
+	if p != "" {
+		return filepath.FromSlash(p)
+	}
+
+	if song.Suffix == "" {
+		return string(song.ID)
+	}
+
+	return fmt.Sprintf("%s.%s", song.ID, song.Suffix)
+}
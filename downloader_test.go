@@ -0,0 +1,250 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDownloader verifies that Downloader downloads songs to their server paths, resumes
+// partial files, skips finished files, and reports failures
+func TestDownloader(t *testing.T) {
+	log.Println("TestDownloader()")
+
+	songs := map[string]struct {
+		path string
+		data []byte
+	}{
+		"1": {"Adventure/Adventure/01 - Wonderland.mp3", bytes.Repeat([]byte("a"), 100000)},
+		"2": {"../../etc/Boston.mp3", []byte("more than a feeling")},
+	}
+
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		song, ok := songs[r.URL.Query().Get("id")]
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/rest/ping.view":
+			w.Write(mockTable[0].data)
+		case !ok:
+			w.Write([]byte(`{"subsonic-response": {"status": "failed", "version": "1.9.0",
+				"error": {"code": 70, "message": "Song not found"}}}`))
+		case r.URL.Path == "/rest/getSong.view":
+			fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.9.0",
+				"song": {"id": %s, "title": "Song", "path": %q, "size": %d}}}`,
+				r.URL.Query().Get("id"), song.path, len(song.data))
+		case r.URL.Path == "/rest/download.view":
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+
+			w.Header().Set("Content-Type", "audio/mpeg")
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(song.data))
+		}
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "Adventure", "Adventure", "01 - Wonderland.mp3")
+
+	// Leave a partial download of the first song
+	if err := os.MkdirAll(filepath.Dir(first), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(first+partSuffix, songs["1"].data[:40000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := s.NewDownloader(dir)
+	d.Concurrency = 2
+
+	done := make(map[ID]DownloadProgress)
+	d.Progress = func(p DownloadProgress) {
+		if p.Done {
+			done[p.ID] = p
+		}
+	}
+
+	err = d.Download(context.Background(), []ID{"1", "2", "3"})
+
+	var be *BulkError
+	if !errors.As(err, &be) || len(be.Items) != 1 || be.Items[0].ID != "3" || !IsNotFound(be.Items[0].Err) {
+		t.Fatalf("Download returned unexpected error: %v", err)
+	}
+
+	if len(done) != 3 || done["1"].Written != 100000 || done["1"].Path != first || done["3"].Err == nil {
+		t.Fatalf("Download reported invalid progress: %+v", done)
+	}
+
+	// Partial files are resumed, and paths never escape the download directory
+	for id, path := range map[string]string{"1": first, "2": filepath.Join(dir, "etc", "Boston.mp3")} {
+		out, err := os.ReadFile(path)
+		if err != nil || !bytes.Equal(out, songs[id].data) {
+			t.Fatalf("Download wrote invalid file %q: %v", path, err)
+		}
+	}
+
+	if _, err := os.Stat(first + partSuffix); !os.IsNotExist(err) {
+		t.Fatalf("Download did not remove partial file: %v", err)
+	}
+
+	if len(ranges) != 2 || (ranges[0] != "bytes=40000-" && ranges[1] != "bytes=40000-") {
+		t.Fatalf("Download made invalid requests: %q", ranges)
+	}
+
+	// Finished songs are not downloaded again
+	if err := d.Download(context.Background(), []ID{"1", "2"}); err != nil {
+		t.Fatalf("Download returned error: %s", err.Error())
+	}
+	if len(ranges) != 2 {
+		t.Fatalf("Download downloaded finished songs again: %q", ranges)
+	}
+
+	// A corrupt partial file is resumed, but rejected by verification and deleted
+	if err := os.Remove(first); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := append(bytes.Repeat([]byte("x"), 100), songs["1"].data[100:40000]...)
+	if err := os.WriteFile(first+partSuffix, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(songs["1"].data)
+	d.Verify = func(song *Audio, r io.Reader) error {
+		h := sha256.New()
+		if _, err := io.Copy(h, r); err != nil {
+			return err
+		}
+		if !bytes.Equal(h.Sum(nil), sum[:]) {
+			return errors.New("checksum mismatch")
+		}
+		return nil
+	}
+
+	err = d.Download(context.Background(), []ID{"1"})
+	if !errors.As(err, &be) || len(be.Items) != 1 || !strings.Contains(be.Items[0].Err.Error(), "checksum mismatch") {
+		t.Fatalf("Download returned unexpected error for corrupt file: %v", err)
+	}
+	for _, p := range []string{first, first + partSuffix} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("Download kept corrupt file %q: %v", p, err)
+		}
+	}
+
+	// The song is downloaded again from the start, and verified
+	if err := d.Download(context.Background(), []ID{"1"}); err != nil {
+		t.Fatalf("Download returned error: %s", err.Error())
+	}
+	if out, err := os.ReadFile(first); err != nil || !bytes.Equal(out, songs["1"].data) {
+		t.Fatalf("Download wrote invalid file after verification failure: %v", err)
+	}
+	if ranges[len(ranges)-1] != "" {
+		t.Fatalf("Download resumed after verification failure: %q", ranges)
+	}
+}
+
+// TestDownloadTo verifies that client.DownloadTo() names files from their metadata, writes
+// tags and cover art, and sets their modification times
+func TestDownloadTo(t *testing.T) {
+	log.Println("TestDownloadTo()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetFixture("getSong", url.Values{"id": {"1"}}, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"song": {"id": 1, "title": "Wonder/land", "artist": "Adventure", "album": "Adventure", "track": 1,
+		"year": 2011, "suffix": "mp3", "coverArt": "cover", "created": "2013-08-12T00:12:24"}}}`))
+	f.SetFixture("getSong", url.Values{"id": {"2"}}, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"song": {"id": 2, "title": "Amber", "artist": 311, "album": "..", "suffix": "flac", "coverArt": "cover"}}}`))
+
+	// An MP3 file with an existing tag, and a FLAC file with stream info and a comment
+	f.SetMedia("1", "audio/mpeg", []byte("ID3\x03\x00\x00\x00\x00\x00\x06OLDTAGaudio"))
+	f.SetMedia("2", "audio/flac", append([]byte("fLaC\x00\x00\x00\x22"), append(make([]byte, 34), "\x84\x00\x00\x01xframes"...)...))
+	f.SetMedia("cover", "image/png", []byte("\x89PNG cover"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Without tags, the file is downloaded unchanged
+	p, err := s.DownloadTo(ctx, "1", dir, nil)
+	if err != nil {
+		t.Fatalf("DownloadTo returned error: %s", err.Error())
+	}
+	if p != filepath.Join(dir, "Adventure", "Adventure", "01 - Wonder_land.mp3") {
+		t.Fatalf("DownloadTo returned invalid path: %s", p)
+	}
+	if b, err := os.ReadFile(p); err != nil || !strings.HasSuffix(string(b), "OLDTAGaudio") {
+		t.Fatalf("DownloadTo wrote invalid file: %q %v", b, err)
+	}
+	if fi, err := os.Stat(p); err != nil || fi.ModTime().Year() != 2013 {
+		t.Fatalf("DownloadTo did not set modification time: %v", err)
+	}
+
+	// The existing ID3 tag is replaced
+	p, err = s.DownloadTo(ctx, "1", dir, &DownloadOptions{Tags: true, CoverArt: true})
+	if err != nil {
+		t.Fatalf("DownloadTo returned error: %s", err.Error())
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatalf("DownloadTo wrote no file: %s", err.Error())
+	}
+	if !bytes.HasPrefix(b, []byte("ID3\x04")) || !bytes.HasSuffix(b, []byte("audio")) || bytes.Contains(b, []byte("OLDTAG")) ||
+		!bytes.Contains(b, []byte("TIT2\x00\x00\x00\x0c\x00\x00\x03Wonder/land")) || !bytes.Contains(b, []byte("APIC")) ||
+		!bytes.Contains(b, []byte("image/png\x00\x03\x00\x89PNG cover")) {
+		t.Fatalf("DownloadTo wrote invalid ID3 tag: %q", b)
+	}
+
+	// Parent directory elements are removed from names, and the FLAC comment is replaced
+	p, err = s.DownloadTo(ctx, "2", dir, &DownloadOptions{Template: "{{.Artist}}/{{.Album}}/{{.Title}}", Tags: true, CoverArt: true})
+	if err != nil {
+		t.Fatalf("DownloadTo returned error: %s", err.Error())
+	}
+	if p != filepath.Join(dir, "311", "Amber.flac") {
+		t.Fatalf("DownloadTo returned invalid path: %s", p)
+	}
+
+	b, err = os.ReadFile(p)
+	if err != nil || !bytes.HasPrefix(b, []byte("fLaC")) || !bytes.HasSuffix(b, []byte("frames")) {
+		t.Fatalf("DownloadTo wrote invalid FLAC file: %q %v", b, err)
+	}
+
+	var types []byte
+	for i := 4; i < len(b)-len("frames"); {
+		types = append(types, b[i])
+		i += 4 + int(b[i+1])<<16 | int(b[i+2])<<8 | int(b[i+3])
+	}
+	if string(types) != "\x00\x04\x86" || !bytes.Contains(b, []byte("TITLE=Amber")) || !bytes.Contains(b, []byte("ARTIST=311")) {
+		t.Fatalf("DownloadTo wrote invalid FLAC metadata: %v %q", types, b)
+	}
+
+	if _, err := s.DownloadTo(ctx, "1", dir, &DownloadOptions{Template: "{{.Missing}}"}); err == nil {
+		t.Fatalf("DownloadTo accepted invalid template")
+	}
+}
@@ -0,0 +1,78 @@
+package gosubsonic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrResponseTooLarge is returned when an API response exceeds the client's maximum
+// response size
+var ErrResponseTooLarge = errors.New("gosubsonic: response exceeds maximum size")
+
+// ErrNotSupported is returned when the server does not support an optional feature, such as
+// free servers which do not report a license
+var ErrNotSupported = errors.New("gosubsonic: not supported by server")
+
+// ErrorCode represents an error code reported by Subsonic
+type ErrorCode int
+
+// Error codes reported by Subsonic
+const (
+	ErrCodeGeneric               ErrorCode = 0
+	ErrCodeMissingParameter      ErrorCode = 10
+	ErrCodeClientTooOld          ErrorCode = 20
+	ErrCodeServerTooOld          ErrorCode = 30
+	ErrCodeWrongCredentials      ErrorCode = 40
+	ErrCodeTokenAuthNotSupported ErrorCode = 41
+	ErrCodeNotAuthorized         ErrorCode = 50
+	ErrCodeTrialExpired          ErrorCode = 60
+	ErrCodeNotFound              ErrorCode = 70
+)
+
+// Error represents an error reported by Subsonic, which may be inspected using errors.As,
+// or the IsAuthError and IsNotFound helpers
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+// APIError is the previous name of Error, retained for compatibility
+type APIError = Error
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("gosubsonic: %d: %s", e.Code, e.Message)
+}
+
+// hasCode determines if an error is an Error with one of the specified codes
+func hasCode(err error, codes ...ErrorCode) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	for _, c := range codes {
+		if e.Code == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAuthError determines if an error was caused by wrong credentials, or by token
+// authentication which is not supported for the user, such as LDAP users
+func IsAuthError(err error) bool {
+	return hasCode(err, ErrCodeWrongCredentials, ErrCodeTokenAuthNotSupported)
+}
+
+// IsNotAuthorized determines if an error was caused by a user who is not authorized to
+// perform an operation, such as a non-admin user attempting to manage users
+func IsNotAuthorized(err error) bool {
+	return hasCode(err, ErrCodeNotAuthorized)
+}
+
+// IsNotFound determines if an error was caused by requested data which was not found
+func IsNotFound(err error) bool {
+	return hasCode(err, ErrCodeNotFound)
+}
@@ -0,0 +1,93 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestProcessJSONError verifies that errors reported by Subsonic are returned as an Error
+func TestProcessJSONError(t *testing.T) {
+	log.Println("TestProcessJSONError()")
+
+	var tests = []struct {
+		code     ErrorCode
+		auth     bool
+		notFound bool
+	}{
+		{ErrCodeGeneric, false, false},
+		{ErrCodeWrongCredentials, true, false},
+		{ErrCodeTokenAuthNotSupported, true, false},
+		{ErrCodeNotAuthorized, false, false},
+		{ErrCodeTrialExpired, false, false},
+		{ErrCodeNotFound, false, true},
+	}
+
+	for _, test := range tests {
+		body := fmt.Sprintf(`{"subsonic-response": {
+			"status": "failed",
+			"version": "1.9.0",
+			"error": {"code": %d, "message": "mock error"}
+		}}`, test.code)
+
+		_, err := processJSON(strings.NewReader(body))
+
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("processJSON returned unexpected error type: %T", err)
+		}
+
+		if e.Code != test.code || e.Message != "mock error" {
+			t.Fatalf("processJSON returned invalid error: %+v", e)
+		}
+
+		if IsAuthError(err) != test.auth || IsNotFound(err) != test.notFound {
+			t.Fatalf("error predicates returned invalid results for code %d", test.code)
+		}
+
+		// Predicates should see through wrapped errors
+		if IsNotFound(fmt.Errorf("wrapped: %w", err)) != test.notFound {
+			t.Fatalf("IsNotFound returned invalid result for wrapped code %d", test.code)
+		}
+	}
+
+	// Other errors are never Subsonic errors
+	if IsAuthError(errors.New("mock")) || IsNotFound(nil) {
+		t.Fatalf("error predicates returned true for non-Subsonic errors")
+	}
+}
+
+// TestFetchBinaryError verifies that errors reported in place of binary data are returned as an Error
+func TestFetchBinaryError(t *testing.T) {
+	log.Println("TestFetchBinaryError()")
+
+	// Test server which reports that all binary data is not found
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/rest/ping.view" {
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		w.Write([]byte(`{"subsonic-response": {
+			"status": "failed",
+			"version": "1.9.0",
+			"error": {"code": 70, "message": "Cover art not found"}
+		}}`))
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	if _, err := s.GetCoverArt(context.Background(), "1", 0); !IsNotFound(err) {
+		t.Fatalf("GetCoverArt returned unexpected error: %v", err)
+	}
+}
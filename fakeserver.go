@@ -0,0 +1,349 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials accepted by a FakeServer by default
+const (
+	fakeUsername = "mock"
+	fakePassword = "mock"
+)
+
+// FakeServer is a fake Subsonic server for testing, which serves the REST API over HTTP using
+// configurable fixtures.  It verifies authentication parameters like a real server, serves
+// binary media with support for range requests, and can inject latency and errors.
+//
+// A new FakeServer serves the same fixtures and media used by NewMock, and accepts the
+// username and password "mock".  A FakeServer is safe for concurrent use.
+type FakeServer struct {
+	// URL is the base URL of the server, which may be used as a Client's Host
+	URL string
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	username string
+	password string
+	latency  time.Duration
+	fixtures map[string][]fakeFixture
+	media    map[ID]fakeMedia
+	errors   map[string]*Error
+	statuses map[string]int
+	requests map[string]int
+}
+
+// fakeFixture represents a response to an API method, for requests with matching parameters
+type fakeFixture struct {
+	params url.Values
+	body   []byte
+}
+
+// fakeMedia represents a binary media file served by a FakeServer
+type fakeMedia struct {
+	contentType string
+	data        []byte
+}
+
+// NewFakeServer creates and starts a new FakeServer.  The server must be closed once it is
+// no longer needed.
+func NewFakeServer() *FakeServer {
+	f := newFakeServer()
+	f.srv = httptest.NewServer(f)
+	f.URL = f.srv.URL
+
+	return f
+}
+
+// newFakeServer creates a FakeServer populated with the mock fixtures, without starting it
+func newFakeServer() *FakeServer {
+	f := &FakeServer{
+		username: fakeUsername,
+		password: fakePassword,
+		fixtures: make(map[string][]fakeFixture),
+		media:    make(map[ID]fakeMedia),
+		errors:   make(map[string]*Error),
+		statuses: make(map[string]int),
+		requests: make(map[string]int),
+	}
+
+	for _, entry := range mockTable {
+		// Mock parameters are static, and always valid
+		params, _ := url.ParseQuery(mockParams[entry.method])
+		f.fixtures[entry.method] = append(f.fixtures[entry.method], fakeFixture{params, entry.data})
+	}
+	for id, m := range mockMedia {
+		f.media[id] = m
+	}
+
+	return f
+}
+
+// Close shuts down the server
+func (f *FakeServer) Close() {
+	if f.srv != nil {
+		f.srv.Close()
+	}
+}
+
+// Client creates a new Client which connects to the server using its credentials
+func (f *FakeServer) Client() (*Client, error) {
+	f.mu.Lock()
+	username, password := f.username, f.password
+	f.mu.Unlock()
+
+	return New(f.URL, WithPassword(username, password))
+}
+
+// SetCredentials sets the username and password which the server accepts
+func (f *FakeServer) SetCredentials(username string, password string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.username = username
+	f.password = password
+}
+
+// SetFixture sets the JSON response body for requests to an API method, such as "getSong",
+// with the specified parameters.  Authentication, client, version, and format parameters are
+// ignored.  If params is nil, the fixture matches requests with any parameters.  Fixtures set
+// later take precedence over earlier ones for the same method.
+func (f *FakeServer) SetFixture(method string, params url.Values, body []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fixtures[method] = append([]fakeFixture{{params, body}}, f.fixtures[method]...)
+}
+
+// SetMedia sets the binary data served for a media ID by the stream, download, and
+// getCoverArt methods
+func (f *FakeServer) SetMedia(id ID, contentType string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.media[id] = fakeMedia{contentType, data}
+}
+
+// SetLatency delays every response by the specified duration, or until the request is
+// canceled
+func (f *FakeServer) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.latency = d
+}
+
+// SetError causes every request to an API method to fail with the specified Subsonic error
+func (f *FakeServer) SetError(method string, code ErrorCode, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors[method] = &Error{Code: code, Message: message}
+}
+
+// SetStatus causes every request to an API method to fail with the specified HTTP status
+// code, and no Subsonic response
+func (f *FakeServer) SetStatus(method string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.statuses[method] = status
+}
+
+// ClearErrors removes all errors set using SetError and SetStatus
+func (f *FakeServer) ClearErrors() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors = make(map[string]*Error)
+	f.statuses = make(map[string]int)
+}
+
+// Requests returns the number of requests received for an API method
+func (f *FakeServer) Requests(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[method]
+}
+
+// ServeHTTP implements http.Handler, and serves the Subsonic REST API
+func (f *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := urlMethod(r.URL.String())
+	q := r.URL.Query()
+
+	f.mu.Lock()
+	f.requests[method]++
+	latency := f.latency
+	status := f.statuses[method]
+	apiErr := f.errors[method]
+	authErr := f.authenticate(q)
+	f.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			t.Stop()
+			return
+		}
+	}
+
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/rest/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case authErr != nil:
+		fakeError(w, authErr)
+		return
+	case apiErr != nil:
+		fakeError(w, apiErr)
+		return
+	}
+
+	switch method {
+	case "stream", "download", "getCoverArt":
+		f.serveMedia(w, r, q)
+		return
+	}
+
+	body, ok := f.fixture(method, q)
+	if !ok {
+		fakeError(w, &Error{Code: ErrCodeNotFound, Message: "Requested data was not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// authenticate verifies a request's authentication parameters, using either a password or a
+// token, and returns an error if they are missing or incorrect
+func (f *FakeServer) authenticate(q url.Values) *Error {
+	if q.Get("u") == "" || (q.Get("p") == "" && q.Get("t") == "") {
+		return &Error{Code: ErrCodeMissingParameter, Message: "Required parameter is missing"}
+	}
+
+	ok := q.Get("u") == f.username
+	switch {
+	case q.Get("t") != "":
+		token := md5.Sum([]byte(f.password + q.Get("s")))
+		ok = ok && q.Get("t") == hex.EncodeToString(token[:])
+	case strings.HasPrefix(q.Get("p"), "enc:"):
+		ok = ok && q.Get("p") == "enc:"+hex.EncodeToString([]byte(f.password))
+	default:
+		ok = ok && q.Get("p") == f.password
+	}
+
+	if !ok {
+		return &Error{Code: ErrCodeWrongCredentials, Message: "Wrong username or password"}
+	}
+
+	return nil
+}
+
+// fixture returns the response body of the first fixture for an API method which matches the
+// request's parameters
+func (f *FakeServer) fixture(method string, q url.Values) ([]byte, bool) {
+	params := url.Values{}
+	for k, v := range q {
+		switch k {
+		case "u", "p", "t", "s", "c", "v", "f":
+			continue
+		}
+
+		params[k] = v
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, fx := range f.fixtures[method] {
+		if fx.params == nil || fx.params.Encode() == params.Encode() {
+			return fx.body, true
+		}
+	}
+
+	return nil, false
+}
+
+// serveMedia serves the binary data for a media ID, including range requests
+func (f *FakeServer) serveMedia(w http.ResponseWriter, r *http.Request, q url.Values) {
+	f.mu.Lock()
+	m, ok := f.media[ID(q.Get("id"))]
+	f.mu.Unlock()
+
+	if !ok {
+		fakeError(w, &Error{Code: ErrCodeNotFound, Message: "Media not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", m.contentType)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(m.data))
+}
+
+// fakeError writes a failed Subsonic response containing an error
+func fakeError(w http.ResponseWriter, e *Error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"subsonic-response": map[string]interface{}{
+			"status":  "failed",
+			"version": "1.9.0",
+			"error": map[string]interface{}{
+				"code":    e.Code,
+				"message": e.Message,
+			},
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// DataSource returns a BinaryDataSource which serves API and binary responses directly
+// from the server, without a network connection.  It may be used with WithDataSource, in
+// which case the Client's Host is ignored.
+func (f *FakeServer) DataSource() BinaryDataSource {
+	return httpDataSource{client: &http.Client{Transport: fakeTransport{server: f}}}
+}
+
+// fakeTransport is a http.RoundTripper which serves requests directly from a FakeServer,
+// without a network connection
+type fakeTransport struct {
+	server *FakeServer
+}
+
+// RoundTrip serves a single request using the FakeServer
+func (t fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	rec := httptest.NewRecorder()
+	t.server.ServeHTTP(rec, req)
+
+	// Report cancellation during injected latency like a real connection would
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	res := rec.Result()
+	res.Request = req
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestFakeServer verifies that FakeServer serves fixtures and media over HTTP, and verifies
+// authentication
+func TestFakeServer(t *testing.T) {
+	log.Println("TestFakeServer()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+	ctx := context.Background()
+
+	// Mock fixtures are served with their parameters, and token authentication is verified
+	s.TokenAuth = true
+	if _, err := s.GetSong(ctx, "1"); err != nil {
+		t.Fatalf("GetSong returned error: %s", err.Error())
+	}
+	if _, err := s.GetSong(ctx, "2"); !IsNotFound(err) {
+		t.Fatalf("GetSong returned unexpected error for missing song: %v", err)
+	}
+	if n := f.Requests("getSong"); n != 2 {
+		t.Fatalf("FakeServer counted %d getSong requests", n)
+	}
+
+	// Fixtures may be added for other parameters
+	f.SetFixture("getSong", url.Values{"id": {"2"}}, []byte(`{"subsonic-response": {
+		"status": "ok", "version": "1.9.0", "song": {"id": 2, "title": "Custom"}}}`))
+	if song, err := s.GetSong(ctx, "2"); err != nil || song.Title != "Custom" {
+		t.Fatalf("GetSong returned unexpected song for fixture: %+v, %v", song, err)
+	}
+
+	// Media is served with range requests
+	f.SetMedia("1", "audio/mpeg", []byte("0123456789"))
+	body, size, err := s.DownloadRange(ctx, "1", 4, 3)
+	if err != nil {
+		t.Fatalf("DownloadRange returned error: %s", err.Error())
+	}
+	out, _ := io.ReadAll(body)
+	body.Close()
+
+	if string(out) != "456" || size != 10 {
+		t.Fatalf("DownloadRange returned %q of %d bytes", out, size)
+	}
+
+	// Wrong credentials are rejected
+	f.SetCredentials("mock", "changed")
+	if _, err := s.Ping(ctx); !IsAuthError(err) {
+		t.Fatalf("Ping returned unexpected error for wrong credentials: %v", err)
+	}
+}
+
+// TestFakeServerErrors verifies that FakeServer injects errors and latency
+func TestFakeServerErrors(t *testing.T) {
+	log.Println("TestFakeServerErrors()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+	ctx := context.Background()
+
+	f.SetError("getMusicFolders", ErrCodeNotAuthorized, "Not authorized")
+	if _, err := s.GetMusicFolders(ctx); !IsNotAuthorized(err) {
+		t.Fatalf("GetMusicFolders returned unexpected error: %v", err)
+	}
+
+	f.SetStatus("getMusicFolders", http.StatusServiceUnavailable)
+	var se *StatusError
+	if _, err := s.GetMusicFolders(ctx); !errors.As(err, &se) || se.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("GetMusicFolders returned unexpected error: %v", err)
+	}
+
+	f.ClearErrors()
+	if _, err := s.GetMusicFolders(ctx); err != nil {
+		t.Fatalf("GetMusicFolders returned error: %s", err.Error())
+	}
+
+	f.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := s.GetMusicFolders(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("GetMusicFolders returned unexpected error with latency: %v", err)
+	}
+}
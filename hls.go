@@ -0,0 +1,234 @@
+package gosubsonic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hlsContentType is the content type of HLS playlists
+const hlsContentType = "application/vnd.apple.mpegurl"
+
+// hlsURIAttribute matches the URI attribute of an HLS tag, such as #EXT-X-KEY or #EXT-X-MEDIA
+var hlsURIAttribute = regexp.MustCompile(`URI="([^"]*)"`)
+
+// hlsClientParams are the query parameters which identify and authenticate a client, which
+// are replaced when a URL is authorized
+var hlsClientParams = []string{"u", "p", "t", "s", "c", "v", "f"}
+
+// HLSRewriter returns the URI written into an HLS playlist in place of each URI in the
+// playlist returned by the server.  u is the original URI, resolved against the server's
+// URL.  playlist is set if u refers to another playlist, such as a variant of an adaptive
+// playlist, rather than a media segment or key.
+type HLSRewriter func(u *url.URL, playlist bool) string
+
+// GetHLSPlaylist retrieves an HTTP Live Streaming (m3u8) playlist, with an optional
+// HLSOptions struct, and rewrites each URI it contains using rewrite.  If rewrite is nil,
+// URIs are resolved against the server, and URIs for the server include authentication
+// parameters, so the playlist may be played by devices which cannot authenticate with the
+// server, such as cast devices.  Token authentication should be used to avoid exposing the
+// password.
+//
+// Players fetch the variants of adaptive playlists themselves, so the URIs within variants
+// are not rewritten.  HLSHandler serves variants with their URIs rewritten too.
+func (s Client) GetHLSPlaylist(ctx context.Context, id ID, options *HLSOptions, rewrite HLSRewriter) ([]byte, error) {
+	return s.hlsPlaylist(ctx, s.hlsURL(id, options), rewrite)
+}
+
+// hlsPlaylist retrieves the HLS playlist at a URL, and rewrites its URIs
+func (s Client) hlsPlaylist(ctx context.Context, src string, rewrite HLSRewriter) ([]byte, error) {
+	body, err := s.fetchBinary(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(s.limitResponse(body, src))
+	if err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to read HLS playlist: %s - %s", err.Error(), redactURL(src))
+	}
+
+	// Playlists must begin with their tag, which also rejects error pages
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("#EXTM3U")) {
+		return nil, fmt.Errorf("gosubsonic: response is not an HLS playlist - %s", redactURL(src))
+	}
+
+	base, err := url.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if rewrite == nil {
+		rewrite = func(u *url.URL, playlist bool) string {
+			return s.authorizeURL(u)
+		}
+	}
+
+	return rewriteHLS(b, base, rewrite), nil
+}
+
+// rewriteHLS rewrites each URI in an HLS playlist, both on their own lines and in the URI
+// attributes of tags, after resolving them against base
+func rewriteHLS(playlist []byte, base *url.URL, rewrite HLSRewriter) []byte {
+	resolve := func(ref string, playlist bool) string {
+		u, err := base.Parse(ref)
+		if err != nil {
+			return ref
+		}
+
+		return rewrite(u, playlist || path.Ext(u.Path) == ".m3u8")
+	}
+
+	var out bytes.Buffer
+	var variant bool
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			// Renditions and I-frame playlists are playlists, while keys and maps are not
+			nested := strings.HasPrefix(line, "#EXT-X-MEDIA:") || strings.HasPrefix(line, "#EXT-X-I-FRAME-STREAM-INF:")
+			line = hlsURIAttribute.ReplaceAllStringFunc(line, func(attr string) string {
+				ref := hlsURIAttribute.FindStringSubmatch(attr)[1]
+				return `URI="` + resolve(ref, nested) + `"`
+			})
+
+			// The URI following a stream tag is a variant playlist
+			if strings.HasPrefix(line, "#EXT-X-STREAM-INF:") {
+				variant = true
+			}
+		default:
+			line = resolve(line, variant)
+			variant = false
+		}
+
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes()
+}
+
+// authorizeURL adds client and authentication parameters to a URL for the server, replacing
+// any which are present.  URLs for other hosts are returned unchanged.
+func (s Client) authorizeURL(u *url.URL) string {
+	server, err := url.Parse(s.baseURL())
+	if err != nil || u.Scheme != server.Scheme || u.Host != server.Host {
+		return u.String()
+	}
+
+	q := u.Query()
+	for _, p := range hlsClientParams {
+		q.Del(p)
+	}
+
+	params := Params(q)
+	params.Set("u", s.Username)
+	params.Set("c", s.clientName())
+	params.Set("v", s.apiVersion())
+	s.authParams(params)
+
+	out := *u
+	out.RawQuery = url.Values(params).Encode()
+	return out.String()
+}
+
+// HLSHandler returns an http.Handler which serves HLS playlists to devices which cannot
+// authenticate with the server, such as cast devices, from a Go backend.  Requests with an
+// id parameter, and optional bitRate and audioTrack parameters as in HLSOptions, are served
+// the playlist for that media item.  Variant playlists are also served by the handler, using
+// a src parameter, while media segments are retrieved directly from the server using URLs
+// with authentication parameters, as with GetHLSPlaylist.
+func (s Client) HLSHandler() http.Handler {
+	return hlsHandler{client: s}
+}
+
+// hlsHandler is the http.Handler returned by HLSHandler
+type hlsHandler struct {
+	client Client
+}
+
+// ServeHTTP serves a single HLS playlist
+func (h hlsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := h.client
+	q := r.URL.Query()
+
+	var src string
+	switch {
+	case q.Get("src") != "":
+		// Variant playlists may only be retrieved from the server
+		u, err := url.Parse(q.Get("src"))
+		if err != nil || u.IsAbs() || u.Host != "" || !strings.HasPrefix(u.Path, "/") || path.Ext(u.Path) != ".m3u8" {
+			http.Error(w, "invalid playlist", http.StatusBadRequest)
+			return
+		}
+
+		src = s.baseURL() + u.String()
+	case q.Get("id") != "":
+		options := &HLSOptions{AudioTrack: ID(q.Get("audioTrack"))}
+		for _, b := range q["bitRate"] {
+			n, err := strconv.ParseInt(b, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid bit rate", http.StatusBadRequest)
+				return
+			}
+
+			options.BitRate = append(options.BitRate, n)
+		}
+
+		src = s.hlsURL(ID(q.Get("id")), options)
+	default:
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	server, err := url.Parse(s.baseURL())
+	if err != nil {
+		http.Error(w, "invalid server URL", http.StatusInternalServerError)
+		return
+	}
+
+	target, err := url.Parse(src)
+	if err != nil {
+		http.Error(w, "invalid playlist", http.StatusBadRequest)
+		return
+	}
+
+	b, err := s.hlsPlaylist(r.Context(), s.authorizeURL(target), func(u *url.URL, playlist bool) string {
+		if !playlist || u.Scheme != server.Scheme || u.Host != server.Host {
+			return s.authorizeURL(u)
+		}
+
+		// Variant playlists are served by the handler, relative to the current request,
+		// without the client's credentials
+		q := u.Query()
+		for _, p := range hlsClientParams {
+			q.Del(p)
+		}
+
+		ref := url.URL{Path: strings.TrimPrefix(u.Path, server.Path), RawQuery: q.Encode()}
+		return "?" + url.Values{"src": {ref.String()}}.Encode()
+	})
+	if err != nil {
+		status := http.StatusBadGateway
+		if IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", hlsContentType)
+	w.Write(b)
+}
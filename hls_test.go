@@ -0,0 +1,145 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// hlsServer is a Subsonic server which serves an adaptive HLS playlist, and its variant,
+// only to authenticated requests
+func hlsServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/rest/hls.m3u8" || q.Get("u") != "mock" || q.Get("p") != "mock" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", hlsContentType)
+		if len(q["bitRate"]) == 1 {
+			w.Write([]byte(strings.Join([]string{
+				"#EXTM3U",
+				`#EXT-X-KEY:METHOD=AES-128,URI="key.view?id=1"`,
+				"#EXTINF:10,",
+				"stream.view?id=1&hls=true&timeOffset=0",
+				"#EXTINF:10,",
+				"https://cdn.example.com/segment.ts",
+				"#EXT-X-ENDLIST",
+			}, "\n")))
+			return
+		}
+
+		w.Write([]byte(strings.Join([]string{
+			"#EXTM3U",
+			`#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",URI="/rest/hls.m3u8?id=1&audioTrack=2"`,
+			"#EXT-X-STREAM-INF:BANDWIDTH=500000",
+			"hls.m3u8?id=1&bitRate=500",
+		}, "\n")))
+	}))
+}
+
+// TestGetHLSPlaylist verifies that client.GetHLSPlaylist() resolves the URIs in a playlist
+// against the server, and authorizes those for the server
+func TestGetHLSPlaylist(t *testing.T) {
+	log.Println("TestGetHLSPlaylist()")
+
+	srv := hlsServer()
+	defer srv.Close()
+
+	s, err := New(srv.URL, WithPassword("mock", "mock"), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	b, err := s.GetHLSPlaylist(context.Background(), "1", &HLSOptions{BitRate: []int64{500}}, nil)
+	if err != nil {
+		t.Fatalf("GetHLSPlaylist returned error: %s", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 7 || lines[5] != "https://cdn.example.com/segment.ts" {
+		t.Fatalf("GetHLSPlaylist returned invalid playlist:\n%s", b)
+	}
+
+	if !strings.HasPrefix(lines[1], `#EXT-X-KEY:METHOD=AES-128,URI="`+srv.URL+"/rest/key.view?") || !strings.Contains(lines[1], "&p=mock&") {
+		t.Fatalf("GetHLSPlaylist did not authorize key: %s", lines[1])
+	}
+
+	u, err := url.Parse(lines[3])
+	if err != nil || u.Path != "/rest/stream.view" || u.Query().Get("timeOffset") != "0" || u.Query().Get("u") != "mock" || u.Query().Get("p") != "mock" {
+		t.Fatalf("GetHLSPlaylist did not authorize segment: %s", lines[3])
+	}
+
+	// Custom rewriters receive resolved URIs
+	var playlists int
+	_, err = s.GetHLSPlaylist(context.Background(), "1", nil, func(u *url.URL, playlist bool) string {
+		if playlist && u.Host == strings.TrimPrefix(srv.URL, "http://") {
+			playlists++
+		}
+
+		return u.String()
+	})
+	if err != nil || playlists != 2 {
+		t.Fatalf("GetHLSPlaylist passed %d playlists to rewriter: %v", playlists, err)
+	}
+}
+
+// TestHLSHandler verifies that client.HLSHandler() serves adaptive playlists and their
+// variants without exposing credentials in variant URIs
+func TestHLSHandler(t *testing.T) {
+	log.Println("TestHLSHandler()")
+
+	srv := hlsServer()
+	defer srv.Close()
+
+	s, err := New(srv.URL, WithPassword("mock", "mock"), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	h := s.HLSHandler()
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := serve("/cast/hls?id=1&bitRate=500&bitRate=1000")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != hlsContentType || len(lines) != 4 {
+		t.Fatalf("HLSHandler returned invalid response: %d\n%s", w.Code, w.Body.String())
+	}
+
+	variant := lines[3]
+	if !strings.HasPrefix(variant, "?src=") || strings.Contains(variant, "p%3Dmock") || !strings.Contains(lines[1], `URI="?src=`) {
+		t.Fatalf("HLSHandler returned invalid variant URIs:\n%s", w.Body.String())
+	}
+
+	// Variants are served with authorized segments
+	w = serve("/cast/hls" + variant)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), srv.URL+"/rest/stream.view?") || !strings.Contains(w.Body.String(), "p=mock") {
+		t.Fatalf("HLSHandler returned invalid variant: %d\n%s", w.Code, w.Body.String())
+	}
+
+	var tests = []struct {
+		target string
+		code   int
+	}{
+		{"/cast/hls", http.StatusBadRequest},
+		{"/cast/hls?id=1&bitRate=high", http.StatusBadRequest},
+		{"/cast/hls?src=" + url.QueryEscape("http://example.com/rest/hls.m3u8"), http.StatusBadRequest},
+		{"/cast/hls?src=" + url.QueryEscape("/rest/getUser.view?username=admin"), http.StatusBadRequest},
+		{"/cast/hls?src=" + url.QueryEscape("/rest/missing.m3u8"), http.StatusBadGateway},
+	}
+
+	for _, test := range tests {
+		if w := serve(test.target); w.Code != test.code {
+			t.Fatalf("HLSHandler(%s) returned %d, expected %d", test.target, w.Code, test.code)
+		}
+	}
+}
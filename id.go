@@ -0,0 +1,87 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ID identifies an item on a Subsonic server, such as an artist, album, song, or playlist.
+// Subsonic uses numeric IDs, while servers such as Navidrome use opaque strings, so IDs are
+// stored as strings, and must be passed back to the server exactly as they were received.
+//
+// Music folders are the exception, as every server identifies them using numbers.
+type ID string
+
+// IntID creates an ID from a numeric ID, for servers which use numeric IDs
+func IntID(id int64) ID {
+	return ID(strconv.FormatInt(id, 10))
+}
+
+// IntIDs creates a slice of IDs from a slice of numeric IDs
+func IntIDs(ids []int64) []ID {
+	out := make([]ID, len(ids))
+	for i, id := range ids {
+		out[i] = IntID(id)
+	}
+
+	return out
+}
+
+// Int64 returns the numeric value of an ID, for servers which use numeric IDs.  An error is
+// returned if the ID is not numeric.
+func (id ID) Int64() (int64, error) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("gosubsonic: ID %q is not numeric", string(id))
+	}
+
+	return n, nil
+}
+
+// String returns the ID as a string
+func (id ID) String() string {
+	return string(id)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  IDs may be returned as numbers or strings,
+// depending on the server, and null decodes to an empty ID.
+func (id *ID) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case len(b) == 0 || string(b) == "null":
+		*id = ""
+	case b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+
+		*id = ID(s)
+	default:
+		// Numbers are kept exactly as they were sent
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("gosubsonic: invalid ID: %s", string(b))
+		}
+
+		*id = ID(n.String())
+	}
+
+	return nil
+}
+
+// idParams generates parameters containing a single ID
+func idParams(id ID) Params {
+	p := Params{}
+	p.Set("id", string(id))
+	return p
+}
+
+// idsParams generates parameters containing one or more IDs
+func idsParams(ids []ID) Params {
+	p := Params{}
+	p.AddIDs("id", ids)
+	return p
+}
@@ -0,0 +1,93 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+)
+
+// TestIDUnmarshalJSON verifies that IDs decode from strings, numbers, and null
+func TestIDUnmarshalJSON(t *testing.T) {
+	log.Println("TestIDUnmarshalJSON()")
+
+	var tests = []struct {
+		input    string
+		expected ID
+	}{
+		{`"al-3f9c2b"`, "al-3f9c2b"},
+		{`"1"`, "1"},
+		{`1`, "1"},
+		{`9007199254740993`, "9007199254740993"},
+		{`null`, ""},
+	}
+
+	for _, test := range tests {
+		var id ID
+		if err := json.Unmarshal([]byte(test.input), &id); err != nil {
+			t.Fatalf("ID: unexpected error for %s: %s", test.input, err.Error())
+		}
+
+		if id != test.expected {
+			t.Fatalf("ID: expected %q for %s, got %q", test.expected, test.input, id)
+		}
+	}
+
+	var id ID
+	if err := json.Unmarshal([]byte(`true`), &id); err == nil {
+		t.Fatalf("ID: expected error for boolean")
+	}
+}
+
+// TestIDInt64 verifies conversion between numeric and string IDs
+func TestIDInt64(t *testing.T) {
+	log.Println("TestIDInt64()")
+
+	if id := IntID(405); id != "405" {
+		t.Fatalf("IntID returned invalid ID: %q", id)
+	}
+
+	if n, err := IntID(405).Int64(); err != nil || n != 405 {
+		t.Fatalf("Int64 returned %d, %v", n, err)
+	}
+
+	if _, err := ID("al-3f9c2b").Int64(); err == nil {
+		t.Fatalf("Int64 returned no error for non-numeric ID")
+	}
+
+	if ids := IntIDs([]int64{1, 2}); len(ids) != 2 || ids[0] != "1" || ids[1] != "2" {
+		t.Fatalf("IntIDs returned invalid IDs: %v", ids)
+	}
+}
+
+// TestStringIDs verifies that non-numeric IDs round-trip through the API unchanged
+func TestStringIDs(t *testing.T) {
+	log.Println("TestStringIDs()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetFixture("getMusicDirectory", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+		"directory": {"id": "al-3f9c2b", "name": "Music", "child": [
+			{"id": "tr-81d0e4", "parent": "al-3f9c2b", "title": "Wonderland", "isDir": false}]}}}`))
+	f.SetMedia("tr-81d0e4", "audio/mpeg", []byte("0123456789"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	content, err := s.GetMusicDirectory(context.Background(), "al-3f9c2b")
+	if err != nil {
+		t.Fatalf("GetMusicDirectory returned error: %s", err.Error())
+	}
+	if len(content.Audio) != 1 || content.Audio[0].ID != "tr-81d0e4" || content.Audio[0].Parent != "al-3f9c2b" {
+		t.Fatalf("GetMusicDirectory returned invalid content: %+v", content)
+	}
+
+	stream, err := s.Stream(context.Background(), content.Audio[0].ID, nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	stream.Close()
+}
@@ -0,0 +1,210 @@
+package gosubsonic
+
+import (
+	"context"
+	"iter"
+)
+
+// iteratorPageSize is the default number of items fetched per page, which is the maximum
+// allowed by Subsonic for paged list methods
+const iteratorPageSize = 500
+
+// Iterator iterates over every item of a paged list, transparently fetching subsequent pages
+// as they are needed.  Iteration stops once the server returns a page shorter than the page
+// size, or an error occurs.
+//
+//	it := s.AlbumList2Iterator(ctx, gosubsonic.AlbumListNewest, nil)
+//	for it.Next() {
+//		album := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	ctx    context.Context
+	fetch  func(ctx context.Context, offset int64, size int64) ([]T, error)
+	offset int64
+	size   int64
+
+	page  []T
+	i     int
+	value T
+	last  bool
+	err   error
+}
+
+// newIterator creates an Iterator which fetches pages of the specified size, beginning at an
+// offset.  A size of 0 uses the default page size.
+func newIterator[T any](ctx context.Context, offset int64, size int64, fetch func(context.Context, int64, int64) ([]T, error)) *Iterator[T] {
+	if size <= 0 {
+		size = iteratorPageSize
+	}
+
+	return &Iterator[T]{
+		ctx:    ctx,
+		fetch:  fetch,
+		offset: offset,
+		size:   size,
+	}
+}
+
+// Next advances to the next item, fetching the next page if needed.  It returns false once
+// all items have been returned, or an error occurs.
+func (it *Iterator[T]) Next() bool {
+	for it.i >= len(it.page) {
+		if it.last || it.err != nil {
+			return false
+		}
+
+		page, err := it.fetch(it.ctx, it.offset, it.size)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.page = page
+		it.i = 0
+		it.offset += int64(len(page))
+		it.last = int64(len(page)) < it.size
+	}
+
+	it.value = it.page[it.i]
+	it.i++
+	return true
+}
+
+// Value returns the current item
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error which stopped iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All returns an iter.Seq2 which yields each remaining item.  If an error occurs, it is
+// yielded once with a zero item, and iteration stops.
+func (it *Iterator[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for it.Next() {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// AlbumListIterator returns an Iterator over every album of the specified list type,
+// organized by file structure.  If set, options.Size is used as the page size, and
+// options.Offset as the starting offset.
+func (s Client) AlbumListIterator(ctx context.Context, listType string, options *AlbumListOptions) *Iterator[Directory] {
+	opts := AlbumListOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	return newIterator(ctx, opts.Offset, opts.Size, func(ctx context.Context, offset int64, size int64) ([]Directory, error) {
+		opts.Offset, opts.Size = offset, size
+		return s.GetAlbumList(ctx, listType, &opts)
+	})
+}
+
+// AlbumList2Iterator returns an Iterator over every album of the specified list type,
+// organized by ID3 tags.  If set, options.Size is used as the page size, and options.Offset
+// as the starting offset.
+func (s Client) AlbumList2Iterator(ctx context.Context, listType string, options *AlbumListOptions) *Iterator[AlbumID3] {
+	opts := AlbumListOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	return newIterator(ctx, opts.Offset, opts.Size, func(ctx context.Context, offset int64, size int64) ([]AlbumID3, error) {
+		opts.Offset, opts.Size = offset, size
+		return s.GetAlbumList2(ctx, listType, &opts)
+	})
+}
+
+// SongsByGenreIterator returns an Iterator over every song in a genre.  If set,
+// options.Count is used as the page size, and options.Offset as the starting offset.
+func (s Client) SongsByGenreIterator(ctx context.Context, genre string, options *SongsByGenreOptions) *Iterator[Audio] {
+	opts := SongsByGenreOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	return newIterator(ctx, opts.Offset, opts.Count, func(ctx context.Context, offset int64, size int64) ([]Audio, error) {
+		opts.Offset, opts.Count = offset, size
+		return s.GetSongsByGenre(ctx, genre, &opts)
+	})
+}
+
+// SearchArtistsIterator returns an Iterator over every artist matching a query, organized by
+// ID3 tags, using Search3.  If set, options.ArtistCount is used as the page size, and
+// options.ArtistOffset as the starting offset.
+func (s Client) SearchArtistsIterator(ctx context.Context, query string, options *SearchOptions) *Iterator[ArtistID3] {
+	opts := searchIteratorOptions(options)
+	opts.AlbumCount, opts.SongCount = -1, -1
+
+	return newIterator(ctx, opts.ArtistOffset, opts.ArtistCount, func(ctx context.Context, offset int64, size int64) ([]ArtistID3, error) {
+		opts.ArtistOffset, opts.ArtistCount = offset, size
+		res, err := s.Search3(ctx, query, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return res.Artists, nil
+	})
+}
+
+// SearchAlbumsIterator returns an Iterator over every album matching a query, organized by
+// ID3 tags, using Search3.  If set, options.AlbumCount is used as the page size, and
+// options.AlbumOffset as the starting offset.
+func (s Client) SearchAlbumsIterator(ctx context.Context, query string, options *SearchOptions) *Iterator[AlbumID3] {
+	opts := searchIteratorOptions(options)
+	opts.ArtistCount, opts.SongCount = -1, -1
+
+	return newIterator(ctx, opts.AlbumOffset, opts.AlbumCount, func(ctx context.Context, offset int64, size int64) ([]AlbumID3, error) {
+		opts.AlbumOffset, opts.AlbumCount = offset, size
+		res, err := s.Search3(ctx, query, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return res.Albums, nil
+	})
+}
+
+// SearchSongsIterator returns an Iterator over every song matching a query, organized by
+// ID3 tags, using Search3.  If set, options.SongCount is used as the page size, and
+// options.SongOffset as the starting offset.
+func (s Client) SearchSongsIterator(ctx context.Context, query string, options *SearchOptions) *Iterator[Audio] {
+	opts := searchIteratorOptions(options)
+	opts.ArtistCount, opts.AlbumCount = -1, -1
+
+	return newIterator(ctx, opts.SongOffset, opts.SongCount, func(ctx context.Context, offset int64, size int64) ([]Audio, error) {
+		opts.SongOffset, opts.SongCount = offset, size
+		res, err := s.Search3(ctx, query, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return res.Songs, nil
+	})
+}
+
+// searchIteratorOptions copies the options for a search iterator, which sets the count and
+// offset of the category it returns for each page
+func searchIteratorOptions(options *SearchOptions) SearchOptions {
+	if options == nil {
+		return SearchOptions{}
+	}
+
+	return *options
+}
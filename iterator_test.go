@@ -0,0 +1,99 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestIterator verifies that an Iterator fetches each page of a list until a short page
+func TestIterator(t *testing.T) {
+	log.Println("TestIterator()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	// Serve five albums in pages of two
+	for _, offset := range []int{0, 2, 4} {
+		params := url.Values{"type": {"newest"}, "size": {"2"}}
+		if offset > 0 {
+			params.Set("offset", fmt.Sprint(offset))
+		}
+
+		var albums []string
+		for i := offset; i < offset+2 && i < 5; i++ {
+			albums = append(albums, fmt.Sprintf(`{"id": "al-%d", "name": "Album %d"}`, i, i))
+		}
+
+		f.SetFixture("getAlbumList2", params, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+			"albumList2": {"album": [`+strings.Join(albums, ",")+`]}}}`))
+	}
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	it := s.AlbumList2Iterator(context.Background(), AlbumListNewest, &AlbumListOptions{Size: 2})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, string(it.Value().ID))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator returned error: %s", err.Error())
+	}
+
+	if strings.Join(ids, ",") != "al-0,al-1,al-2,al-3,al-4" || f.Requests("getAlbumList2") != 3 {
+		t.Fatalf("Iterator returned invalid albums: %v", ids)
+	}
+
+	// Iteration may stop early, and errors stop iteration
+	f.SetError("getAlbumList2", ErrCodeGeneric, "failed")
+
+	var n int
+	for album, err := range s.AlbumList2Iterator(context.Background(), AlbumListNewest, &AlbumListOptions{Size: 2}).All() {
+		if err == nil || album.ID != "" {
+			t.Fatalf("All yielded %+v, %v after error", album, err)
+		}
+		n++
+	}
+
+	if n != 1 {
+		t.Fatalf("All yielded %d errors", n)
+	}
+}
+
+// TestSearchSongsIterator verifies that search iterators only request their own category, and
+// apply other options to each page
+func TestSearchSongsIterator(t *testing.T) {
+	log.Println("TestSearchSongsIterator()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	params := url.Values{"query": {"311"}, "artistCount": {"0"}, "albumCount": {"0"}, "songCount": {"500"}, "musicFolderId": {"2"}}
+	f.SetFixture("search3", params, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+		"searchResult3": {"song": [{"id": 1, "title": "Amber"}, {"id": 2, "title": "Down"}]}}}`))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	var titles []string
+	for song, err := range s.SearchSongsIterator(context.Background(), "311", &SearchOptions{ArtistCount: 5, MusicFolderID: 2}).All() {
+		if err != nil {
+			t.Fatalf("SearchSongsIterator returned error: %s", err.Error())
+		}
+
+		titles = append(titles, song.Title)
+	}
+
+	if strings.Join(titles, ",") != "Amber,Down" {
+		t.Fatalf("SearchSongsIterator returned invalid songs: %v", titles)
+	}
+}
@@ -0,0 +1,126 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// maxParseWarnings is the maximum number of malformed items skipped in a single response,
+// since the response is decoded again after each is skipped
+const maxParseWarnings = 100
+
+// skippedItem replaces a malformed item in a response during lenient parsing, and is skipped
+// when decoding lists
+var skippedItem = []byte(`"gosubsonic:skipped"`)
+
+// ParseWarning describes a malformed item in a response, which was skipped by lenient
+// parsing instead of failing the call
+type ParseWarning struct {
+	// Method is the Subsonic API method, such as "getMusicDirectory"
+	Method string
+
+	// ID is the ID of the item, if it could be determined
+	ID ID
+
+	// Item is the item's raw JSON.  Items in XML responses are converted into JSON.
+	Item string
+
+	// Err is the error which occurred while decoding the item
+	Err error
+}
+
+// String returns a description of a ParseWarning
+func (w ParseWarning) String() string {
+	return fmt.Sprintf("gosubsonic: skipped malformed item %q in %s response: %s", w.ID, w.Method, w.Err.Error())
+}
+
+// parseWarningsKey is the context key for a function set by WithParseWarnings
+type parseWarningsKey struct{}
+
+// WithParseWarnings returns a context which reports the malformed items skipped by lenient
+// parsing in calls made using it to fn, which is called once for each item before the call
+// returns.  Lenient parsing is enabled by WithLenientParsing.
+func WithParseWarnings(ctx context.Context, fn func(ParseWarning)) context.Context {
+	return context.WithValue(ctx, parseWarningsKey{}, fn)
+}
+
+// reportParseWarnings reports the warnings from parsing a response of an API method to the
+// function set by WithParseWarnings, and records them if the call is being logged
+func reportParseWarnings(ctx context.Context, method string, warnings []ParseWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	fn, _ := ctx.Value(parseWarningsKey{}).(func(ParseWarning))
+	l, _ := ctx.Value(callLogKey{}).(*callLog)
+	for _, w := range warnings {
+		w.Method = method
+		if fn != nil {
+			fn(w)
+		}
+		if l != nil {
+			l.warnings = append(l.warnings, w.String())
+		}
+	}
+}
+
+// processJSONLenient decodes JSON into an apiContainer, skipping malformed items in lists
+// and returning a warning for each.  Each malformed item is replaced in a copy of the body,
+// which is then decoded again.
+func processJSONLenient(body []byte) (*apiContainer, []ParseWarning, error) {
+	// Items may be passed to decoders compacted, so they are only found in a compact body
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, body); err == nil {
+		body = buf.Bytes()
+	}
+
+	var warnings []ParseWarning
+	for {
+		res, err := processJSON(bytes.NewReader(body))
+		if err == nil {
+			return res, warnings, nil
+		}
+
+		ie := innermostItemError(err)
+		if ie == nil || len(warnings) >= maxParseWarnings {
+			return nil, warnings, err
+		}
+
+		i := bytes.Index(body, ie.raw)
+		if i < 0 {
+			return nil, warnings, err
+		}
+
+		out := make([]byte, 0, len(body)-len(ie.raw)+len(skippedItem))
+		out = append(out, body[:i]...)
+		out = append(out, skippedItem...)
+		body = append(out, body[i+len(ie.raw):]...)
+
+		w := ParseWarning{Item: string(ie.raw), Err: ie.err}
+
+		// The ID is only a hint, so it may be missing or malformed
+		var item struct{ ID ID }
+		if json.Unmarshal(ie.raw, &item) == nil {
+			w.ID = item.ID
+		}
+
+		warnings = append(warnings, w)
+	}
+}
+
+// innermostItemError returns the innermost *itemError in an error's chain, which is the
+// malformed item itself, rather than an item in an outer list which contains it
+func innermostItemError(err error) *itemError {
+	var found *itemError
+	for {
+		var ie *itemError
+		if !errors.As(err, &ie) {
+			return found
+		}
+
+		found, err = ie, ie.err
+	}
+}
@@ -0,0 +1,108 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLenientParsing verifies that lenient parsing skips malformed items in lists, including
+// nested lists, and reports each as a warning, while strict parsing fails the call
+func TestLenientParsing(t *testing.T) {
+	log.Println("TestLenientParsing()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetFixture("getMusicDirectory", url.Values{"id": {"1"}}, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"directory": {"id": "1", "name": "Album", "child": [
+			{"id": "10", "title": "One", "isDir": false},
+			{"id": "11", "title": "Two", "isDir": false, "contentType": 5},
+			{"id": "12", "title": "Three", "isDir": false}
+		]}}}`))
+	f.SetFixture("getIndexes", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"indexes": {"lastModified": 1, "index": [
+			{"name": "A", "artist": [{"id": "1", "name": "ABBA"}, {"id": {"bad": true}, "name": "Broken"}]},
+			{"name": "B", "artist": {"id": "2", "name": "Beatles"}}
+		]}}}`))
+
+	strict, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	if _, err := strict.GetMusicDirectory(context.Background(), "1"); err == nil {
+		t.Fatalf("GetMusicDirectory did not fail with strict parsing")
+	}
+
+	var buf bytes.Buffer
+	s := *strict
+	s.Lenient = true
+	s.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+	var warnings []ParseWarning
+	ctx := WithParseWarnings(context.Background(), func(w ParseWarning) {
+		warnings = append(warnings, w)
+	})
+
+	content, err := s.GetMusicDirectory(ctx, "1")
+	if err != nil {
+		t.Fatalf("GetMusicDirectory returned error: %s", err.Error())
+	}
+
+	if len(content.Audio) != 2 || content.Audio[0].ID != "10" || content.Audio[1].ID != "12" {
+		t.Fatalf("GetMusicDirectory returned invalid items: %+v", content.Audio)
+	}
+	if len(warnings) != 1 || warnings[0].Method != "getMusicDirectory" || warnings[0].ID != "11" || !strings.Contains(warnings[0].Item, `"Two"`) {
+		t.Fatalf("GetMusicDirectory reported invalid warnings: %+v", warnings)
+	}
+	if !strings.Contains(buf.String(), "level=WARN") || !strings.Contains(buf.String(), `skipped malformed item \"11\"`) {
+		t.Fatalf("GetMusicDirectory did not log warnings: %s", buf.String())
+	}
+
+	// Only the malformed artist is skipped, and not the index which contains it
+	warnings = nil
+	indexes, err := s.GetIndexes(ctx, -1, time.Time{})
+	if err != nil {
+		t.Fatalf("GetIndexes returned error: %s", err.Error())
+	}
+
+	if len(indexes.Index) != 2 || len(indexes.Index[0].Artist) != 1 || indexes.Index[0].Artist[0].Name != "ABBA" || len(indexes.Index[1].Artist) != 1 {
+		t.Fatalf("GetIndexes returned invalid indexes: %+v", indexes.Index)
+	}
+	if len(warnings) != 1 || warnings[0].ID != "" || warnings[0].Err == nil {
+		t.Fatalf("GetIndexes reported invalid warnings: %+v", warnings)
+	}
+}
+
+// TestProcessJSONLenient verifies that processJSONLenient() stops skipping items after the
+// maximum number of warnings, and never modifies the body
+func TestProcessJSONLenient(t *testing.T) {
+	log.Println("TestProcessJSONLenient()")
+
+	var b strings.Builder
+	b.WriteString(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "directory": {"child": [`)
+	for i := 0; i <= maxParseWarnings; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(`{"id": "1", "contentType": ` + strings.Repeat("1", i+1) + `}`)
+	}
+	b.WriteString(`]}}}`)
+
+	body := []byte(b.String())
+	orig := string(body)
+
+	_, warnings, err := processJSONLenient(body)
+	if err == nil || len(warnings) != maxParseWarnings {
+		t.Fatalf("processJSONLenient returned %d warnings, error %v", len(warnings), err)
+	}
+	if string(body) != orig {
+		t.Fatalf("processJSONLenient modified body")
+	}
+}
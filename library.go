@@ -0,0 +1,332 @@
+package gosubsonic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LibraryOptions represents additional options for the BuildLibrary() method
+type LibraryOptions struct {
+	// MusicFolderID limits the library to a music folder, if set (>= 1)
+	MusicFolderID int64
+
+	// Concurrency is the maximum number of concurrent directory requests, default 4
+	Concurrency int
+
+	// Progress is called after each directory is retrieved.  Calls are never concurrent.
+	Progress func(LibraryProgress)
+
+	// Deep retrieves every directory again during SyncLibrary, which detects changes within
+	// existing albums, such as retagged songs, at the cost of a full crawl
+	Deep bool
+}
+
+// LibraryProgress reports the progress of a library crawl
+type LibraryProgress struct {
+	// Artists is the total number of artists in the library
+	Artists int
+
+	// Directories is the number of directories retrieved so far
+	Directories int
+
+	// Songs is the number of songs found so far
+	Songs int
+}
+
+// Library represents a catalog of the artists, albums, and songs in a Subsonic library,
+// organized by file structure
+type Library struct {
+	Artists []LibraryArtist
+
+	// LastModified is the time the library was last modified on the server, as reported
+	// when the library was crawled
+	LastModified time.Time
+}
+
+// LibraryArtist represents an artist in a Library, and its albums
+type LibraryArtist struct {
+	IndexArtist
+
+	Albums []LibraryAlbum
+}
+
+// LibraryAlbum represents an album in a Library, which is any directory containing songs.
+// Songs stored directly in an artist's directory are grouped into an album with the
+// artist's ID and name.
+type LibraryAlbum struct {
+	Directory
+
+	Songs []Audio
+}
+
+// Songs returns all songs in the library, in catalog order
+func (l *Library) Songs() []Audio {
+	var songs []Audio
+	for _, ar := range l.Artists {
+		for _, al := range ar.Albums {
+			songs = append(songs, al.Songs...)
+		}
+	}
+
+	return songs
+}
+
+// BuildLibrary crawls the entire library, starting from the indexes and recursively retrieving
+// each music directory, with an optional LibraryOptions struct.  Directories are retrieved
+// concurrently, but the catalog is ordered as it appears on the server.  If any directory
+// cannot be retrieved, the crawl stops and the error is returned.
+func (s Client) BuildLibrary(ctx context.Context, options *LibraryOptions) (*Library, error) {
+	o := LibraryOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = bulkConcurrency
+	}
+
+	indexes, err := s.libraryIndexes(ctx, o.MusicFolderID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newLibraryCrawler(ctx, s, o, nil).crawl(indexes.Index, indexes.LastModified)
+}
+
+// libraryIndexes retrieves the indexes of a music folder, if set (>= 1), optionally only if
+// they were modified since the specified time
+func (s Client) libraryIndexes(ctx context.Context, folderID int64, modifiedSince time.Time) (*Indexes, error) {
+	if folderID <= 0 {
+		folderID = -1
+	}
+
+	return s.GetIndexes(ctx, folderID, modifiedSince)
+}
+
+// libraryCrawler retrieves music directories with bounded concurrency, and records the first
+// error which occurs, canceling all further requests.  Albums from a previous crawl may be
+// reused instead of being retrieved again.
+type libraryCrawler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	client Client
+	sem    chan struct{}
+	report func(LibraryProgress)
+
+	known    map[ID]LibraryAlbum
+	children map[ID][]ID
+
+	mu       sync.Mutex
+	err      error
+	progress LibraryProgress
+	seen     map[ID]bool
+}
+
+// newLibraryCrawler creates a libraryCrawler using the specified options, which reuses
+// unchanged albums from a previous library, if set
+func newLibraryCrawler(ctx context.Context, s Client, o LibraryOptions, previous *Library) *libraryCrawler {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &libraryCrawler{
+		ctx:      ctx,
+		cancel:   cancel,
+		client:   s,
+		sem:      make(chan struct{}, o.Concurrency),
+		report:   o.Progress,
+		known:    make(map[ID]LibraryAlbum),
+		children: make(map[ID][]ID),
+		seen:     make(map[ID]bool),
+	}
+
+	if previous == nil {
+		return c
+	}
+
+	for _, ar := range previous.Artists {
+		for _, al := range ar.Albums {
+			c.known[al.ID] = al
+			c.children[al.Parent] = append(c.children[al.Parent], al.ID)
+		}
+	}
+
+	return c
+}
+
+// crawl crawls the directory tree of each artist in the specified indexes
+func (c *libraryCrawler) crawl(indexes []Index, lastModified time.Time) (*Library, error) {
+	defer c.cancel()
+
+	var artists []IndexArtist
+	for _, idx := range indexes {
+		artists = append(artists, idx.Artist...)
+	}
+	c.progress.Artists = len(artists)
+
+	// Artists' directories are marked before crawling, so subdirectories which lead back to
+	// any artist are not crawled again
+	for _, a := range artists {
+		c.seen[a.ID] = true
+	}
+
+	lib := &Library{
+		Artists:      make([]LibraryArtist, len(artists)),
+		LastModified: lastModified,
+	}
+
+	var wg sync.WaitGroup
+	for i, a := range artists {
+		wg.Add(1)
+		go func(i int, a IndexArtist) {
+			defer wg.Done()
+			lib.Artists[i] = c.artist(a)
+		}(i, a)
+	}
+	wg.Wait()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return lib, nil
+}
+
+// artist crawls the directory tree of a single artist
+func (c *libraryCrawler) artist(a IndexArtist) LibraryArtist {
+	// The artist's directory is treated as an album for any songs stored directly within it,
+	// and is always retrieved, as it is the only way to discover new albums
+	root := Directory{
+		ID:     a.ID,
+		Artist: a.Name,
+		Title:  a.Name,
+	}
+
+	return LibraryArtist{
+		IndexArtist: a,
+		Albums:      c.retrieve(root),
+	}
+}
+
+// directory crawls a directory and all of its subdirectories, returning each directory which
+// contains songs as an album, in the order they appear on the server.  Unchanged albums from
+// a previous crawl are reused.
+func (c *libraryCrawler) directory(d Directory) []LibraryAlbum {
+	if known, ok := c.known[d.ID]; ok && sameDirectory(known.Directory, d) {
+		return c.reuse(d.ID)
+	}
+
+	return c.retrieve(d)
+}
+
+// reuse returns a known album, and the known albums within it
+func (c *libraryCrawler) reuse(id ID) []LibraryAlbum {
+	albums := []LibraryAlbum{c.known[id]}
+	for _, ch := range c.children[id] {
+		if c.visit(ch) {
+			albums = append(albums, c.reuse(ch)...)
+		}
+	}
+
+	return albums
+}
+
+// sameDirectory determines if a directory's entry is unchanged since a previous crawl
+func sameDirectory(a Directory, b Directory) bool {
+	return a.ID == b.ID && a.Parent == b.Parent && a.Title == b.Title &&
+		a.CreatedRaw == b.CreatedRaw && a.CoverArt == b.CoverArt
+}
+
+// retrieve retrieves a directory and crawls all of its subdirectories
+func (c *libraryCrawler) retrieve(d Directory) []LibraryAlbum {
+	content, err := c.fetch(d.ID)
+	if err != nil {
+		return nil
+	}
+
+	// Retrieve subdirectories concurrently, and collect their albums in order
+	children := make([][]LibraryAlbum, len(content.Directories))
+	var wg sync.WaitGroup
+	for i, sub := range content.Directories {
+		// Directories are only crawled once, in case the server reports a cycle
+		if !c.visit(sub.ID) {
+			continue
+		}
+
+		// Known albums are associated with their parent, so some servers' omission of the
+		// parent ID must be corrected
+		if sub.Parent == "" {
+			sub.Parent = d.ID
+		}
+
+		wg.Add(1)
+		go func(i int, sub Directory) {
+			defer wg.Done()
+			children[i] = c.directory(sub)
+		}(i, sub)
+	}
+	wg.Wait()
+
+	var albums []LibraryAlbum
+	if len(content.Audio) > 0 {
+		albums = append(albums, LibraryAlbum{
+			Directory: d,
+			Songs:     content.Audio,
+		})
+	}
+	for _, ch := range children {
+		albums = append(albums, ch...)
+	}
+
+	return albums
+}
+
+// fetch retrieves a single music directory, holding a slot only for the duration of the
+// request, and reports progress
+func (c *libraryCrawler) fetch(id ID) (*Content, error) {
+	select {
+	case c.sem <- struct{}{}:
+	case <-c.ctx.Done():
+		c.fail(c.ctx.Err())
+		return nil, c.ctx.Err()
+	}
+
+	content, err := c.client.GetMusicDirectory(c.ctx, id)
+	<-c.sem
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.progress.Directories++
+	c.progress.Songs += len(content.Audio)
+	if c.report != nil {
+		c.report(c.progress)
+	}
+
+	return content, nil
+}
+
+// visit marks a directory as crawled, and reports whether it was not already crawled
+func (c *libraryCrawler) visit(id ID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seen[id] {
+		return false
+	}
+
+	c.seen[id] = true
+	return true
+}
+
+// fail records the first error of a crawl, and cancels any further requests
+func (c *libraryCrawler) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		c.cancel()
+	}
+}
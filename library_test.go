@@ -0,0 +1,274 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// libraryTestServer represents a test server which serves a small library, which may be
+// modified between requests
+type libraryTestServer struct {
+	mu           sync.Mutex
+	lastModified int64
+	artists      string
+	directories  map[string]string
+}
+
+// ServeHTTP serves the indexes and music directories of the library
+func (l *libraryTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/rest/getIndexes.view":
+		// Indexes are omitted when they were not modified
+		since, err := strconv.ParseInt(r.URL.Query().Get("ifModifiedSince"), 10, 64)
+		if err == nil && since >= l.lastModified {
+			fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.9.0",
+				"indexes": {"lastModified": %d}}}`, l.lastModified)
+			return
+		}
+
+		fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.9.0",
+			"indexes": {"lastModified": %d, "index": {"name": "A", "artist": [%s]}}}}`,
+			l.lastModified, l.artists)
+	case "/rest/getMusicDirectory.view":
+		children, ok := l.directories[r.URL.Query().Get("id")]
+		if !ok {
+			w.Write([]byte(`{"subsonic-response": {"status": "failed", "version": "1.9.0",
+				"error": {"code": 70, "message": "Directory not found"}}}`))
+			return
+		}
+
+		fmt.Fprintf(w, `{"subsonic-response": {"status": "ok", "version": "1.9.0",
+			"directory": {"child": [%s]}}}`, children)
+	default:
+		w.Write(mockTable[0].data)
+	}
+}
+
+// newLibraryTestServer creates a libraryTestServer with two artists: one with an album
+// containing two discs, and one with songs stored directly in the artist's directory
+func newLibraryTestServer() *libraryTestServer {
+	return &libraryTestServer{
+		lastModified: 1000,
+		artists:      `{"id": 1, "name": "Adventure"}, {"id": 2, "name": "Boston"}`,
+		directories: map[string]string{
+			"1":  `{"id": 10, "isDir": true, "title": "Adventure", "artist": "Adventure"}`,
+			"10": `{"id": 100, "isDir": true, "title": "CD1"}, {"id": 101, "isDir": true, "title": "CD2"}, {"id": 11, "title": "Intro", "duration": 30}`,
+			"100": `{"id": 1000, "title": "Wonderland", "duration": 214},
+				{"id": 1001, "title": "Another Day", "duration": 187}`,
+			"101": `{"id": 1010, "title": "Light Sleeper", "duration": 200}`,
+			"2":   `{"id": 20, "title": "More Than a Feeling", "duration": 285}`,
+		},
+	}
+}
+
+// libraryTestClient generates a client for a libraryTestServer
+func libraryTestClient(t *testing.T, l *libraryTestServer) (*Client, func()) {
+	srv := httptest.NewServer(l)
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		srv.Close()
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	return s, srv.Close
+}
+
+// TestBuildLibrary verifies that client.BuildLibrary() crawls the entire library in order
+func TestBuildLibrary(t *testing.T) {
+	log.Println("TestBuildLibrary()")
+
+	s, done := libraryTestClient(t, newLibraryTestServer())
+	defer done()
+
+	var last LibraryProgress
+	calls := 0
+	lib, err := s.BuildLibrary(context.Background(), &LibraryOptions{
+		Concurrency: 2,
+		Progress: func(p LibraryProgress) {
+			calls++
+			last = p
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildLibrary returned error: %s", err.Error())
+	}
+
+	if !lib.LastModified.Equal(time.UnixMilli(1000)) || len(lib.Artists) != 2 {
+		t.Fatalf("BuildLibrary returned invalid library: %+v", lib)
+	}
+
+	var titles []string
+	for _, ar := range lib.Artists {
+		for _, al := range ar.Albums {
+			titles = append(titles, ar.Name+"/"+al.Title)
+		}
+	}
+
+	expected := "Adventure/Adventure,Adventure/CD1,Adventure/CD2,Boston/Boston"
+	if strings.Join(titles, ",") != expected {
+		t.Fatalf("BuildLibrary returned invalid albums: %v", titles)
+	}
+
+	if len(lib.Songs()) != 5 {
+		t.Fatalf("BuildLibrary returned invalid number of songs: %d", len(lib.Songs()))
+	}
+
+	if calls != 5 || last != (LibraryProgress{Artists: 2, Directories: 5, Songs: 5}) {
+		t.Fatalf("BuildLibrary reported invalid progress: %d calls, %+v", calls, last)
+	}
+}
+
+// TestBuildLibraryError verifies that client.BuildLibrary() returns an error when any
+// directory cannot be retrieved
+func TestBuildLibraryError(t *testing.T) {
+	log.Println("TestBuildLibraryError()")
+
+	l := newLibraryTestServer()
+	delete(l.directories, "101")
+
+	s, done := libraryTestClient(t, l)
+	defer done()
+
+	if _, err := s.BuildLibrary(context.Background(), nil); !IsNotFound(err) {
+		t.Fatalf("BuildLibrary returned unexpected error: %v", err)
+	}
+}
+
+// TestBuildLibraryCycle verifies that client.BuildLibrary() crawls each directory once when
+// the server reports a cycle
+func TestBuildLibraryCycle(t *testing.T) {
+	log.Println("TestBuildLibraryCycle()")
+
+	// Each disc links back to the album, and the second artist links back to the first
+	l := newLibraryTestServer()
+	l.directories["100"] += `, {"id": 10, "isDir": true, "title": "Adventure"}`
+	l.directories["101"] += `, {"id": 100, "isDir": true, "title": "CD1"}`
+	l.directories["2"] += `, {"id": 1, "isDir": true, "title": "Adventure"}`
+
+	s, done := libraryTestClient(t, l)
+	defer done()
+
+	var last LibraryProgress
+	lib, err := s.BuildLibrary(context.Background(), &LibraryOptions{
+		Progress: func(p LibraryProgress) {
+			last = p
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildLibrary returned error: %s", err.Error())
+	}
+
+	if len(lib.Songs()) != 5 || last != (LibraryProgress{Artists: 2, Directories: 5, Songs: 5}) {
+		t.Fatalf("BuildLibrary returned %d songs, and reported progress %+v", len(lib.Songs()), last)
+	}
+}
+
+// TestSyncLibrary verifies that client.SyncLibrary() reports changes to the library, and only
+// retrieves directories which may have changed
+func TestSyncLibrary(t *testing.T) {
+	log.Println("TestSyncLibrary()")
+
+	l := newLibraryTestServer()
+	s, done := libraryTestClient(t, l)
+	defer done()
+
+	ctx := context.Background()
+	lib, err := s.BuildLibrary(ctx, nil)
+	if err != nil {
+		t.Fatalf("BuildLibrary returned error: %s", err.Error())
+	}
+
+	// syncLibrary synchronizes the library, returning each event and the number of directories retrieved
+	syncLibrary := func(options *LibraryOptions) ([]string, int) {
+		o := LibraryOptions{}
+		if options != nil {
+			o = *options
+		}
+
+		directories := 0
+		o.Progress = func(p LibraryProgress) {
+			directories = p.Directories
+		}
+
+		events := make(chan LibraryEvent)
+		var out []string
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			for ev := range events {
+				name := ev.Artist.Name
+				if ev.Album != nil {
+					name += "/" + ev.Album.Title
+				}
+				if ev.Song != nil {
+					name += "/" + ev.Song.Title
+				}
+
+				out = append(out, ev.Type.String()+" "+name)
+			}
+		}()
+
+		lib, err = s.SyncLibrary(ctx, lib, events, &o)
+		<-finished
+		if err != nil {
+			t.Fatalf("SyncLibrary returned error: %s", err.Error())
+		}
+
+		return out, directories
+	}
+
+	// Unmodified libraries are not crawled
+	if events, directories := syncLibrary(nil); len(events) != 0 || directories != 0 {
+		t.Fatalf("SyncLibrary reported changes for unmodified library: %v, %d directories", events, directories)
+	}
+
+	// Add an album and remove a song, and rename a song within an unchanged album
+	l.mu.Lock()
+	l.lastModified = 2000
+	l.directories["2"] = `{"id": 21, "isDir": true, "title": "Third Stage"}`
+	l.directories["21"] = `{"id": 210, "title": "Amanda", "duration": 256}`
+	l.directories["100"] = `{"id": 1000, "title": "Wonderland (Remix)", "duration": 214}`
+	l.mu.Unlock()
+
+	// Unchanged album directories are reused
+	events, directories := syncLibrary(nil)
+	expected := []string{
+		"removed Boston/Boston",
+		"removed Boston/Boston/More Than a Feeling",
+		"added Boston/Third Stage",
+		"added Boston/Third Stage/Amanda",
+	}
+	if strings.Join(events, ",") != strings.Join(expected, ",") || directories != 3 {
+		t.Fatalf("SyncLibrary reported invalid changes: %v, %d directories", events, directories)
+	}
+
+	// Deep synchronization finds changes within existing albums
+	l.mu.Lock()
+	l.lastModified = 3000
+	l.mu.Unlock()
+
+	events, directories = syncLibrary(&LibraryOptions{Deep: true})
+	expected = []string{
+		"removed Adventure/CD1/Another Day",
+		"changed Adventure/CD1/Wonderland (Remix)",
+	}
+	if strings.Join(events, ",") != strings.Join(expected, ",") || directories != 6 {
+		t.Fatalf("SyncLibrary reported invalid deep changes: %v, %d directories", events, directories)
+	}
+
+	if len(lib.Songs()) != 4 {
+		t.Fatalf("SyncLibrary returned invalid number of songs: %d", len(lib.Songs()))
+	}
+}
@@ -0,0 +1,415 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNotDir and errIsDir are returned by LibraryFS for operations on the wrong kind of file
+var (
+	errNotDir = errors.New("gosubsonic: not a directory")
+	errIsDir  = errors.New("gosubsonic: is a directory")
+)
+
+// LibraryFS exposes a Subsonic library as a read-only filesystem organized by file structure,
+// so it may be walked and read using io/fs, such as by fs.WalkDir, http.FS, or archivers.
+// The root directory contains the shortcuts, artists, and files in the index, and every other
+// directory contains the items returned by GetMusicDirectory.  Names are made safe for common
+// file systems, and items whose names would collide have their IDs appended.
+//
+// Directory listings are cached, and files are only retrieved from the server when they are
+// read, using range requests after each seek.  The Sys method of each fs.FileInfo returns
+// the item's *Directory, *Audio, *Video, or *IndexArtist.  A LibraryFS is safe for concurrent
+// use.
+type LibraryFS struct {
+	// TTL is the duration for which directory listings are cached, or forever if 0
+	TTL time.Duration
+
+	client   Client
+	ctx      context.Context
+	folderID int64
+
+	mu   sync.Mutex
+	dirs map[ID]*fsDir
+}
+
+// fsDir is a cached directory listing in a LibraryFS
+type fsDir struct {
+	entries []*fsEntry
+	byName  map[string]*fsEntry
+	modTime time.Time
+	fetched time.Time
+}
+
+// fsEntry is a file or directory in a LibraryFS, and implements fs.FileInfo
+type fsEntry struct {
+	name    string
+	id      ID
+	dir     bool
+	size    int64
+	modTime time.Time
+	item    interface{}
+}
+
+// NewLibraryFS creates a LibraryFS.  If folderID is set (>= 0), only the artists in that
+// music folder are included.  fs.FS methods do not accept a context, so ctx is used for all
+// requests made by the filesystem and its files.
+func (s Client) NewLibraryFS(ctx context.Context, folderID int64) *LibraryFS {
+	return &LibraryFS{
+		client:   s,
+		ctx:      ctx,
+		folderID: folderID,
+		dirs:     make(map[ID]*fsDir),
+	}
+}
+
+// Refresh discards all cached directory listings
+func (f *LibraryFS) Refresh() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.dirs = make(map[ID]*fsDir)
+}
+
+// Open implements fs.FS.  Files are opened without contacting the server, and are retrieved
+// once read.
+func (f *LibraryFS) Open(name string) (fs.File, error) {
+	e, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.dir {
+		return &fsDirFile{fsys: f, entry: e, path: name}, nil
+	}
+
+	// Sizes of 0 are treated as unknown, since servers may omit them
+	size := e.size
+	if size == 0 {
+		size = -1
+	}
+
+	return &fsFile{
+		RangeReader: &RangeReader{
+			ctx:    f.ctx,
+			client: f.client,
+			url:    f.client.makeURL("download", idParams(e.id)),
+			size:   size,
+		},
+		entry: e,
+	}, nil
+}
+
+// ReadDir implements fs.ReadDirFS, returning the entries of a directory sorted by name
+func (f *LibraryFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !e.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+
+	d, err := f.list(e.id)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fsError(err)}
+	}
+
+	return dirEntries(d.entries), nil
+}
+
+// Stat implements fs.StatFS
+func (f *LibraryFS) Stat(name string) (fs.FileInfo, error) {
+	return f.lookup("stat", name)
+}
+
+// lookup finds the entry for a path, listing each directory along it
+func (f *LibraryFS) lookup(op string, name string) (*fsEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	// The root directory is identified by an empty ID
+	d, err := f.list("")
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fsError(err)}
+	}
+
+	e := &fsEntry{name: ".", dir: true, modTime: d.modTime}
+	if name == "." {
+		return e, nil
+	}
+
+	for i, elem := range strings.Split(name, "/") {
+		if i > 0 {
+			if !e.dir {
+				return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+			}
+
+			if d, err = f.list(e.id); err != nil {
+				return nil, &fs.PathError{Op: op, Path: name, Err: fsError(err)}
+			}
+		}
+
+		var ok bool
+		if e, ok = d.byName[elem]; !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+	}
+
+	return e, nil
+}
+
+// list returns the listing of a directory, retrieving it from the server unless it is cached
+func (f *LibraryFS) list(id ID) (*fsDir, error) {
+	f.mu.Lock()
+	d, ok := f.dirs[id]
+	f.mu.Unlock()
+
+	if ok && (f.TTL == 0 || time.Since(d.fetched) < f.TTL) {
+		return d, nil
+	}
+
+	// Concurrent lookups may retrieve the same directory, which is harmless
+	var err error
+	if id == "" {
+		d, err = f.listIndex()
+	} else {
+		d, err = f.listDirectory(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.fetched = time.Now()
+
+	f.mu.Lock()
+	f.dirs[id] = d
+	f.mu.Unlock()
+
+	return d, nil
+}
+
+// listIndex lists the root directory from the index
+func (f *LibraryFS) listIndex() (*fsDir, error) {
+	indexes, err := f.client.GetIndexes(f.ctx, f.folderID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*fsEntry
+	for i := range indexes.Shortcuts {
+		a := &indexes.Shortcuts[i]
+		entries = append(entries, &fsEntry{name: a.Name, id: a.ID, dir: true, item: a})
+	}
+	for i := range indexes.Index {
+		for j := range indexes.Index[i].Artist {
+			a := &indexes.Index[i].Artist[j]
+			entries = append(entries, &fsEntry{name: a.Name, id: a.ID, dir: true, item: a})
+		}
+	}
+	for _, c := range indexes.Children.Children {
+		entries = append(entries, childEntry(c))
+	}
+
+	return newFSDir(entries, indexes.LastModified), nil
+}
+
+// listDirectory lists a music directory
+func (f *LibraryFS) listDirectory(id ID) (*fsDir, error) {
+	content, err := f.client.GetMusicDirectory(f.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*fsEntry, 0, len(content.Children))
+	for _, c := range content.Children {
+		entries = append(entries, childEntry(c))
+	}
+
+	return newFSDir(entries, time.Time{}), nil
+}
+
+// childEntry creates the entry for an item in a directory.  Files are named after the file on
+// the server if its path is known, or otherwise after their title and suffix.
+func childEntry(c Child) *fsEntry {
+	fileName := func(p string, title string, suffix string) string {
+		if p != "" {
+			return path.Base(p)
+		}
+		if suffix != "" {
+			return title + "." + suffix
+		}
+
+		return title
+	}
+
+	switch c := c.(type) {
+	case *Audio:
+		return &fsEntry{name: fileName(c.Path, c.Title, c.Suffix), id: c.ID, size: c.Size, modTime: c.Created, item: c}
+	case *Video:
+		return &fsEntry{name: fileName(c.Path, c.Title, c.Suffix), id: c.ID, size: c.Size, modTime: c.Created, item: c}
+	case *Directory:
+		return &fsEntry{name: c.Title, id: c.ID, dir: true, modTime: c.Created, item: c}
+	}
+
+	return &fsEntry{name: c.ChildTitle(), id: c.ChildID(), item: c}
+}
+
+// newFSDir creates a directory listing, making the names of its entries valid and unique
+func newFSDir(entries []*fsEntry, modTime time.Time) *fsDir {
+	counts := make(map[string]int, len(entries))
+	for _, e := range entries {
+		e.name = downloadNameReplacer.Replace(e.name)
+		if e.name == "" || e.name == "." || e.name == ".." {
+			e.name = string(e.id)
+		}
+
+		counts[e.name]++
+	}
+
+	// Every item sharing a name is renamed, so names do not depend on the server's order
+	for _, e := range entries {
+		if counts[e.name] > 1 {
+			ext := ""
+			if !e.dir {
+				ext = path.Ext(e.name)
+			}
+
+			e.name = strings.TrimSuffix(e.name, ext) + " (" + downloadNameReplacer.Replace(string(e.id)) + ")" + ext
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	d := &fsDir{
+		entries: entries,
+		byName:  make(map[string]*fsEntry, len(entries)),
+		modTime: modTime,
+	}
+	for _, e := range entries {
+		d.byName[e.name] = e
+	}
+
+	return d
+}
+
+// dirEntries converts entries into fs.DirEntry values
+func dirEntries(entries []*fsEntry) []fs.DirEntry {
+	out := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, fs.FileInfoToDirEntry(e))
+	}
+
+	return out
+}
+
+// fsError converts errors for items which do not exist into fs.ErrNotExist
+func fsError(err error) error {
+	if IsNotFound(err) {
+		return fs.ErrNotExist
+	}
+
+	return err
+}
+
+// Name implements fs.FileInfo
+func (e *fsEntry) Name() string { return e.name }
+
+// Size implements fs.FileInfo
+func (e *fsEntry) Size() int64 { return e.size }
+
+// Mode implements fs.FileInfo
+func (e *fsEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0555
+	}
+
+	return 0444
+}
+
+// ModTime implements fs.FileInfo
+func (e *fsEntry) ModTime() time.Time { return e.modTime }
+
+// IsDir implements fs.FileInfo
+func (e *fsEntry) IsDir() bool { return e.dir }
+
+// Sys implements fs.FileInfo, returning the item from the server
+func (e *fsEntry) Sys() interface{} { return e.item }
+
+// fsFile is a file opened from a LibraryFS, which is read using a RangeReader
+type fsFile struct {
+	*RangeReader
+	entry *fsEntry
+}
+
+// Stat implements fs.File
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return f.entry, nil
+}
+
+// fsDirFile is a directory opened from a LibraryFS, and implements fs.ReadDirFile
+type fsDirFile struct {
+	fsys  *LibraryFS
+	entry *fsEntry
+	path  string
+
+	entries []*fsEntry
+	read    bool
+}
+
+// Stat implements fs.File
+func (d *fsDirFile) Stat() (fs.FileInfo, error) {
+	return d.entry, nil
+}
+
+// Read implements fs.File, and always fails for directories
+func (d *fsDirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errIsDir}
+}
+
+// Close implements fs.File
+func (d *fsDirFile) Close() error {
+	return nil
+}
+
+// ReadDir implements fs.ReadDirFile.  The directory is listed on the first call, and each
+// call returns the next n entries, or all remaining entries if n <= 0.
+func (d *fsDirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		listing, err := d.fsys.list(d.entry.id)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.path, Err: fsError(err)}
+		}
+
+		d.entries = listing.entries
+		d.read = true
+	}
+
+	if n <= 0 {
+		out := dirEntries(d.entries)
+		d.entries = nil
+		return out, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+
+	out := dirEntries(d.entries[:n])
+	d.entries = d.entries[n:]
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"net/url"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// TestLibraryFS verifies that LibraryFS exposes the library as a filesystem which satisfies
+// io/fs, with unique names, cached listings, and lazily retrieved files
+func TestLibraryFS(t *testing.T) {
+	log.Println("TestLibraryFS()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	// The mock index contains artists 1 and 2, shortcut 10, and file 11, and artist 1
+	// contains directory 405, which contains two songs with the same title
+	empty := []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "directory": {}}}`)
+	f.SetFixture("getMusicDirectory", url.Values{"id": {"2"}}, empty)
+	f.SetFixture("getMusicDirectory", url.Values{"id": {"10"}}, empty)
+	f.SetFixture("getMusicDirectory", url.Values{"id": {"405"}}, []byte(`{"subsonic-response": {
+		"status": "ok", "version": "1.9.0", "directory": {"child": [
+			{"id": 406, "title": "Wonderland", "suffix": "mp3", "size": 5, "created": "2013-08-12T00:12:24"},
+			{"id": 407, "title": "Wonderland", "suffix": "mp3", "size": 4},
+			{"id": 408, "title": "AC/DC", "path": "Adventure/Adventure/03 - AC-DC.flac", "size": 3}
+		]}}}`))
+	f.SetMedia("11", "audio/mpeg", []byte("intro"))
+	f.SetMedia("406", "audio/mpeg", []byte("first"))
+	f.SetMedia("407", "audio/mpeg", []byte("last"))
+	f.SetMedia("408", "audio/flac", []byte("fla"))
+
+	fsys := s.NewLibraryFS(context.Background(), -1)
+	if err := fstest.TestFS(fsys,
+		"Intro",
+		"New Music",
+		"Boston",
+		"Adventure/2008 - Adventure/Wonderland (406).mp3",
+		"Adventure/2008 - Adventure/Wonderland (407).mp3",
+		"Adventure/2008 - Adventure/03 - AC-DC.flac",
+	); err != nil {
+		t.Fatalf("LibraryFS failed fstest: %s", err.Error())
+	}
+
+	// Listings are cached, so each directory was only retrieved once
+	if n := f.Requests("getMusicDirectory"); n != 4 {
+		t.Fatalf("LibraryFS retrieved %d directories", n)
+	}
+
+	b, err := fs.ReadFile(fsys, "Adventure/2008 - Adventure/Wonderland (407).mp3")
+	if err != nil || string(b) != "last" {
+		t.Fatalf("ReadFile returned %q, %v", b, err)
+	}
+
+	// Items are available from file info
+	info, err := fs.Stat(fsys, "Adventure/2008 - Adventure/Wonderland (406).mp3")
+	if err != nil {
+		t.Fatalf("Stat returned error: %s", err.Error())
+	}
+	if a, ok := info.Sys().(*Audio); !ok || a.ID != "406" || info.Size() != 5 || !info.ModTime().Equal(time.Date(2013, 8, 12, 0, 12, 24, 0, time.UTC)) {
+		t.Fatalf("Stat returned unexpected info: %+v", info)
+	}
+
+	// Opening files does not contact the server
+	downloads := f.Requests("download")
+	file, err := fsys.Open("Intro")
+	if err != nil {
+		t.Fatalf("Open returned error: %s", err.Error())
+	}
+	file.Close()
+	if n := f.Requests("download"); n != downloads {
+		t.Fatalf("Open made %d download requests", n-downloads)
+	}
+
+	// Missing items and directories on the server are reported as not existing
+	for _, name := range []string{"Missing", "Intro/Child", "Adventure/2008 - Adventure/Wonderland.mp3"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Open(%q) returned unexpected error: %v", name, err)
+		}
+	}
+	if _, err := fsys.Open("/Adventure"); !errors.Is(err, fs.ErrInvalid) {
+		t.Fatalf("Open returned unexpected error for invalid path: %v", err)
+	}
+
+	// Refreshing discards listings
+	fsys.Refresh()
+	if _, err := fsys.ReadDir("Adventure"); err != nil {
+		t.Fatalf("ReadDir returned error: %s", err.Error())
+	}
+	if n := f.Requests("getMusicDirectory"); n != 5 {
+		t.Fatalf("LibraryFS retrieved %d directories after refresh", n)
+	}
+}
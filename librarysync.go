@@ -0,0 +1,227 @@
+package gosubsonic
+
+import (
+	"context"
+	"reflect"
+)
+
+// LibraryEventType represents the type of change reported by a LibraryEvent
+type LibraryEventType int
+
+// Types of changes reported by SyncLibrary
+const (
+	LibraryAdded LibraryEventType = iota
+	LibraryRemoved
+	LibraryChanged
+)
+
+// String returns the name of a LibraryEventType
+func (t LibraryEventType) String() string {
+	switch t {
+	case LibraryAdded:
+		return "added"
+	case LibraryRemoved:
+		return "removed"
+	case LibraryChanged:
+		return "changed"
+	}
+
+	return "unknown"
+}
+
+// LibraryEvent reports a single change to a Library.  Artist is always set.  Album is set for
+// changes to albums and songs, and Song is set only for changes to songs.  Adding or removing
+// an artist or album also reports each of its albums and songs.
+type LibraryEvent struct {
+	Type   LibraryEventType
+	Artist IndexArtist
+	Album  *LibraryAlbum
+	Song   *Audio
+}
+
+// SyncLibrary updates a Library built using BuildLibrary, with an optional LibraryOptions
+// struct, and returns the updated Library.  If the server reports that the library has not
+// been modified, no directories are retrieved.  Otherwise, each artist is retrieved, but
+// albums are only retrieved again if they are new or their directory has changed, unless
+// LibraryOptions.Deep is set.
+//
+// Each change is sent on events, if it is not nil, and events is closed when SyncLibrary
+// returns.  The caller must receive from events until it is closed.
+func (s Client) SyncLibrary(ctx context.Context, lib *Library, events chan<- LibraryEvent, options *LibraryOptions) (*Library, error) {
+	if events != nil {
+		defer close(events)
+	}
+
+	o := LibraryOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = bulkConcurrency
+	}
+
+	indexes, err := s.libraryIndexes(ctx, o.MusicFolderID, lib.LastModified)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subsonic omits the indexes when they are not modified
+	if len(indexes.Index) == 0 && !indexes.LastModified.After(lib.LastModified) {
+		return lib, nil
+	}
+
+	previous := lib
+	if o.Deep {
+		previous = nil
+	}
+
+	updated, err := newLibraryCrawler(ctx, s, o, previous).crawl(indexes.Index, indexes.LastModified)
+	if err != nil {
+		return nil, err
+	}
+
+	if events == nil {
+		return updated, nil
+	}
+
+	for _, ev := range diffLibrary(lib, updated) {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return updated, nil
+}
+
+// diffLibrary generates the events which describe the changes from one Library to another,
+// in catalog order, with removals reported before additions
+func diffLibrary(old *Library, updated *Library) []LibraryEvent {
+	var events []LibraryEvent
+
+	oldArtists := make(map[ID]LibraryArtist, len(old.Artists))
+	for _, a := range old.Artists {
+		oldArtists[a.ID] = a
+	}
+
+	newArtists := make(map[ID]bool, len(updated.Artists))
+	for _, a := range updated.Artists {
+		newArtists[a.ID] = true
+	}
+
+	for _, a := range old.Artists {
+		if !newArtists[a.ID] {
+			events = append(events, artistEvents(LibraryRemoved, a)...)
+		}
+	}
+
+	for _, a := range updated.Artists {
+		o, ok := oldArtists[a.ID]
+		if !ok {
+			events = append(events, artistEvents(LibraryAdded, a)...)
+			continue
+		}
+
+		if o.Name != a.Name {
+			events = append(events, LibraryEvent{Type: LibraryChanged, Artist: a.IndexArtist})
+		}
+
+		events = append(events, diffAlbums(a.IndexArtist, o.Albums, a.Albums)...)
+	}
+
+	return events
+}
+
+// diffAlbums generates the events which describe the changes to an artist's albums
+func diffAlbums(artist IndexArtist, old []LibraryAlbum, updated []LibraryAlbum) []LibraryEvent {
+	var events []LibraryEvent
+
+	oldAlbums := make(map[ID]LibraryAlbum, len(old))
+	for _, al := range old {
+		oldAlbums[al.ID] = al
+	}
+
+	newAlbums := make(map[ID]bool, len(updated))
+	for _, al := range updated {
+		newAlbums[al.ID] = true
+	}
+
+	for _, al := range old {
+		if !newAlbums[al.ID] {
+			events = append(events, albumEvents(LibraryRemoved, artist, al)...)
+		}
+	}
+
+	for _, al := range updated {
+		o, ok := oldAlbums[al.ID]
+		if !ok {
+			events = append(events, albumEvents(LibraryAdded, artist, al)...)
+			continue
+		}
+
+		album := al
+		if !sameDirectory(o.Directory, al.Directory) {
+			events = append(events, LibraryEvent{Type: LibraryChanged, Artist: artist, Album: &album})
+		}
+
+		events = append(events, diffSongs(artist, &album, o.Songs, al.Songs)...)
+	}
+
+	return events
+}
+
+// diffSongs generates the events which describe the changes to an album's songs
+func diffSongs(artist IndexArtist, album *LibraryAlbum, old []Audio, updated []Audio) []LibraryEvent {
+	var events []LibraryEvent
+
+	oldSongs := make(map[ID]Audio, len(old))
+	for _, s := range old {
+		oldSongs[s.ID] = s
+	}
+
+	newSongs := make(map[ID]bool, len(updated))
+	for _, s := range updated {
+		newSongs[s.ID] = true
+	}
+
+	for i := range old {
+		if !newSongs[old[i].ID] {
+			events = append(events, LibraryEvent{Type: LibraryRemoved, Artist: artist, Album: album, Song: &old[i]})
+		}
+	}
+
+	for i := range updated {
+		o, ok := oldSongs[updated[i].ID]
+		switch {
+		case !ok:
+			events = append(events, LibraryEvent{Type: LibraryAdded, Artist: artist, Album: album, Song: &updated[i]})
+		case !reflect.DeepEqual(o, updated[i]):
+			events = append(events, LibraryEvent{Type: LibraryChanged, Artist: artist, Album: album, Song: &updated[i]})
+		}
+	}
+
+	return events
+}
+
+// artistEvents generates events for an artist which was added or removed, and all of its
+// albums and songs
+func artistEvents(t LibraryEventType, a LibraryArtist) []LibraryEvent {
+	events := []LibraryEvent{{Type: t, Artist: a.IndexArtist}}
+	for _, al := range a.Albums {
+		events = append(events, albumEvents(t, a.IndexArtist, al)...)
+	}
+
+	return events
+}
+
+// albumEvents generates events for an album which was added or removed, and all of its songs
+func albumEvents(t LibraryEventType, artist IndexArtist, al LibraryAlbum) []LibraryEvent {
+	album := &al
+	events := []LibraryEvent{{Type: t, Artist: artist, Album: album}}
+	for i := range al.Songs {
+		events = append(events, LibraryEvent{Type: t, Artist: artist, Album: album, Song: &al.Songs[i]})
+	}
+
+	return events
+}
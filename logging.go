@@ -0,0 +1,149 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// LogRedaction determines how much of each request URL is redacted when calls are logged
+type LogRedaction int
+
+// Levels of redaction for logged request URLs, from least to most redacted
+const (
+	// RedactCredentials redacts passwords, tokens, and salts, which are never logged
+	RedactCredentials LogRedaction = iota
+
+	// RedactIdentity also redacts the username and client name
+	RedactIdentity
+
+	// RedactParams omits the URL and its parameters entirely, so only the method is logged
+	RedactParams
+)
+
+// callLogKey is the context key for the callLog of a request being logged
+type callLogKey struct{}
+
+// callLog collects details about a request being logged, which are only known by the layers
+// which perform it
+type callLog struct {
+	// httpStatus is the HTTP status code of a binary response
+	httpStatus int
+
+	// warnings describe the malformed items skipped by lenient parsing
+	warnings []string
+}
+
+// recordHTTPStatus records the HTTP status code of a response, if its request is being logged
+func recordHTTPStatus(ctx context.Context, status int) {
+	if l, ok := ctx.Value(callLogKey{}).(*callLog); ok {
+		l.httpStatus = status
+	}
+}
+
+// logCall performs a request using fn, and logs its outcome to the client's logger.
+// Successful calls are logged at debug level, and failed calls, or those with malformed
+// items skipped by lenient parsing, at warning level.
+func (s Client) logCall(ctx context.Context, req *Request, fn func(ctx context.Context) error) error {
+	l := &callLog{}
+	start := time.Now()
+	err := fn(context.WithValue(ctx, callLogKey{}, l))
+
+	attrs := []slog.Attr{slog.String("method", req.Method)}
+	if u := s.logURL(req.URL); u != "" {
+		attrs = append(attrs, slog.String("url", u))
+	}
+	if req.Binary {
+		attrs = append(attrs, slog.Bool("binary", true))
+	}
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+	// Failed HTTP responses are only reported as errors
+	var se *StatusError
+	if l.httpStatus == 0 && errors.As(err, &se) {
+		l.httpStatus = se.StatusCode
+	}
+	if l.httpStatus != 0 {
+		attrs = append(attrs, slog.Int("http_status", l.httpStatus))
+	}
+
+	if len(l.warnings) > 0 {
+		attrs = append(attrs, slog.Any("warnings", l.warnings))
+	}
+
+	// Calls which succeeded despite malformed items are worth attention
+	if err == nil {
+		level := slog.LevelDebug
+		if len(l.warnings) > 0 {
+			level = slog.LevelWarn
+		}
+
+		attrs = append(attrs, slog.String("status", "ok"))
+		s.Logger.LogAttrs(ctx, level, "gosubsonic: call", attrs...)
+		return nil
+	}
+
+	attrs = append(attrs, slog.String("status", "failed"), slog.String("error", err.Error()))
+	attrs = append(attrs, errorAttrs(err)...)
+	s.Logger.LogAttrs(ctx, slog.LevelWarn, "gosubsonic: call", attrs...)
+
+	return err
+}
+
+// logURL redacts a request URL, whose credentials are already redacted, according to the
+// client's redaction level
+func (s Client) logURL(rawURL string) string {
+	switch s.LogRedaction {
+	case RedactParams:
+		return ""
+	case RedactIdentity:
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return ""
+		}
+
+		q := u.Query()
+		for _, p := range []string{"u", "c"} {
+			if q.Has(p) {
+				q.Set(p, redacted)
+			}
+		}
+		u.RawQuery = q.Encode()
+
+		return u.String()
+	}
+
+	return rawURL
+}
+
+// errorAttrs returns attributes which describe an error in detail, such as the Subsonic error
+// code, or the location of malformed data in a response
+func errorAttrs(err error) []slog.Attr {
+	var attrs []slog.Attr
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		attrs = append(attrs, slog.Int("code", int(apiErr.Code)))
+	}
+
+	// Malformed responses are reported with the field and position which could not be
+	// decoded, if known
+	var te *json.UnmarshalTypeError
+	var syn *json.SyntaxError
+	switch {
+	case errors.As(err, &te):
+		if te.Field != "" {
+			attrs = append(attrs, slog.String("field", te.Field))
+		}
+		// Items are decoded individually, so the offset of a type error is relative to its
+		// item, and is omitted
+		attrs = append(attrs, slog.String("value", te.Value))
+	case errors.As(err, &syn):
+		attrs = append(attrs, slog.Int64("offset", syn.Offset))
+	}
+
+	return attrs
+}
@@ -0,0 +1,102 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestLogger verifies that calls are logged with their outcome, and with request URLs
+// redacted according to the client's redaction level
+func TestLogger(t *testing.T) {
+	log.Println("TestLogger()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetMedia("1", "audio/mpeg", []byte("media"))
+	f.SetFixture("getSong", url.Values{"id": {"2"}}, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"song": {"id": "2", "title": "Song", "contentType": 5}}}`))
+
+	var tests = []struct {
+		redaction LogRedaction
+		call      func(s *Client) error
+		attrs     map[string]interface{}
+		absent    []string
+		field     string
+	}{
+		{
+			redaction: RedactCredentials,
+			call:      func(s *Client) error { _, err := s.Ping(context.Background()); return err },
+			attrs:     map[string]interface{}{"level": "DEBUG", "method": "ping", "status": "ok"},
+		},
+		{
+			redaction: RedactIdentity,
+			call:      func(s *Client) error { _, err := s.GetSong(context.Background(), "404"); return err },
+			attrs:     map[string]interface{}{"level": "WARN", "method": "getSong", "status": "failed", "code": float64(ErrCodeNotFound)},
+		},
+		{
+			redaction: RedactParams,
+			call: func(s *Client) error {
+				r, err := s.Stream(context.Background(), "1", nil)
+				if err == nil {
+					r.Close()
+				}
+				return err
+			},
+			attrs:  map[string]interface{}{"method": "stream", "binary": true, "http_status": float64(200)},
+			absent: []string{"url"},
+		},
+		{
+			redaction: RedactCredentials,
+			call:      func(s *Client) error { _, err := s.GetSong(context.Background(), "2"); return err },
+			attrs:     map[string]interface{}{"status": "failed", "value": "number"},
+			field:     "contentType",
+		},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		s, err := f.Client()
+		if err != nil {
+			t.Fatalf("Client returned error: %s", err.Error())
+		}
+		s.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		s.LogRedaction = test.redaction
+
+		test.call(s)
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("[%02d] Logger wrote invalid record: %s: %q", i, err.Error(), buf.String())
+		}
+
+		for k, v := range test.attrs {
+			if record[k] != v {
+				t.Fatalf("[%02d] Logger wrote %s = %v, expected %v: %s", i, k, record[k], v, buf.String())
+			}
+		}
+		// The path of a malformed field depends on the JSON implementation, but always ends
+		// with its name
+		if f, _ := record["field"].(string); !strings.HasSuffix(strings.ToLower(f), strings.ToLower(test.field)) {
+			t.Fatalf("[%02d] Logger wrote field = %q, expected %q: %s", i, f, test.field, buf.String())
+		}
+
+		for _, k := range test.absent {
+			if _, ok := record[k]; ok {
+				t.Fatalf("[%02d] Logger wrote %s: %s", i, k, buf.String())
+			}
+		}
+
+		// Credentials are never logged, and the identity only when permitted
+		u, _ := record["url"].(string)
+		if strings.Contains(u, "p=mock") || (test.redaction == RedactIdentity) != strings.Contains(u, "u=REDACTED") {
+			t.Fatalf("[%02d] Logger did not redact URL: %s", i, u)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gosubsonic
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// m3uTrackNumber matches a leading track number in a file name, such as "01 - "
+var m3uTrackNumber = regexp.MustCompile(`^\d+[\s.\-_]*`)
+
+// M3UOptions represents additional options for the ExportM3U() and ExportPlaylistM3U() methods
+type M3UOptions struct {
+	// Download links to the original media files, instead of streams
+	Download bool
+
+	// Stream configures the stream URLs, if Download is not set
+	Stream *StreamOptions
+}
+
+// M3UImport represents the result of importing an M3U playlist
+type M3UImport struct {
+	// Songs are the songs which were found in the library, in playlist order
+	Songs []Audio
+
+	// Unresolved are the playlist entries which were not found in the library
+	Unresolved []string
+}
+
+// ExportM3U writes an extended M3U playlist containing a stream or download URL for each
+// song, which may be opened by external players.  The URLs contain authentication
+// parameters, so token authentication should be used to avoid exposing the password.
+func (s Client) ExportM3U(w io.Writer, songs []Audio, options *M3UOptions) error {
+	return s.writeM3U(w, "", songs, options)
+}
+
+// ExportPlaylistM3U writes a server playlist as an extended M3U playlist, as with ExportM3U
+func (s Client) ExportPlaylistM3U(ctx context.Context, w io.Writer, id ID, options *M3UOptions) error {
+	playlist, err := s.GetPlaylist(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	songs := make([]Audio, len(playlist.Entry))
+	for i, e := range playlist.Entry {
+		songs[i] = e.Audio
+	}
+
+	return s.writeM3U(w, playlist.Name, songs, options)
+}
+
+// writeM3U writes an extended M3U playlist, with an optional name
+func (s Client) writeM3U(w io.Writer, name string, songs []Audio, options *M3UOptions) error {
+	if options == nil {
+		options = &M3UOptions{}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("#EXTM3U\n")
+	if name != "" {
+		fmt.Fprintf(bw, "#PLAYLIST:%s\n", m3uEscape(name))
+	}
+
+	for _, song := range songs {
+		// Unknown durations are reported as -1
+		seconds := int64(song.Duration.Seconds())
+		if song.Duration == 0 {
+			seconds = -1
+		}
+
+		u := s.streamURL(song.ID, options.Stream)
+		if options.Download {
+			u = s.makeURL("download", idParams(song.ID))
+		}
+
+		fmt.Fprintf(bw, "#EXTINF:%d,%s - %s\n%s\n", seconds, m3uEscape(song.Artist), m3uEscape(song.Title), u)
+	}
+
+	return bw.Flush()
+}
+
+// m3uEscape removes line breaks from a value written to an M3U playlist
+func m3uEscape(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// ImportM3U reads an M3U or extended M3U playlist, and resolves each entry to a song in the
+// library.  Stream and download URLs from this package are resolved by ID.  File paths are
+// resolved using Search3, by matching the end of the path, or else the artist and title from
+// the entry's #EXTINF line.  Entries which are not found are reported as unresolved.
+func (s Client) ImportM3U(ctx context.Context, r io.Reader) (*M3UImport, error) {
+	out := &M3UImport{}
+
+	var artist, title string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			artist, title = m3uInfo(line)
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		song, err := s.resolveM3U(ctx, line, artist, title)
+		if err != nil {
+			return nil, err
+		}
+
+		if song != nil {
+			out.Songs = append(out.Songs, *song)
+		} else {
+			out.Unresolved = append(out.Unresolved, line)
+		}
+
+		artist, title = "", ""
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// m3uInfo parses the artist and title from an #EXTINF line, such as
+// "#EXTINF:214,Adventure - Wonderland"
+func m3uInfo(line string) (string, string) {
+	i := strings.Index(line, ",")
+	if i == -1 {
+		return "", ""
+	}
+
+	info := line[i+1:]
+	if j := strings.Index(info, " - "); j != -1 {
+		return strings.TrimSpace(info[:j]), strings.TrimSpace(info[j+3:])
+	}
+
+	return "", strings.TrimSpace(info)
+}
+
+// resolveM3U finds the song for an M3U entry, returning nil if it is not found
+func (s Client) resolveM3U(ctx context.Context, entry string, artist string, title string) (*Audio, error) {
+	// Stream and download URLs contain the song's ID
+	if u, err := url.Parse(entry); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		method := urlMethod(entry)
+		if (method != "stream" && method != "download") || u.Query().Get("id") == "" {
+			return nil, nil
+		}
+
+		song, err := s.GetSong(ctx, ID(u.Query().Get("id")))
+		if IsNotFound(err) {
+			return nil, nil
+		}
+
+		return song, err
+	}
+
+	p := strings.ReplaceAll(entry, "\\", "/")
+	if title == "" {
+		// Guess the title from the file name, such as "01 - Wonderland.mp3"
+		base := path.Base(p)
+		title = m3uTrackNumber.ReplaceAllString(strings.TrimSuffix(base, path.Ext(base)), "")
+	}
+	if title == "" {
+		return nil, nil
+	}
+
+	res, err := s.Search3(ctx, title, &SearchOptions{ArtistCount: -1, AlbumCount: -1})
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer an exact path match, then a matching artist and title
+	for i := range res.Songs {
+		if m3uPathMatch(res.Songs[i].Path, p) {
+			return &res.Songs[i], nil
+		}
+	}
+	for i := range res.Songs {
+		song := &res.Songs[i]
+		if strings.EqualFold(song.Title, title) && (artist == "" || strings.EqualFold(song.Artist, artist)) {
+			return song, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// m3uPathMatch determines if two paths refer to the same file, where either may be relative
+// to a different root, by comparing their trailing path elements
+func m3uPathMatch(a string, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	a, b = m3uCleanPath(a), m3uCleanPath(b)
+	return a == b || strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
+
+// m3uCleanPath normalizes a path for comparison, removing any leading root or parent
+// directory elements
+func m3uCleanPath(p string) string {
+	p = path.Clean("/" + strings.ToLower(strings.ReplaceAll(p, "\\", "/")))
+	return strings.TrimPrefix(p, "/")
+}
@@ -0,0 +1,93 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExportM3U verifies that client.ExportM3U() writes an extended M3U playlist
+func TestExportM3U(t *testing.T) {
+	log.Println("TestExportM3U()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	songs := []Audio{
+		{ID: "406", Artist: "Adventure", Title: "Wonderland", Duration: 214 * time.Second},
+		{ID: "407", Artist: "Adventure", Title: "Another\nDay"},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := s.ExportM3U(buf, songs, &M3UOptions{Stream: &StreamOptions{Format: "mp3"}}); err != nil {
+		t.Fatalf("ExportM3U returned error: %s", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || lines[0] != "#EXTM3U" || lines[1] != "#EXTINF:214,Adventure - Wonderland" || lines[3] != "#EXTINF:-1,Adventure - Another Day" {
+		t.Fatalf("ExportM3U wrote invalid playlist:\n%s", buf.String())
+	}
+
+	u, err := url.Parse(lines[4])
+	if err != nil || urlMethod(lines[4]) != "stream" || u.Query().Get("id") != "407" || u.Query().Get("format") != "mp3" {
+		t.Fatalf("ExportM3U wrote invalid URL: %s", lines[4])
+	}
+
+	// Server playlists include their name
+	buf.Reset()
+	if err := s.ExportPlaylistM3U(context.Background(), buf, "1", &M3UOptions{Download: true}); err != nil {
+		t.Fatalf("ExportPlaylistM3U returned error: %s", err.Error())
+	}
+
+	if !strings.HasPrefix(buf.String(), "#EXTM3U\n#PLAYLIST:") || !strings.Contains(buf.String(), "/rest/download.view?") {
+		t.Fatalf("ExportPlaylistM3U wrote invalid playlist:\n%s", buf.String())
+	}
+}
+
+// TestImportM3U verifies that client.ImportM3U() resolves URLs and paths against the library
+func TestImportM3U(t *testing.T) {
+	log.Println("TestImportM3U()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetFixture("search3", url.Values{"query": {"Wonderland"}, "artistCount": {"0"}, "albumCount": {"0"}},
+		[]byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0", "searchResult3": {"song": [
+			{"id": 9, "title": "Wonderland", "artist": "Other", "path": "Other/Wonderland.mp3"},
+			{"id": 406, "title": "Wonderland", "artist": "Adventure", "path": "Adventure/Adventure/01 - Wonderland.mp3"}]}}}`))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	playlist := strings.Join([]string{
+		"\ufeff#EXTM3U",
+		"/media/music/Adventure/Adventure/01 - Wonderland.mp3",
+		"#EXTINF:214,Adventure - Wonderland",
+		"C:\\Music\\Wonderland.mp3",
+		"",
+		s.streamURL("1", nil),
+		"Missing/02 - Missing.mp3",
+	}, "\r\n")
+
+	res, err := s.ImportM3U(context.Background(), strings.NewReader(playlist))
+	if err != nil {
+		t.Fatalf("ImportM3U returned error: %s", err.Error())
+	}
+
+	if len(res.Songs) != 3 || res.Songs[0].ID != "406" || res.Songs[1].ID != "406" || res.Songs[2].ID != "1" {
+		t.Fatalf("ImportM3U resolved invalid songs: %+v", res.Songs)
+	}
+
+	if len(res.Unresolved) != 1 || res.Unresolved[0] != "Missing/02 - Missing.mp3" {
+		t.Fatalf("ImportM3U returned invalid unresolved entries: %v", res.Unresolved)
+	}
+}
@@ -0,0 +1,86 @@
+package gosubsonic
+
+import (
+	"context"
+)
+
+// Request describes a single request made by a Client, as seen by an Interceptor
+type Request struct {
+	// Method is the Subsonic API method, such as "getSong" or "stream"
+	Method string
+
+	// URL is the full request URL, with credentials redacted
+	URL string
+
+	// Binary is set for requests which return binary data, such as Stream or GetCoverArt.
+	// For these requests, the Interceptor returns once the response headers are received,
+	// and reading the body is not intercepted.
+	Binary bool
+}
+
+// Invoker performs a Request
+type Invoker func(ctx context.Context, req *Request) error
+
+// Interceptor wraps each request made by a Client, such as for logging, metrics, or tracing.
+// It must call next to perform the request, and may replace ctx, for example to attach a
+// tracing span.  The error returned by next includes the server's Subsonic error, if any,
+// as an *Error, and should usually be returned unchanged.
+type Interceptor func(ctx context.Context, req *Request, next Invoker) error
+
+// intercept performs a request using fn, through each of the client's interceptors, and logs
+// it if the client has a logger.  Each request is intercepted once, including any retries,
+// but responses served from the cache are not intercepted.
+func (s Client) intercept(ctx context.Context, req *Request, fn Invoker) error {
+	// Calls are logged outside of any interceptors, so the log reflects what the caller sees
+	if s.Logger != nil {
+		return s.logCall(ctx, req, func(ctx context.Context) error {
+			return s.chain(ctx, req, fn)
+		})
+	}
+
+	return s.chain(ctx, req, fn)
+}
+
+// chain performs a request using fn, through each of the client's interceptors.  The first
+// interceptor is the outermost.
+func (s Client) chain(ctx context.Context, req *Request, fn Invoker) error {
+	next := fn
+	for i := len(s.Interceptors) - 1; i >= 0; i-- {
+		ic, n := s.Interceptors[i], next
+		next = func(ctx context.Context, req *Request) error {
+			return ic(ctx, req, n)
+		}
+	}
+
+	return next(ctx, req)
+}
+
+// call retrieves and processes an API response, through the client's interceptors.  The raw
+// body is only returned if keepBody is set, such as for caching.  Otherwise, responses from a
+// StreamingDataSource are decoded as they are read, unless the client's compatibility profile
+// must correct the entire body, or lenient parsing is enabled.
+func (s Client) call(ctx context.Context, url string, keepBody bool) ([]byte, *apiContainer, error) {
+	var body []byte
+	var res *apiContainer
+	err := s.intercept(ctx, &Request{Method: urlMethod(url), URL: redactURL(url)}, func(ctx context.Context, req *Request) error {
+		var err error
+		if ss, ok := s.dataSource().(StreamingDataSource); ok && !keepBody && !s.Profile.normalizes() && !s.Lenient {
+			res, err = s.fetchDecode(ctx, ss, url)
+			return err
+		}
+
+		if body, err = s.fetch(ctx, url); err != nil {
+			return err
+		}
+
+		var warnings []ParseWarning
+		res, warnings, err = s.parse(body)
+		reportParseWarnings(ctx, req.Method, warnings)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, res, nil
+}
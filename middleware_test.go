@@ -0,0 +1,90 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// interceptorKey is a context key used to verify that interceptors may replace the context
+type interceptorKey struct{}
+
+// TestInterceptors verifies that interceptors wrap API and binary requests in order, may
+// replace the context, and observe Subsonic errors
+func TestInterceptors(t *testing.T) {
+	log.Println("TestInterceptors()")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/getMusicDirectory.view":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"subsonic-response": {"status": "failed", "version": "1.9.0",
+				"error": {"code": 70, "message": "Directory not found"}}}`))
+		case "/rest/stream.view":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("audio"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	var calls []string
+	s.Interceptors = []Interceptor{
+		func(ctx context.Context, req *Request, next Invoker) error {
+			calls = append(calls, "outer "+req.Method)
+			return next(context.WithValue(ctx, interceptorKey{}, "traced"), req)
+		},
+		func(ctx context.Context, req *Request, next Invoker) error {
+			err := next(ctx, req)
+
+			result := "ok"
+			var se *Error
+			if errors.As(err, &se) {
+				result = se.Message
+			}
+
+			calls = append(calls, "inner "+req.Method+" "+ctx.Value(interceptorKey{}).(string)+" "+result)
+			if req.Binary != (req.Method == "stream") {
+				t.Errorf("invalid binary flag for method %q", req.Method)
+			}
+
+			return err
+		},
+	}
+
+	if _, err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping returned error: %s", err.Error())
+	}
+	if _, err := s.GetMusicDirectory(context.Background(), "1"); !IsNotFound(err) {
+		t.Fatalf("GetMusicDirectory returned unexpected error: %v", err)
+	}
+
+	stream, err := s.Stream(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	stream.Close()
+
+	expected := []string{
+		"outer ping",
+		"inner ping traced ok",
+		"outer getMusicDirectory",
+		"inner getMusicDirectory traced Directory not found",
+		"outer stream",
+		"inner stream traced ok",
+	}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected interceptor calls: %q", calls)
+	}
+}
@@ -1,7 +1,11 @@
 package gosubsonic
 
-// mockData maps a mock URL to mock data from the mockTable
-var mockData map[string][]byte
+// mockEmptyResponse is the mock JSON data for methods which return no data
+var mockEmptyResponse = []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)
 
 // mockTable maps a method to mock JSON data for testing
 var mockTable = []struct {
@@ -24,6 +28,21 @@ var mockTable = []struct {
 		},
 		"version": "1.9.0"
 	}}`)},
+	{"getOpenSubsonicExtensions", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"version": "1.16.1",
+		"type": "navidrome",
+		"serverVersion": "0.49.3",
+		"openSubsonic": true,
+		"openSubsonicExtensions": [{
+			"name": "transcodeOffset",
+			"versions": [1]
+		},
+		{
+			"name": "formPost",
+			"versions": [1, 2]
+		}]
+	}}`)},
 	{"getMusicFolders", []byte(`{"subsonic-response": {
 		"status": "ok",
 		"xmlns": "http://subsonic.org/restapi",
@@ -50,6 +69,18 @@ var mockTable = []struct {
 					"name": "Boston"
 				}
 			}],
+			"shortcut": {
+				"id": 10,
+				"name": "New Music"
+			},
+			"child": [{
+				"id": 11,
+				"parent": 0,
+				"title": "Intro",
+				"isDir": false,
+				"duration": 30
+			}],
+			"ignoredArticles": "The El La",
 			"lastModified": 1395014311154
 		},
 		"xmlns": "http://subsonic.org/restapi",
@@ -74,35 +105,1018 @@ var mockTable = []struct {
 		"xmlns": "http://subsonic.org/restapi",
 		"version": "1.9.0"
 	}}`)},
+	{"getArtists", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"artists": {
+			"ignoredArticles": "The El La Los Las Le Les",
+			"index": [{
+				"name": "#",
+				"artist": {
+					"id": 2,
+					"name": 311,
+					"albumCount": 3
+				}
+			},
+			{
+				"name": "A",
+				"artist": [{
+					"id": 1,
+					"name": "Adventure",
+					"coverArt": 405,
+					"albumCount": 1
+				},
+				{
+					"id": 3,
+					"name": "Anamanaguchi",
+					"albumCount": 2
+				}]
+			}]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getArtist", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"artist": {
+			"id": 1,
+			"name": "Adventure",
+			"coverArt": 405,
+			"albumCount": 1,
+			"album": {
+				"id": 1,
+				"name": "Adventure",
+				"artist": "Adventure",
+				"artistId": 1,
+				"coverArt": 405,
+				"songCount": 2,
+				"duration": 401,
+				"created": "2013-08-12T00:12:24"
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getAlbum", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"album": {
+			"id": 1,
+			"name": "Adventure",
+			"artist": "Adventure",
+			"artistId": 1,
+			"coverArt": 405,
+			"songCount": 2,
+			"duration": 401,
+			"created": "2013-08-12T00:12:24",
+			"year": 2008,
+			"genre": "Electronic",
+			"song": [{
+				"id": 406,
+				"parent": 405,
+				"title": "Wonderland",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"track": 1,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"albumId": 1,
+				"artistId": 1,
+				"type": "music"
+			},
+			{
+				"id": 407,
+				"parent": 405,
+				"title": "Another Day",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 187,
+				"track": 2,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"albumId": 1,
+				"artistId": 1,
+				"type": "music"
+			}]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getSong", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"song": {
+			"id": 1,
+			"parent": 405,
+			"title": 311,
+			"album": "Adventure",
+			"artist": "Adventure",
+			"isDir": false,
+			"created": "2013-08-12T00:12:24",
+			"duration": 214,
+			"track": 1,
+			"suffix": "mp3",
+			"contentType": "audio/mpeg",
+			"type": "music",
+			"musicBrainzId": "2ed43a0d-6a4b-4bd7-9b39-5e0ec0fe9b4a",
+			"sortName": "three eleven",
+			"replayGain": {
+				"trackGain": -6.5,
+				"albumGain": -7.25,
+				"trackPeak": 0.98
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getGenres", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"genres": {"genre": [{
+			"songCount": 28,
+			"albumCount": 6,
+			"value": "Electronic"
+		},
+		{
+			"songCount": 6,
+			"albumCount": 2,
+			"content": "Hip-Hop"
+		},
+		"Rock"]},
+		"version": "1.9.0"
+	}}`)},
+	{"getVideos", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"videos": {"video": {
+			"id": 460,
+			"parent": 1,
+			"title": "Mock Video",
+			"isDir": false,
+			"isVideo": true,
+			"created": "2013-08-12T00:12:24",
+			"duration": 3600,
+			"bitRate": 1500,
+			"suffix": "mkv",
+			"contentType": "video/x-matroska"
+		}},
+		"version": "1.9.0"
+	}}`)},
+	{"getVideoInfo", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"videoInfo": {
+			"id": 460,
+			"captions": {
+				"id": 0,
+				"name": "Mock Video.srt"
+			},
+			"audioTrack": [{
+				"id": 1,
+				"name": "English",
+				"languageCode": "eng"
+			},
+			{
+				"id": 3,
+				"name": "Norwegian",
+				"languageCode": "nor"
+			}],
+			"conversion": {
+				"id": 37,
+				"bitRate": 1000
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getArtistInfo", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"artistInfo": {
+			"biography": "Adventure is an 8-bit band from Baltimore &amp; elsewhere",
+			"musicBrainzId": "0a1b2c3d-0000-0000-0000-000000000000",
+			"lastFmUrl": "http://www.last.fm/music/Adventure",
+			"smallImageUrl": "http://example.com/small.png",
+			"mediumImageUrl": "http://example.com/medium.png",
+			"largeImageUrl": "http://example.com/large.png",
+			"similarArtist": [{
+				"id": 2,
+				"name": 311
+			},
+			{
+				"id": 3,
+				"name": "Anamanaguchi"
+			}]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getArtistInfo2", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"artistInfo2": {
+			"lastFmUrl": "http://www.last.fm/music/Adventure",
+			"similarArtist": {
+				"id": 3,
+				"name": "Anamanaguchi",
+				"albumCount": 2
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getAlbumInfo", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"albumInfo": {
+			"notes": "The debut album &amp; more",
+			"lastFmUrl": "http://www.last.fm/music/Adventure/Adventure",
+			"largeImageUrl": "http://example.com/album.png"
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getTopSongs", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"topSongs": {"song": {
+			"id": 1,
+			"parent": 405,
+			"title": "Mock Song",
+			"album": "Adventure",
+			"artist": "Adventure",
+			"isDir": false,
+			"created": "2013-08-12T00:12:24",
+			"duration": 214,
+			"suffix": "mp3",
+			"contentType": "audio/mpeg",
+			"type": "music"
+		}},
+		"version": "1.9.0"
+	}}`)},
+	{"getSimilarSongs2", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"similarSongs2": "",
+		"version": "1.9.0"
+	}}`)},
+	{"getNowPlaying", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"nowPlaying": {"entry": [{
+			"id": 406,
+			"parent": 405,
+			"title": "Wonderland",
+			"album": "Adventure",
+			"artist": "Adventure",
+			"isDir": false,
+			"coverArt": 405,
+			"created": "2013-08-12T00:12:24",
+			"duration": 214,
+			"bitRate": 320,
+			"track": 1,
+			"discNumber": 1,
+			"year": 2011,
+			"genre": "Electronic",
+			"size": 8596476,
+			"suffix": "mp3",
+			"contentType": "audio/mpeg",
+			"isVideo": false,
+			"path": "Adventure/Adventure/01 - Wonderland.mp3",
+			"albumId": 405,
+			"artistId": 1,
+			"type": "music",
+			"username": "mock",
+			"minutesAgo": 2,
+			"playerId": 3,
+			"playerName": "gosubsonic-player"
+		},
+		{
+			"id": 407,
+			"title": "Another Day",
+			"username": "guest",
+			"minutesAgo": 0
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"getAlbumList", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"albumList": {"album": [{
+			"id": 405,
+			"parent": 1,
+			"title": "Adventure",
+			"artist": "Adventure",
+			"isDir": true,
+			"created": "2013-08-12T00:12:24"
+		},
+		{
+			"id": 406,
+			"parent": 2,
+			"title": 311,
+			"artist": 311,
+			"isDir": true,
+			"created": "2013-08-12T00:12:24"
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"getAlbumList2", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"albumList2": "",
+		"version": "1.9.0"
+	}}`)},
+	{"getRandomSongs", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"randomSongs": {"song": [{
+			"id": 1,
+			"parent": 405,
+			"title": "Mock Song",
+			"album": "Adventure",
+			"artist": "Adventure",
+			"isDir": false,
+			"created": "2013-08-12T00:12:24",
+			"duration": 214,
+			"genre": "Electronic",
+			"year": 2010,
+			"suffix": "mp3",
+			"contentType": "audio/mpeg",
+			"type": "music"
+		},
+		{
+			"id": 2,
+			"parent": 405,
+			"title": 311,
+			"album": "Adventure",
+			"artist": "Adventure",
+			"isDir": false,
+			"created": "2013-08-12T00:12:24",
+			"duration": 187,
+			"genre": "Electronic",
+			"year": 2010,
+			"suffix": "mp3",
+			"contentType": "audio/mpeg",
+			"type": "music"
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"getSongsByGenre", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"songsByGenre": {"song": {
+			"id": 1,
+			"parent": 405,
+			"title": "Mock Song",
+			"album": "Adventure",
+			"artist": "Adventure",
+			"isDir": false,
+			"created": "2013-08-12T00:12:24",
+			"duration": 214,
+			"genre": "Electronic",
+			"suffix": "mp3",
+			"contentType": "audio/mpeg",
+			"type": "music"
+		}},
+		"version": "1.9.0"
+	}}`)},
+	{"search2", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"searchResult2": {
+			"artist": {
+				"id": 1,
+				"name": "Adventure"
+			},
+			"album": {
+				"id": 405,
+				"parent": 1,
+				"title": "Adventure",
+				"artist": "Adventure",
+				"isDir": true,
+				"created": "2013-08-12T00:12:24"
+			},
+			"song": [{
+				"id": 1,
+				"parent": 405,
+				"title": "Mock Song",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			},
+			{
+				"id": 2,
+				"parent": 405,
+				"title": 311,
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 187,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			}]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"search3", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"searchResult3": {
+			"artist": [{
+				"id": 1,
+				"name": "Adventure",
+				"coverArt": 405,
+				"albumCount": 1
+			},
+			{
+				"id": 2,
+				"name": 311,
+				"albumCount": 3
+			}],
+			"album": {
+				"id": 1,
+				"name": "Adventure",
+				"artist": "Adventure",
+				"artistId": 1,
+				"coverArt": 405,
+				"songCount": 2,
+				"duration": 401,
+				"created": "2013-08-12T00:12:24"
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getPlaylists", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"playlists": {"playlist": [{
+			"id": "1",
+			"name": "Favorites",
+			"comment": "Mock playlist",
+			"owner": "mock",
+			"public": true,
+			"songCount": 1,
+			"duration": 214,
+			"created": "2014-03-15T21:22:31.468Z",
+			"changed": "2014-03-16T10:00:00.000Z"
+		},
+		{
+			"id": "2",
+			"name": 311,
+			"owner": "mock",
+			"public": false,
+			"songCount": 0,
+			"duration": 0,
+			"created": "2014-03-15T21:22:31.468Z"
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"getPlaylist", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"playlist": {
+			"id": "1",
+			"name": "Favorites",
+			"comment": "Mock playlist",
+			"owner": "mock",
+			"public": true,
+			"songCount": 1,
+			"duration": 214,
+			"created": "2014-03-15T21:22:31.468Z",
+			"changed": "2014-03-16T10:00:00.000Z",
+			"entry": {
+				"id": 406,
+				"parent": 405,
+				"title": "Wonderland",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"track": 1,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"updatePlaylist", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)},
+	{"createShare", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"shares": {"share": {
+			"id": 12,
+			"url": "http://mock.example.com/share/abc",
+			"description": "Mock share",
+			"username": "mock",
+			"created": "2014-03-20T21:55:32",
+			"visitCount": 0
+		}},
+		"version": "1.9.0"
+	}}`)},
+	{"getShares", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"shares": {"share": [{
+			"id": "12",
+			"url": "http://mock.example.com/share/abc",
+			"description": "Mock share",
+			"username": "mock",
+			"created": "2014-03-20T21:55:32",
+			"expires": "2014-04-20T21:55:32.000Z",
+			"lastVisited": "2014-03-21T08:00:00.000Z",
+			"visitCount": 3,
+			"entry": [{
+				"id": 1,
+				"parent": 405,
+				"title": "Mock Song",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			},
+			{
+				"id": 2,
+				"parent": 405,
+				"title": 311,
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 187,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			}]
+		},
+		{
+			"id": 13,
+			"url": "http://mock.example.com/share/def",
+			"username": "mock",
+			"created": "2014-03-22T10:00:00",
+			"visitCount": 0
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"updateShare", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)},
+	{"getPodcasts", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"podcasts": {"channel": [{
+			"id": 1,
+			"url": "http://example.com/podcast.rss",
+			"title": "Mock Podcast",
+			"description": "A mock podcast",
+			"coverArt": 500,
+			"status": "completed",
+			"episode": [{
+				"id": "34",
+				"streamId": "523",
+				"channelId": "1",
+				"title": "Episode 1",
+				"description": "The first episode",
+				"publishDate": "2014-03-15T21:22:31.468Z",
+				"status": "completed",
+				"duration": 3146,
+				"bitRate": 128,
+				"size": 50345216,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg"
+			},
+			{
+				"id": "35",
+				"channelId": "1",
+				"title": 311,
+				"description": "The second episode",
+				"publishDate": "2014-03-22T21:22:31.468Z",
+				"status": "new"
+			}]
+		},
+		{
+			"id": 2,
+			"url": "http://example.com/broken.rss",
+			"title": "Broken Podcast",
+			"status": "error",
+			"errorMessage": "Failed to fetch feed"
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"jukeboxControl", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"jukeboxPlaylist": {
+			"currentIndex": 0,
+			"playing": true,
+			"gain": 0.75,
+			"position": 42,
+			"entry": [{
+				"id": 406,
+				"parent": 405,
+				"title": "Wonderland",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"bitRate": 320,
+				"track": 1,
+				"size": 8577837,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"isVideo": false,
+				"path": "Adventure/2008 - Adventure/01 - Wonderland.mp3",
+				"type": "music"
+			},
+			{
+				"id": 407,
+				"parent": 405,
+				"title": "Another Day",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 187,
+				"bitRate": 320,
+				"track": 2,
+				"size": 7502931,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"isVideo": false,
+				"path": "Adventure/2008 - Adventure/02 - Another Day.mp3",
+				"type": "music"
+			}]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getInternetRadioStations", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"internetRadioStations": {"internetRadioStation": [{
+			"id": "1",
+			"name": "Mock Radio",
+			"streamUrl": "http://radio.example.com/stream",
+			"homePageUrl": "http://radio.example.com/"
+		},
+		{
+			"id": 2,
+			"name": 311,
+			"streamUrl": "http://311.example.com/stream"
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"getChatMessages", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"chatMessages": {"chatMessage": [{
+			"username": "mock",
+			"time": 1395014311000,
+			"message": "Hello"
+		},
+		{
+			"username": "admin",
+			"time": 1395014371000,
+			"message": 311
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"getUser", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"user": {
+			"username": "mock",
+			"email": "mock@example.com",
+			"scrobblingEnabled": true,
+			"maxBitRate": 320,
+			"adminRole": false,
+			"settingsRole": true,
+			"downloadRole": true,
+			"uploadRole": false,
+			"playlistRole": true,
+			"coverArtRole": false,
+			"commentRole": false,
+			"podcastRole": false,
+			"streamRole": true,
+			"jukeboxRole": true,
+			"shareRole": true,
+			"videoConversionRole": false,
+			"avatarLastChanged": "2014-03-15T21:22:31.468Z",
+			"folder": [0, 1]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getUsers", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"users": {"user": [{
+			"username": "admin",
+			"email": "admin@example.com",
+			"adminRole": true,
+			"streamRole": true,
+			"folder": 0
+		},
+		{
+			"username": 311,
+			"streamRole": false
+		}]},
+		"version": "1.9.0"
+	}}`)},
+	{"changePassword", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)},
+	{"getLyrics", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"lyrics": {
+			"artist": 311,
+			"title": "Amber",
+			"value": "Whoa, now you&#39;re amber\nStay this way"
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getBookmarks", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"bookmarks": {"bookmark": {
+			"position": 90500,
+			"username": "mock",
+			"comment": 311,
+			"created": "2014-03-15T21:22:31.468Z",
+			"changed": "2014-03-16T10:00:00.000Z",
+			"entry": {
+				"id": 1,
+				"parent": 405,
+				"title": "Mock Song",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			}
+		}},
+		"version": "1.9.0"
+	}}`)},
+	{"getPlayQueue", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"playQueue": {
+			"current": 2,
+			"position": 30000,
+			"username": "mock",
+			"changed": "2014-03-16T10:00:00.000Z",
+			"changedBy": "gosubsonic",
+			"entry": [{
+				"id": 1,
+				"parent": 405,
+				"title": "Mock Song",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			},
+			{
+				"id": 2,
+				"parent": 405,
+				"title": "Second Song",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			}]
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"savePlayQueue", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)},
+	{"getScanStatus", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"scanStatus": {
+			"scanning": true,
+			"count": 4271
+		},
+		"version": "1.9.0"
+	}}`)},
 	{"scrobble", []byte(`{"subsonic-response": {
 		"status": "ok",
 		"xmlns": "http://subsonic.org/restapi",
 		"version": "1.9.0"
 	}}`)},
+	{"star", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)},
+	{"setRating", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"version": "1.9.0"
+	}}`)},
+	{"getStarred", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"starred": {
+			"artist": [{
+				"id": 1,
+				"name": "Adventure"
+			},
+			{
+				"id": 2,
+				"name": 311
+			}],
+			"song": {
+				"id": 1,
+				"parent": 405,
+				"title": "Mock Song",
+				"album": "Adventure",
+				"artist": "Adventure",
+				"isDir": false,
+				"created": "2013-08-12T00:12:24",
+				"duration": 214,
+				"suffix": "mp3",
+				"contentType": "audio/mpeg",
+				"type": "music"
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getStarred2", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"starred2": {
+			"album": {
+				"id": 1,
+				"name": "Adventure",
+				"artist": "Adventure",
+				"artistId": 1,
+				"coverArt": 405,
+				"songCount": 2,
+				"duration": 401,
+				"created": "2013-08-12T00:12:24"
+			}
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"getSimilarSongs", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"similarSongs": {"song": {
+			"id": 406,
+			"parent": 405,
+			"title": "Wonderland",
+			"album": "Adventure",
+			"artist": true,
+			"isDir": false,
+			"duration": 214,
+			"suffix": "mp3",
+			"path": "Adventure/Adventure &amp; Friends/01 - Wonderland.mp3"
+		}},
+		"version": "1.9.0"
+	}}`)},
+	{"createPlaylist", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"playlist": {
+			"id": 3,
+			"name": "New Playlist",
+			"owner": "mock",
+			"public": false,
+			"songCount": 1,
+			"duration": 214,
+			"created": "2014-03-20T21:55:32.000Z",
+			"changed": "2014-03-20T21:55:32.000Z",
+			"entry": [{
+				"id": 406,
+				"title": "Wonderland",
+				"isDir": false,
+				"duration": 214
+			}]
+		},
+		"version": "1.14.0"
+	}}`)},
+	{"startScan", []byte(`{"subsonic-response": {
+		"status": "ok",
+		"xmlns": "http://subsonic.org/restapi",
+		"scanStatus": {
+			"scanning": true,
+			"count": 0
+		},
+		"version": "1.9.0"
+	}}`)},
+	{"deletePlaylist", mockEmptyResponse},
+	{"unstar", mockEmptyResponse},
+	{"deleteShare", mockEmptyResponse},
+	{"refreshPodcasts", mockEmptyResponse},
+	{"createPodcastChannel", mockEmptyResponse},
+	{"deletePodcastChannel", mockEmptyResponse},
+	{"deletePodcastEpisode", mockEmptyResponse},
+	{"downloadPodcastEpisode", mockEmptyResponse},
+	{"createInternetRadioStation", mockEmptyResponse},
+	{"updateInternetRadioStation", mockEmptyResponse},
+	{"deleteInternetRadioStation", mockEmptyResponse},
+	{"addChatMessage", mockEmptyResponse},
+	{"createUser", mockEmptyResponse},
+	{"updateUser", mockEmptyResponse},
+	{"deleteUser", mockEmptyResponse},
+	{"createBookmark", mockEmptyResponse},
+	{"deleteBookmark", mockEmptyResponse},
 }
 
-// mockInit generates the mock data map, so we can test gosubsonic against known, static data
-func mockInit(s Client) error {
-	// Initialize map
-	mockData = map[string][]byte{}
+// mockParams maps a method to the parameters which must be sent to receive its mock data
+// from the mockTable.  Methods which are not listed must be sent no parameters.
+var mockParams = map[string]string{
+	"getMusicDirectory": "id=1",
+	"getArtist":         "id=1",
+	"getAlbum":          "id=1",
+	"getSong":           "id=1",
+	"getVideoInfo":      "id=460",
+	"getArtistInfo":     "id=1",
+	"getArtistInfo2":    "id=1",
+	"getAlbumInfo":      "id=1",
+	"getSimilarSongs2":  "id=1",
+	"getTopSongs":       "artist=Adventure&count=5",
+	"getAlbumList":      "type=newest",
+	"getAlbumList2":     "type=newest",
+	"getSongsByGenre":   "genre=Electronic",
+	"search2":           "query=mock",
+	"search3":           "query=mock",
+	"getPlaylist":       "id=1",
+	"updatePlaylist":    "playlistId=1&songIdToAdd=407&songIndexToRemove=0",
+	"createShare":       "id=1",
+	"updateShare":       "id=12&description=Updated&expires=0",
+	"getPodcasts":       "includeEpisodes=true",
+	"jukeboxControl":    "action=get",
+	"star":              "id=1&albumId=2&artistId=3",
+	"setRating":         "id=1&rating=5",
+	"getLyrics":         "artist=311&title=Amber",
+	"getUser":           "username=mock",
+	"changePassword":    "username=mock&password=enc:6d6f636b32",
+	"savePlayQueue":     "id=1&id=2&current=2&position=30000",
+	"scrobble":          "id=1&submission=false",
+	"getSimilarSongs":   "id=1&count=5",
+	"createPlaylist":    "name=New Playlist&songId=406",
+	"deletePlaylist":    "id=3",
+	"unstar":            "id=1&albumId=2&artistId=3",
+	"deleteShare":       "id=12",
 
-	// Populate map using this client's URLs
-	for _, entry := range mockTable {
-		// Extra options
-		optStr := ""
+	"createPodcastChannel":   "url=http://mock.example.com/feed.xml",
+	"deletePodcastChannel":   "id=1",
+	"deletePodcastEpisode":   "id=34",
+	"downloadPodcastEpisode": "id=34",
 
-		// getMusicDirectory - add mock ID
-		if entry.method == "getMusicDirectory" {
-			optStr = optStr + "&id=1"
-		}
+	"createInternetRadioStation": "streamUrl=http://mock.example.com/stream&name=Mock Radio&homepageUrl=http://mock.example.com",
+	"updateInternetRadioStation": "id=1&streamUrl=http://mock.example.com/stream&name=Mock Radio",
+	"deleteInternetRadioStation": "id=1",
 
-		// scrobble - add mock ID and submission
-		if entry.method == "scrobble" {
-			optStr = optStr + "&id=1&submission=false"
-		}
+	"addChatMessage": "message=Hello",
+	"createUser":     "username=guest&email=guest@example.com&password=enc:6775657374&" + mockUserRoles,
+	"updateUser":     "username=guest&email=guest@example.com&streamRole=true",
+	"deleteUser":     "username=guest",
+	"createBookmark": "id=406&position=30000&comment=Mock",
+	"deleteBookmark": "id=406",
+}
 
-		mockData[s.makeURL(entry.method)+optStr] = entry.data
-	}
+// mockUserRoles are the settings and roles sent for the mock user "guest", who may only stream
+const mockUserRoles = "scrobblingEnabled=false&ldapAuthenticated=false&adminRole=false&settingsRole=false" +
+	"&downloadRole=false&uploadRole=false&playlistRole=false&coverArtRole=false&commentRole=false" +
+	"&podcastRole=false&streamRole=true&jukeboxRole=false&shareRole=false&videoConversionRole=false"
 
-	return nil
+// mockMedia maps a media ID to the binary data served for it by the stream, download, and
+// getCoverArt methods
+var mockMedia = map[ID]fakeMedia{
+	"1":   {"audio/mpeg", []byte("ID3mock audio data")},
+	"405": {"image/jpeg", []byte("\xff\xd8\xffmock cover art")},
 }
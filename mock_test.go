@@ -0,0 +1,260 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestMockFixtures verifies that every mock fixture is served for its mock parameters, and
+// that the methods without other tests succeed against their fixtures
+func TestMockFixtures(t *testing.T) {
+	log.Println("TestMockFixtures()")
+
+	// Parameters are parsed when the fake server is created, ignoring errors
+	for method, params := range mockParams {
+		if _, err := url.ParseQuery(params); err != nil {
+			t.Fatalf("mockParams: invalid parameters for %s: %s", method, err.Error())
+		}
+
+		var found bool
+		for _, entry := range mockTable {
+			found = found || entry.method == method
+		}
+		if !found {
+			t.Fatalf("mockParams: no fixture for %s", method)
+		}
+	}
+
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+	ctx := context.Background()
+
+	guest := User{Username: "guest", Email: "guest@example.com", StreamRole: true}
+	radio := "http://mock.example.com/stream"
+	star := StarOptions{IDs: []ID{"1"}, AlbumIDs: []ID{"2"}, ArtistIDs: []ID{"3"}}
+
+	var tests = []struct {
+		method string
+		call   func() error
+	}{
+		{"getSimilarSongs", func() error {
+			songs, err := s.GetSimilarSongs(ctx, "1", 5)
+			if err == nil && (len(songs) != 1 || songs[0].Artist != "True" || songs[0].Path != "Adventure/Adventure & Friends/01 - Wonderland.mp3") {
+				return fmt.Errorf("unexpected songs: %+v", songs)
+			}
+			return err
+		}},
+		{"createPlaylist", func() error {
+			p, err := s.CreatePlaylist(ctx, "New Playlist", []ID{"406"})
+			if err == nil && (p == nil || p.ID != "3" || len(p.Entry) != 1) {
+				return fmt.Errorf("unexpected playlist: %+v", p)
+			}
+			return err
+		}},
+		{"startScan", func() error {
+			status, err := s.StartScan(ctx)
+			if err == nil && !status.Scanning {
+				return fmt.Errorf("unexpected status: %+v", status)
+			}
+			return err
+		}},
+		{"deletePlaylist", func() error { return s.DeletePlaylist(ctx, "3") }},
+		{"unstar", func() error { return s.Unstar(ctx, star) }},
+		{"deleteShare", func() error { return s.DeleteShare(ctx, "12") }},
+		{"refreshPodcasts", func() error { return s.RefreshPodcasts(ctx) }},
+		{"createPodcastChannel", func() error { return s.CreatePodcastChannel(ctx, "http://mock.example.com/feed.xml") }},
+		{"deletePodcastChannel", func() error { return s.DeletePodcastChannel(ctx, "1") }},
+		{"deletePodcastEpisode", func() error { return s.DeletePodcastEpisode(ctx, "34") }},
+		{"downloadPodcastEpisode", func() error { return s.DownloadPodcastEpisode(ctx, "34") }},
+		{"createInternetRadioStation", func() error {
+			return s.CreateInternetRadioStation(ctx, radio, "Mock Radio", "http://mock.example.com")
+		}},
+		{"updateInternetRadioStation", func() error { return s.UpdateInternetRadioStation(ctx, "1", radio, "Mock Radio", "") }},
+		{"deleteInternetRadioStation", func() error { return s.DeleteInternetRadioStation(ctx, "1") }},
+		{"addChatMessage", func() error { return s.AddChatMessage(ctx, "Hello") }},
+		{"createUser", func() error { return s.CreateUser(ctx, guest, "guest") }},
+		{"updateUser", func() error {
+			return s.UpdateUser(ctx, "guest", UpdateUserOptions{Email: "guest@example.com", StreamRole: &guest.StreamRole})
+		}},
+		{"deleteUser", func() error { return s.DeleteUser(ctx, "guest") }},
+		{"createBookmark", func() error { return s.CreateBookmark(ctx, "406", 30*time.Second, "Mock") }},
+		{"deleteBookmark", func() error { return s.DeleteBookmark(ctx, "406") }},
+		{"stream", func() error {
+			stream, err := s.Stream(ctx, "1", nil)
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+
+			b, err := io.ReadAll(stream)
+			if err == nil && (string(b) != "ID3mock audio data" || stream.ContentType != "audio/mpeg") {
+				return fmt.Errorf("unexpected stream: %q, %s", b, stream.ContentType)
+			}
+			return err
+		}},
+		{"getCoverArt", func() error {
+			body, err := s.GetCoverArt(ctx, "405", 0)
+			if err != nil {
+				return err
+			}
+			return body.Close()
+		}},
+	}
+
+	for _, test := range tests {
+		if err := test.call(); err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.method, err.Error())
+		}
+	}
+}
+
+// TestPathologicalResponses verifies that responses with the unusual shapes returned by real
+// servers are parsed, and that error responses are reported
+func TestPathologicalResponses(t *testing.T) {
+	log.Println("TestPathologicalResponses()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+	ctx := context.Background()
+
+	response := func(body string) []byte {
+		return []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", ` + body + `}}`)
+	}
+
+	var tests = []struct {
+		description string
+		method      string
+		body        []byte
+		check       func() error
+	}{
+		{
+			description: "single child instead of array",
+			method:      "getMusicDirectory",
+			body:        response(`"directory": {"id": 1, "name": "Mock", "child": {"id": 406, "title": "Wonderland", "isDir": false}}`),
+			check: func() error {
+				c, err := s.GetMusicDirectory(ctx, "1")
+				if err == nil && (len(c.Audio) != 1 || c.Audio[0].ID != "406") {
+					return fmt.Errorf("unexpected content: %+v", c)
+				}
+				return err
+			},
+		},
+		{
+			description: "directory without children",
+			method:      "getMusicDirectory",
+			body:        response(`"directory": {"id": 1, "name": "Mock"}`),
+			check: func() error {
+				c, err := s.GetMusicDirectory(ctx, "1")
+				if err == nil && len(c.Children) != 0 {
+					return fmt.Errorf("unexpected content: %+v", c)
+				}
+				return err
+			},
+		},
+		{
+			description: "numeric and boolean artist names",
+			method:      "getIndexes",
+			body:        response(`"indexes": {"index": {"name": "#", "artist": [{"id": 1, "name": 311}, {"id": 2, "name": false}]}}`),
+			check: func() error {
+				i, err := s.GetIndexes(ctx, -1, time.Time{})
+				if err != nil {
+					return err
+				}
+				if len(i.Index) != 1 || len(i.Index[0].Artist) != 2 || i.Index[0].Artist[0].Name != "311" || i.Index[0].Artist[1].Name != "False" {
+					return fmt.Errorf("unexpected indexes: %+v", i)
+				}
+				return nil
+			},
+		},
+		{
+			description: "missing optional fields",
+			method:      "getSong",
+			body:        response(`"song": {"id": 1}`),
+			check: func() error {
+				a, err := s.GetSong(ctx, "1")
+				if err == nil && (a.ID != "1" || a.Title != "" || !a.Created.IsZero() || a.Duration != 0 || a.ReplayGain != nil) {
+					return fmt.Errorf("unexpected song: %+v", a)
+				}
+				return err
+			},
+		},
+		{
+			description: "empty now playing string",
+			method:      "getNowPlaying",
+			body:        response(`"nowPlaying": ""`),
+			check: func() error {
+				entries, err := s.GetNowPlaying(ctx)
+				if err == nil && len(entries) != 0 {
+					return fmt.Errorf("unexpected entries: %+v", entries)
+				}
+				return err
+			},
+		},
+		{
+			description: "empty playlists string",
+			method:      "getPlaylists",
+			body:        response(`"playlists": ""`),
+			check: func() error {
+				playlists, err := s.GetPlaylists(ctx, "")
+				if err == nil && len(playlists) != 0 {
+					return fmt.Errorf("unexpected playlists: %+v", playlists)
+				}
+				return err
+			},
+		},
+		{
+			description: "HTML-escaped path and title",
+			method:      "getSong",
+			body:        response(`"song": {"id": 1, "title": "Rock &amp; Roll", "path": "AC&#47;DC/Rock &amp; Roll.mp3"}`),
+			check: func() error {
+				a, err := s.GetSong(ctx, "1")
+				if err == nil && (a.Title != "Rock & Roll" || a.Path != "AC/DC/Rock & Roll.mp3") {
+					return fmt.Errorf("unexpected song: %+v", a)
+				}
+				return err
+			},
+		},
+		{
+			description: "error response",
+			method:      "getSong",
+			body:        []byte(`{"subsonic-response": {"status": "failed", "version": "1.9.0", "error": {"code": 0, "message": "Database locked"}}}`),
+			check: func() error {
+				_, err := s.GetSong(ctx, "1")
+				if apiErr, ok := err.(*Error); !ok || apiErr.Code != ErrCodeGeneric || apiErr.Message != "Database locked" {
+					return fmt.Errorf("unexpected error: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			description: "error response with numeric message",
+			method:      "getAlbum",
+			body:        []byte(`{"subsonic-response": {"status": "failed", "version": "1.9.0", "error": {"code": 50, "message": 50}}}`),
+			check: func() error {
+				if _, err := s.GetAlbum(ctx, "1"); !IsNotAuthorized(err) {
+					return fmt.Errorf("unexpected error: %v", err)
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		f.SetFixture(test.method, nil, test.body)
+		if err := test.check(); err != nil {
+			t.Fatalf("%s: %s", test.description, err.Error())
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+)
+
+// Artist is an artist organized by ID3 tags, which can navigate to its albums without
+// threading IDs between calls.  Artists are created by Client.Artist and Client.Artists.
+//
+// The CoverArt method shadows the embedded cover art ID, which remains available as
+// ArtistID3.CoverArt.  The same applies to Album and Song.
+type Artist struct {
+	ArtistID3
+
+	client Client
+}
+
+// Album is an album organized by ID3 tags, which can navigate to its songs without
+// threading IDs between calls.  Albums are created by Client.Album and Artist.Albums.
+type Album struct {
+	AlbumID3
+
+	client Client
+}
+
+// Song is a song which can be streamed, downloaded, or scrobbled without threading IDs
+// between calls.  Songs are created by Client.Song and Album.Songs.
+type Song struct {
+	Audio
+
+	client Client
+}
+
+// Artists returns all artists in the library, organized by ID3 tags, in index order.  If
+// folderID is set (>= 0), only artists in that music folder are returned.
+func (s Client) Artists(ctx context.Context, folderID int64) ([]*Artist, error) {
+	indexes, err := s.GetArtists(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var artists []*Artist
+	for _, i := range indexes {
+		for _, a := range i.Artist {
+			artists = append(artists, &Artist{a, s})
+		}
+	}
+
+	return artists, nil
+}
+
+// Artist returns an artist, including its albums
+func (s Client) Artist(ctx context.Context, id ID) (*Artist, error) {
+	artist, err := s.GetArtist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Artist{*artist, s}, nil
+}
+
+// Album returns an album, including its songs
+func (s Client) Album(ctx context.Context, id ID) (*Album, error) {
+	album, err := s.GetAlbum(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Album{*album, s}, nil
+}
+
+// Song returns a song
+func (s Client) Song(ctx context.Context, id ID) (*Song, error) {
+	song, err := s.GetSong(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Song{*song, s}, nil
+}
+
+// Albums returns the artist's albums.  Albums which were not returned with the artist,
+// such as for artists from Client.Artists, are fetched from the server.
+func (a *Artist) Albums(ctx context.Context) ([]*Album, error) {
+	albums := a.Album
+	if albums == nil {
+		artist, err := a.client.GetArtist(ctx, a.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		albums = artist.Album
+	}
+
+	out := make([]*Album, len(albums))
+	for i := range albums {
+		out[i] = &Album{albums[i], a.client}
+	}
+
+	return out, nil
+}
+
+// CoverArt returns the artist's cover art image, with an optional size
+func (a *Artist) CoverArt(ctx context.Context, size int64) (io.ReadCloser, error) {
+	return a.client.GetCoverArt(ctx, a.ArtistID3.CoverArt, size)
+}
+
+// Songs returns the album's songs.  Songs which were not returned with the album, such as
+// for albums from Artist.Albums, are fetched from the server.
+func (a *Album) Songs(ctx context.Context) ([]*Song, error) {
+	songs := a.Song
+	if songs == nil {
+		album, err := a.client.GetAlbum(ctx, a.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		songs = album.Song
+	}
+
+	out := make([]*Song, len(songs))
+	for i := range songs {
+		out[i] = &Song{songs[i], a.client}
+	}
+
+	return out, nil
+}
+
+// CoverArt returns the album's cover art image, with an optional size
+func (a *Album) CoverArt(ctx context.Context, size int64) (io.ReadCloser, error) {
+	return a.client.GetCoverArt(ctx, a.AlbumID3.CoverArt, size)
+}
+
+// Stream returns a stream of the song, with an optional StreamOptions struct
+func (s *Song) Stream(ctx context.Context, options *StreamOptions) (*StreamResponse, error) {
+	return s.client.Stream(ctx, s.ID, options)
+}
+
+// Download returns the song's original media file
+func (s *Song) Download(ctx context.Context) (io.ReadCloser, error) {
+	return s.client.Download(ctx, s.ID)
+}
+
+// CoverArt returns the song's cover art image, with an optional size
+func (s *Song) CoverArt(ctx context.Context, size int64) (io.ReadCloser, error) {
+	return s.client.GetCoverArt(ctx, s.Audio.CoverArt, size)
+}
+
+// NewSession creates a new Session which tracks playback of the song, with an optional
+// SessionOptions struct
+func (s *Song) NewSession(options *SessionOptions) *Session {
+	return s.client.NewSession(s.ID, s.Duration, options)
+}
@@ -0,0 +1,80 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+)
+
+// TestObjectModel verifies that artists, albums, and songs can be navigated lazily
+func TestObjectModel(t *testing.T) {
+	log.Println("TestObjectModel()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetMedia("405", "image/jpeg", []byte("cover"))
+	f.SetMedia("406", "audio/mpeg", []byte("0123456789"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	artists, err := s.Artists(ctx, -1)
+	if err != nil {
+		t.Fatalf("Artists returned error: %s", err.Error())
+	}
+	if len(artists) != 3 || artists[1].ID != "1" || artists[1].Name != "Adventure" {
+		t.Fatalf("Artists returned invalid artists: %+v", artists)
+	}
+
+	// Albums are not returned by getArtists, so they are fetched
+	albums, err := artists[1].Albums(ctx)
+	if err != nil {
+		t.Fatalf("Albums returned error: %s", err.Error())
+	}
+	if len(albums) != 1 || albums[0].ID != "1" || f.Requests("getArtist") != 1 {
+		t.Fatalf("Albums returned invalid albums: %+v", albums)
+	}
+
+	// Songs are not returned by getArtist, so they are fetched
+	songs, err := albums[0].Songs(ctx)
+	if err != nil {
+		t.Fatalf("Songs returned error: %s", err.Error())
+	}
+	if len(songs) != 2 || songs[0].ID != "406" || songs[1].Title != "Another Day" {
+		t.Fatalf("Songs returned invalid songs: %+v", songs)
+	}
+
+	// Albums returned with an artist are not fetched again
+	artist, err := s.Artist(ctx, "1")
+	if err != nil {
+		t.Fatalf("Artist returned error: %s", err.Error())
+	}
+	if _, err := artist.Albums(ctx); err != nil || f.Requests("getArtist") != 2 {
+		t.Fatalf("Albums fetched albums returned with artist: %v", err)
+	}
+
+	stream, err := songs[0].Stream(ctx, nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	defer stream.Close()
+
+	if b, err := io.ReadAll(stream); err != nil || string(b) != "0123456789" {
+		t.Fatalf("Stream returned invalid data: %q, %v", b, err)
+	}
+
+	art, err := albums[0].CoverArt(ctx, 0)
+	if err != nil {
+		t.Fatalf("CoverArt returned error: %s", err.Error())
+	}
+	defer art.Close()
+
+	if b, err := io.ReadAll(art); err != nil || string(b) != "cover" {
+		t.Fatalf("CoverArt returned invalid data: %q, %v", b, err)
+	}
+}
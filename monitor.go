@@ -0,0 +1,286 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// monitorDefaultInterval is the default interval between samples taken by a Monitor
+const monitorDefaultInterval = 30 * time.Second
+
+// promLabelEscaper escapes the value of a label in the Prometheus text format
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Monitor periodically samples the state of a server using GetNowPlaying, GetScanStatus, and
+// GetUsers, and counts the requests made through its Interceptor, so a server may be graphed
+// and alerted on.  A Monitor is an http.Handler which serves its metrics in the Prometheus
+// text format, and an expvar.Var which may be published using expvar.Publish.  A Monitor is
+// safe for concurrent use.
+type Monitor struct {
+	// Interval is the interval between samples taken by Run, default 30 seconds
+	Interval time.Duration
+
+	// Errors is called when a sample fails, if set.  Failed samples do not change the
+	// values reported for their metrics.
+	Errors func(error)
+
+	client Client
+
+	mu          sync.Mutex
+	stats       MonitorStats
+	requests    map[string]uint64
+	failures    map[string]uint64
+	usersDenied bool
+}
+
+// MonitorStats is a snapshot of the metrics collected by a Monitor
+type MonitorStats struct {
+	// Time is the time of the last sample, or zero if none was taken
+	Time time.Time
+
+	// Up reports whether the server responded to any request in the last sample
+	Up bool
+
+	// Streams is the number of entries currently being played
+	Streams int
+
+	// UsersOnline is the number of distinct users currently playing media
+	UsersOnline int
+
+	// Users is the total number of users, or -1 if unknown, such as when the monitoring user
+	// is not an administrator
+	Users int
+
+	// Scanning reports whether a media library scan is in progress, and ScanCount is the
+	// number of items scanned
+	Scanning  bool
+	ScanCount int64
+
+	// Requests and Failures count the requests made through the Monitor's Interceptor, and
+	// those which failed, by API method
+	Requests map[string]uint64
+	Failures map[string]uint64
+}
+
+// NewMonitor creates a new Monitor which samples at the specified interval.  An interval of
+// 0 uses the default interval.  Requests made by the Monitor itself are counted.
+func (s Client) NewMonitor(interval time.Duration) *Monitor {
+	m := &Monitor{
+		Interval: interval,
+		stats:    MonitorStats{Users: -1},
+		requests: make(map[string]uint64),
+		failures: make(map[string]uint64),
+	}
+
+	s.Interceptors = append(append([]Interceptor(nil), s.Interceptors...), m.Interceptor())
+	m.client = s
+
+	return m
+}
+
+// Interceptor returns an Interceptor which counts requests and failures by API method, which
+// may be added to other clients using WithInterceptors so their requests are counted too.
+// Requests canceled by their callers are not counted as failures.
+func (m *Monitor) Interceptor() Interceptor {
+	return func(ctx context.Context, req *Request, next Invoker) error {
+		err := next(ctx, req)
+
+		m.mu.Lock()
+		m.requests[req.Method]++
+		if err != nil && ctx.Err() == nil {
+			m.failures[req.Method]++
+		}
+		m.mu.Unlock()
+
+		return err
+	}
+}
+
+// Sample samples the state of the server once.  Each source is sampled even if another fails,
+// and the first error is returned, after it is reported to Errors.  Users are only sampled
+// until the server reports that the monitoring user is not authorized to list them.
+func (m *Monitor) Sample(ctx context.Context) error {
+	var firstErr error
+	var up bool
+	fail := func(err error) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Errors from the server show it is up
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			up = true
+		}
+
+		if m.Errors != nil {
+			m.Errors(err)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	m.mu.Lock()
+	stats, usersDenied := m.stats, m.usersDenied
+	m.mu.Unlock()
+
+	if entries, err := m.client.GetNowPlaying(ctx); err != nil {
+		fail(err)
+	} else {
+		up = true
+
+		users := make(map[string]bool, len(entries))
+		for _, e := range entries {
+			users[e.Username] = true
+		}
+
+		stats.Streams = len(entries)
+		stats.UsersOnline = len(users)
+	}
+
+	if status, err := m.client.GetScanStatus(ctx); err != nil {
+		fail(err)
+	} else {
+		up = true
+		stats.Scanning = status.Scanning
+		stats.ScanCount = status.Count
+	}
+
+	// Most users may not list users, so stop asking once the server says so
+	if !usersDenied {
+		users, err := m.client.GetUsers(ctx)
+		switch {
+		case IsNotAuthorized(err):
+			up = true
+			usersDenied = true
+			stats.Users = -1
+		case err != nil:
+			fail(err)
+		default:
+			up = true
+			stats.Users = len(users)
+		}
+	}
+
+	// Canceled samples are discarded, rather than reporting the server as down
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stats.Time = time.Now()
+	stats.Up = up
+
+	m.mu.Lock()
+	m.stats, m.usersDenied = stats, usersDenied
+	m.mu.Unlock()
+
+	return firstErr
+}
+
+// Run samples the server immediately, and then at each interval, until ctx is canceled
+func (m *Monitor) Run(ctx context.Context) {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = monitorDefaultInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		m.Sample(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Stats returns a snapshot of the Monitor's metrics
+func (m *Monitor) Stats() MonitorStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Requests = make(map[string]uint64, len(m.requests))
+	for k, v := range m.requests {
+		stats.Requests[k] = v
+	}
+	stats.Failures = make(map[string]uint64, len(m.failures))
+	for k, v := range m.failures {
+		stats.Failures[k] = v
+	}
+
+	return stats
+}
+
+// String implements expvar.Var, returning the Monitor's metrics as JSON
+func (m *Monitor) String() string {
+	b, err := json.Marshal(m.Stats())
+	if err != nil {
+		return "{}"
+	}
+
+	return string(b)
+}
+
+// ServeHTTP serves the Monitor's metrics in the Prometheus text format
+func (m *Monitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.Stats().writePrometheus(w)
+}
+
+// writePrometheus writes metrics in the Prometheus text format.  Gauges for the server's
+// state are omitted until they are first sampled.
+func (st MonitorStats) writePrometheus(w io.Writer) {
+	gauge := func(name string, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+	boolValue := func(b bool) int {
+		if b {
+			return 1
+		}
+
+		return 0
+	}
+
+	if !st.Time.IsZero() {
+		gauge("subsonic_up", "Whether the server responded to the last sample.", boolValue(st.Up))
+		gauge("subsonic_last_sample_timestamp_seconds", "Time of the last sample.", st.Time.Unix())
+		gauge("subsonic_streams", "Number of entries currently being played.", st.Streams)
+		gauge("subsonic_users_online", "Number of distinct users currently playing media.", st.UsersOnline)
+		if st.Users >= 0 {
+			gauge("subsonic_users", "Total number of users.", st.Users)
+		}
+		gauge("subsonic_scan_in_progress", "Whether a media library scan is in progress.", boolValue(st.Scanning))
+		gauge("subsonic_scan_items", "Number of items scanned by the current or last scan.", st.ScanCount)
+	}
+
+	counter := func(name string, help string, values map[string]uint64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+		methods := make([]string, 0, len(values))
+		for k := range values {
+			methods = append(methods, k)
+		}
+		sort.Strings(methods)
+
+		for _, k := range methods {
+			fmt.Fprintf(w, "%s{method=\"%s\"} %d\n", name, promLabelEscaper.Replace(k), values[k])
+		}
+	}
+
+	counter("subsonic_api_requests_total", "Number of API requests, by method.", st.Requests)
+	counter("subsonic_api_failures_total", "Number of failed API requests, by method.", st.Failures)
+}
@@ -0,0 +1,104 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestMonitor verifies that Monitor samples the server's state, counts requests and failures,
+// and exports its metrics in the Prometheus text format and as JSON
+func TestMonitor(t *testing.T) {
+	log.Println("TestMonitor()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+	ctx := context.Background()
+
+	m := s.NewMonitor(0)
+
+	// Metrics are only reported once sampled
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "subsonic_up") {
+		t.Fatalf("Monitor reported state before sampling:\n%s", rec.Body.String())
+	}
+
+	if err := m.Sample(ctx); err != nil {
+		t.Fatalf("Sample returned error: %s", err.Error())
+	}
+
+	// The mock server has two entries playing for two users, two users in total, and a scan
+	// in progress
+	stats := m.Stats()
+	if !stats.Up || stats.Streams != 2 || stats.UsersOnline != 2 || stats.Users != 2 || !stats.Scanning || stats.ScanCount != 4271 {
+		t.Fatalf("Monitor returned unexpected stats: %+v", stats)
+	}
+
+	// Users are no longer sampled once the server refuses, and other failures are reported
+	// and counted
+	f.SetError("getUsers", ErrCodeNotAuthorized, "Not authorized")
+	f.SetError("getScanStatus", ErrCodeGeneric, "Scan failed")
+
+	var reported []error
+	m.Errors = func(err error) {
+		reported = append(reported, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := m.Sample(ctx); err == nil {
+			t.Fatalf("Sample returned no error for failed scan status")
+		}
+	}
+	if len(reported) != 2 || f.Requests("getUsers") != 2 {
+		t.Fatalf("Monitor reported %d errors, and made %d getUsers requests", len(reported), f.Requests("getUsers"))
+	}
+
+	// Failed samples keep the previous values, and the server is still up
+	stats = m.Stats()
+	if !stats.Up || stats.Users != -1 || stats.ScanCount != 4271 {
+		t.Fatalf("Monitor returned unexpected stats after failures: %+v", stats)
+	}
+	if stats.Requests["getScanStatus"] != 3 || stats.Failures["getScanStatus"] != 2 || stats.Failures["getNowPlaying"] != 0 {
+		t.Fatalf("Monitor counted unexpected requests: %v, failures: %v", stats.Requests, stats.Failures)
+	}
+
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE subsonic_up gauge",
+		"subsonic_up 1",
+		"subsonic_streams 2",
+		"subsonic_users_online 2",
+		"subsonic_scan_in_progress 1",
+		"subsonic_scan_items 4271",
+		"# TYPE subsonic_api_requests_total counter",
+		`subsonic_api_requests_total{method="getScanStatus"} 3`,
+		`subsonic_api_failures_total{method="getScanStatus"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("Monitor metrics missing %q:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "subsonic_users ") {
+		t.Fatalf("Monitor reported unknown user count:\n%s", body)
+	}
+
+	// Metrics are also available as JSON for expvar
+	var out MonitorStats
+	if err := json.Unmarshal([]byte(m.String()), &out); err != nil {
+		t.Fatalf("String returned invalid JSON: %s", err.Error())
+	}
+	if out.Streams != 2 || out.Requests["getNowPlaying"] != 3 {
+		t.Fatalf("String returned unexpected stats: %+v", out)
+	}
+}
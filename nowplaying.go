@@ -0,0 +1,156 @@
+package gosubsonic
+
+import (
+	"context"
+	"time"
+)
+
+// nowPlayingDefaultInterval is the default interval between polls of GetNowPlaying
+const nowPlayingDefaultInterval = 10 * time.Second
+
+// NowPlayingEventType is the type of a NowPlayingEvent
+type NowPlayingEventType int
+
+// Now playing event types
+const (
+	// NowPlayingStarted is sent when a user's player begins playing an entry
+	NowPlayingStarted NowPlayingEventType = iota
+
+	// NowPlayingStopped is sent when an entry is no longer playing
+	NowPlayingStopped
+)
+
+// String returns the name of a NowPlayingEventType
+func (t NowPlayingEventType) String() string {
+	switch t {
+	case NowPlayingStarted:
+		return "started"
+	case NowPlayingStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// NowPlayingEvent reports that an entry started or stopped playing
+type NowPlayingEvent struct {
+	Type  NowPlayingEventType
+	Entry NowPlaying
+}
+
+// NowPlayingWatcher polls GetNowPlaying, and reports when entries start or stop playing.
+// Entries are identified by their user, player, and ID, so duplicate entries are reported once.
+type NowPlayingWatcher struct {
+	// Interval is the interval between polls, default 10 seconds
+	Interval time.Duration
+
+	// Errors is called when a poll fails, if set.  Failed polls do not change the entries
+	// which are playing, so no events are sent for them.
+	Errors func(error)
+
+	client Client
+}
+
+// nowPlayingKey identifies an entry which is playing
+type nowPlayingKey struct {
+	username string
+	playerID int64
+	id       ID
+}
+
+// NewNowPlayingWatcher creates a new NowPlayingWatcher which polls at the specified interval.
+// An interval of 0 uses the default interval.
+func (s Client) NewNowPlayingWatcher(interval time.Duration) *NowPlayingWatcher {
+	return &NowPlayingWatcher{
+		Interval: interval,
+		client:   s,
+	}
+}
+
+// Watch begins polling in the background, and returns a channel of events.  Entries playing
+// at the first poll are reported as started.  For each poll, stopped entries are reported
+// before started entries.  The channel is closed once ctx is canceled.
+func (w *NowPlayingWatcher) Watch(ctx context.Context) <-chan NowPlayingEvent {
+	events := make(chan NowPlayingEvent)
+	go w.run(ctx, events)
+	return events
+}
+
+// run polls until ctx is canceled
+func (w *NowPlayingWatcher) run(ctx context.Context, events chan<- NowPlayingEvent) {
+	defer close(events)
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = nowPlayingDefaultInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	var playing []NowPlaying
+	for {
+		entries, err := w.client.GetNowPlaying(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			if w.Errors != nil {
+				w.Errors(err)
+			}
+		default:
+			var ok bool
+			if playing, ok = w.update(ctx, events, playing, entries); !ok {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// update sends events for the differences between the previous and current entries, and
+// returns the deduplicated current entries.  It returns false if ctx is canceled.
+func (w *NowPlayingWatcher) update(ctx context.Context, events chan<- NowPlayingEvent, previous []NowPlaying, entries []NowPlaying) ([]NowPlaying, bool) {
+	current := make(map[nowPlayingKey]bool, len(entries))
+	var playing []NowPlaying
+	for _, e := range entries {
+		k := nowPlayingKey{e.Username, e.PlayerID, e.ID}
+		if current[k] {
+			continue
+		}
+
+		current[k] = true
+		playing = append(playing, e)
+	}
+
+	var out []NowPlayingEvent
+	before := make(map[nowPlayingKey]bool, len(previous))
+	for _, e := range previous {
+		k := nowPlayingKey{e.Username, e.PlayerID, e.ID}
+		before[k] = true
+
+		if !current[k] {
+			out = append(out, NowPlayingEvent{Type: NowPlayingStopped, Entry: e})
+		}
+	}
+	for _, e := range playing {
+		if !before[nowPlayingKey{e.Username, e.PlayerID, e.ID}] {
+			out = append(out, NowPlayingEvent{Type: NowPlayingStarted, Entry: e})
+		}
+	}
+
+	for _, ev := range out {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+
+	return playing, true
+}
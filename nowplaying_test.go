@@ -0,0 +1,81 @@
+package gosubsonic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNowPlayingWatcher verifies that a NowPlayingWatcher reports entries as they start and stop
+func TestNowPlayingWatcher(t *testing.T) {
+	log.Println("TestNowPlayingWatcher()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	// setEntries serves a now playing response containing entries for each ID
+	setEntries := func(ids ...string) {
+		var entries []string
+		for _, id := range ids {
+			entries = append(entries, fmt.Sprintf(`{"id": %q, "title": "Song %s", "username": "alice", "playerId": 1}`, id, id))
+		}
+
+		f.SetFixture("getNowPlaying", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+			"nowPlaying": {"entry": [`+strings.Join(entries, ",")+`]}}}`))
+	}
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Duplicate entries are only reported once
+	setEntries("1", "1")
+	events := s.NewNowPlayingWatcher(10 * time.Millisecond).Watch(ctx)
+
+	// next returns the next event as a string, such as "started 1"
+	next := func() string {
+		select {
+		case ev := <-events:
+			return fmt.Sprintf("%s %s", ev.Type, ev.Entry.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("NowPlayingWatcher sent no event")
+			return ""
+		}
+	}
+
+	if ev := next(); ev != "started 1" {
+		t.Fatalf("NowPlayingWatcher sent invalid event: %s", ev)
+	}
+
+	// Failed polls do not stop entries
+	f.SetError("getNowPlaying", ErrCodeGeneric, "failed")
+	for f.Requests("getNowPlaying") < 4 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	f.ClearErrors()
+
+	setEntries("2")
+	if ev := next(); ev != "stopped 1" {
+		t.Fatalf("NowPlayingWatcher sent invalid event: %s", ev)
+	}
+	if ev := next(); ev != "started 2" {
+		t.Fatalf("NowPlayingWatcher sent invalid event: %s", ev)
+	}
+
+	setEntries()
+	if ev := next(); ev != "stopped 2" {
+		t.Fatalf("NowPlayingWatcher sent invalid event: %s", ev)
+	}
+
+	// The channel is closed once the context is canceled
+	cancel()
+	for range events {
+	}
+}
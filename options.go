@@ -0,0 +1,238 @@
+package gosubsonic
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Default timeouts for Clients created by New
+const (
+	// DefaultTimeout limits the duration of each API request, including any retries
+	DefaultTimeout = time.Minute
+
+	// DefaultConnectTimeout limits the time spent establishing a connection to the server
+	DefaultConnectTimeout = 10 * time.Second
+
+	// DefaultResponseHeaderTimeout limits the time spent waiting for the server to begin
+	// responding to a request, including binary requests such as streams
+	DefaultResponseHeaderTimeout = 30 * time.Second
+)
+
+// Option configures a Client created by New
+type Option func(*options)
+
+// options represents the configuration of a Client being created by New
+type options struct {
+	client     Client
+	httpClient *http.Client
+	source     DataSource
+	ping       bool
+
+	connectTimeout        time.Duration
+	responseHeaderTimeout time.Duration
+
+	uncompressed bool
+}
+
+// New creates a new Client for a Subsonic server, configured using zero or more Options.
+// Host is either a host with optional port, which is accessed over HTTP, or a full base URL.
+//
+// Unless WithoutPing is set, New verifies connectivity to the server, and selects a
+// compatibility profile for it.  The Client is returned along with any error from the ping,
+// so it may still be used once the server is reachable.
+func New(host string, opts ...Option) (*Client, error) {
+	o := options{
+		client: Client{Host: host, Timeout: DefaultTimeout},
+		ping:   true,
+
+		connectTimeout:        DefaultConnectTimeout,
+		responseHeaderTimeout: DefaultResponseHeaderTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	client := o.client
+	client.httpClient = o.httpClient
+	switch {
+	case client.httpClient != nil:
+	case o.connectTimeout == DefaultConnectTimeout && o.responseHeaderTimeout == DefaultResponseHeaderTimeout:
+		client.httpClient = sharedHTTPClient
+	default:
+		client.httpClient = newHTTPClient(o.connectTimeout, o.responseHeaderTimeout)
+	}
+
+	// Use HTTP as the data source, unless another is set
+	client.source = o.source
+	if client.source == nil {
+		client.source = httpDataSource{client: client.httpClient, uncompressed: o.uncompressed}
+	}
+
+	if !o.ping {
+		return &client, nil
+	}
+
+	// Attempt to ping the Subsonic server, and select a compatibility profile for it
+	status, err := client.Ping(context.Background())
+	if err == nil {
+		client.Profile = detectProfile(status)
+	}
+
+	return &client, err
+}
+
+// WithPassword sets the username and password used to authenticate with the server
+func WithPassword(username string, password string) Option {
+	return WithCredentials(username, Secret(password))
+}
+
+// WithCredentials sets the username used to authenticate with the server, and the Credentials
+// which supply its password
+func WithCredentials(username string, credentials Credentials) Option {
+	return func(o *options) {
+		o.client.Username = username
+		o.client.Credentials = credentials
+	}
+}
+
+// WithTokenAuth enables token authentication (Subsonic 1.13.0+), so the password is never
+// sent to the server
+func WithTokenAuth() Option {
+	return func(o *options) {
+		o.client.TokenAuth = true
+	}
+}
+
+// WithClientName sets the client name reported to the server, which identifies the
+// application in the server's logs and player list
+func WithClientName(name string) Option {
+	return func(o *options) {
+		o.client.ClientName = name
+	}
+}
+
+// WithAPIVersion sets the REST API version reported to the server, such as "1.8.0", which
+// may be lowered to request the behavior of older API versions
+func WithAPIVersion(version string) Option {
+	return func(o *options) {
+		o.client.APIVersion = version
+	}
+}
+
+// WithHTTPClient sets the *http.Client used to send all requests, which allows configuration
+// of proxies, TLS settings, and connection pooling.  If not set, a client which applies the
+// connect and response header timeouts is used, which is shared by every Client with the
+// default timeouts.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// maxIdleConnsPerHost is the number of idle connections kept open to each server, so
+// concurrent calls reuse connections instead of repeatedly opening new ones
+const maxIdleConnsPerHost = 32
+
+// sharedHTTPClient is used by every Client with the default timeouts, so their connections
+// to the same server are pooled together
+var sharedHTTPClient = newHTTPClient(DefaultConnectTimeout, DefaultResponseHeaderTimeout)
+
+// newHTTPClient creates the *http.Client used when none is set by WithHTTPClient, based on
+// http.DefaultTransport with the specified timeouts, where zero means no timeout
+func newHTTPClient(connectTimeout time.Duration, responseHeaderTimeout time.Duration) *http.Client {
+	t := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if dt, ok := http.DefaultTransport.(*http.Transport); ok {
+		t = dt.Clone()
+	}
+
+	t.DialContext = (&net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	t.ResponseHeaderTimeout = responseHeaderTimeout
+	t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	return &http.Client{Transport: t}
+}
+
+// WithConnectTimeout sets the maximum time spent establishing a connection to the server, or
+// 0 for no limit.  If not set, DefaultConnectTimeout is used.  It has no effect if
+// WithHTTPClient is set.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.connectTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout sets the maximum time spent waiting for the server to begin
+// responding to each request, including streams, or 0 for no limit.  If not set,
+// DefaultResponseHeaderTimeout is used.  It has no effect if WithHTTPClient is set.
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.responseHeaderTimeout = d
+	}
+}
+
+// WithTimeout sets the maximum duration of each API request, including any retries, or 0 for
+// no limit.  If not set, DefaultTimeout is used.  Binary requests, such as streams, are not
+// limited, so long media may be read to completion.  The timeout may be overridden for a
+// single call using WithCallTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.client.Timeout = d
+	}
+}
+
+// WithoutCompression disables compression of API responses, which are otherwise requested
+// using gzip or deflate and decompressed transparently.  Compression greatly reduces the size
+// of large responses, but may be disabled for servers or proxies which mishandle it.
+func WithoutCompression() Option {
+	return func(o *options) {
+		o.uncompressed = true
+	}
+}
+
+// WithMaxResponseSize sets the maximum size of each API response body in bytes, or a negative
+// size for no limit.  If not set, DefaultMaxResponseSize is used.
+func WithMaxResponseSize(n int64) Option {
+	return func(o *options) {
+		o.client.MaxResponseSize = n
+	}
+}
+
+// WithLogger logs each API call and binary request made by the client to logger, with the
+// specified redaction of request URLs
+func WithLogger(logger *slog.Logger, redaction LogRedaction) Option {
+	return func(o *options) {
+		o.client.Logger = logger
+		o.client.LogRedaction = redaction
+	}
+}
+
+// WithLenientParsing skips malformed items in lists, instead of failing the entire call.
+// Skipped items are reported to the function set by WithParseWarnings.
+func WithLenientParsing() Option {
+	return func(o *options) {
+		o.client.Lenient = true
+	}
+}
+
+// WithDataSource sets the DataSource which retrieves API responses, in place of HTTP.  If it
+// is a BinaryDataSource, it also retrieves binary responses, such as streams and cover art.
+func WithDataSource(source DataSource) Option {
+	return func(o *options) {
+		o.source = source
+	}
+}
+
+// WithoutPing skips the ping performed by New, so a Client may be created while the server
+// is unreachable, such as when starting offline.  No compatibility profile is selected, but
+// one may be set later using DetectProfile.
+func WithoutPing() Option {
+	return func(o *options) {
+		o.ping = false
+	}
+}
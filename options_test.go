@@ -0,0 +1,263 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNewOptions verifies that New applies each Option to the Client
+func TestNewOptions(t *testing.T) {
+	log.Println("TestNewOptions()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := New(f.URL,
+		WithPassword(fakeUsername, fakePassword),
+		WithTokenAuth(),
+		WithClientName("myplayer"),
+		WithAPIVersion("1.13.0"),
+	)
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	if s.Profile == nil || f.Requests("ping") != 1 {
+		t.Fatalf("New did not ping server: %+v", s.Profile)
+	}
+
+	u, err := url.Parse(s.makeURL("ping", nil))
+	if err != nil {
+		t.Fatalf("makeURL returned invalid URL: %s", err.Error())
+	}
+
+	q := u.Query()
+	if q.Get("c") != "myplayer" || q.Get("v") != "1.13.0" || q.Get("t") == "" || q.Get("p") != "" {
+		t.Fatalf("makeURL returned URL without options: %s", u)
+	}
+}
+
+// TestNewWithoutPing verifies that a Client may be created while the server is unreachable
+func TestNewWithoutPing(t *testing.T) {
+	log.Println("TestNewWithoutPing()")
+
+	// Nothing listens on port 1
+	if _, err := New("127.0.0.1:1", WithPassword("mock", "mock")); err == nil {
+		t.Fatalf("New returned no error for unreachable server")
+	}
+
+	s, err := New("127.0.0.1:1", WithPassword("mock", "mock"), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error without ping: %s", err.Error())
+	}
+
+	if s.Profile != nil {
+		t.Fatalf("New selected profile without ping: %+v", s.Profile)
+	}
+}
+
+// fakeDataSource is a DataSource which serves the same response to every request
+type fakeDataSource []byte
+
+// Get returns the response
+func (d fakeDataSource) Get(ctx context.Context, url string) ([]byte, error) {
+	return d, nil
+}
+
+// TestNewWithDataSource verifies that API requests are served by a custom DataSource
+func TestNewWithDataSource(t *testing.T) {
+	log.Println("TestNewWithDataSource()")
+
+	s, err := New("offline.example.com", WithDataSource(fakeDataSource(mockTable[0].data)))
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	if _, err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping returned error: %s", err.Error())
+	}
+}
+
+// TestBinaryDataSource verifies that binary requests are served by a BinaryDataSource, and
+// fall back to HTTP for other data sources
+func TestBinaryDataSource(t *testing.T) {
+	log.Println("TestBinaryDataSource()")
+
+	f := NewFakeServer()
+	defer f.Close()
+	f.SetMedia("1", "audio/mpeg", []byte("audio"))
+	f.SetMedia("2", "image/png", []byte("cover"))
+
+	var sources = []struct {
+		host   string
+		source DataSource
+	}{
+		// The fake server's data source serves requests without a network connection
+		{"offline.example.com", f.DataSource()},
+		// Other data sources only serve API requests
+		{f.URL, fakeDataSource(mockTable[0].data)},
+	}
+
+	ctx := context.Background()
+	for _, test := range sources {
+		s, err := New(test.host, WithPassword(fakeUsername, fakePassword), WithDataSource(test.source))
+		if err != nil {
+			t.Fatalf("New returned error: %s", err.Error())
+		}
+
+		var binary int
+		s.Interceptors = []Interceptor{func(ctx context.Context, req *Request, next Invoker) error {
+			if req.Binary {
+				binary++
+			}
+
+			return next(ctx, req)
+		}}
+
+		stream, err := s.Stream(ctx, "1", nil)
+		if err != nil {
+			t.Fatalf("Stream returned error: %s", err.Error())
+		}
+		b, _ := io.ReadAll(stream)
+		stream.Close()
+		if string(b) != "audio" || stream.ContentType != "audio/mpeg" {
+			t.Fatalf("Stream returned invalid data: %q, %s", b, stream.ContentType)
+		}
+
+		// Range requests are passed to the data source
+		r, n, err := s.DownloadRange(ctx, "1", 2, 2)
+		if err != nil {
+			t.Fatalf("DownloadRange returned error: %s", err.Error())
+		}
+		b, _ = io.ReadAll(r)
+		r.Close()
+		if string(b) != "di" || n != 5 {
+			t.Fatalf("DownloadRange returned invalid data: %q, %d", b, n)
+		}
+
+		cover, err := s.GetCoverArt(ctx, "2", 0)
+		if err != nil {
+			t.Fatalf("GetCoverArt returned error: %s", err.Error())
+		}
+		b, _ = io.ReadAll(cover)
+		cover.Close()
+		if string(b) != "cover" || binary != 3 {
+			t.Fatalf("GetCoverArt returned invalid data: %q, %d intercepted binary requests", b, binary)
+		}
+	}
+}
+
+// TestNewWithTimeout verifies that API requests fail once the timeout elapses
+func TestNewWithTimeout(t *testing.T) {
+	log.Println("TestNewWithTimeout()")
+
+	f := NewFakeServer()
+	defer f.Close()
+	f.SetLatency(time.Second)
+
+	start := time.Now()
+	if _, err := New(f.URL, WithPassword(fakeUsername, fakePassword), WithTimeout(50*time.Millisecond)); err != context.DeadlineExceeded {
+		t.Fatalf("New returned unexpected error: %v", err)
+	}
+
+	if d := time.Since(start); d >= time.Second {
+		t.Fatalf("New did not time out: %s", d)
+	}
+}
+
+// TestTimeouts verifies that New applies default timeouts, that the response header timeout
+// stops a hung server from blocking New, and that WithCallTimeout overrides the client's
+// timeout for API requests, but not streams
+func TestTimeouts(t *testing.T) {
+	log.Println("TestTimeouts()")
+
+	// The server never responds until it is closed
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	s, err := New(host, WithPassword("mock", "mock"), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	tr, ok := s.httpClient.Transport.(*http.Transport)
+	if !ok || s.Timeout != DefaultTimeout || tr.ResponseHeaderTimeout != DefaultResponseHeaderTimeout {
+		t.Fatalf("New did not apply default timeouts: %s, %+v", s.Timeout, s.httpClient.Transport)
+	}
+
+	start := time.Now()
+	if _, err := New(host, WithPassword("mock", "mock"), WithTimeout(0), WithResponseHeaderTimeout(50*time.Millisecond)); err == nil {
+		t.Fatalf("New returned no error for hung server")
+	}
+	if d := time.Since(start); d >= time.Second {
+		t.Fatalf("New did not time out: %s", d)
+	}
+
+	f := NewFakeServer()
+	defer f.Close()
+	f.SetLatency(100 * time.Millisecond)
+	f.SetMedia("1", "audio/mpeg", []byte("audio"))
+
+	s, err = New(f.URL, WithPassword(fakeUsername, fakePassword), WithTimeout(20*time.Millisecond), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	if _, err := s.Ping(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Ping returned unexpected error: %v", err)
+	}
+	if _, err := s.Ping(WithCallTimeout(ctx, time.Second)); err != nil {
+		t.Fatalf("Ping with call timeout returned error: %s", err.Error())
+	}
+	if _, err := s.Ping(WithCallTimeout(ctx, 0)); err != nil {
+		t.Fatalf("Ping without timeout returned error: %s", err.Error())
+	}
+
+	// Streams are exempt from the client's timeout
+	stream, err := s.Stream(ctx, "1", nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	stream.Close()
+}
+
+// TestSharedHTTPClient verifies that clients with the default timeouts share pooled
+// connections, while clients with other timeouts do not
+func TestSharedHTTPClient(t *testing.T) {
+	log.Println("TestSharedHTTPClient()")
+
+	a, err := New("localhost", WithPassword("mock", "mock"), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+	b, err := New("localhost", WithPassword("mock", "mock"), WithoutPing())
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+	c, err := New("localhost", WithPassword("mock", "mock"), WithoutPing(), WithConnectTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+
+	if a.httpClient != b.httpClient || a.httpClient == c.httpClient {
+		t.Fatalf("New did not share HTTP client between clients with default timeouts")
+	}
+
+	tr, ok := a.httpClient.Transport.(*http.Transport)
+	if !ok || tr.MaxIdleConnsPerHost != maxIdleConnsPerHost {
+		t.Fatalf("New did not tune idle connections: %+v", a.httpClient.Transport)
+	}
+}
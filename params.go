@@ -0,0 +1,70 @@
+package gosubsonic
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Params represents the query parameters for a Subsonic API request.  Parameters are
+// URL-encoded when a request URL is generated, so values may safely contain any characters,
+// such as spaces, ampersands, or non-ASCII text in usernames and search queries.
+type Params url.Values
+
+// Set sets a parameter to a single value, replacing any existing values
+func (p Params) Set(key string, value string) {
+	url.Values(p).Set(key, value)
+}
+
+// Add appends a value to a parameter, for parameters which may be repeated
+func (p Params) Add(key string, value string) {
+	url.Values(p).Add(key, value)
+}
+
+// SetInt sets a parameter to a single integer value, replacing any existing values
+func (p Params) SetInt(key string, value int64) {
+	p.Set(key, strconv.FormatInt(value, 10))
+}
+
+// AddInt appends an integer value to a parameter, for parameters which may be repeated
+func (p Params) AddInt(key string, value int64) {
+	p.Add(key, strconv.FormatInt(value, 10))
+}
+
+// AddInts appends each of a slice of integer values to a parameter, such as a list of bit rates
+func (p Params) AddInts(key string, values []int64) {
+	for _, v := range values {
+		p.AddInt(key, v)
+	}
+}
+
+// AddIDs appends each of a slice of IDs to a parameter, such as a list of media IDs
+func (p Params) AddIDs(key string, ids []ID) {
+	for _, id := range ids {
+		p.Add(key, string(id))
+	}
+}
+
+// SetBool sets a parameter to a single boolean value, replacing any existing values
+func (p Params) SetBool(key string, value bool) {
+	p.Set(key, strconv.FormatBool(value))
+}
+
+// Get returns the first value of a parameter, or an empty string if it is not set
+func (p Params) Get(key string) string {
+	return url.Values(p).Get(key)
+}
+
+// Encode encodes the parameters into URL query string form, sorted by key
+func (p Params) Encode() string {
+	return url.Values(p).Encode()
+}
+
+// clone returns a copy of the parameters, so they may be modified without affecting the original
+func (p Params) clone() Params {
+	out := make(Params, len(p))
+	for k, v := range p {
+		out[k] = append([]string(nil), v...)
+	}
+
+	return out
+}
@@ -0,0 +1,57 @@
+package gosubsonic
+
+import (
+	"log"
+	"net/url"
+	"testing"
+)
+
+// TestParams verifies that Params are composed and encoded properly
+func TestParams(t *testing.T) {
+	log.Println("TestParams()")
+
+	p := Params{}
+	p.Set("query", "Tom & Jerry")
+	p.SetInt("count", 5)
+	p.SetBool("public", true)
+	p.AddInts("id", []int64{1, 2})
+
+	if q := p.Encode(); q != "count=5&id=1&id=2&public=true&query=Tom+%26+Jerry" {
+		t.Fatalf("Params returned invalid query string: %s", q)
+	}
+
+	// Set should replace existing values
+	p.SetInt("id", 3)
+	if q := p.Get("id"); q != "3" || len(p["id"]) != 1 {
+		t.Fatalf("Params did not replace values: %v", p["id"])
+	}
+}
+
+// TestMakeURLEncoding verifies that client.makeURL() encodes credentials and parameters
+// containing special characters, and does not modify the caller's parameters
+func TestMakeURLEncoding(t *testing.T) {
+	log.Println("TestMakeURLEncoding()")
+
+	s := Client{
+		Host:        "example.com",
+		Username:    "mock user",
+		Credentials: Secret("p&ss=word"),
+	}
+
+	params := Params{}
+	params.Set("query", "a&b=c")
+
+	u, err := url.Parse(s.makeURL("search3", params))
+	if err != nil {
+		t.Fatalf("makeURL returned invalid URL: %s", err.Error())
+	}
+
+	q := u.Query()
+	if q.Get("u") != s.Username || q.Get("p") != s.password() || q.Get("query") != "a&b=c" {
+		t.Fatalf("makeURL returned improperly encoded URL: %s", u.String())
+	}
+
+	if len(params) != 1 {
+		t.Fatalf("makeURL modified caller's parameters: %v", params)
+	}
+}
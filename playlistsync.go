@@ -0,0 +1,131 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+)
+
+// PlaylistSync describes the changes made to a server playlist by SyncPlaylist
+type PlaylistSync struct {
+	// ID is the ID of the server playlist
+	ID ID
+
+	// Created is set if the playlist did not exist, and was created
+	Created bool
+
+	// Removed are the zero-based positions of entries removed from the playlist, relative to
+	// the playlist before any changes
+	Removed []int64
+
+	// Added are the songs appended to the end of the playlist, after any removals
+	Added []ID
+}
+
+// SyncPlaylist updates the server playlist with the specified name, owned by the client's
+// user, so that it contains exactly the specified songs in order.  If the playlist does not
+// exist, it is created.
+//
+// The server only supports removing entries and appending songs, so SyncPlaylist keeps the
+// longest run of existing entries which begins the desired order, removes all others, and
+// appends the remaining songs, using a single update.  Songs which move within the playlist
+// are removed and appended again.  If the playlist already matches, it is not updated.
+func (s Client) SyncPlaylist(ctx context.Context, name string, songIDs []ID) (*PlaylistSync, error) {
+	if name == "" {
+		return nil, errors.New("gosubsonic: playlist name is required")
+	}
+
+	id, err := s.findPlaylist(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		playlist, err := s.CreatePlaylist(ctx, name, songIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// Older servers do not return the new playlist, so find it by name
+		if playlist != nil {
+			id = playlist.ID
+		} else if id, err = s.findPlaylist(ctx, name); err != nil {
+			return nil, err
+		}
+
+		return &PlaylistSync{ID: id, Created: true, Added: songIDs}, nil
+	}
+
+	playlist, err := s.GetPlaylist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]ID, len(playlist.Entry))
+	for i, e := range playlist.Entry {
+		current[i] = e.ID
+	}
+
+	removed, added := playlistDiff(current, songIDs)
+	out := &PlaylistSync{ID: id, Removed: removed, Added: added}
+	if len(removed) == 0 && len(added) == 0 {
+		return out, nil
+	}
+
+	err = s.UpdatePlaylist(ctx, id, UpdatePlaylistOptions{
+		SongIDToAdd:       added,
+		SongIndexToRemove: removed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// findPlaylist returns the ID of the first playlist with the specified name which is owned
+// by the client's user, or an empty ID if there is none.  Playlists without an owner, which
+// are returned by older servers, are assumed to be owned by the user.
+func (s Client) findPlaylist(ctx context.Context, name string) (ID, error) {
+	playlists, err := s.GetPlaylists(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range playlists {
+		if p.Name == name && (p.Owner == "" || p.Owner == s.Username) {
+			return p.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// playlistDiff computes the entries to remove from a playlist, and the songs to append, to
+// transform it into the desired order.  The longest prefix of desired which appears in order
+// within current is kept, which minimizes both removals and additions.
+func playlistDiff(current []ID, desired []ID) ([]int64, []ID) {
+	// Match the desired songs against the current entries greedily, which finds the
+	// longest matching prefix
+	keep := make([]bool, len(current))
+	var k int
+	for i, id := range current {
+		if k < len(desired) && id == desired[k] {
+			keep[i] = true
+			k++
+		}
+	}
+
+	var removed []int64
+	for i := range current {
+		if !keep[i] {
+			removed = append(removed, int64(i))
+		}
+	}
+
+	var added []ID
+	if k < len(desired) {
+		added = desired[k:]
+	}
+
+	return removed, added
+}
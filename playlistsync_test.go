@@ -0,0 +1,84 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestPlaylistDiff verifies that playlistDiff() computes the minimal removals and additions
+// which transform a playlist into the desired order
+func TestPlaylistDiff(t *testing.T) {
+	log.Println("TestPlaylistDiff()")
+
+	var tests = []struct {
+		current []ID
+		desired []ID
+		removed []int64
+		added   []ID
+	}{
+		{[]ID{"1", "2", "3"}, []ID{"1", "2", "3"}, nil, nil},
+		{nil, []ID{"1", "2"}, nil, []ID{"1", "2"}},
+		{[]ID{"1", "2"}, nil, []int64{0, 1}, nil},
+		{[]ID{"1", "2", "3"}, []ID{"1", "3", "4"}, []int64{1}, []ID{"4"}},
+		{[]ID{"1", "2", "3"}, []ID{"2", "1", "3"}, []int64{0, 2}, []ID{"1", "3"}},
+		{[]ID{"1", "1", "2"}, []ID{"1", "2", "2"}, []int64{1}, []ID{"2"}},
+	}
+
+	for _, test := range tests {
+		removed, added := playlistDiff(test.current, test.desired)
+		if !reflect.DeepEqual(removed, test.removed) || !reflect.DeepEqual(added, test.added) {
+			t.Fatalf("playlistDiff(%v, %v) returned %v, %v, expected %v, %v",
+				test.current, test.desired, removed, added, test.removed, test.added)
+		}
+	}
+}
+
+// TestSyncPlaylist verifies that client.SyncPlaylist() creates missing playlists, and updates
+// existing playlists only when they differ
+func TestSyncPlaylist(t *testing.T) {
+	log.Println("TestSyncPlaylist()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	// Another user's playlist with the same name is ignored
+	f.SetFixture("getPlaylists", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "playlists": {"playlist": [
+		{"id": 5, "name": "Mix", "owner": "other"},
+		{"id": 6, "name": "Mix", "owner": "mock"}]}}}`))
+	f.SetFixture("getPlaylist", url.Values{"id": {"6"}}, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"playlist": {"id": 6, "name": "Mix", "entry": [{"id": 1}, {"id": 2}, {"id": 3}]}}}`))
+	f.SetFixture("updatePlaylist", url.Values{"playlistId": {"6"}, "songIndexToRemove": {"1"}, "songIdToAdd": {"4"}},
+		[]byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0"}}`))
+	f.SetFixture("createPlaylist", url.Values{"name": {"New"}, "songId": {"1", "2"}},
+		[]byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "playlist": {"id": 7, "name": "New"}}}`))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	res, err := s.SyncPlaylist(ctx, "Mix", []ID{"1", "3", "4"})
+	if err != nil {
+		t.Fatalf("SyncPlaylist returned error: %s", err.Error())
+	}
+	if res.ID != "6" || res.Created || !reflect.DeepEqual(res.Removed, []int64{1}) || !reflect.DeepEqual(res.Added, []ID{"4"}) {
+		t.Fatalf("SyncPlaylist returned invalid changes: %+v", res)
+	}
+
+	// Matching playlists are not updated
+	if _, err := s.SyncPlaylist(ctx, "Mix", []ID{"1", "2", "3"}); err != nil || f.Requests("updatePlaylist") != 1 {
+		t.Fatalf("SyncPlaylist updated matching playlist: %v", err)
+	}
+
+	res, err = s.SyncPlaylist(ctx, "New", []ID{"1", "2"})
+	if err != nil {
+		t.Fatalf("SyncPlaylist returned error: %s", err.Error())
+	}
+	if res.ID != "7" || !res.Created || len(res.Added) != 2 {
+		t.Fatalf("SyncPlaylist did not create playlist: %+v", res)
+	}
+}
@@ -0,0 +1,208 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Profile describes the quirks of a Subsonic server implementation, which are corrected in
+// each response before it is decoded.  Quirks shared by all servers, such as single item
+// lists returned as bare objects or IDs returned as numeric strings, are always handled.
+type Profile struct {
+	// Name identifies the server implementation, such as "navidrome"
+	Name string
+
+	// TimeLayouts are additional layouts for timestamps, which are tried when a timestamp
+	// is not in any of the formats recognized for all servers
+	TimeLayouts []string
+
+	// DropEmpty removes empty strings, arrays, and objects from responses, for servers which
+	// return any of them in place of an omitted list or object where they cannot be decoded.
+	// Empty containers are already ignored when decoding lists and objects, so none of the
+	// built-in profiles set it, since it requires decoding the entire response twice.
+	DropEmpty bool
+}
+
+// Compatibility profiles for popular server implementations
+var (
+	// ProfileSubsonic is used for the original Subsonic server, and any server which does not
+	// identify itself.  Its empty string containers are ignored by the decoders.
+	ProfileSubsonic = &Profile{
+		Name: "subsonic",
+	}
+
+	// ProfileAirsonic is used for Airsonic and Airsonic-Advanced, which retain the empty
+	// containers of the original Subsonic server
+	ProfileAirsonic = &Profile{
+		Name: "airsonic",
+	}
+
+	// ProfileNavidrome is used for Navidrome
+	ProfileNavidrome = &Profile{
+		Name: "navidrome",
+	}
+
+	// ProfileGonic is used for Gonic, which may return timestamps in Go's default format
+	ProfileGonic = &Profile{
+		Name:        "gonic",
+		TimeLayouts: []string{"2006-01-02 15:04:05.999999999 -0700 MST"},
+	}
+)
+
+// profileTimeKeys are the keys of response values which contain timestamps
+var profileTimeKeys = map[string]bool{
+	"created":           true,
+	"changed":           true,
+	"expires":           true,
+	"lastVisited":       true,
+	"publishDate":       true,
+	"date":              true,
+	"starred":           true,
+	"avatarLastChanged": true,
+}
+
+// DetectProfile identifies the server implementation using the type reported by OpenSubsonic
+// servers, and returns its compatibility Profile.  Servers which do not identify themselves are
+// assumed to be the original Subsonic server.
+func (s Client) DetectProfile(ctx context.Context) (*Profile, error) {
+	status, err := s.Ping(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return detectProfile(status), nil
+}
+
+// detectProfile returns the compatibility Profile for the server which sent a response
+func detectProfile(status *APIStatus) *Profile {
+	switch t := strings.ToLower(status.Type); t {
+	case "", "subsonic":
+		return ProfileSubsonic
+	case "airsonic", "airsonic-advanced", "airsonic advanced":
+		return ProfileAirsonic
+	case "navidrome":
+		return ProfileNavidrome
+	case "gonic":
+		return ProfileGonic
+	default:
+		// Other servers identify themselves, so they likely implement OpenSubsonic and
+		// need no corrections
+		return &Profile{Name: t}
+	}
+}
+
+// parse parses a raw JSON or XML response body into an apiContainer, applying the client's
+// compatibility profile, if any.  With lenient parsing, malformed items are skipped and
+// returned as warnings.
+func (s Client) parse(body []byte) (*apiContainer, []ParseWarning, error) {
+	if !s.Lenient && !s.Profile.normalizes() {
+		res, err := processResponse(body)
+		return res, nil, err
+	}
+
+	body, err := s.responseJSON(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.Lenient {
+		return processJSONLenient(body)
+	}
+
+	res, err := processJSON(bytes.NewReader(body))
+	return res, nil, err
+}
+
+// responseJSON converts a raw JSON or XML response body into the JSON which is decoded,
+// applying the client's compatibility profile, if any
+func (s Client) responseJSON(body []byte) ([]byte, error) {
+	if isXML(body) {
+		out, err := xmlToJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("gosubsonic: failed to parse response XML: %s", err.Error())
+		}
+		body = out
+	}
+
+	p := s.Profile
+	if !p.normalizes() {
+		return body, nil
+	}
+
+	// Decode numbers exactly, so large IDs are not rounded
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to parse response JSON: %w", err)
+	}
+
+	return json.Marshal(p.normalize("", v))
+}
+
+// normalizes determines if a profile corrects the quirks in decoded responses, which
+// requires the entire response body
+func (p *Profile) normalizes() bool {
+	return p != nil && (len(p.TimeLayouts) > 0 || p.DropEmpty)
+}
+
+// normalize corrects the quirks in a decoded JSON value, stored under the specified key
+func (p *Profile) normalize(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			child = p.normalize(k, child)
+			if p.DropEmpty && isEmptyValue(child) {
+				delete(v, k)
+				continue
+			}
+
+			v[k] = child
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = p.normalize(key, v[i])
+		}
+	case string:
+		if profileTimeKeys[key] {
+			return p.normalizeTime(v)
+		}
+	}
+
+	return v
+}
+
+// normalizeTime converts a timestamp in one of the profile's layouts into RFC 3339 format,
+// leaving any other value unchanged
+func (p *Profile) normalizeTime(raw string) string {
+	if _, err := parseSubsonicTime(raw); err == nil {
+		return raw
+	}
+
+	for _, layout := range p.TimeLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+
+	return raw
+}
+
+// isEmptyValue determines if a decoded JSON value is an empty string, array, or object
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+
+	return false
+}
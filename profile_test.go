@@ -0,0 +1,88 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+)
+
+// TestDetectProfile verifies that detectProfile selects a profile for each server type
+func TestDetectProfile(t *testing.T) {
+	log.Println("TestDetectProfile()")
+
+	var tests = []struct {
+		serverType string
+		name       string
+	}{
+		{"", "subsonic"},
+		{"airsonic", "airsonic"},
+		{"Airsonic-Advanced", "airsonic"},
+		{"navidrome", "navidrome"},
+		{"gonic", "gonic"},
+		{"ampache", "ampache"},
+	}
+
+	for _, test := range tests {
+		if p := detectProfile(&APIStatus{Type: test.serverType}); p.Name != test.name {
+			t.Fatalf("detectProfile returned %q for %q", p.Name, test.serverType)
+		}
+	}
+}
+
+// TestProfileNormalize verifies that profiles correct timestamps, and that empty containers are
+// ignored
+func TestProfileNormalize(t *testing.T) {
+	log.Println("TestProfileNormalize()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetFixture("ping", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+		"type": "gonic", "openSubsonic": true}}`))
+	f.SetFixture("getSong", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+		"song": {"id": 1, "title": "Wonderland", "created": "2019-01-02 03:04:05.5 +0100 CET"}}}`))
+	f.SetFixture("getIndexes", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.15.0",
+		"indexes": []}}`))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+	if s.Profile != ProfileGonic {
+		t.Fatalf("New selected invalid profile: %+v", s.Profile)
+	}
+
+	ctx := context.Background()
+	song, err := s.GetSong(ctx, "1")
+	if err != nil {
+		t.Fatalf("GetSong returned error: %s", err.Error())
+	}
+
+	expected := time.Date(2019, 1, 2, 2, 4, 5, 5e8, time.UTC)
+	if !song.Created.Equal(expected) {
+		t.Fatalf("GetSong returned invalid created time: %s", song.Created)
+	}
+
+	// Without a profile, the timestamp is not recognized
+	s.Profile = nil
+	if song, err := s.GetSong(ctx, "1"); err != nil || !song.Created.IsZero() || song.CreatedRaw == "" {
+		t.Fatalf("GetSong returned %+v, %v without profile", song, err)
+	}
+
+	// Empty containers are ignored by the decoders, so the default profile does not need
+	// the entire response to remove them
+	s.Profile = ProfileSubsonic
+	if ProfileSubsonic.normalizes() {
+		t.Fatalf("Default profile normalizes responses")
+	}
+	if indexes, err := s.GetIndexes(ctx, -1, time.Time{}); err != nil || len(indexes.Index) != 0 {
+		t.Fatalf("GetIndexes returned %v, %v for empty indexes", indexes, err)
+	}
+
+	// Profiles may still remove them
+	s.Profile = &Profile{Name: "custom", DropEmpty: true}
+	if indexes, err := s.GetIndexes(ctx, -1, time.Time{}); err != nil || len(indexes.Index) != 0 {
+		t.Fatalf("GetIndexes returned %v, %v for empty indexes with DropEmpty", indexes, err)
+	}
+}
@@ -0,0 +1,234 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum interval between progress reports for a transfer, other
+// than its final report
+const progressInterval = 100 * time.Millisecond
+
+// TransferProgress reports the progress of a binary transfer, such as a stream or download
+type TransferProgress struct {
+	// Method is the Subsonic API method, such as "stream" or "download"
+	Method string
+
+	// Bytes is the number of bytes read so far
+	Bytes int64
+
+	// Total is the length of the response in bytes, or -1 if unknown, such as for most
+	// transcoded streams.  For range requests, it is the length of the range.
+	Total int64
+
+	// Elapsed is the time since the response was received
+	Elapsed time.Duration
+
+	// Done is set in the final report, once the response has been read entirely or closed
+	Done bool
+}
+
+// Percent returns the percentage of the response which has been read, from 0 to 100, or -1
+// if its length is unknown
+func (p TransferProgress) Percent() float64 {
+	if p.Total < 0 {
+		return -1
+	}
+	if p.Total == 0 {
+		return 100
+	}
+
+	return float64(p.Bytes) / float64(p.Total) * 100
+}
+
+// Throughput returns the average rate of the transfer in bytes per second
+func (p TransferProgress) Throughput() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Bytes) / p.Elapsed.Seconds()
+}
+
+// progressKey is the context key for a function set by WithProgress
+type progressKey struct{}
+
+// WithProgress returns a context which reports the progress of binary transfers made using
+// it, such as Stream or Download, to fn.  fn is called as data is read, at most once every
+// 100 milliseconds, and always once the transfer is done.  It is called by the goroutine
+// reading or closing the response, so it should return quickly.
+func WithProgress(ctx context.Context, fn func(TransferProgress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// TransferStats aggregates the binary transfers made by the clients which share it, such as
+// to track data usage.  The zero value is ready to use, and a TransferStats is safe for
+// concurrent use.
+type TransferStats struct {
+	mu        sync.Mutex
+	bytes     int64
+	transfers int64
+	active    int64
+	elapsed   time.Duration
+	methods   map[string]int64
+}
+
+// TransferTotals is a snapshot of the transfers aggregated by a TransferStats
+type TransferTotals struct {
+	// Bytes is the number of bytes read by all transfers, including active transfers
+	Bytes int64
+
+	// Transfers is the number of transfers started, and Active is the number which are not
+	// yet done
+	Transfers int64
+	Active    int64
+
+	// Elapsed is the total duration of the transfers which are done
+	Elapsed time.Duration
+
+	// Methods is the number of bytes read for each Subsonic API method, such as "stream"
+	Methods map[string]int64
+}
+
+// Totals returns a snapshot of the aggregated transfers
+func (t *TransferStats) Totals() TransferTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	methods := make(map[string]int64, len(t.methods))
+	for m, n := range t.methods {
+		methods[m] = n
+	}
+
+	return TransferTotals{
+		Bytes:     t.bytes,
+		Transfers: t.transfers,
+		Active:    t.active,
+		Elapsed:   t.elapsed,
+		Methods:   methods,
+	}
+}
+
+// start records a new transfer
+func (t *TransferStats) start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.transfers++
+	t.active++
+}
+
+// read records bytes read by a transfer
+func (t *TransferStats) read(method string, n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.methods == nil {
+		t.methods = make(map[string]int64)
+	}
+
+	t.bytes += n
+	t.methods[method] += n
+}
+
+// done records the end of a transfer
+func (t *TransferStats) done(elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active--
+	t.elapsed += elapsed
+}
+
+// trackProgress wraps a binary response body to report its progress to the function set
+// by WithProgress, and to record it in the client's TransferStats, if either is set
+func (s Client) trackProgress(ctx context.Context, url string, body io.ReadCloser, total int64) io.ReadCloser {
+	fn, _ := ctx.Value(progressKey{}).(func(TransferProgress))
+	if fn == nil && s.Transfers == nil {
+		return body
+	}
+
+	if s.Transfers != nil {
+		s.Transfers.start()
+	}
+
+	now := time.Now()
+	return &transferReader{
+		ReadCloser: body,
+		fn:         fn,
+		stats:      s.Transfers,
+		start:      now,
+		last:       now,
+		progress:   TransferProgress{Method: urlMethod(url), Total: total},
+	}
+}
+
+// transferReader is an io.ReadCloser which reports the progress of reading its data
+type transferReader struct {
+	io.ReadCloser
+	fn    func(TransferProgress)
+	stats *TransferStats
+	start time.Time
+
+	mu       sync.Mutex
+	last     time.Time
+	progress TransferProgress
+}
+
+// Read reads data, and reports progress if the report interval has elapsed, or the data
+// has been read entirely
+func (r *transferReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	if n > 0 && r.stats != nil {
+		r.stats.read(r.progress.Method, int64(n))
+	}
+
+	r.mu.Lock()
+	r.progress.Bytes += int64(n)
+	now := time.Now()
+	report := r.fn != nil && n > 0 && now.Sub(r.last) >= progressInterval
+	if report {
+		r.last = now
+	}
+	p := r.progress
+	r.mu.Unlock()
+
+	if err == io.EOF {
+		r.finish()
+	} else if report {
+		p.Elapsed = now.Sub(r.start)
+		r.fn(p)
+	}
+
+	return n, err
+}
+
+// Close closes the underlying body, and reports the final progress if it was not already
+func (r *transferReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.finish()
+	return err
+}
+
+// finish reports the final progress of the transfer, only once
+func (r *transferReader) finish() {
+	r.mu.Lock()
+	if r.progress.Done {
+		r.mu.Unlock()
+		return
+	}
+
+	r.progress.Done = true
+	r.progress.Elapsed = time.Since(r.start)
+	p := r.progress
+	r.mu.Unlock()
+
+	if r.stats != nil {
+		r.stats.done(p.Elapsed)
+	}
+	if r.fn != nil {
+		r.fn(p)
+	}
+}
@@ -0,0 +1,92 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestProgress verifies that binary transfers report their progress, and are aggregated by
+// a client's TransferStats
+func TestProgress(t *testing.T) {
+	log.Println("TestProgress()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	media := []byte(strings.Repeat("audio", 1000))
+	f.SetMedia("1", "audio/mpeg", media)
+	f.SetMedia("2", "image/png", []byte("cover"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+	s.Transfers = &TransferStats{}
+
+	var mu sync.Mutex
+	var reports []TransferProgress
+	ctx := WithProgress(context.Background(), func(p TransferProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reports = append(reports, p)
+	})
+
+	stream, err := s.Stream(ctx, "1", nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+
+	// Progress is reported as data is read, once the report interval elapses
+	b := make([]byte, 1000)
+	if _, err := io.ReadFull(stream, b); err != nil {
+		t.Fatalf("Read returned error: %s", err.Error())
+	}
+	time.Sleep(progressInterval)
+	if _, err := io.ReadFull(stream, b); err != nil {
+		t.Fatalf("Read returned error: %s", err.Error())
+	}
+
+	if totals := s.Transfers.Totals(); totals.Active != 1 || totals.Bytes != 2000 {
+		t.Fatalf("TransferStats returned invalid totals for active transfer: %+v", totals)
+	}
+
+	rest, _ := io.ReadAll(stream)
+	stream.Close()
+	if !bytes.Equal(append(b, rest...), media[1000:]) {
+		t.Fatalf("Stream returned invalid data")
+	}
+
+	mu.Lock()
+	n := len(reports)
+	if n < 2 || reports[n-2].Bytes != 2000 || reports[n-2].Percent() != 40 || reports[n-2].Done {
+		t.Fatalf("Stream reported invalid progress: %+v", reports)
+	}
+	if last := reports[n-1]; !last.Done || last.Bytes != 5000 || last.Percent() != 100 || last.Method != "stream" || last.Throughput() <= 0 {
+		t.Fatalf("Stream reported invalid final progress: %+v", last)
+	}
+	mu.Unlock()
+
+	// Transfers closed early are done, and are still aggregated without a progress function
+	cover, err := s.GetCoverArt(context.Background(), "2", 0)
+	if err != nil {
+		t.Fatalf("GetCoverArt returned error: %s", err.Error())
+	}
+	cover.Read(make([]byte, 2))
+	cover.Close()
+
+	totals := s.Transfers.Totals()
+	if totals.Transfers != 2 || totals.Active != 0 || totals.Bytes != 5002 || totals.Methods["getCoverArt"] != 2 || totals.Elapsed <= 0 {
+		t.Fatalf("TransferStats returned invalid totals: %+v", totals)
+	}
+
+	if p := (TransferProgress{Total: -1}); p.Percent() != -1 || p.Throughput() != 0 {
+		t.Fatalf("TransferProgress reported progress for unknown length")
+	}
+}
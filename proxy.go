@@ -0,0 +1,271 @@
+package gosubsonic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StreamProxy serves selected media items over HTTP using clean URLs, which contain no
+// credentials, by proxying their data from Subsonic.  Its URLs may be handed to devices
+// which cannot authenticate with Subsonic, such as Sonos speakers, DLNA renderers, and
+// browsers.  Items are only served once added, using unguessable URLs, and range requests
+// are supported so devices may seek.  A StreamProxy is an http.Handler, and is safe for
+// concurrent use.
+type StreamProxy struct {
+	client Client
+	base   string
+
+	mu    sync.Mutex
+	items map[string]proxyItem
+}
+
+// proxyItem is a media item served by a StreamProxy
+type proxyItem struct {
+	id          ID
+	download    bool
+	options     *StreamOptions
+	contentType string
+}
+
+// NewStreamProxy creates a StreamProxy.  base is the URL at which devices reach the proxy,
+// such as "http://192.168.1.10:8080" or "http://192.168.1.10/media", and is used to generate
+// the URLs of items.  The proxy handles requests for any path ending in an item's name, so
+// it may be mounted under a prefix without stripping it.
+func (s Client) NewStreamProxy(base string) *StreamProxy {
+	return &StreamProxy{
+		client: s,
+		base:   strings.TrimSuffix(base, "/"),
+		items:  make(map[string]proxyItem),
+	}
+}
+
+// Add serves a processed media file stream, with an optional CastOptions struct, and returns
+// its URL and content type.  As with StreamCastURL, the stream is always forced to a known
+// format (mp3 by default).
+func (p *StreamProxy) Add(id ID, options *CastOptions) CastMedia {
+	format := castDefaultFormat
+	var maxBitRate int64
+	if options != nil {
+		if options.Format != "" {
+			format = strings.ToLower(options.Format)
+		}
+
+		maxBitRate = options.MaxBitRate
+	}
+
+	return p.add(format, proxyItem{
+		id: id,
+		options: &StreamOptions{
+			Format:     format,
+			MaxBitRate: maxBitRate,
+		},
+		contentType: formatContentType(format),
+	})
+}
+
+// AddDownload serves a raw, non-transcoded media file, and returns its URL and content type.
+// suffix is the file's extension, such as "flac", which determines the content type, and is
+// also used in the URL since some devices rely on it.
+func (p *StreamProxy) AddDownload(id ID, suffix string) CastMedia {
+	suffix = strings.ToLower(strings.TrimPrefix(suffix, "."))
+	return p.add(suffix, proxyItem{
+		id:          id,
+		download:    true,
+		contentType: formatContentType(suffix),
+	})
+}
+
+// add serves an item under a new random name, with the specified extension
+func (p *StreamProxy) add(ext string, item proxyItem) CastMedia {
+	// The URLs of items must not be guessable, since the proxy does not authenticate
+	// devices.  rand.Read never returns an error.
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	name := hex.EncodeToString(b)
+	if ext != "" {
+		name += "." + ext
+	}
+
+	p.mu.Lock()
+	p.items[name] = item
+	p.mu.Unlock()
+
+	return CastMedia{
+		URL:         p.base + "/" + name,
+		ContentType: item.contentType,
+	}
+}
+
+// Remove stops serving the item with the specified URL, as returned by Add or AddDownload
+func (p *StreamProxy) Remove(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.items, path.Base(url))
+}
+
+// Clear stops serving all items
+func (p *StreamProxy) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.items = make(map[string]proxyItem)
+}
+
+// Serve serves the proxy's items on a listener, until ctx is canceled
+func (p *StreamProxy) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{Handler: p}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			srv.Close()
+		case <-done:
+		}
+	}()
+
+	if err := srv.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// ServeHTTP serves a single item, or part of it for range requests
+func (p *StreamProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	p.mu.Lock()
+	item, ok := p.items[path.Base(r.URL.Path)]
+	p.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Only a single range is supported, and others are ignored, which HTTP permits
+	offset, length, partial := parseRange(r.Header.Get("Range"))
+
+	body, size, err := p.fetch(r.Context(), item, offset, length)
+	switch {
+	case errors.Is(err, errRangeNotSatisfiable):
+		// Unsatisfiable ranges are reported with the total size, so retrieve it from the
+		// entire item
+		body, size, err = p.fetch(r.Context(), item, 0, 0)
+	case err == nil && partial && size < 0:
+		// Partial responses require the total size, so serve the entire item instead
+		body.Close()
+		offset, length, partial = 0, 0, false
+		body, size, err = p.fetch(r.Context(), item, 0, 0)
+	}
+	if err != nil {
+		status := http.StatusBadGateway
+		if IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	defer body.Close()
+
+	h := w.Header()
+	if partial && offset >= size {
+		if size >= 0 {
+			h.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		}
+
+		status := http.StatusRequestedRangeNotSatisfiable
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	h.Set("Content-Type", item.contentType)
+
+	status := http.StatusOK
+	switch {
+	case partial:
+		if length == 0 || offset+length > size {
+			length = size - offset
+		}
+
+		h.Set("Accept-Ranges", "bytes")
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size))
+		h.Set("Content-Length", strconv.FormatInt(length, 10))
+		status = http.StatusPartialContent
+	case size >= 0:
+		h.Set("Accept-Ranges", "bytes")
+		h.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	io.Copy(w, body)
+}
+
+// fetch retrieves length bytes of an item starting at offset, and its total size
+func (p *StreamProxy) fetch(ctx context.Context, item proxyItem, offset int64, length int64) (io.ReadCloser, int64, error) {
+	if item.download {
+		return p.client.DownloadRange(ctx, item.id, offset, length)
+	}
+
+	return p.client.StreamRange(ctx, item.id, item.options, offset, length)
+}
+
+// parseRange parses a HTTP Range header containing a single range with a starting position,
+// such as "bytes=1024-" or "bytes=0-1023", returning its offset and length.  Ranges which
+// begin at the start of the item are not partial, and neither are suffix ranges or multiple
+// ranges, which are not supported.
+func parseRange(header string) (int64, int64, bool) {
+	spec := strings.TrimPrefix(header, "bytes=")
+	if spec == header || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	i := strings.Index(spec, "-")
+	if i <= 0 {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(strings.TrimSpace(spec[:i]), 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+
+	var length int64
+	if end := strings.TrimSpace(spec[i+1:]); end != "" {
+		n, err := strconv.ParseInt(end, 10, 64)
+		if err != nil || n < start {
+			return 0, 0, false
+		}
+
+		length = n - start + 1
+	}
+
+	if start == 0 && length == 0 {
+		return 0, 0, false
+	}
+
+	return start, length, true
+}
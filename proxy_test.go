@@ -0,0 +1,164 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestStreamProxy verifies that a StreamProxy serves added items, and parts of them for
+// range requests, whether or not the server honors HTTP Range requests
+func TestStreamProxy(t *testing.T) {
+	log.Println("TestStreamProxy()")
+
+	media := []byte("0123456789abcdefghij")
+
+	for _, ranges := range []bool{true, false} {
+		s, done := rangeTestClient(t, media, ranges)
+
+		p := s.NewStreamProxy("http://192.168.1.10:8080/media/")
+		stream := p.Add("1", nil)
+		if !strings.HasPrefix(stream.URL, "http://192.168.1.10:8080/media/") || !strings.HasSuffix(stream.URL, ".mp3") || stream.ContentType != "audio/mpeg" {
+			t.Fatalf("Add returned invalid media: %v", stream)
+		}
+
+		download := p.AddDownload("2", ".FLAC")
+		if !strings.HasSuffix(download.URL, ".flac") || download.ContentType != "audio/flac" || download.URL == stream.URL {
+			t.Fatalf("AddDownload returned invalid media: %v", download)
+		}
+
+		var tests = []struct {
+			method string
+			url    string
+			header string
+			code   int
+			output string
+			length string
+			crange string
+		}{
+			{http.MethodGet, stream.URL, "", http.StatusOK, string(media), "20", ""},
+			{http.MethodGet, download.URL, "", http.StatusOK, string(media), "20", ""},
+			{http.MethodHead, download.URL, "", http.StatusOK, "", "20", ""},
+			{http.MethodGet, stream.URL, "bytes=0-", http.StatusOK, string(media), "20", ""},
+			{http.MethodGet, download.URL, "bytes=10-", http.StatusPartialContent, "abcdefghij", "10", "bytes 10-19/20"},
+			{http.MethodGet, stream.URL, "bytes=5-7", http.StatusPartialContent, "567", "3", "bytes 5-7/20"},
+			{http.MethodGet, stream.URL, "bytes=-5", http.StatusOK, string(media), "20", ""},
+			{http.MethodGet, stream.URL, "bytes=20-", http.StatusRequestedRangeNotSatisfiable, "", "", "bytes */20"},
+			{http.MethodGet, download.URL, "bytes=25-30", http.StatusRequestedRangeNotSatisfiable, "", "", "bytes */20"},
+			{http.MethodGet, "http://192.168.1.10:8080/media/unknown.mp3", "", http.StatusNotFound, "", "", ""},
+			{http.MethodPost, stream.URL, "", http.StatusMethodNotAllowed, "", "", ""},
+		}
+
+		for _, test := range tests {
+			r := httptest.NewRequest(test.method, test.url, nil)
+			if test.header != "" {
+				r.Header.Set("Range", test.header)
+			}
+
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, r)
+
+			if w.Code != test.code {
+				t.Fatalf("%s %s (%q) returned %d, expected %d", test.method, test.url, test.header, w.Code, test.code)
+			}
+			if c := w.Header().Get("Content-Range"); c != test.crange {
+				t.Fatalf("%s %s (%q) returned range %q, expected %q", test.method, test.url, test.header, c, test.crange)
+			}
+			if test.code >= http.StatusBadRequest {
+				continue
+			}
+
+			if body := w.Body.String(); body != test.output {
+				t.Fatalf("%s %s (%q) returned %q, expected %q", test.method, test.url, test.header, body, test.output)
+			}
+			if l := w.Header().Get("Content-Length"); l != test.length {
+				t.Fatalf("%s %s (%q) returned length %q, expected %q", test.method, test.url, test.header, l, test.length)
+			}
+		}
+
+		// Removed items are no longer served
+		p.Remove(stream.URL)
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, stream.URL, nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("Removed item returned %d, expected %d", w.Code, http.StatusNotFound)
+		}
+
+		done()
+	}
+}
+
+// TestStreamProxyServe verifies that StreamProxy.Serve() serves items on a listener until
+// its context is canceled
+func TestStreamProxyServe(t *testing.T) {
+	log.Println("TestStreamProxyServe()")
+
+	s, done := rangeTestClient(t, []byte("media"), true)
+	defer done()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %s", err.Error())
+	}
+
+	p := s.NewStreamProxy("http://" + l.Addr().String())
+	m := p.Add("1", &CastOptions{Format: "ogg"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- p.Serve(ctx, l)
+	}()
+
+	res, err := http.Get(m.URL)
+	if err != nil {
+		t.Fatalf("GET returned error: %s", err.Error())
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if string(body) != "media" || res.Header.Get("Content-Type") != "audio/ogg" {
+		t.Fatalf("Proxy returned invalid response: %q, %q", body, res.Header.Get("Content-Type"))
+	}
+
+	cancel()
+	if err := <-errC; err != nil {
+		t.Fatalf("Serve returned error: %s", err.Error())
+	}
+}
+
+// TestParseRange verifies that parseRange() only accepts single ranges with a starting
+// position
+func TestParseRange(t *testing.T) {
+	log.Println("TestParseRange()")
+
+	var tests = []struct {
+		header  string
+		offset  int64
+		length  int64
+		partial bool
+	}{
+		{"", 0, 0, false},
+		{"bytes=0-", 0, 0, false},
+		{"bytes=1024-", 1024, 0, true},
+		{"bytes=0-1023", 0, 1024, true},
+		{"bytes=10-10", 10, 1, true},
+		{"bytes=-500", 0, 0, false},
+		{"bytes=0-10,20-30", 0, 0, false},
+		{"bytes=10-5", 0, 0, false},
+		{"items=0-10", 0, 0, false},
+	}
+
+	for _, test := range tests {
+		offset, length, partial := parseRange(test.header)
+		if offset != test.offset || length != test.length || partial != test.partial {
+			t.Fatalf("parseRange(%q) returned (%d, %d, %v), expected (%d, %d, %v)",
+				test.header, offset, length, partial, test.offset, test.length, test.partial)
+		}
+	}
+}
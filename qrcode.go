@@ -0,0 +1,579 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// QR code constants.  Share URLs are short, so only byte mode with error correction
+// level M is supported, in versions 1 through 10.
+const (
+	qrMaxVersion = 10
+	qrQuietZone  = 4
+	qrModeByte   = 0x4
+	qrFormatM    = 0x0
+)
+
+// qrBlocks describes the error correction block structure for each version at level M,
+// indexed by version: EC codewords per block, then the block count and data codewords
+// per block for each of the two block groups
+var qrBlocks = [qrMaxVersion + 1]struct {
+	ec       int
+	g1, g1cw int
+	g2, g2cw int
+}{
+	{},
+	{10, 1, 16, 0, 0},
+	{16, 1, 28, 0, 0},
+	{26, 1, 44, 0, 0},
+	{18, 2, 32, 0, 0},
+	{24, 2, 43, 0, 0},
+	{16, 4, 27, 0, 0},
+	{18, 4, 31, 0, 0},
+	{22, 2, 38, 2, 39},
+	{22, 3, 36, 2, 37},
+	{26, 4, 43, 1, 44},
+}
+
+// qrAlignment lists the alignment pattern center coordinates for each version
+var qrAlignment = [qrMaxVersion + 1][]int{
+	nil,
+	nil,
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+// qrCode represents an encoded QR code symbol
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// qrEncode encodes data as a QR code in byte mode, using the smallest version which fits
+func qrEncode(data []byte) (*qrCode, error) {
+	// Find the smallest version which can hold the data
+	version := 0
+	for v := 1; v <= qrMaxVersion; v++ {
+		if qrHeaderBits(v)+len(data)*8 <= qrDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+
+	if version == 0 {
+		return nil, errors.New("gosubsonic: data too long for QR code")
+	}
+
+	// Build the data bit stream: mode, character count, and data
+	bits := &qrBitBuffer{}
+	bits.append(qrModeByte, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	// Add terminator and pad to a byte boundary
+	capacity := qrDataCodewords(version) * 8
+	for i := 0; i < 4 && len(*bits) < capacity; i++ {
+		bits.append(0, 1)
+	}
+	for len(*bits)%8 != 0 {
+		bits.append(0, 1)
+	}
+
+	// Fill remaining capacity with alternating pad bytes
+	for pad := 0xEC; len(*bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	// Generate the symbol, drawing function patterns before data
+	size := version*4 + 17
+	q := &qrCode{
+		size:     size,
+		modules:  qrGrid(size),
+		function: qrGrid(size),
+	}
+
+	q.drawFunctionPatterns(version)
+	q.drawCodewords(qrInterleave(version, bits.bytes()))
+
+	// Apply the mask with the lowest penalty score
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormat(mask)
+		if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+
+		// Masks are their own inverse
+		q.applyMask(mask)
+	}
+
+	q.applyMask(best)
+	q.drawFormat(best)
+
+	return q, nil
+}
+
+// png renders the QR code as a PNG image, with each module drawn as a square of scale pixels
+func (q *qrCode) png(scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	// Use a black and white palette, surrounded by a quiet zone
+	dim := (q.size + qrQuietZone*2) * scale
+	img := image.NewPaletted(image.Rect(0, 0, dim, dim), color.Palette{color.White, color.Black})
+
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if !q.modules[y][x] {
+				continue
+			}
+
+			// Draw a dark module
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+qrQuietZone)*scale+dx, (y+qrQuietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// drawFunctionPatterns draws finder, timing, alignment, and version patterns, and
+// reserves space for format information
+func (q *qrCode) drawFunctionPatterns(version int) {
+	// Timing patterns
+	for i := 0; i < q.size; i++ {
+		q.set(6, i, i%2 == 0)
+		q.set(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns, including separators
+	q.drawFinder(3, 3)
+	q.drawFinder(q.size-4, 3)
+	q.drawFinder(3, q.size-4)
+
+	// Alignment patterns, skipping those which overlap finder patterns
+	pos := qrAlignment[version]
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == len(pos)-1) || (i == len(pos)-1 && j == 0) {
+				continue
+			}
+
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.set(pos[i]+dx, pos[j]+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve format information with a placeholder mask
+	q.drawFormat(0)
+
+	// Version information, for version 7 and up
+	if version < 7 {
+		return
+	}
+
+	bits := qrVersionBits(version)
+	for i := 0; i < 18; i++ {
+		bit := (bits>>uint(i))&1 != 0
+		a, b := q.size-11+i%3, i/3
+		q.set(a, b, bit)
+		q.set(b, a, bit)
+	}
+}
+
+// drawFinder draws a finder pattern and its separator, centered at x, y
+func (q *qrCode) drawFinder(x int, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+
+			dist := qrMax(qrAbs(dx), qrAbs(dy))
+			q.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for level M and a mask
+func (q *qrCode) drawFormat(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool {
+		return (bits>>uint(i))&1 != 0
+	}
+
+	// First copy, around the top left finder
+	for i := 0; i <= 5; i++ {
+		q.set(8, i, bit(i))
+	}
+	q.set(8, 7, bit(6))
+	q.set(8, 8, bit(7))
+	q.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.set(14-i, 8, bit(i))
+	}
+
+	// Second copy, split between the top right and bottom left finders
+	for i := 0; i < 8; i++ {
+		q.set(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.set(8, q.size-15+i, bit(i))
+	}
+
+	// Dark module
+	q.set(8, q.size-8, true)
+}
+
+// drawCodewords places codewords in the zigzag pattern, skipping function modules
+func (q *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		// Skip the vertical timing pattern
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+
+				if q.function[y][x] || i >= len(data)*8 {
+					continue
+				}
+
+				q.modules[y][x] = (data[i>>3]>>uint(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts data modules selected by the specified mask pattern
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.function[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the current symbol using the four QR code penalty rules
+func (q *qrCode) penalty() int {
+	score := 0
+	dark := 0
+
+	// Finder-like patterns, with four light modules on either side
+	finderA := []bool{true, false, true, true, true, false, true, false, false, false, false}
+	finderB := []bool{false, false, false, false, true, false, true, true, true, false, true}
+
+	for i := 0; i < q.size; i++ {
+		for _, horizontal := range []bool{true, false} {
+			get := func(j int) bool {
+				if horizontal {
+					return q.modules[i][j]
+				}
+
+				return q.modules[j][i]
+			}
+
+			// Rule 1: runs of five or more modules of the same color
+			run := 1
+			for j := 1; j < q.size; j++ {
+				if get(j) == get(j-1) {
+					run++
+					continue
+				}
+
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				score += run - 2
+			}
+
+			// Rule 3: finder-like patterns
+			for j := 0; j+len(finderA) <= q.size; j++ {
+				matchA, matchB := true, true
+				for k := range finderA {
+					matchA = matchA && get(j+k) == finderA[k]
+					matchB = matchB && get(j+k) == finderB[k]
+				}
+
+				if matchA {
+					score += 40
+				}
+				if matchB {
+					score += 40
+				}
+			}
+		}
+	}
+
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+
+			// Rule 2: 2x2 blocks of the same color
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// Rule 4: balance of dark and light modules
+	total := q.size * q.size
+	k := (qrAbs(dark*20-total*10)+total-1)/total - 1
+	if k > 0 {
+		score += k * 10
+	}
+
+	return score
+}
+
+// set sets a function module at x, y
+func (q *qrCode) set(x int, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// qrFormatBits computes the BCH-encoded format information for level M and a mask
+func qrFormatBits(mask int) int {
+	data := qrFormatM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// qrVersionBits computes the BCH-encoded version information for a version
+func qrVersionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+
+	return version<<12 | rem
+}
+
+// qrInterleave splits data into blocks, computes error correction for each, and interleaves them
+func qrInterleave(version int, data []byte) []byte {
+	b := qrBlocks[version]
+	divisor := qrDivisor(b.ec)
+
+	// Split data into blocks, computing error correction codewords for each
+	var dataBlocks, ecBlocks [][]byte
+	offset := 0
+	for i := 0; i < b.g1+b.g2; i++ {
+		n := b.g1cw
+		if i >= b.g1 {
+			n = b.g2cw
+		}
+
+		block := data[offset : offset+n]
+		offset += n
+
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, qrRemainder(block, divisor))
+	}
+
+	// Interleave data codewords, then error correction codewords
+	out := make([]byte, 0, len(data)+len(ecBlocks)*b.ec)
+	for i := 0; i < qrMax(b.g1cw, b.g2cw); i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < b.ec; i++ {
+		for _, block := range ecBlocks {
+			out = append(out, block[i])
+		}
+	}
+
+	return out
+}
+
+// qrDivisor computes the Reed-Solomon generator polynomial of the specified degree
+func qrDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+
+		root = qrMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+// qrRemainder computes Reed-Solomon error correction codewords for data
+func qrRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, c := range divisor {
+			result[i] ^= qrMultiply(c, factor)
+		}
+	}
+
+	return result
+}
+
+// qrMultiply multiplies two elements of GF(2^8), modulo the QR code polynomial
+func qrMultiply(x byte, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+// qrDataCodewords returns the number of data codewords for a version at level M
+func qrDataCodewords(version int) int {
+	b := qrBlocks[version]
+	return b.g1*b.g1cw + b.g2*b.g2cw
+}
+
+// qrCountBits returns the length of the byte mode character count for a version
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+
+	return 16
+}
+
+// qrHeaderBits returns the length of the mode indicator and character count for a version
+func qrHeaderBits(version int) int {
+	return 4 + qrCountBits(version)
+}
+
+// qrGrid allocates a square grid of modules
+func qrGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+
+	return grid
+}
+
+// qrBitBuffer is a sequence of bits, used to build QR code data
+type qrBitBuffer []bool
+
+// append appends the low n bits of value, most significant bit first
+func (b *qrBitBuffer) append(value int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>uint(i))&1 != 0)
+	}
+}
+
+// bytes packs the bit buffer into bytes
+func (b qrBitBuffer) bytes() []byte {
+	out := make([]byte, (len(b)+7)/8)
+	for i, bit := range b {
+		if bit {
+			out[i>>3] |= 1 << uint(7-i&7)
+		}
+	}
+
+	return out
+}
+
+// qrAbs returns the absolute value of an integer
+func qrAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// qrMax returns the larger of two integers
+func qrMax(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,81 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"image/png"
+	"log"
+	"strings"
+	"testing"
+)
+
+// TestQRRemainder verifies that Reed-Solomon error correction matches a known QR code
+func TestQRRemainder(t *testing.T) {
+	log.Println("TestQRRemainder()")
+
+	// Known data and error correction codewords for "HELLO WORLD", version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ec := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if out := qrRemainder(data, qrDivisor(len(ec))); !bytes.Equal(out, ec) {
+		t.Fatalf("qrRemainder returned invalid codewords: %v", out)
+	}
+}
+
+// TestQRFormatVersionBits verifies that format and version information match known values
+func TestQRFormatVersionBits(t *testing.T) {
+	log.Println("TestQRFormatVersionBits()")
+
+	// Level M, mask 0, and level M, mask 5
+	if bits := qrFormatBits(0); bits != 0x5412 {
+		t.Fatalf("qrFormatBits returned invalid bits for mask 0: %015b", bits)
+	}
+	if bits := qrFormatBits(5); bits != 0x40CE {
+		t.Fatalf("qrFormatBits returned invalid bits for mask 5: %015b", bits)
+	}
+
+	// Version 7
+	if bits := qrVersionBits(7); bits != 0x07C94 {
+		t.Fatalf("qrVersionBits returned invalid bits for version 7: %018b", bits)
+	}
+}
+
+// TestQREncode verifies that qrEncode picks a proper version and renders a PNG image
+func TestQREncode(t *testing.T) {
+	log.Println("TestQREncode()")
+
+	// A typical share URL should fit in a small version
+	q, err := qrEncode([]byte("http://subsonic.example.com/share/abcdef"))
+	if err != nil {
+		t.Fatalf("qrEncode returned error: %s", err.Error())
+	}
+
+	// 40 bytes requires version 3 at level M
+	if q.size != 29 {
+		t.Fatalf("qrEncode returned invalid size: %d", q.size)
+	}
+
+	// Check for finder pattern corners
+	if !q.modules[0][0] || !q.modules[0][q.size-1] || !q.modules[q.size-1][0] {
+		t.Fatalf("qrEncode returned symbol without finder patterns")
+	}
+
+	// Render and decode the PNG
+	buf, err := q.png(2)
+	if err != nil {
+		t.Fatalf("png returned error: %s", err.Error())
+	}
+
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatalf("png returned invalid image: %s", err.Error())
+	}
+
+	if dim := img.Bounds().Dx(); dim != (29+8)*2 {
+		t.Fatalf("png returned invalid image size: %d", dim)
+	}
+
+	// Data which cannot fit in the largest supported version should fail
+	if _, err := qrEncode([]byte(strings.Repeat("a", 300))); err == nil {
+		t.Fatalf("qrEncode accepted data which is too long")
+	}
+}
@@ -0,0 +1,240 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errRangeNotSatisfiable is returned when the server rejects a range beyond the end of the media
+var errRangeNotSatisfiable = errors.New("gosubsonic: range not satisfiable")
+
+// RangeReader is an io.ReadSeekCloser for a media stream or download from Subsonic.  Each
+// Seek to a new position closes the current response, and the next Read issues a new HTTP
+// Range request starting at that position, which allows players to seek and downloads to
+// resume without retrieving the entire file.
+type RangeReader struct {
+	ctx    context.Context
+	client Client
+	url    string
+
+	body   io.ReadCloser
+	offset int64
+	size   int64
+}
+
+// OpenStream opens a seekable processed media file stream, with an optional StreamOptions
+// struct.  Servers may not support seeking within transcoded streams, in which case Seek
+// falls back to discarding data up to the requested position.
+func (s Client) OpenStream(ctx context.Context, id ID, options *StreamOptions) (*RangeReader, error) {
+	r, err := s.openRange(ctx, s.streamURL(id, options))
+	if err != nil {
+		return nil, err
+	}
+
+	s.autoScrobble(ctx, id, options)
+	return r, nil
+}
+
+// OpenDownload opens a seekable raw, non-transcoded media file stream
+func (s Client) OpenDownload(ctx context.Context, id ID) (*RangeReader, error) {
+	return s.openRange(ctx, s.makeURL("download", idParams(id)))
+}
+
+// StreamRange returns a io.ReadCloser which contains length bytes of a processed media file
+// stream starting at offset, with an optional StreamOptions struct.  A length of 0 retrieves
+// all remaining bytes.  The total size of the stream is also returned, or -1 if unknown.
+func (s Client) StreamRange(ctx context.Context, id ID, options *StreamOptions, offset int64, length int64) (io.ReadCloser, int64, error) {
+	body, size, err := s.fetchRange(ctx, s.streamURL(id, options), offset, length)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Only the start of a stream begins playback
+	if offset == 0 {
+		s.autoScrobble(ctx, id, options)
+	}
+
+	return body, size, nil
+}
+
+// DownloadRange returns a io.ReadCloser which contains length bytes of a raw, non-transcoded
+// media file stream starting at offset.  A length of 0 retrieves all remaining bytes.  The total
+// size of the file is also returned, or -1 if unknown.
+func (s Client) DownloadRange(ctx context.Context, id ID, offset int64, length int64) (io.ReadCloser, int64, error) {
+	return s.fetchRange(ctx, s.makeURL("download", idParams(id)), offset, length)
+}
+
+// openRange opens a RangeReader at the start of the specified URL
+func (s Client) openRange(ctx context.Context, url string) (*RangeReader, error) {
+	body, size, err := s.fetchRange(ctx, url, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeReader{
+		ctx:    ctx,
+		client: s,
+		url:    url,
+		body:   body,
+		size:   size,
+	}, nil
+}
+
+// Size returns the total size of the media in bytes, or -1 if the server did not report it
+func (r *RangeReader) Size() int64 {
+	return r.size
+}
+
+// Read implements io.Reader, issuing a new request if the reader was moved by Seek
+func (r *RangeReader) Read(p []byte) (int, error) {
+	if r.body == nil {
+		if r.size >= 0 && r.offset >= r.size {
+			return 0, io.EOF
+		}
+
+		body, size, err := r.client.fetchRange(r.ctx, r.url, r.offset, 0)
+		if err != nil {
+			return 0, err
+		}
+
+		// The size may only be reported by later responses
+		if r.size < 0 {
+			r.size = size
+		}
+		r.body = body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+// Seek implements io.Seeker.  Seeking relative to the end requires the size to be known.
+func (r *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		if r.size < 0 {
+			return 0, errors.New("gosubsonic: cannot seek relative to end of media with unknown size")
+		}
+
+		abs = r.size + offset
+	default:
+		return 0, errors.New("gosubsonic: invalid seek whence")
+	}
+
+	if abs < 0 {
+		return 0, errors.New("gosubsonic: cannot seek to negative position")
+	}
+
+	// The current response is only discarded when the position actually changes
+	if abs != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+
+	r.offset = abs
+	return abs, nil
+}
+
+// Close implements io.Closer
+func (r *RangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// fetchRange retrieves length bytes of a binary stream starting at offset, and returns the
+// stream along with the total size reported by the server, or -1 if unknown
+func (s Client) fetchRange(ctx context.Context, url string, offset int64, length int64) (io.ReadCloser, int64, error) {
+	if offset < 0 || length < 0 {
+		return nil, 0, errors.New("gosubsonic: invalid range")
+	}
+
+	// Only request a range when one is needed, so servers which handle ranges poorly
+	// are unaffected for ordinary requests
+	var header http.Header
+	if offset > 0 || length > 0 {
+		header = http.Header{"Range": {rangeHeader(offset, length)}}
+	}
+
+	res, err := s.fetchBinaryResponse(ctx, url, header)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		return res.Body, contentRangeSize(res.Header.Get("Content-Range")), nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		res.Body.Close()
+		return nil, 0, fmt.Errorf("%w: %s - %s", errRangeNotSatisfiable, rangeHeader(offset, length), redactURL(url))
+	}
+
+	// The server ignored the range and returned the entire file, so skip to the offset and
+	// limit the length manually
+	size := res.ContentLength
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+
+			// The offset is beyond the end of the media
+			if err == io.EOF {
+				return nil, 0, fmt.Errorf("%w: %s - %s", errRangeNotSatisfiable, rangeHeader(offset, length), redactURL(url))
+			}
+
+			return nil, 0, fmt.Errorf("gosubsonic: failed to skip to offset %d: %s - %s", offset, err.Error(), redactURL(url))
+		}
+	}
+
+	if length > 0 {
+		return limitReadCloser{io.LimitReader(res.Body, length), res.Body}, size, nil
+	}
+
+	return res.Body, size, nil
+}
+
+// rangeHeader generates the value of a HTTP Range header for the specified offset and length
+func rangeHeader(offset int64, length int64) string {
+	if length == 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// contentRangeSize parses the total size from a HTTP Content-Range header, such as
+// "bytes 0-1023/4096", returning -1 if the size is unknown
+func contentRangeSize(contentRange string) int64 {
+	i := strings.LastIndex(contentRange, "/")
+	if i < 0 {
+		return -1
+	}
+
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return size
+}
+
+// limitReadCloser is an io.ReadCloser which reads from a limited reader, and closes the
+// underlying response body
+type limitReadCloser struct {
+	io.Reader
+	io.Closer
+}
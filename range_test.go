@@ -0,0 +1,136 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rangeTestClient generates a client for a test server which serves media for stream and
+// download requests, optionally honoring HTTP Range requests
+func rangeTestClient(t *testing.T, media []byte, ranges bool) (*Client, func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/ping.view" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		w.Header().Set("Content-Type", "audio/mpeg")
+		if !ranges {
+			w.Write(media)
+			return
+		}
+
+		http.ServeContent(w, r, "media.mp3", time.Time{}, bytes.NewReader(media))
+	}))
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		srv.Close()
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	return s, srv.Close
+}
+
+// TestDownloadRange verifies that client.DownloadRange() retrieves the requested bytes,
+// whether or not the server honors HTTP Range requests
+func TestDownloadRange(t *testing.T) {
+	log.Println("TestDownloadRange()")
+
+	media := []byte("0123456789abcdefghij")
+
+	var tests = []struct {
+		offset int64
+		length int64
+		output string
+	}{
+		{0, 0, "0123456789abcdefghij"},
+		{10, 0, "abcdefghij"},
+		{5, 3, "567"},
+	}
+
+	for _, ranges := range []bool{true, false} {
+		s, done := rangeTestClient(t, media, ranges)
+
+		for _, test := range tests {
+			body, size, err := s.DownloadRange(context.Background(), "1", test.offset, test.length)
+			if err != nil {
+				t.Fatalf("DownloadRange returned error: %s", err.Error())
+			}
+
+			out, err := io.ReadAll(body)
+			body.Close()
+			if err != nil {
+				t.Fatalf("DownloadRange body returned error: %s", err.Error())
+			}
+
+			if string(out) != test.output || size != int64(len(media)) {
+				t.Fatalf("DownloadRange(%d, %d) with ranges %v returned %q, size %d",
+					test.offset, test.length, ranges, out, size)
+			}
+		}
+
+		done()
+	}
+}
+
+// TestOpenDownloadSeek verifies that a RangeReader reads from the correct position after seeking
+func TestOpenDownloadSeek(t *testing.T) {
+	log.Println("TestOpenDownloadSeek()")
+
+	media := []byte("0123456789abcdefghij")
+	s, done := rangeTestClient(t, media, true)
+	defer done()
+
+	r, err := s.OpenDownload(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("OpenDownload returned error: %s", err.Error())
+	}
+	defer r.Close()
+
+	if r.Size() != int64(len(media)) {
+		t.Fatalf("OpenDownload returned invalid size: %d", r.Size())
+	}
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(r, buf); err != nil || string(buf) != "0123" {
+		t.Fatalf("RangeReader returned invalid data: %q, %v", buf, err)
+	}
+
+	var tests = []struct {
+		offset int64
+		whence int
+		output string
+	}{
+		{-4, io.SeekEnd, "ghij"},
+		{10, io.SeekStart, "abcd"},
+		{2, io.SeekCurrent, "ghij"},
+	}
+
+	for _, test := range tests {
+		if _, err := r.Seek(test.offset, test.whence); err != nil {
+			t.Fatalf("RangeReader seek returned error: %s", err.Error())
+		}
+
+		if _, err := io.ReadFull(r, buf); err != nil || string(buf) != test.output {
+			t.Fatalf("RangeReader returned invalid data after seek: %q, %v", buf, err)
+		}
+	}
+
+	// Reading at the end returns EOF without another request
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Fatalf("RangeReader returned unexpected error at end: %v", err)
+	}
+
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Fatalf("RangeReader allowed seek to negative position")
+	}
+}
@@ -0,0 +1,168 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the rate and concurrency of requests to a server
+type RateLimit struct {
+	// RequestsPerSecond limits the average rate of requests, if set (> 0)
+	RequestsPerSecond float64
+
+	// Burst is the number of requests which may be made at once before RequestsPerSecond
+	// applies, default 1
+	Burst int
+
+	// MaxInFlight limits the number of concurrent requests, if set (>= 1).  Binary requests,
+	// such as Stream, remain in flight until their body is closed.
+	MaxInFlight int
+}
+
+// Limiter enforces a RateLimit on all requests made by the clients which share it, with
+// optional overrides for individual API methods.  A Limiter is safe for concurrent use.
+type Limiter struct {
+	limit   *limit
+	methods map[string]*limit
+}
+
+// NewLimiter creates a new Limiter which applies the specified RateLimit to all requests.
+// Requests for each API method in methods, such as "stream", are instead limited by their
+// own RateLimit, independently of all other requests.
+func NewLimiter(l RateLimit, methods map[string]RateLimit) *Limiter {
+	lim := &Limiter{
+		limit:   newLimit(l),
+		methods: make(map[string]*limit, len(methods)),
+	}
+	for m, ml := range methods {
+		lim.methods[m] = newLimit(ml)
+	}
+
+	return lim
+}
+
+// wait waits until a request for the specified API method is permitted, and returns a
+// function which must be called once the request is finished
+func (l *Limiter) wait(ctx context.Context, method string) (func(), error) {
+	if ml, ok := l.methods[method]; ok {
+		return ml.wait(ctx)
+	}
+
+	return l.limit.wait(ctx)
+}
+
+// limit tracks the state of a single RateLimit, using a token bucket for the request rate
+// and a semaphore for requests in flight
+type limit struct {
+	sem chan struct{}
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimit creates a limit for the specified RateLimit
+func newLimit(l RateLimit) *limit {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	lim := &limit{
+		rate:   l.RequestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+	if l.MaxInFlight > 0 {
+		lim.sem = make(chan struct{}, l.MaxInFlight)
+	}
+
+	return lim
+}
+
+// wait waits for a token and a free slot, unless ctx is canceled first
+func (l *limit) wait(ctx context.Context) (func(), error) {
+	if err := l.reserve(ctx); err != nil {
+		return nil, err
+	}
+
+	if l.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.sem })
+	}, nil
+}
+
+// reserve takes a token from the bucket, waiting until one is available
+func (l *limit) reserve(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// Return the unused token, so canceled requests do not delay others
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// limit waits until the client's Limiter permits a request for the specified URL, and
+// returns a function which must be called once the request is finished
+func (s Client) limit(ctx context.Context, url string) (func(), error) {
+	if s.Limiter == nil {
+		return func() {}, nil
+	}
+
+	return s.Limiter.wait(ctx, urlMethod(url))
+}
+
+// releaseReadCloser is an io.ReadCloser which invokes a function once it is closed
+type releaseReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the underlying io.ReadCloser, and invokes the release function
+func (r releaseReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,142 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLimiterMaxInFlight verifies that a Limiter caps the number of concurrent requests
+func TestLimiterMaxInFlight(t *testing.T) {
+	log.Println("TestLimiterMaxInFlight()")
+
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(mockTable[0].data)
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+	s.Limiter = NewLimiter(RateLimit{MaxInFlight: 2}, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Ping(context.Background()); err != nil {
+				t.Errorf("Ping returned error: %s", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Fatalf("Limiter permitted %d concurrent requests", peak)
+	}
+}
+
+// TestLimiterRate verifies that a Limiter limits the rate of requests after a burst
+func TestLimiterRate(t *testing.T) {
+	log.Println("TestLimiterRate()")
+
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+	s.Limiter = NewLimiter(RateLimit{RequestsPerSecond: 100, Burst: 2}, nil)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := s.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping returned error: %s", err.Error())
+		}
+	}
+
+	// Two requests are permitted immediately, and the remaining four are spaced by 10ms
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("Limiter permitted requests too quickly: %s", elapsed)
+	}
+
+	// Requests waiting for a token are canceled along with their context
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	s.Limiter = NewLimiter(RateLimit{RequestsPerSecond: 0.1}, nil)
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatalf("Ping returned error: %s", err.Error())
+	}
+	if _, err := s.Ping(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Ping returned unexpected error: %v", err)
+	}
+}
+
+// TestLimiterMethod verifies that a Limiter applies per-method limits independently, and that
+// binary requests remain in flight until their body is closed
+func TestLimiterMethod(t *testing.T) {
+	log.Println("TestLimiterMethod()")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/stream.view" {
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("audio"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(mockTable[0].data)
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+	s.Limiter = NewLimiter(RateLimit{MaxInFlight: 1}, map[string]RateLimit{
+		"stream": {MaxInFlight: 1},
+	})
+
+	stream, err := s.Stream(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+
+	// Other methods are unaffected by an open stream
+	if _, err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping returned error: %s", err.Error())
+	}
+
+	// A second stream waits until the first is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Stream(ctx, "1", nil); err != context.DeadlineExceeded {
+		t.Fatalf("Stream returned unexpected error: %v", err)
+	}
+
+	stream.Close()
+	stream, err = s.Stream(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	stream.Close()
+}
@@ -0,0 +1,52 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Raw calls an API method which this package does not implement, such as a new or
+// server-specific endpoint, and returns the contents of its "subsonic-response" object,
+// which may be decoded into a caller's own types.  Authentication and client parameters are
+// added to params automatically.  Errors reported by the server are returned as *Error.
+//
+// XML responses are converted into the equivalent JSON.  Responses are never cached.
+func (s Client) Raw(ctx context.Context, method string, params Params) (json.RawMessage, error) {
+	body, _, err := s.call(ctx, s.makeURL(method, params), true)
+	if err != nil {
+		return nil, err
+	}
+
+	if isXML(body) {
+		if body, err = xmlToJSON(body); err != nil {
+			return nil, fmt.Errorf("gosubsonic: failed to parse response XML: %s", err.Error())
+		}
+	}
+
+	var res struct {
+		Response json.RawMessage `json:"subsonic-response"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to parse response JSON: %s", err.Error())
+	}
+
+	return res.Response, nil
+}
+
+// RawBinary calls an API method which this package does not implement and which returns
+// binary data, such as a server-specific media endpoint, and returns the response as a
+// StreamResponse, which must be closed by the caller.  As with Raw, authentication and client
+// parameters are added to params automatically.
+func (s Client) RawBinary(ctx context.Context, method string, params Params) (*StreamResponse, error) {
+	res, err := s.fetchBinaryResponse(ctx, s.makeURL(method, params), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamResponse{
+		ReadCloser:    res.Body,
+		ContentType:   res.Header.Get("Content-Type"),
+		ContentLength: res.ContentLength,
+	}, nil
+}
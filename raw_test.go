@@ -0,0 +1,70 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/url"
+	"testing"
+)
+
+// TestRaw verifies that client.Raw() calls unimplemented API methods, returning their
+// responses as JSON and their errors as *Error
+func TestRaw(t *testing.T) {
+	log.Println("TestRaw()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	f.SetFixture("getTranscodeDecision", url.Values{"mediaId": {"1"}}, []byte(`{"subsonic-response": {
+		"status": "ok", "version": "1.16.1", "transcodeDecision": {"canDirectPlay": true}}}`))
+	f.SetFixture("getLyricsBySongId", nil, []byte(`<subsonic-response status="ok" version="1.16.1">
+		<lyricsList><structuredLyrics lang="eng" synced="false"/></lyricsList></subsonic-response>`))
+	f.SetMedia("1", "image/png", []byte("image"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	ctx := context.Background()
+	raw, err := s.Raw(ctx, "getTranscodeDecision", Params{"mediaId": {"1"}})
+	if err != nil {
+		t.Fatalf("Raw returned error: %s", err.Error())
+	}
+
+	var res struct {
+		Status            string `json:"status"`
+		TranscodeDecision struct {
+			CanDirectPlay bool `json:"canDirectPlay"`
+		} `json:"transcodeDecision"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil || res.Status != "ok" || !res.TranscodeDecision.CanDirectPlay {
+		t.Fatalf("Raw returned invalid response: %s", raw)
+	}
+
+	// XML responses are converted to JSON
+	raw, err = s.Raw(ctx, "getLyricsBySongId", nil)
+	if err != nil {
+		t.Fatalf("Raw returned error: %s", err.Error())
+	}
+	if !json.Valid(raw) || raw[0] != '{' {
+		t.Fatalf("Raw returned invalid XML response: %s", raw)
+	}
+
+	// Errors are reported by the server
+	if _, err := s.Raw(ctx, "getTranscodeDecision", Params{"mediaId": {"2"}}); !IsNotFound(err) {
+		t.Fatalf("Raw returned unexpected error: %v", err)
+	}
+
+	stream, err := s.RawBinary(ctx, "getCoverArt", Params{"id": {"1"}})
+	if err != nil {
+		t.Fatalf("RawBinary returned error: %s", err.Error())
+	}
+	defer stream.Close()
+
+	if b, err := io.ReadAll(stream); err != nil || string(b) != "image" || stream.ContentType != "image/png" {
+		t.Fatalf("RawBinary returned invalid response: %q %s", b, stream.ContentType)
+	}
+}
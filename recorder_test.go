@@ -0,0 +1,79 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecorderReplayer verifies that responses saved by a Recorder are served by a Replayer,
+// and that recordings contain no credentials
+func TestRecorderReplayer(t *testing.T) {
+	log.Println("TestRecorderReplayer()")
+
+	f := NewFakeServer()
+	defer f.Close()
+	f.SetCredentials("alice", "hunter2")
+	f.SetMedia("1", "audio/mpeg", []byte("0123456789"))
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// record performs the requests to be recorded or replayed
+	record := func(s *Client) (string, string) {
+		song, err := s.GetSong(ctx, "1")
+		if err != nil {
+			t.Fatalf("GetSong returned error: %s", err.Error())
+		}
+
+		body, _, err := s.DownloadRange(ctx, "1", 2, 4)
+		if err != nil {
+			t.Fatalf("DownloadRange returned error: %s", err.Error())
+		}
+		defer body.Close()
+
+		out, _ := io.ReadAll(body)
+		return song.Title, string(out)
+	}
+
+	s, err := New(f.URL, WithPassword("alice", "hunter2"), WithHTTPClient(&http.Client{Transport: &Recorder{Dir: dir}}))
+	if err != nil {
+		t.Fatalf("Could not generate recording client: %s", err.Error())
+	}
+	s.TokenAuth = true
+	title, data := record(s)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("Recorder saved %d recordings", len(files))
+	}
+	for _, file := range files {
+		out, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(out), "alice") || strings.Contains(string(out), "hunter2") {
+			t.Fatalf("Recorder saved credentials: %s", out)
+		}
+	}
+
+	// Replayed responses are identical, and do not require a server
+	f.Close()
+	r, err := New("replay.example.com", WithPassword("bob", "secret"), WithHTTPClient(&http.Client{Transport: &Replayer{Dir: dir}}))
+	if err != nil {
+		t.Fatalf("Could not generate replay client: %s", err.Error())
+	}
+
+	if rTitle, rData := record(r); rTitle != title || rData != data || rData != "2345" {
+		t.Fatalf("Replayer returned %q, %q instead of %q, %q", rTitle, rData, title, data)
+	}
+
+	if _, err := r.GetSong(ctx, "2"); err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Fatalf("GetSong returned unexpected error without recording: %v", err)
+	}
+}
@@ -9,12 +9,6 @@ type apiContainer struct {
 	Response APIStatus `json:"subsonic-response"`
 }
 
-// APIError represents any errors reported by Subsonic
-type APIError struct {
-	Code    int
-	Message string
-}
-
 // APIStatus represents the current status of Subsonic
 type APIStatus struct {
 	// Common fields
@@ -22,23 +16,172 @@ type APIStatus struct {
 	Version string
 	Xmlns   string
 
+	// OpenSubsonic fields - returned only by servers which implement OpenSubsonic, such as
+	// Navidrome and Gonic
+	Type          string
+	ServerVersion string
+	OpenSubsonic  bool
+
 	// API error - returned only when an error occurs
-	Error APIError
+	Error Error
 
 	// license - returned only in GetLicense
 	License License
 
+	// openSubsonicExtensions - returned only in GetOpenSubsonicExtensions
+	OpenSubsonicExtensions oneOrMany[OpenSubsonicExtension]
+
 	// musicFolders - returned only in GetMusicFolders
-	MusicFolders apiMusicFolderContainer
+	MusicFolders struct {
+		MusicFolder oneOrMany[MusicFolder]
+	}
 
 	// indexes - returned only in GetIndexes
-	Indexes apiIndexesContainer
+	Indexes Indexes
 
 	// directory - returned only in GetMusicDirectory
-	Directory apiMusicDirectoryContainer
+	Directory struct {
+		Child Content
+	}
+
+	// artists - returned only in GetArtists
+	Artists struct {
+		Index oneOrMany[IndexID3]
+	}
+
+	// artist - returned only in GetArtist
+	Artist *ArtistID3
+
+	// album - returned only in GetAlbum
+	Album *AlbumID3
+
+	// song - returned only in GetSong
+	Song *Audio
+
+	// playlists - returned only in GetPlaylists
+	Playlists struct {
+		Playlist oneOrMany[Playlist]
+	}
+
+	// playlist - returned only in GetPlaylist and CreatePlaylist
+	Playlist *Playlist
+
+	// searchResult2 - returned only in Search2
+	SearchResult2 SearchResult2
+
+	// searchResult3 - returned only in Search3
+	SearchResult3 SearchResult3
+
+	// genres - returned only in GetGenres
+	Genres struct {
+		Genre oneOrMany[Genre]
+	}
+
+	// videos - returned only in GetVideos
+	Videos struct {
+		Video oneOrMany[Video]
+	}
+
+	// videoInfo - returned only in GetVideoInfo
+	VideoInfo *VideoInfo
+
+	// artistInfo - returned only in GetArtistInfo
+	ArtistInfo *ArtistInfo
+
+	// artistInfo2 - returned only in GetArtistInfo2
+	ArtistInfo2 *ArtistInfo2
+
+	// albumInfo - returned only in GetAlbumInfo and GetAlbumInfo2
+	AlbumInfo *AlbumInfo
+
+	// similarSongs - returned only in GetSimilarSongs
+	SimilarSongs apiSongsContainer
+
+	// similarSongs2 - returned only in GetSimilarSongs2
+	SimilarSongs2 apiSongsContainer
+
+	// topSongs - returned only in GetTopSongs
+	TopSongs apiSongsContainer
+
+	// albumList - returned only in GetAlbumList
+	AlbumList struct {
+		Album oneOrMany[Directory]
+	}
+
+	// albumList2 - returned only in GetAlbumList2
+	AlbumList2 struct {
+		Album oneOrMany[AlbumID3]
+	}
+
+	// randomSongs - returned only in GetRandomSongs
+	RandomSongs apiSongsContainer
+
+	// songsByGenre - returned only in GetSongsByGenre
+	SongsByGenre apiSongsContainer
+
+	// starred - returned only in GetStarred
+	Starred Starred
+
+	// starred2 - returned only in GetStarred2
+	Starred2 Starred2
+
+	// lyrics - returned only in GetLyrics
+	Lyrics *Lyrics
 
 	// nowPlaying - returned only in GetNowPlaying
-	NowPlaying interface{}
+	NowPlaying struct {
+		Entry oneOrMany[NowPlaying]
+	}
+
+	// shares - returned only in share methods
+	Shares struct {
+		Share oneOrMany[Share]
+	}
+
+	// podcasts - returned only in GetPodcasts
+	Podcasts struct {
+		Channel oneOrMany[PodcastChannel]
+	}
+
+	// internetRadioStations - returned only in GetInternetRadioStations
+	InternetRadioStations struct {
+		InternetRadioStation oneOrMany[InternetRadioStation]
+	}
+
+	// chatMessages - returned only in GetChatMessages
+	ChatMessages struct {
+		ChatMessage oneOrMany[ChatMessage]
+	}
+
+	// user - returned only in GetUser
+	User *User
+
+	// users - returned only in GetUsers
+	Users struct {
+		User oneOrMany[User]
+	}
+
+	// bookmarks - returned only in GetBookmarks
+	Bookmarks struct {
+		Bookmark oneOrMany[Bookmark]
+	}
+
+	// playQueue - returned only in GetPlayQueue
+	PlayQueue *PlayQueue
+
+	// scanStatus - returned only in GetScanStatus and StartScan
+	ScanStatus ScanStatus
+
+	// jukeboxStatus - returned only in jukebox control actions
+	JukeboxStatus JukeboxStatus
+
+	// jukeboxPlaylist - returned only in JukeboxGet
+	JukeboxPlaylist JukeboxPlaylist
+}
+
+// apiSongsContainer represents the container for a list of songs
+type apiSongsContainer struct {
+	Song oneOrMany[Audio]
 }
 
 // License represents the license status of Subsonic
@@ -51,11 +194,27 @@ type License struct {
 
 	// Parsed values
 	Date time.Time
+
+	// present is set if the response contained a license element
+	present bool
 }
 
-// apiMusicFolderContainer represents the container for one or more MusicFolders
-type apiMusicFolderContainer struct {
-	MusicFolder interface{}
+// OpenSubsonicExtension represents an OpenSubsonic API extension supported by a server,
+// and the versions of that extension which are supported
+type OpenSubsonicExtension struct {
+	Name     string
+	Versions []int64
+}
+
+// Supports determines if the specified version of the extension is supported
+func (e OpenSubsonicExtension) Supports(version int64) bool {
+	for _, v := range e.Versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
 }
 
 // MusicFolder represents a top-level music folders of Subsonic
@@ -64,48 +223,113 @@ type MusicFolder struct {
 	Name string
 }
 
-// apiIndexesContainer represents the container for a slice of Index structs
-type apiIndexesContainer struct {
-	Index interface{}
+// Indexes represents the indexed structure of all artists from Subsonic
+type Indexes struct {
+	// Index contains the artists, grouped by the first letter of their names
+	Index []Index
+
+	// Shortcuts are directories configured as shortcuts on the server, such as a folder of
+	// new music, which are displayed before the index
+	Shortcuts []IndexArtist
+
+	// Children are media files stored directly in the root of a music folder
+	Children Content
+
+	// IgnoredArticles are the articles ignored when sorting artists, such as "The El La"
+	IgnoredArticles string
+
+	// LastModified is the time the indexes were last modified, which may be passed to
+	// GetIndexes to retrieve the indexes only if they were modified since
+	LastModified time.Time
+}
+
+// lastModified returns the time the indexes were last modified, in milliseconds, or 0 if
+// unknown
+func (i *Indexes) lastModified() int64 {
+	if i.LastModified.IsZero() {
+		return 0
+	}
+
+	return i.LastModified.UnixMilli()
 }
 
 // Index represents a group in the Subsonic index
 type Index struct {
-	// Raw values
-	Name      string
-	ArtistRaw interface{} `json:"artist"`
-
-	// Artist - generated from raw interfaces
+	Name   string
 	Artist []IndexArtist
 }
 
 // IndexArtist represents an artist in the Subsonic index
 type IndexArtist struct {
-	ID   int64
+	ID   ID
 	Name string
 }
 
-// apiMusicDirectoryContainer represents the container for a slice of Directory structs
-type apiMusicDirectoryContainer struct {
-	Child interface{}
-}
-
 // Content is a container used to contain the Directory, Audio, and Video structs residing in this Directory
 type Content struct {
 	Audio       []Audio
 	Directories []Directory
 	Video       []Video
+
+	// Children contains every item in the directory, in the order returned by the server.
+	// Each points to the same item in the Audio, Directories, or Video slice.
+	Children []Child
+}
+
+// Child is an item in a directory, which is one of *Directory, *Audio, or *Video, and may be
+// distinguished using a type switch:
+//
+//	for _, ch := range content.Children {
+//		switch ch := ch.(type) {
+//		case *gosubsonic.Directory:
+//			fmt.Println("dir:", ch.Title)
+//		case *gosubsonic.Audio:
+//			fmt.Println("song:", ch.Title)
+//		}
+//	}
+type Child interface {
+	// ChildID returns the ID of the item
+	ChildID() ID
+
+	// ChildTitle returns the title of the item
+	ChildTitle() string
+
+	isChild()
 }
 
+// ChildID implements Child
+func (d *Directory) ChildID() ID { return d.ID }
+
+// ChildTitle implements Child
+func (d *Directory) ChildTitle() string { return d.Title }
+
+func (d *Directory) isChild() {}
+
+// ChildID implements Child
+func (a *Audio) ChildID() ID { return a.ID }
+
+// ChildTitle implements Child
+func (a *Audio) ChildTitle() string { return a.Title }
+
+func (a *Audio) isChild() {}
+
+// ChildID implements Child
+func (v *Video) ChildID() ID { return v.ID }
+
+// ChildTitle implements Child
+func (v *Video) ChildTitle() string { return v.Title }
+
+func (v *Video) isChild() {}
+
 // Directory represents a media directory from Subsonic
 type Directory struct {
 	// Raw values
-	ID         int64
+	ID         ID
 	Album      string
 	Artist     string
-	CoverArt   int64
+	CoverArt   ID
 	CreatedRaw string `json:"created"`
-	Parent     int64
+	Parent     ID
 	Title      string
 
 	// Parsed values
@@ -115,19 +339,19 @@ type Directory struct {
 // Audio represents an audio item from Subsonic
 type Audio struct {
 	// Raw values
-	ID                    int64
+	ID                    ID
 	Album                 string
-	AlbumID               int64
+	AlbumID               ID
 	Artist                string
-	ArtistID              int64
+	ArtistID              ID
 	BitRate               int64
 	ContentType           string
-	CoverArt              int64
+	CoverArt              ID
 	CreatedRaw            string `json:"created"`
 	DiscNumber            int64
 	DurationRaw           int64 `json:"duration"`
 	Genre                 string
-	Parent                int64
+	Parent                ID
 	Path                  string
 	Size                  int64
 	Suffix                string
@@ -138,21 +362,38 @@ type Audio struct {
 	Type                  string
 	Year                  int64
 
+	// OpenSubsonic values - only returned by servers which implement OpenSubsonic
+	MusicBrainzID string
+	SortName      string
+	BPM           int64
+	Comment       string
+	ReplayGain    *ReplayGain
+
 	// Parsed values
 	Created  time.Time
 	Duration time.Duration
 }
 
+// ReplayGain represents the OpenSubsonic ReplayGain values for an audio item, in dB
+type ReplayGain struct {
+	TrackGain    float64
+	AlbumGain    float64
+	TrackPeak    float64
+	AlbumPeak    float64
+	BaseGain     float64
+	FallbackGain float64
+}
+
 // Video represents a video item from Subsonic
 type Video struct {
 	// Raw values
-	ID                    int64
+	ID                    ID
 	BitRate               int64
 	ContentType           string
-	CoverArt              int64
+	CoverArt              ID
 	CreatedRaw            string `json:"created"`
 	DurationRaw           int64  `json:"duration"`
-	Parent                int64
+	Parent                ID
 	Path                  string
 	Size                  int64
 	Suffix                string
@@ -165,22 +406,139 @@ type Video struct {
 	Duration time.Duration
 }
 
-// apiNowPlayingContainer represents the container for a slice of NowPlaying structs
-type apiNowPlayingContainer struct {
-	Entry interface{}
+// Genre represents a genre from Subsonic
+type Genre struct {
+	Name       string
+	SongCount  int64
+	AlbumCount int64
+}
+
+// ArtistBio represents biography, Last.fm, and image details for an artist from Subsonic
+type ArtistBio struct {
+	Biography      string
+	MusicBrainzID  string
+	LastFMURL      string
+	SmallImageURL  string
+	MediumImageURL string
+	LargeImageURL  string
+}
+
+// ArtistInfo represents details and similar artists for an artist, organized by file structure
+type ArtistInfo struct {
+	ArtistBio
+
+	SimilarArtist []IndexArtist
+}
+
+// ArtistInfo2 represents details and similar artists for an artist, organized by ID3 tags
+type ArtistInfo2 struct {
+	ArtistBio
+
+	SimilarArtist []ArtistID3
+}
+
+// AlbumInfo represents notes, Last.fm, and image details for an album from Subsonic
+type AlbumInfo struct {
+	Notes          string
+	MusicBrainzID  string
+	LastFMURL      string
+	SmallImageURL  string
+	MediumImageURL string
+	LargeImageURL  string
+}
+
+// VideoInfo represents the captions, audio tracks, and conversions available for a video
+type VideoInfo struct {
+	ID          ID
+	Captions    []VideoCaptions
+	AudioTracks []VideoAudioTrack
+	Conversions []VideoConversion
+}
+
+// VideoCaptions represents captions available for a video
+type VideoCaptions struct {
+	ID   ID
+	Name string
+}
+
+// VideoAudioTrack represents an audio track available for a video
+type VideoAudioTrack struct {
+	ID           ID
+	Name         string
+	LanguageCode string
+}
+
+// VideoConversion represents a converted version of a video
+type VideoConversion struct {
+	ID      ID
+	BitRate int64
+}
+
+// IndexID3 represents a group of artists from Subsonic, organized by ID3 tags
+type IndexID3 struct {
+	Name   string
+	Artist []ArtistID3
+}
+
+// ArtistID3 represents an artist from Subsonic, organized by ID3 tags
+type ArtistID3 struct {
+	ID         ID
+	Name       string
+	CoverArt   ID
+	AlbumCount int64
+
+	// OpenSubsonic values - only returned by servers which implement OpenSubsonic
+	MusicBrainzID string
+	SortName      string
+
+	// Album - only in GetArtist
+	Album []AlbumID3
+}
+
+// AlbumID3 represents an album from Subsonic, organized by ID3 tags
+type AlbumID3 struct {
+	// Raw values
+	ID          ID
+	Name        string
+	Artist      string
+	ArtistID    ID
+	CoverArt    ID
+	SongCount   int64
+	CreatedRaw  string `json:"created"`
+	DurationRaw int64  `json:"duration"`
+	Genre       string
+	Year        int64
+
+	// OpenSubsonic values - only returned by servers which implement OpenSubsonic
+	MusicBrainzID string
+	SortName      string
+	IsCompilation bool
+
+	Song []Audio
+
+	// Parsed values
+	Created  time.Time
+	Duration time.Duration
+}
+
+// Lyrics represents the lyrics for a song from Subsonic
+type Lyrics struct {
+	Artist string
+	Title  string
+	Text   string
 }
 
 // NowPlaying represents a now playing entry from Subsonic
 type NowPlaying struct {
 	// Raw values
-	ID          int64
+	ID          ID
 	Album       string
-	AlbumID     int64
+	AlbumID     ID
 	Artist      string
-	ArtistID    int64
+	ArtistID    ID
 	BitRate     int64
 	ContentType string
-	CoverArt    int64
+	CoverArt    ID
 	CreatedRaw  string `json:"created"`
 	DiscNumber  int64
 	DurationRaw int64
@@ -188,9 +546,10 @@ type NowPlaying struct {
 	IsDir       bool
 	IsVideo     bool
 	MinutesAgo  int64
-	Parent      int64
+	Parent      ID
 	Path        string
 	PlayerID    int64
+	PlayerName  string
 	Size        int64
 	Suffix      string
 	Title       string
@@ -202,3 +561,261 @@ type NowPlaying struct {
 	Created  time.Time
 	Duration time.Duration
 }
+
+// SearchResult2 represents search results from Subsonic, organized by file structure
+type SearchResult2 struct {
+	Artists []IndexArtist
+	Albums  []Directory
+	Songs   []Audio
+}
+
+// SearchResult3 represents search results from Subsonic, organized by ID3 tags
+type SearchResult3 struct {
+	Artists []ArtistID3
+	Albums  []AlbumID3
+	Songs   []Audio
+}
+
+// Starred represents starred items from Subsonic, organized by file structure
+type Starred struct {
+	Artists []IndexArtist
+	Albums  []Directory
+	Songs   []Audio
+}
+
+// Starred2 represents starred items from Subsonic, organized by ID3 tags
+type Starred2 struct {
+	Artists []ArtistID3
+	Albums  []AlbumID3
+	Songs   []Audio
+}
+
+// Playlist represents a playlist from Subsonic
+type Playlist struct {
+	// Raw values
+	ID          ID
+	Name        string
+	Comment     string
+	Owner       string
+	Public      bool
+	SongCount   int64
+	DurationRaw int64  `json:"duration"`
+	CreatedRaw  string `json:"created"`
+	ChangedRaw  string `json:"changed"`
+
+	// Entry - only returned in GetPlaylist
+	Entry []PlaylistEntry
+
+	// Parsed values
+	Created  time.Time
+	Changed  time.Time
+	Duration time.Duration
+}
+
+// PlaylistEntry represents an audio item in a playlist, along with its position in the playlist
+type PlaylistEntry struct {
+	Audio
+
+	// Zero-based position in the playlist, as used by UpdatePlaylistOptions.SongIndexToRemove
+	Index int64
+}
+
+// Share represents a public share of media from Subsonic
+type Share struct {
+	// Raw values
+	ID             ID
+	URL            string
+	Description    string
+	Username       string
+	VisitCount     int64
+	CreatedRaw     string `json:"created"`
+	ExpiresRaw     string `json:"expires"`
+	LastVisitedRaw string `json:"lastVisited"`
+
+	Entry []Audio
+
+	// Parsed values
+	Created     time.Time
+	Expires     time.Time
+	LastVisited time.Time
+}
+
+// PodcastStatus represents the download status of a podcast channel or episode
+type PodcastStatus string
+
+// Podcast statuses returned by Subsonic
+const (
+	PodcastStatusNew         PodcastStatus = "new"
+	PodcastStatusDownloading PodcastStatus = "downloading"
+	PodcastStatusCompleted   PodcastStatus = "completed"
+	PodcastStatusError       PodcastStatus = "error"
+	PodcastStatusDeleted     PodcastStatus = "deleted"
+	PodcastStatusSkipped     PodcastStatus = "skipped"
+)
+
+// PodcastChannel represents a podcast channel from Subsonic
+type PodcastChannel struct {
+	ID               ID
+	URL              string
+	Title            string
+	Description      string
+	CoverArt         ID
+	OriginalImageURL string
+	Status           PodcastStatus
+	ErrorMessage     string
+
+	// Episode - only when episodes are requested
+	Episode []PodcastEpisode
+}
+
+// PodcastEpisode represents a podcast episode from Subsonic
+type PodcastEpisode struct {
+	// Raw values
+	ID             ID
+	StreamID       ID
+	ChannelID      ID
+	Title          string
+	Description    string
+	Status         PodcastStatus
+	CoverArt       ID
+	BitRate        int64
+	ContentType    string
+	Size           int64
+	Suffix         string
+	DurationRaw    int64  `json:"duration"`
+	PublishDateRaw string `json:"publishDate"`
+
+	// Parsed values
+	Duration    time.Duration
+	PublishDate time.Time
+}
+
+// JukeboxStatus represents the current status of the Subsonic jukebox
+type JukeboxStatus struct {
+	CurrentIndex int64
+	Playing      bool
+	Gain         float64
+	Position     int64
+}
+
+// JukeboxPlaylist represents the current playlist of the Subsonic jukebox
+type JukeboxPlaylist struct {
+	JukeboxStatus
+
+	Entry []Audio
+}
+
+// InternetRadioStation represents an internet radio station from Subsonic
+type InternetRadioStation struct {
+	ID          ID
+	Name        string
+	StreamURL   string
+	HomePageURL string
+}
+
+// ChatMessage represents a chat message from Subsonic
+type ChatMessage struct {
+	// Raw values
+	Username string
+	TimeRaw  int64 `json:"time"`
+	Message  string
+
+	// Parsed values
+	Time time.Time
+}
+
+// User represents a Subsonic user, with settings and roles
+type User struct {
+	// Raw values
+	Username             string
+	Email                string
+	MaxBitRate           int64
+	AvatarLastChangedRaw string `json:"avatarLastChanged"`
+
+	// Settings and roles
+	ScrobblingEnabled   bool
+	LdapAuthenticated   bool
+	AdminRole           bool
+	SettingsRole        bool
+	DownloadRole        bool
+	UploadRole          bool
+	PlaylistRole        bool
+	CoverArtRole        bool
+	CommentRole         bool
+	PodcastRole         bool
+	StreamRole          bool
+	JukeboxRole         bool
+	ShareRole           bool
+	VideoConversionRole bool
+
+	// Folder - IDs of music folders this user may access
+	Folder []int64
+
+	// Parsed values
+	AvatarLastChanged time.Time
+}
+
+// roles maps the name of each boolean setting and role in the Subsonic API to its field
+func (u *User) roles() []struct {
+	name  string
+	value *bool
+} {
+	return []struct {
+		name  string
+		value *bool
+	}{
+		{"scrobblingEnabled", &u.ScrobblingEnabled},
+		{"ldapAuthenticated", &u.LdapAuthenticated},
+		{"adminRole", &u.AdminRole},
+		{"settingsRole", &u.SettingsRole},
+		{"downloadRole", &u.DownloadRole},
+		{"uploadRole", &u.UploadRole},
+		{"playlistRole", &u.PlaylistRole},
+		{"coverArtRole", &u.CoverArtRole},
+		{"commentRole", &u.CommentRole},
+		{"podcastRole", &u.PodcastRole},
+		{"streamRole", &u.StreamRole},
+		{"jukeboxRole", &u.JukeboxRole},
+		{"shareRole", &u.ShareRole},
+		{"videoConversionRole", &u.VideoConversionRole},
+	}
+}
+
+// Bookmark represents a saved position within a media item from Subsonic
+type Bookmark struct {
+	// Raw values
+	PositionRaw int64 `json:"position"`
+	Username    string
+	Comment     string
+	CreatedRaw  string `json:"created"`
+	ChangedRaw  string `json:"changed"`
+
+	Entry Audio
+
+	// Parsed values
+	Position time.Duration
+	Created  time.Time
+	Changed  time.Time
+}
+
+// PlayQueue represents a saved play queue from Subsonic
+type PlayQueue struct {
+	// Raw values
+	Current     ID
+	PositionRaw int64 `json:"position"`
+	Username    string
+	ChangedRaw  string `json:"changed"`
+	ChangedBy   string
+
+	Entry []Audio
+
+	// Parsed values
+	Position time.Duration
+	Changed  time.Time
+}
+
+// ScanStatus represents the status of a media library scan from Subsonic
+type ScanStatus struct {
+	Scanning bool
+	Count    int64
+}
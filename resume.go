@@ -0,0 +1,131 @@
+package gosubsonic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// resumeDefaultInterval is the default playback progress between saved bookmarks
+const resumeDefaultInterval = 30 * time.Second
+
+// Resume resumes playback of a media item, such as an audiobook or podcast episode, from the
+// position saved in the user's bookmark, and saves the playback position as it progresses.
+// The player reports its position using Progress, and a bookmark is saved each time playback
+// moves by at least Interval.  A Resume is safe for concurrent use.
+type Resume struct {
+	// Interval is the minimum change in position between saved bookmarks, default 30
+	// seconds
+	Interval time.Duration
+
+	// Comment is saved with each bookmark, if set
+	Comment string
+
+	client Client
+	id     ID
+
+	mu       sync.Mutex
+	position time.Duration
+	saved    time.Duration
+}
+
+// NewResume creates a Resume for a media item, beginning at the position of the user's
+// bookmark for the item, or at the start if there is none
+func (s Client) NewResume(ctx context.Context, id ID) (*Resume, error) {
+	bookmarks, err := s.GetBookmarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Resume{
+		client: s,
+		id:     id,
+	}
+
+	for _, b := range bookmarks {
+		if b.Entry.ID == id {
+			r.position, r.saved = b.Position, b.Position
+			break
+		}
+	}
+
+	return r, nil
+}
+
+// Position returns the current playback position
+func (r *Resume) Position() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.position
+}
+
+// Stream streams the media item from the current playback position, with an optional
+// StreamOptions struct.  The position is passed to the server as StreamOptions.TimeOffset,
+// in whole seconds, which some servers only apply to transcoded streams.
+func (r *Resume) Stream(ctx context.Context, options *StreamOptions) (*StreamResponse, error) {
+	opts := StreamOptions{}
+	if options != nil {
+		opts = *options
+	}
+	opts.TimeOffset = int64(r.Position() / time.Second)
+
+	return r.client.Stream(ctx, r.id, &opts)
+}
+
+// Progress reports the player's current position, and saves it as a bookmark if it has moved
+// by at least Interval since the last saved bookmark, in either direction
+func (r *Resume) Progress(ctx context.Context, position time.Duration) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = resumeDefaultInterval
+	}
+
+	r.mu.Lock()
+	r.position = position
+	moved := position - r.saved
+	r.mu.Unlock()
+
+	if moved < interval && moved > -interval {
+		return nil
+	}
+
+	return r.Save(ctx)
+}
+
+// Save saves the current position as a bookmark, unless it is already saved, such as when
+// playback is paused or stopped
+func (r *Resume) Save(ctx context.Context) error {
+	r.mu.Lock()
+	position := r.position
+	saved := position == r.saved
+	r.mu.Unlock()
+
+	if saved {
+		return nil
+	}
+
+	if err := r.client.CreateBookmark(ctx, r.id, position, r.Comment); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.saved = position
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Finish deletes the bookmark once the media item has been played to completion, so it is
+// next played from the start
+func (r *Resume) Finish(ctx context.Context) error {
+	if err := r.client.DeleteBookmark(ctx, r.id); err != nil && !IsNotFound(err) {
+		return err
+	}
+
+	r.mu.Lock()
+	r.position, r.saved = 0, 0
+	r.mu.Unlock()
+
+	return nil
+}
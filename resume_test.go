@@ -0,0 +1,103 @@
+package gosubsonic
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestResume verifies that a Resume streams from the bookmarked position, and saves the
+// position as playback progresses
+func TestResume(t *testing.T) {
+	log.Println("TestResume()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	ok := []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0"}}`)
+	f.SetFixture("createBookmark", url.Values{"id": {"1"}, "position": {"151000"}, "comment": {"Chapter 3"}}, ok)
+	f.SetFixture("createBookmark", url.Values{"id": {"1"}, "position": {"60000"}, "comment": {"Chapter 3"}}, ok)
+	f.SetFixture("deleteBookmark", url.Values{"id": {"1"}}, ok)
+	f.SetMedia("1", "audio/mpeg", []byte("audio"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	var offset string
+	s.Interceptors = []Interceptor{func(ctx context.Context, req *Request, next Invoker) error {
+		if req.Method == "stream" {
+			u, _ := url.Parse(req.URL)
+			offset = u.Query().Get("timeOffset")
+		}
+
+		return next(ctx, req)
+	}}
+
+	ctx := context.Background()
+	r, err := s.NewResume(ctx, "1")
+	if err != nil {
+		t.Fatalf("NewResume returned error: %s", err.Error())
+	}
+	r.Interval = time.Minute
+	r.Comment = "Chapter 3"
+
+	if r.Position() != 90500*time.Millisecond {
+		t.Fatalf("NewResume did not start at bookmark: %s", r.Position())
+	}
+
+	// Streams begin at the bookmark, in whole seconds
+	stream, err := r.Stream(ctx, &StreamOptions{Format: "mp3"})
+	if err != nil {
+		t.Fatalf("Stream returned error: %s", err.Error())
+	}
+	io.Copy(io.Discard, stream)
+	stream.Close()
+
+	if offset != "90" {
+		t.Fatalf("Stream did not begin at bookmark: timeOffset=%q", offset)
+	}
+
+	// Bookmarks are only saved once playback moves by the interval, in either direction
+	var tests = []struct {
+		position time.Duration
+		saves    int
+	}{
+		{120 * time.Second, 0},
+		{151 * time.Second, 1},
+		{200 * time.Second, 1},
+		{60 * time.Second, 2},
+	}
+
+	for _, test := range tests {
+		if err := r.Progress(ctx, test.position); err != nil {
+			t.Fatalf("Progress(%s) returned error: %s", test.position, err.Error())
+		}
+
+		if n := f.Requests("createBookmark"); n != test.saves {
+			t.Fatalf("Progress(%s) saved %d bookmarks, expected %d", test.position, n, test.saves)
+		}
+	}
+
+	// The saved position is not saved again
+	if err := r.Save(ctx); err != nil || f.Requests("createBookmark") != 2 {
+		t.Fatalf("Save saved unchanged position: %v", err)
+	}
+
+	if err := r.Finish(ctx); err != nil {
+		t.Fatalf("Finish returned error: %s", err.Error())
+	}
+	if r.Position() != 0 || f.Requests("deleteBookmark") != 1 {
+		t.Fatalf("Finish did not delete bookmark")
+	}
+
+	// Items without a bookmark start at the beginning
+	r, err = s.NewResume(ctx, "2")
+	if err != nil || r.Position() != 0 {
+		t.Fatalf("NewResume returned invalid position: %v", err)
+	}
+}
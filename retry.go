@@ -0,0 +1,277 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Default retry policy values
+const (
+	retryDefaultMaxAttempts    = 3
+	retryDefaultInitialBackoff = 500 * time.Millisecond
+	retryDefaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy configures retries of requests which fail due to transient errors, such as
+// network timeouts or a busy server.  Retries apply to both API calls and binary fetches.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a request, including the first,
+	// default 3
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry, which doubles for each further
+	// retry, default 500 milliseconds
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries, default 10 seconds
+	MaxBackoff time.Duration
+
+	// Retryable determines if a failed request should be retried, default IsTemporary
+	Retryable func(err error) bool
+}
+
+// StatusError represents an unsuccessful HTTP status code returned by a server in place
+// of a Subsonic response
+type StatusError struct {
+	StatusCode int
+	URL        string
+
+	// RetryAfter is the delay requested by the server using a Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("gosubsonic: HTTP %d %s - %s", e.StatusCode, http.StatusText(e.StatusCode), e.URL)
+}
+
+// IsTemporary determines if an error was caused by a transient condition, such as a network
+// timeout, a reset connection, or a server which is busy or temporarily unavailable
+func IsTemporary(err error) bool {
+	// Requests canceled by the caller should never be retried
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var se *StatusError
+	if errors.As(err, &se) {
+		switch se.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+
+		return false
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// statusError returns a StatusError if a HTTP response has a status code which indicates
+// the server could not process the request, or nil otherwise.  Subsonic reports its own
+// errors with a successful status code.
+func statusError(res *http.Response, url string) error {
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < http.StatusInternalServerError {
+		return nil
+	}
+
+	e := &StatusError{
+		StatusCode: res.StatusCode,
+		URL:        redactURL(url),
+	}
+
+	// Retry-After may also be a HTTP date, which is not supported
+	if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs > 0 {
+		e.RetryAfter = time.Duration(secs) * time.Second
+	}
+
+	return e
+}
+
+// dataSource returns the client's data source, falling back to HTTP for clients which were
+// not constructed using New
+func (s Client) dataSource() DataSource {
+	if s.source == nil {
+		return httpDataSource{client: s.httpClient}
+	}
+
+	return s.source
+}
+
+// binaryDataSource returns the client's data source for binary responses, falling back to
+// HTTP for data sources which do not support them
+func (s Client) binaryDataSource() BinaryDataSource {
+	if bs, ok := s.dataSource().(BinaryDataSource); ok {
+		return bs
+	}
+
+	return httpDataSource{client: s.httpClient}
+}
+
+// callTimeoutKey is the context key for a timeout set by WithCallTimeout
+type callTimeoutKey struct{}
+
+// WithCallTimeout returns a context which overrides the client's Timeout for API requests
+// made using it, such as to allow a slow search of a large library more time.  A duration of
+// 0 disables the timeout.  Binary requests, such as streams, are never limited by the
+// timeout, but are limited by any deadline set on ctx itself.
+func WithCallTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutKey{}, d)
+}
+
+// withTimeout applies the client's timeout, or the timeout set by WithCallTimeout, to ctx
+func (s Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	d := s.Timeout
+	if v, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok {
+		d = v
+	}
+
+	if d <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, d)
+}
+
+// fetch retrieves a raw response from the data source, retrying according to the retry policy
+func (s Client) fetch(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var body []byte
+	err := s.retry(ctx, func() error {
+		release, err := s.limit(ctx, url)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		body, err = s.read(ctx, url)
+		return err
+	})
+
+	return body, err
+}
+
+// read retrieves a raw response from the data source, which may not exceed the maximum
+// response size
+func (s Client) read(ctx context.Context, url string) ([]byte, error) {
+	source := s.dataSource()
+	ss, ok := source.(StreamingDataSource)
+	if !ok {
+		body, err := source.Get(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		if max := s.maxResponseSize(); max > 0 && int64(len(body)) > max {
+			return nil, responseTooLarge(url, max)
+		}
+
+		return body, nil
+	}
+
+	body, err := ss.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(s.limitResponse(body, url))
+}
+
+// fetchDecode retrieves a response from a streaming data source, and decodes it as it is
+// read, so the body is never held in memory.  Only opening the response is retried, since
+// a partially decoded response cannot be resumed.
+func (s Client) fetchDecode(ctx context.Context, source StreamingDataSource, url string) (*apiContainer, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var body io.ReadCloser
+	err := s.retry(ctx, func() error {
+		release, err := s.limit(ctx, url)
+		if err != nil {
+			return err
+		}
+
+		rc, err := source.Open(ctx, url)
+		if err != nil {
+			release()
+			return err
+		}
+
+		body = releaseReadCloser{rc, release}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return decodeResponse(s.limitResponse(body, url))
+}
+
+// retry invokes fn until it succeeds, returns an error which is not retryable, or the retry
+// policy's maximum attempts are exhausted.  With no retry policy, fn is invoked once.  If ctx
+// is done while waiting to retry, the returned error wraps both ctx.Err() and the last error.
+func (s Client) retry(ctx context.Context, fn func() error) error {
+	if s.Retry == nil {
+		return fn()
+	}
+
+	// Apply defaults for any unset values
+	p := *s.Retry
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = retryDefaultMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = retryDefaultInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = retryDefaultMaxBackoff
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsTemporary
+	}
+
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) {
+			return err
+		}
+
+		// Honor the server's requested delay, if it is longer
+		delay := backoff
+		var se *StatusError
+		if errors.As(err, &se) && se.RetryAfter > delay {
+			delay = se.RetryAfter
+		}
+		if delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			// Report the cancellation, along with the error which caused the retry
+			t.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+
+		backoff *= 2
+	}
+}
@@ -0,0 +1,218 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// retryTestClient generates a client for a test server which responds to the specified
+// number of requests with an error status code, and counts all requests other than ping
+func retryTestClient(t *testing.T, failures int32, status int) (*Client, *int32, func()) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/ping.view" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+			return
+		}
+
+		if atomic.AddInt32(&count, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/rest/getCoverArt.view":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("mock image"))
+		case "/rest/getSong.view":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"subsonic-response": {
+				"status": "failed",
+				"version": "1.9.0",
+				"error": {"code": 70, "message": "Song not found"}
+			}}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(mockTable[0].data)
+		}
+	}))
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		srv.Close()
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	return s, &count, srv.Close
+}
+
+// TestRetry verifies that requests are retried according to the retry policy
+func TestRetry(t *testing.T) {
+	log.Println("TestRetry()")
+
+	policy := &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}
+
+	var tests = []struct {
+		failures int32
+		status   int
+		retry    *RetryPolicy
+		count    int32
+		ok       bool
+	}{
+		// No retry policy
+		{1, http.StatusServiceUnavailable, nil, 1, false},
+		// Recovers before attempts are exhausted
+		{2, http.StatusServiceUnavailable, policy, 3, true},
+		// Attempts are exhausted
+		{5, http.StatusBadGateway, policy, 3, false},
+		// Errors which are not transient are not retried
+		{5, http.StatusInternalServerError, policy, 1, false},
+	}
+
+	for _, test := range tests {
+		s, count, done := retryTestClient(t, test.failures, test.status)
+		s.Retry = test.retry
+
+		_, err := s.GetMusicFolders(context.Background())
+		if (err == nil) != test.ok {
+			t.Fatalf("GetMusicFolders returned unexpected error for %d failures: %v", test.failures, err)
+		}
+
+		var se *StatusError
+		if err != nil && (!errors.As(err, &se) || se.StatusCode != test.status) {
+			t.Fatalf("GetMusicFolders returned unexpected error type: %v", err)
+		}
+
+		if c := atomic.LoadInt32(count); c != test.count {
+			t.Fatalf("GetMusicFolders made %d attempts, expected %d", c, test.count)
+		}
+
+		done()
+	}
+}
+
+// TestRetryBinary verifies that binary fetches are retried, and Subsonic errors are not
+func TestRetryBinary(t *testing.T) {
+	log.Println("TestRetryBinary()")
+
+	s, count, done := retryTestClient(t, 1, http.StatusServiceUnavailable)
+	defer done()
+	s.Retry = &RetryPolicy{InitialBackoff: time.Millisecond}
+
+	art, err := s.GetCoverArt(context.Background(), "1", 0)
+	if err != nil {
+		t.Fatalf("GetCoverArt returned error: %s", err.Error())
+	}
+
+	out, _ := io.ReadAll(art)
+	art.Close()
+	if string(out) != "mock image" || atomic.LoadInt32(count) != 2 {
+		t.Fatalf("GetCoverArt returned %q after %d attempts", out, atomic.LoadInt32(count))
+	}
+
+	if _, err := s.GetSong(context.Background(), "1"); !IsNotFound(err) || atomic.LoadInt32(count) != 3 {
+		t.Fatalf("GetSong returned unexpected error %v after %d attempts", err, atomic.LoadInt32(count))
+	}
+}
+
+// TestBinaryStatusError verifies that unsuccessful responses to binary requests, such as error
+// pages from a reverse proxy, are reported as errors rather than returned as media
+func TestBinaryStatusError(t *testing.T) {
+	log.Println("TestBinaryStatusError()")
+
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		s, count, done := retryTestClient(t, 1, status)
+		s.Retry = &RetryPolicy{InitialBackoff: time.Millisecond}
+
+		body, err := s.Download(context.Background(), "1")
+		done()
+
+		var se *StatusError
+		if !errors.As(err, &se) || se.StatusCode != status {
+			if body != nil {
+				body.Close()
+			}
+			t.Fatalf("Download returned unexpected error for HTTP %d: %v", status, err)
+		}
+
+		// Client errors are not transient, so they are not retried
+		if c := atomic.LoadInt32(count); c != 1 {
+			t.Fatalf("Download made %d attempts for HTTP %d", c, status)
+		}
+	}
+}
+
+// TestRetryCanceled verifies that retries stop when the context is canceled during backoff,
+// and that the cancellation and the last error are both reported
+func TestRetryCanceled(t *testing.T) {
+	log.Println("TestRetryCanceled()")
+
+	var tests = []struct {
+		description string
+		context     func() (context.Context, context.CancelFunc)
+		err         error
+	}{
+		{"deadline", func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		}, context.DeadlineExceeded},
+		{"cancel", func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+			return ctx, cancel
+		}, context.Canceled},
+	}
+
+	for _, test := range tests {
+		s, count, done := retryTestClient(t, 5, http.StatusServiceUnavailable)
+		s.Retry = &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+
+		ctx, cancel := test.context()
+		_, err := s.GetMusicFolders(ctx)
+		cancel()
+		done()
+
+		var se *StatusError
+		if !errors.Is(err, test.err) || !errors.As(err, &se) || se.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("%s: GetMusicFolders returned unexpected error: %v", test.description, err)
+		}
+
+		if c := atomic.LoadInt32(count); c != 1 {
+			t.Fatalf("%s: GetMusicFolders made %d attempts after cancellation", test.description, c)
+		}
+	}
+}
+
+// TestIsTemporary verifies that transient errors are detected
+func TestIsTemporary(t *testing.T) {
+	log.Println("TestIsTemporary()")
+
+	var tests = []struct {
+		err       error
+		temporary bool
+	}{
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{&StatusError{StatusCode: http.StatusNotImplemented}, false},
+		{io.ErrUnexpectedEOF, true},
+		{context.Canceled, false},
+		{&Error{Code: ErrCodeGeneric}, false},
+		{errors.New("mock"), false},
+	}
+
+	for _, test := range tests {
+		if IsTemporary(test.err) != test.temporary {
+			t.Fatalf("IsTemporary returned invalid result for %v", test.err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// scrobblerDefaultInterval is the default interval between attempts to flush queued scrobbles
+const scrobblerDefaultInterval = 30 * time.Second
+
+// QueuedScrobble is a scrobble submission which has not yet been accepted by the server
+type QueuedScrobble struct {
+	// ID is the ID of the media which was played
+	ID ID
+
+	// Time is the time at which playback started
+	Time time.Time
+}
+
+// Scrobbler submits scrobbles, queueing them while the server is unreachable and submitting
+// them with their original timestamps once it is reachable again.  If a path is set, queued
+// scrobbles are saved to a file, so they survive restarts.  A Scrobbler is safe for
+// concurrent use.
+type Scrobbler struct {
+	// Interval is the interval between attempts to flush queued scrobbles by Run, default
+	// 30 seconds
+	Interval time.Duration
+
+	// Errors is called when queued scrobbles cannot be flushed, if set
+	Errors func(error)
+
+	client Client
+	path   string
+
+	// flushMu serializes flushes, so no scrobble is submitted twice
+	flushMu sync.Mutex
+
+	mu    sync.Mutex
+	queue []QueuedScrobble
+}
+
+// NewScrobbler creates a new Scrobbler.  If path is set, queued scrobbles are saved to the
+// file at that path, and any scrobbles saved by a previous Scrobbler are loaded from it.
+func (s Client) NewScrobbler(path string) (*Scrobbler, error) {
+	sc := &Scrobbler{
+		client: s,
+		path:   path,
+	}
+
+	if path == "" {
+		return sc, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &sc.queue); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to load scrobble queue: %s", err.Error())
+	}
+
+	return sc, nil
+}
+
+// Scrobble queues a submission for media which started playing at the specified time, and
+// attempts to flush the queue.  An error is returned only if the scrobble could not be
+// queued; scrobbles which cannot be submitted remain queued.
+func (sc *Scrobbler) Scrobble(ctx context.Context, id ID, at time.Time) error {
+	sc.mu.Lock()
+	sc.queue = append(sc.queue, QueuedScrobble{ID: id, Time: at})
+	err := sc.save()
+	sc.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := sc.Flush(ctx); err != nil && sc.Errors != nil {
+		sc.Errors(err)
+	}
+
+	return nil
+}
+
+// NowPlaying submits a "Now Playing" notification.  These are never queued, since they are
+// meaningless once playback has ended.
+func (sc *Scrobbler) NowPlaying(ctx context.Context, id ID) error {
+	return sc.client.Scrobble(ctx, id, -1, false)
+}
+
+// Pending returns the scrobbles which are queued
+func (sc *Scrobbler) Pending() []QueuedScrobble {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return append([]QueuedScrobble(nil), sc.queue...)
+}
+
+// Flush submits queued scrobbles in order, stopping at the first which cannot be submitted.
+// Scrobbles rejected by the server, such as for media which no longer exists, are discarded,
+// unless they were rejected due to authentication.
+func (sc *Scrobbler) Flush(ctx context.Context) error {
+	sc.flushMu.Lock()
+	defer sc.flushMu.Unlock()
+
+	for {
+		sc.mu.Lock()
+		if len(sc.queue) == 0 {
+			sc.mu.Unlock()
+			return nil
+		}
+		next := sc.queue[0]
+		sc.mu.Unlock()
+
+		err := sc.client.Scrobble(ctx, next.ID, next.Time.UnixMilli(), true)
+
+		var apiErr *Error
+		if err != nil && (!errors.As(err, &apiErr) || IsAuthError(err)) {
+			return err
+		}
+
+		// Only this flush removes scrobbles, so the submitted scrobble is still first
+		sc.mu.Lock()
+		sc.queue = sc.queue[1:]
+		saveErr := sc.save()
+		sc.mu.Unlock()
+
+		if saveErr != nil {
+			return saveErr
+		}
+	}
+}
+
+// Run flushes queued scrobbles at each interval, until ctx is canceled
+func (sc *Scrobbler) Run(ctx context.Context) {
+	interval := sc.Interval
+	if interval <= 0 {
+		interval = scrobblerDefaultInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		if err := sc.Flush(ctx); err != nil && ctx.Err() == nil && sc.Errors != nil {
+			sc.Errors(err)
+		}
+	}
+}
+
+// save writes the queue to its file, if set.  The caller must hold sc.mu.
+func (sc *Scrobbler) save() error {
+	if sc.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(sc.queue)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(sc.path, b); err != nil {
+		return fmt.Errorf("gosubsonic: failed to save scrobble queue: %s", err.Error())
+	}
+
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with data atomically, so a crash never leaves a
+// partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return err
+}
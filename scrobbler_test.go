@@ -0,0 +1,76 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestScrobbler verifies that a Scrobbler queues scrobbles while the server is unreachable,
+// and submits them with their original times once it is reachable
+func TestScrobbler(t *testing.T) {
+	log.Println("TestScrobbler()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	path := filepath.Join(t.TempDir(), "scrobbles.json")
+	sc, err := s.NewScrobbler(path)
+	if err != nil {
+		t.Fatalf("NewScrobbler returned error: %s", err.Error())
+	}
+
+	var errs int
+	sc.Errors = func(error) { errs++ }
+
+	// Scrobbles are queued while the server is unavailable
+	f.SetStatus("scrobble", http.StatusServiceUnavailable)
+
+	ctx := context.Background()
+	played := time.Unix(1400000000, 0)
+	if err := sc.Scrobble(ctx, "1", played); err != nil {
+		t.Fatalf("Scrobble returned error: %s", err.Error())
+	}
+	if err := sc.Scrobble(ctx, "2", played.Add(4*time.Minute)); err != nil {
+		t.Fatalf("Scrobble returned error: %s", err.Error())
+	}
+
+	if p := sc.Pending(); len(p) != 2 || p[0].ID != "1" || errs != 2 {
+		t.Fatalf("Scrobbler queued invalid scrobbles: %+v, %d errors", p, errs)
+	}
+
+	// Queued scrobbles are loaded by a new Scrobbler
+	sc, err = s.NewScrobbler(path)
+	if err != nil {
+		t.Fatalf("NewScrobbler returned error: %s", err.Error())
+	}
+	if p := sc.Pending(); len(p) != 2 || p[1].ID != "2" || !p[1].Time.Equal(played.Add(4*time.Minute)) {
+		t.Fatalf("NewScrobbler loaded invalid scrobbles: %+v", p)
+	}
+
+	// Scrobbles are submitted with their original times, and scrobbles rejected by the
+	// server are discarded
+	f.ClearErrors()
+	f.SetFixture("scrobble", url.Values{"id": {"1"}, "time": {"1400000000000"}, "submission": {"true"}}, mockTable[0].data)
+
+	if err := sc.Flush(ctx); err != nil {
+		t.Fatalf("Flush returned error: %s", err.Error())
+	}
+
+	if p := sc.Pending(); len(p) != 0 || f.Requests("scrobble") != 4 {
+		t.Fatalf("Flush left scrobbles queued: %+v, %d requests", p, f.Requests("scrobble"))
+	}
+
+	if sc, err := s.NewScrobbler(path); err != nil || len(sc.Pending()) != 0 {
+		t.Fatalf("NewScrobbler loaded flushed scrobbles: %v", err)
+	}
+}
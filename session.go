@@ -0,0 +1,164 @@
+package gosubsonic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Default scrobbling thresholds, following Last.fm conventions
+const (
+	sessionDefaultPercent   = 0.5
+	sessionDefaultDuration  = 4 * time.Minute
+	sessionDefaultMinLength = 30 * time.Second
+
+	// sessionRestartPosition is the position below which a backward seek is treated as a restart
+	sessionRestartPosition = 2 * time.Second
+
+	// sessionSeekTolerance is the amount a position may advance beyond wall clock time,
+	// before the difference is treated as a seek instead of playback
+	sessionSeekTolerance = time.Second
+)
+
+// SessionOptions represents additional options for the NewSession() method
+type SessionOptions struct {
+	// Percent of the track which must be played before scrobbling, default 0.5
+	Percent float64
+
+	// Duration of playback after which a track is scrobbled, even if Percent is not
+	// reached, default 4 minutes
+	Duration time.Duration
+
+	// MinLength is the minimum track length which may be scrobbled, default 30 seconds
+	MinLength time.Duration
+}
+
+// Session tracks playback of a single media item, and submits a scrobble once enough
+// of it has been played.  Seeking does not count towards playback, and restarting the
+// track begins a new play which may be scrobbled again.
+type Session struct {
+	client   Client
+	id       ID
+	length   time.Duration
+	options  SessionOptions
+	now      func() time.Time
+	mu       sync.Mutex
+	started  time.Time
+	updated  time.Time
+	position time.Duration
+	played   time.Duration
+	done     bool
+}
+
+// NewSession creates a new Session for the specified media ID and track length, with an
+// optional SessionOptions struct
+func (s Client) NewSession(id ID, length time.Duration, options *SessionOptions) *Session {
+	// Apply defaults for any unset thresholds
+	o := SessionOptions{}
+	if options != nil {
+		o = *options
+	}
+
+	if o.Percent <= 0 {
+		o.Percent = sessionDefaultPercent
+	}
+	if o.Duration <= 0 {
+		o.Duration = sessionDefaultDuration
+	}
+	if o.MinLength <= 0 {
+		o.MinLength = sessionDefaultMinLength
+	}
+
+	session := &Session{
+		client:  s,
+		id:      id,
+		length:  length,
+		options: o,
+		now:     time.Now,
+	}
+	session.restart()
+
+	return session
+}
+
+// Progress reports the current playback position of the track.  If enough of the track has
+// now been played, a scrobble submission is sent, and Progress returns true.
+func (s *Session) Progress(ctx context.Context, position time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	elapsed := now.Sub(s.updated)
+	delta := position - s.position
+
+	switch {
+	// Jumping back to the start of the track begins a new play
+	case delta < 0 && position < sessionRestartPosition:
+		s.restart()
+	// Any other backward movement is a seek, which counts for nothing
+	case delta < 0:
+		break
+	// Forward movement counts only as far as wall clock time allows, so seeks are ignored
+	case delta <= elapsed+sessionSeekTolerance:
+		s.played += delta
+	}
+
+	s.position = position
+	s.updated = now
+
+	// Check if this play should be scrobbled
+	if s.done || !s.ready() {
+		return false, nil
+	}
+
+	// Submit the scrobble, using the time at which playback started
+	if err := s.client.Scrobble(ctx, s.id, s.started.UnixMilli(), true); err != nil {
+		return false, err
+	}
+
+	s.done = true
+	return true, nil
+}
+
+// Restart begins a new play of the track, which may be scrobbled again
+func (s *Session) Restart() {
+	s.mu.Lock()
+	s.restart()
+	s.mu.Unlock()
+}
+
+// Played returns the total playback time of the current play, excluding seeks
+func (s *Session) Played() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.played
+}
+
+// Scrobbled returns whether the current play has been scrobbled
+func (s *Session) Scrobbled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.done
+}
+
+// restart resets playback state for a new play
+func (s *Session) restart() {
+	s.started = s.now()
+	s.updated = s.started
+	s.position = 0
+	s.played = 0
+	s.done = false
+}
+
+// ready determines if enough of the track has been played to scrobble
+func (s *Session) ready() bool {
+	// Very short tracks are never scrobbled
+	if s.length < s.options.MinLength {
+		return false
+	}
+
+	return s.played >= s.options.Duration ||
+		float64(s.played) >= float64(s.length)*s.options.Percent
+}
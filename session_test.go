@@ -0,0 +1,109 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSession verifies that Session only scrobbles once enough of a track has been played
+func TestSession(t *testing.T) {
+	log.Println("TestSession()")
+
+	// Record scrobble submissions made to a test server
+	submissions := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/scrobble.view" && r.URL.Query().Get("submission") == "true" {
+			submissions++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(mockTable[0].data)
+	}))
+	defer srv.Close()
+
+	// Generate client for test server
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+
+	// Control the session's clock
+	now := time.Unix(1395014311, 0)
+	session := s.NewSession("1", 4*time.Minute, nil)
+	session.now = func() time.Time { return now }
+	session.Restart()
+
+	// progress advances the clock and reports a new position
+	progress := func(elapsed time.Duration, position time.Duration) bool {
+		now = now.Add(elapsed)
+		ok, err := session.Progress(context.Background(), position)
+		if err != nil {
+			t.Fatalf("Progress returned error: %s", err.Error())
+		}
+
+		return ok
+	}
+
+	// Play one minute, then seek ahead to three minutes, which should not count
+	if progress(time.Minute, time.Minute) || progress(time.Second, 3*time.Minute) {
+		t.Fatalf("Session scrobbled after seeking")
+	}
+
+	if played := session.Played(); played != time.Minute {
+		t.Fatalf("Session counted seek as playback: %s", played)
+	}
+
+	// Seek back, and play another minute to reach 50%
+	progress(time.Second, time.Minute)
+	if !progress(time.Minute, 2*time.Minute) {
+		t.Fatalf("Session did not scrobble after 50%% playback")
+	}
+
+	// Further playback should not scrobble again
+	if progress(time.Minute, 3*time.Minute) || submissions != 1 {
+		t.Fatalf("Session scrobbled more than once: %d", submissions)
+	}
+
+	// Restarting the track should allow another scrobble
+	progress(time.Second, 0)
+	if session.Scrobbled() {
+		t.Fatalf("Session did not restart")
+	}
+
+	progress(time.Minute, time.Minute)
+	if !progress(time.Minute, 2*time.Minute) || submissions != 2 {
+		t.Fatalf("Session did not scrobble after restart: %d", submissions)
+	}
+}
+
+// TestSessionShortTrack verifies that Session never scrobbles very short tracks
+func TestSessionShortTrack(t *testing.T) {
+	log.Println("TestSessionShortTrack()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Play an entire 20 second track
+	now := time.Unix(1395014311, 0)
+	session := s.NewSession("1", 20*time.Second, nil)
+	session.now = func() time.Time { return now }
+	session.Restart()
+
+	now = now.Add(20 * time.Second)
+	ok, err := session.Progress(context.Background(), 20*time.Second)
+	if err != nil {
+		t.Fatalf("Progress returned error: %s", err.Error())
+	}
+
+	if ok || session.Played() != 20*time.Second {
+		t.Fatalf("Session scrobbled short track")
+	}
+}
@@ -0,0 +1,40 @@
+package gosubsonic
+
+import (
+	"context"
+	"time"
+)
+
+// ShareQR represents a newly created share, along with a QR code which links to it
+type ShareQR struct {
+	Share Share
+
+	// PNG image bytes of a QR code encoding the share's public URL
+	PNG []byte
+}
+
+// CreateShareQR creates a public share for one or more media IDs, and renders a QR code
+// for its public URL as a PNG image.  Each QR code module is drawn as a square of scale pixels.
+func (s Client) CreateShareQR(ctx context.Context, ids []ID, description string, expires time.Time, scale int) (*ShareQR, error) {
+	// Create the share on Subsonic
+	share, err := s.CreateShare(ctx, ids, description, expires)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode the public URL as a QR code
+	q, err := qrEncode([]byte(share.URL))
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := q.png(scale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShareQR{
+		Share: *share,
+		PNG:   img,
+	}, nil
+}
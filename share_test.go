@@ -0,0 +1,37 @@
+package gosubsonic
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"log"
+	"testing"
+	"time"
+)
+
+// TestCreateShareQR verifies that client.CreateShareQR() is working properly
+func TestCreateShareQR(t *testing.T) {
+	log.Println("TestCreateShareQR()")
+
+	// Generate mock client
+	s, err := NewMock()
+	if err != nil {
+		t.Fatalf("Could not generate mock client: %s", err.Error())
+	}
+
+	// Create a share with a QR code from mock data
+	share, err := s.CreateShareQR(context.Background(), []ID{"1"}, "", time.Time{}, 4)
+	if err != nil {
+		t.Fatalf("CreateShareQR returned error: %s", err.Error())
+	}
+
+	// Check for known URL
+	if share.Share.URL != "http://mock.example.com/share/abc" {
+		t.Fatalf("CreateShareQR returned invalid URL: %s", share.Share.URL)
+	}
+
+	// Check for a valid PNG image
+	if _, err := png.Decode(bytes.NewReader(share.PNG)); err != nil {
+		t.Fatalf("CreateShareQR returned invalid PNG: %s", err.Error())
+	}
+}
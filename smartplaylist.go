@@ -0,0 +1,565 @@
+package gosubsonic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SmartOrderRandom orders the songs of a smart playlist randomly
+const SmartOrderRandom = "random"
+
+// smartSearchFields are the fields which may be matched by a Search3 query
+var smartSearchFields = map[string]bool{
+	"title":  true,
+	"artist": true,
+	"album":  true,
+}
+
+// smartField describes a song field which may be used in a smart playlist rule.  Exactly one
+// of str and num is set.
+type smartField struct {
+	str func(*Audio) string
+	num func(*Audio) int64
+}
+
+// smartFields maps the name of each smart playlist field to its value
+var smartFields = map[string]smartField{
+	"title":   {str: func(a *Audio) string { return a.Title }},
+	"artist":  {str: func(a *Audio) string { return a.Artist }},
+	"album":   {str: func(a *Audio) string { return a.Album }},
+	"genre":   {str: func(a *Audio) string { return a.Genre }},
+	"suffix":  {str: func(a *Audio) string { return a.Suffix }},
+	"path":    {str: func(a *Audio) string { return a.Path }},
+	"year":    {num: func(a *Audio) int64 { return a.Year }},
+	"track":   {num: func(a *Audio) int64 { return a.Track }},
+	"disc":    {num: func(a *Audio) int64 { return a.DiscNumber }},
+	"bitrate": {num: func(a *Audio) int64 { return a.BitRate }},
+	"bpm":     {num: func(a *Audio) int64 { return a.BPM }},
+	"created": {num: func(a *Audio) int64 { return a.Created.Unix() }},
+
+	// Durations are compared in seconds
+	"duration": {num: func(a *Audio) int64 { return int64(a.Duration.Seconds()) }},
+}
+
+// smartOps are the operators which may be used in a smart playlist rule.  "~" and "!~" match
+// strings which do or do not contain a value, and may only be used with string fields.
+// Strings are compared without regard to case.
+var smartOps = map[string]bool{
+	"==": true,
+	"!=": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+	"~":  true,
+	"!~": true,
+}
+
+// SmartRule is a condition on a song field which songs must match to be included in a smart
+// playlist, such as genre == "Jazz".  Numeric fields are year, track, disc, bitrate, bpm,
+// duration (in seconds), and created (as a Unix timestamp).  String fields are title,
+// artist, album, genre, suffix, and path.
+type SmartRule struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// SmartPlaylist is a set of rules which select songs from the library, which are evaluated
+// using searches and list methods, and filtered client-side.  A SmartPlaylist may be built
+// directly, or parsed from a query using ParseSmartPlaylist.
+type SmartPlaylist struct {
+	// Rules are the conditions songs must all match
+	Rules []SmartRule
+
+	// Limit is the maximum number of songs, or 0 for no limit
+	Limit int
+
+	// Order is a field by which songs are sorted, SmartOrderRandom to shuffle them, or
+	// empty to leave them in the order they are returned by the server
+	Order string
+
+	// Descending reverses the order of songs sorted by a field
+	Descending bool
+
+	// MusicFolderID limits songs to a music folder, if set (>= 1)
+	MusicFolderID int64
+}
+
+// ParseSmartPlaylist parses a smart playlist query.  Queries contain rules joined by AND,
+// followed by optional LIMIT and ORDER clauses, such as:
+//
+//	genre == "Jazz" AND year >= 1990 LIMIT 100 ORDER random
+//	artist ~ "Adventure" AND duration < 300 ORDER year DESC
+//
+// Keywords are not case sensitive, and values which are not numbers or single words must be
+// quoted.
+func ParseSmartPlaylist(query string) (*SmartPlaylist, error) {
+	tokens, err := smartTokens(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &SmartPlaylist{}
+	next := func() string {
+		if len(tokens) == 0 {
+			return ""
+		}
+
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t
+	}
+
+	// Rules must come first, and be joined by AND
+	and := true
+	for len(tokens) > 0 {
+		word := strings.ToUpper(tokens[0])
+		if word == "LIMIT" || word == "ORDER" {
+			break
+		}
+
+		if !and {
+			if word != "AND" {
+				return nil, fmt.Errorf("gosubsonic: invalid smart playlist: expected AND, found %q", tokens[0])
+			}
+
+			next()
+			and = true
+			continue
+		}
+
+		field, op, value := next(), next(), next()
+		if field == "" || op == "" || value == "" {
+			return nil, errors.New("gosubsonic: invalid smart playlist: incomplete rule")
+		}
+
+		p.Rules = append(p.Rules, SmartRule{Field: strings.ToLower(field), Op: op, Value: smartUnquote(value)})
+		and = false
+	}
+
+	if and && len(p.Rules) > 0 {
+		return nil, errors.New("gosubsonic: invalid smart playlist: expected rule after AND")
+	}
+
+	for len(tokens) > 0 {
+		switch word := next(); strings.ToUpper(word) {
+		case "LIMIT":
+			n, err := strconv.Atoi(next())
+			if err != nil || n < 0 {
+				return nil, errors.New("gosubsonic: invalid smart playlist: LIMIT requires a number")
+			}
+
+			p.Limit = n
+		case "ORDER":
+			order := next()
+			if strings.EqualFold(order, "BY") {
+				order = next()
+			}
+			if order == "" {
+				return nil, errors.New("gosubsonic: invalid smart playlist: ORDER requires a field")
+			}
+
+			p.Order = strings.ToLower(order)
+			if len(tokens) > 0 && (strings.EqualFold(tokens[0], "ASC") || strings.EqualFold(tokens[0], "DESC")) {
+				p.Descending = strings.EqualFold(next(), "DESC")
+			}
+		default:
+			return nil, fmt.Errorf("gosubsonic: invalid smart playlist: unexpected %q", word)
+		}
+	}
+
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// smartTokens splits a smart playlist query into words, numbers, quoted strings, and
+// operators.  Commas are treated as whitespace, so clauses may be separated by them.
+func smartTokens(query string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ',' || unicode.IsSpace(rune(c)):
+			i++
+		case c == '"':
+			// Find the closing quote, skipping escaped quotes
+			j := i + 1
+			for j < len(query) && query[j] != '"' {
+				if query[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(query) {
+				return nil, errors.New("gosubsonic: invalid smart playlist: unterminated string")
+			}
+
+			tokens = append(tokens, query[i:j+1])
+			i = j + 1
+		case strings.ContainsRune("=!<>~", rune(c)):
+			j := i + 1
+			for j < len(query) && strings.ContainsRune("=~", rune(query[j])) && j-i < 2 {
+				j++
+			}
+
+			// A single "=" is accepted for equality
+			op := query[i:j]
+			if op == "=" {
+				op = "=="
+			}
+
+			tokens = append(tokens, op)
+			i = j
+		default:
+			j := i
+			for j < len(query) && query[j] != ',' && query[j] != '"' && !unicode.IsSpace(rune(query[j])) &&
+				!strings.ContainsRune("=!<>~", rune(query[j])) {
+				j++
+			}
+
+			tokens = append(tokens, query[i:j])
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// smartUnquote removes the quotes from a quoted string value
+func smartUnquote(s string) string {
+	if !strings.HasPrefix(s, `"`) {
+		return s
+	}
+
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+
+	return strings.Trim(s, `"`)
+}
+
+// check validates the rules and order of a smart playlist
+func (p *SmartPlaylist) check() error {
+	for _, r := range p.Rules {
+		f, ok := smartFields[r.Field]
+		if !ok {
+			return fmt.Errorf("gosubsonic: invalid smart playlist field: %s", r.Field)
+		}
+		if !smartOps[r.Op] {
+			return fmt.Errorf("gosubsonic: invalid smart playlist operator: %s", r.Op)
+		}
+
+		if f.num != nil {
+			if r.Op == "~" || r.Op == "!~" {
+				return fmt.Errorf("gosubsonic: operator %s cannot be used with numeric field: %s", r.Op, r.Field)
+			}
+			if _, err := strconv.ParseInt(r.Value, 10, 64); err != nil {
+				return fmt.Errorf("gosubsonic: invalid value for numeric field %s: %q", r.Field, r.Value)
+			}
+		} else if r.Op != "==" && r.Op != "!=" && r.Op != "~" && r.Op != "!~" {
+			return fmt.Errorf("gosubsonic: operator %s cannot be used with string field: %s", r.Op, r.Field)
+		}
+	}
+
+	if p.Limit < 0 {
+		return errors.New("gosubsonic: smart playlist limit must not be negative")
+	}
+
+	if _, ok := smartFields[p.Order]; !ok && p.Order != "" && p.Order != SmartOrderRandom {
+		return fmt.Errorf("gosubsonic: invalid smart playlist order: %s", p.Order)
+	}
+
+	return nil
+}
+
+// Match determines if a song matches every rule of a smart playlist.  Rules are assumed to
+// be valid, and invalid rules do not match.
+func (p *SmartPlaylist) Match(song *Audio) bool {
+	for _, r := range p.Rules {
+		if !r.match(song) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match determines if a song matches a rule
+func (r SmartRule) match(song *Audio) bool {
+	f, ok := smartFields[r.Field]
+	if !ok {
+		return false
+	}
+
+	if f.str != nil {
+		v, want := strings.ToLower(f.str(song)), strings.ToLower(r.Value)
+		switch r.Op {
+		case "==":
+			return v == want
+		case "!=":
+			return v != want
+		case "~":
+			return strings.Contains(v, want)
+		case "!~":
+			return !strings.Contains(v, want)
+		}
+
+		return false
+	}
+
+	want, err := strconv.ParseInt(r.Value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	v := f.num(song)
+	switch r.Op {
+	case "==":
+		return v == want
+	case "!=":
+		return v != want
+	case "<":
+		return v < want
+	case "<=":
+		return v <= want
+	case ">":
+		return v > want
+	case ">=":
+		return v >= want
+	}
+
+	return false
+}
+
+// genre returns the genre required by an equality rule, if any
+func (p *SmartPlaylist) genre() string {
+	for _, r := range p.Rules {
+		if r.Field == "genre" && r.Op == "==" && r.Value != "" {
+			return r.Value
+		}
+	}
+
+	return ""
+}
+
+// years returns the range of years allowed by the year rules, where 0 indicates no bound
+func (p *SmartPlaylist) years() (int64, int64) {
+	var from, to int64
+	for _, r := range p.Rules {
+		if r.Field != "year" {
+			continue
+		}
+
+		v, _ := strconv.ParseInt(r.Value, 10, 64)
+		switch r.Op {
+		case "==":
+			from, to = max(from, v), v
+		case ">":
+			from = max(from, v+1)
+		case ">=":
+			from = max(from, v)
+		case "<":
+			to = v - 1
+		case "<=":
+			to = v
+		}
+	}
+
+	return from, to
+}
+
+// random determines if a smart playlist can be evaluated entirely by GetRandomSongs, which
+// requires random order, a limit within the maximum size, and only genre and year rules
+func (p *SmartPlaylist) random() bool {
+	if p.Order != SmartOrderRandom || p.Limit <= 0 || p.Limit > iteratorPageSize {
+		return false
+	}
+
+	for _, r := range p.Rules {
+		if !(r.Field == "genre" && r.Op == "==") && !(r.Field == "year" && r.Op != "!=") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// search returns the rule used as a Search3 query, if any, preferring exact matches
+func (p *SmartPlaylist) search() *SmartRule {
+	var found *SmartRule
+	for i, r := range p.Rules {
+		if !smartSearchFields[r.Field] || r.Value == "" {
+			continue
+		}
+
+		if r.Op == "==" {
+			return &p.Rules[i]
+		}
+		if r.Op == "~" && found == nil {
+			found = &p.Rules[i]
+		}
+	}
+
+	return found
+}
+
+// SmartPlaylistSongs evaluates a smart playlist, and returns the songs which match it.  Its
+// rules are compiled into the narrowest available source of songs: random songs if they
+// fit, songs in a genre, a song search, or the songs of albums in a range of years, or else
+// every album in the library.  Every rule is then checked client-side, so broad sources
+// such as the whole library may require many requests.
+func (s Client) SmartPlaylistSongs(ctx context.Context, p *SmartPlaylist) ([]Audio, error) {
+	if p == nil {
+		return nil, errors.New("gosubsonic: smart playlist is required")
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	// Songs may only be returned early if they are not reordered
+	early := p.Limit > 0 && p.Order == ""
+
+	var songs []Audio
+	seen := make(map[ID]bool)
+	err := s.smartCandidates(ctx, p, func(song *Audio) bool {
+		if seen[song.ID] || !p.Match(song) {
+			return true
+		}
+
+		seen[song.ID] = true
+		songs = append(songs, *song)
+		return !early || len(songs) < p.Limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.Order {
+	case "":
+	case SmartOrderRandom:
+		rand.Shuffle(len(songs), func(i, j int) {
+			songs[i], songs[j] = songs[j], songs[i]
+		})
+	default:
+		f := smartFields[p.Order]
+		sort.SliceStable(songs, func(i, j int) bool {
+			a, b := &songs[i], &songs[j]
+			if p.Descending {
+				a, b = b, a
+			}
+
+			if f.str != nil {
+				return strings.ToLower(f.str(a)) < strings.ToLower(f.str(b))
+			}
+
+			return f.num(a) < f.num(b)
+		})
+	}
+
+	if p.Limit > 0 && len(songs) > p.Limit {
+		songs = songs[:p.Limit]
+	}
+
+	return songs, nil
+}
+
+// CreateSmartPlaylist evaluates a smart playlist, and creates a server playlist with the
+// specified name containing its songs
+func (s Client) CreateSmartPlaylist(ctx context.Context, name string, p *SmartPlaylist) (*Playlist, error) {
+	songs, err := s.SmartPlaylistSongs(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]ID, len(songs))
+	for i := range songs {
+		ids[i] = songs[i].ID
+	}
+
+	return s.CreatePlaylist(ctx, name, ids)
+}
+
+// smartCandidates calls fn with each song which may match a smart playlist, until fn
+// returns false
+func (s Client) smartCandidates(ctx context.Context, p *SmartPlaylist, fn func(*Audio) bool) error {
+	genre := p.genre()
+	from, to := p.years()
+
+	if p.random() {
+		songs, err := s.GetRandomSongs(ctx, &RandomSongsOptions{
+			Size:          int64(p.Limit),
+			Genre:         genre,
+			FromYear:      from,
+			ToYear:        to,
+			MusicFolderID: p.MusicFolderID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range songs {
+			if !fn(&songs[i]) {
+				break
+			}
+		}
+
+		return nil
+	}
+
+	if genre != "" {
+		return smartSongs(s.SongsByGenreIterator(ctx, genre, &SongsByGenreOptions{MusicFolderID: p.MusicFolderID}), fn)
+	}
+
+	if r := p.search(); r != nil {
+		return smartSongs(s.SearchSongsIterator(ctx, r.Value, &SearchOptions{MusicFolderID: p.MusicFolderID}), fn)
+	}
+
+	// Without other rules to narrow the search, use the songs of every album, or only
+	// albums in a range of years
+	listType, options := AlbumListAlphabeticalByName, &AlbumListOptions{MusicFolderID: p.MusicFolderID}
+	if from > 0 || to > 0 {
+		listType = AlbumListByYear
+		options.FromYear, options.ToYear = max(from, 1), to
+		if to <= 0 {
+			options.ToYear = 9999
+		}
+	}
+
+	it := s.AlbumList2Iterator(ctx, listType, options)
+	for it.Next() {
+		album, err := s.GetAlbum(ctx, it.Value().ID)
+		if err != nil {
+			return err
+		}
+
+		for i := range album.Song {
+			if !fn(&album.Song[i]) {
+				return nil
+			}
+		}
+	}
+
+	return it.Err()
+}
+
+// smartSongs calls fn with each song from an iterator, until fn returns false
+func smartSongs(it *Iterator[Audio], fn func(*Audio) bool) error {
+	for it.Next() {
+		song := it.Value()
+		if !fn(&song) {
+			return nil
+		}
+	}
+
+	return it.Err()
+}
@@ -0,0 +1,165 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseSmartPlaylist verifies that ParseSmartPlaylist() parses rules, limits, and orders,
+// and rejects invalid queries
+func TestParseSmartPlaylist(t *testing.T) {
+	log.Println("TestParseSmartPlaylist()")
+
+	var tests = []struct {
+		query string
+		p     *SmartPlaylist
+	}{
+		{`genre == "jazz" AND year >= 1990, limit 100, order random`, &SmartPlaylist{
+			Rules: []SmartRule{{"genre", "==", "jazz"}, {"year", ">=", "1990"}},
+			Limit: 100,
+			Order: SmartOrderRandom,
+		}},
+		{`Artist~"Boards \"of\" Canada" and duration<300 ORDER BY year DESC`, &SmartPlaylist{
+			Rules:      []SmartRule{{"artist", "~", `Boards "of" Canada`}, {"duration", "<", "300"}},
+			Order:      "year",
+			Descending: true,
+		}},
+		{`suffix = flac LIMIT 5`, &SmartPlaylist{
+			Rules: []SmartRule{{"suffix", "==", "flac"}},
+			Limit: 5,
+		}},
+		{`ORDER title`, &SmartPlaylist{Order: "title"}},
+		{`genre == jazz year > 1990`, nil},
+		{`genre == jazz AND`, nil},
+		{`genre ==`, nil},
+		{`rating > 3`, nil},
+		{`year ~ 19`, nil},
+		{`year > recent`, nil},
+		{`title < "B"`, nil},
+		{`title == "unterminated`, nil},
+		{`LIMIT many`, nil},
+		{`ORDER rating`, nil},
+		{`LIMIT 1 genre == jazz`, nil},
+	}
+
+	for _, test := range tests {
+		p, err := ParseSmartPlaylist(test.query)
+		if test.p == nil {
+			if err == nil {
+				t.Fatalf("ParseSmartPlaylist(%q) accepted invalid query", test.query)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("ParseSmartPlaylist(%q) returned error: %s", test.query, err.Error())
+		}
+
+		if !reflect.DeepEqual(p, test.p) {
+			t.Fatalf("ParseSmartPlaylist(%q) returned %+v, expected %+v", test.query, p, test.p)
+		}
+	}
+}
+
+// TestSmartPlaylistSongs verifies that client.SmartPlaylistSongs() compiles rules into the
+// narrowest source of songs, and filters, orders, and limits them
+func TestSmartPlaylistSongs(t *testing.T) {
+	log.Println("TestSmartPlaylistSongs()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	songs := `{"subsonic-response": {"status": "ok", "version": "1.9.0", "%s": {"song": [
+		{"id": 1, "title": "Blue", "artist": "Adventure", "genre": "Jazz", "year": 1985, "duration": 180},
+		{"id": 2, "title": "Green", "artist": "Adventure", "genre": "Jazz", "year": 1995, "duration": 240},
+		{"id": 3, "title": "Red", "artist": "Other", "genre": "jazz", "year": 2005, "duration": 320}]}}}`
+	fixture := func(key string) []byte {
+		return []byte(strings.Replace(songs, "%s", key, 1))
+	}
+
+	f.SetFixture("getRandomSongs", url.Values{"size": {"2"}, "genre": {"Jazz"}, "fromYear": {"1990"}}, fixture("randomSongs"))
+	f.SetFixture("getSongsByGenre", url.Values{"genre": {"Jazz"}, "count": {"500"}}, fixture("songsByGenre"))
+	f.SetFixture("search3", nil, fixture("searchResult3"))
+	f.SetFixture("getAlbumList2", url.Values{"type": {"byYear"}, "fromYear": {"2000"}, "toYear": {"9999"}, "size": {"500"}},
+		[]byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "albumList2": {"album": [{"id": 7}]}}}`))
+	f.SetFixture("getAlbum", url.Values{"id": {"7"}}, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"album": {"id": 7, "song": [{"id": 3, "title": "Red", "year": 2005}, {"id": 4, "title": "Orange", "year": 1999}]}}}`))
+	f.SetFixture("createPlaylist", url.Values{"name": {"Jazz"}, "songId": {"3", "2"}},
+		[]byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0", "playlist": {"id": 9, "name": "Jazz"}}}`))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	var tests = []struct {
+		query  string
+		method string
+		ids    []ID
+	}{
+		// Random songs within the maximum size are fetched directly
+		{`genre == Jazz AND year >= 1990 LIMIT 2 ORDER random`, "getRandomSongs", nil},
+		// Genre rules use songs in the genre, and other rules are checked client-side
+		{`genre == Jazz AND year >= 1990 ORDER year DESC`, "getSongsByGenre", []ID{"3", "2"}},
+		{`genre == Jazz LIMIT 1`, "getSongsByGenre", []ID{"1"}},
+		// Title, artist, and album rules use a search
+		{`artist ~ adv AND duration < 200`, "search3", []ID{"1"}},
+		// Year rules use albums in a range of years
+		{`year >= 2000`, "getAlbumList2", []ID{"3"}},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		p, err := ParseSmartPlaylist(test.query)
+		if err != nil {
+			t.Fatalf("ParseSmartPlaylist(%q) returned error: %s", test.query, err.Error())
+		}
+
+		n := f.Requests(test.method)
+		res, err := s.SmartPlaylistSongs(ctx, p)
+		if err != nil {
+			t.Fatalf("SmartPlaylistSongs(%q) returned error: %s", test.query, err.Error())
+		}
+
+		if f.Requests(test.method) != n+1 {
+			t.Fatalf("SmartPlaylistSongs(%q) did not call %s", test.query, test.method)
+		}
+
+		ids := make([]ID, len(res))
+		for i := range res {
+			ids[i] = res[i].ID
+		}
+
+		// Random songs are checked by membership, since their order varies
+		if test.ids == nil {
+			if len(ids) != 2 || (ids[0] != "2" && ids[0] != "3") || (ids[1] != "2" && ids[1] != "3") {
+				t.Fatalf("SmartPlaylistSongs(%q) returned invalid songs: %v", test.query, ids)
+			}
+
+			continue
+		}
+
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Fatalf("SmartPlaylistSongs(%q) returned %v, expected %v", test.query, ids, test.ids)
+		}
+	}
+
+	// Playlists are created with the songs in order
+	p := &SmartPlaylist{Rules: []SmartRule{{"genre", "==", "Jazz"}, {"year", ">", "1990"}}, Order: "year", Descending: true}
+	playlist, err := s.CreateSmartPlaylist(ctx, "Jazz", p)
+	if err != nil {
+		t.Fatalf("CreateSmartPlaylist returned error: %s", err.Error())
+	}
+	if playlist == nil || playlist.ID != "9" {
+		t.Fatalf("CreateSmartPlaylist returned invalid playlist: %+v", playlist)
+	}
+
+	if _, err := s.SmartPlaylistSongs(ctx, &SmartPlaylist{Rules: []SmartRule{{"rating", "==", "5"}}}); err == nil {
+		t.Fatalf("SmartPlaylistSongs accepted invalid rule")
+	}
+}
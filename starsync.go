@@ -0,0 +1,545 @@
+package gosubsonic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// starSyncDefaultInterval is the default interval between syncs performed by StarSync.Run
+const starSyncDefaultInterval = 5 * time.Minute
+
+// StarKind represents the kind of a starred item, organized by ID3 tags
+type StarKind int
+
+// Kinds of items which may be starred
+const (
+	StarSong StarKind = iota
+	StarAlbum
+	StarArtist
+)
+
+// String returns the name of a StarKind
+func (k StarKind) String() string {
+	switch k {
+	case StarSong:
+		return "song"
+	case StarAlbum:
+		return "album"
+	case StarArtist:
+		return "artist"
+	}
+
+	return "unknown"
+}
+
+// StarItem identifies an item which may be starred
+type StarItem struct {
+	Kind StarKind
+	ID   ID
+}
+
+// StarChange is a change to whether an item is starred, made at the specified time
+type StarChange struct {
+	Item    StarItem
+	Starred bool
+	Time    time.Time
+}
+
+// StarConflict describes a local change to an item which was also changed on the server
+// since the previous sync, such as by another client
+type StarConflict struct {
+	// Local is the local change
+	Local StarChange
+
+	// Remote is set if the item is starred on the server, and RemoteTime is the time at
+	// which it was starred, if reported.  Servers do not record when items are unstarred.
+	Remote     bool
+	RemoteTime time.Time
+}
+
+// StarSyncResult describes the changes made by StarSync.Sync
+type StarSyncResult struct {
+	// Pushed are the local changes applied to the server
+	Pushed []StarChange
+
+	// Starred and Unstarred are the items starred and unstarred on the server since the
+	// previous sync, which were applied locally
+	Starred   []StarItem
+	Unstarred []StarItem
+
+	// Conflicts are the local changes which conflicted with changes on the server.  Each is
+	// also reported in Pushed if the local change won, or in Starred or Unstarred otherwise.
+	Conflicts []StarConflict
+
+	// Rejected are the local changes which the server rejected, such as for items which no
+	// longer exist, and which were discarded
+	Rejected []StarChange
+}
+
+// StarSync maintains a local set of starred songs, albums, and artists, which may be changed
+// while the server is unreachable, and synchronizes it with the server in both directions.
+// If a path is set, the local set and its unsynchronized changes are saved to a file, so they
+// survive restarts.  A StarSync is safe for concurrent use.
+//
+// Conflicts occur when an item is changed both locally and on the server between syncs.  By
+// default, the most recent change wins, and since servers do not record when items are
+// unstarred, local changes always win over items unstarred on the server.  If Prompt is set,
+// it decides instead.
+type StarSync struct {
+	// Interval is the interval between syncs performed by Run, default 5 minutes
+	Interval time.Duration
+
+	// Errors is called when a sync performed by Run fails, if set
+	Errors func(error)
+
+	// Prompt is called for each conflict during a sync, if set, and returns true to keep the
+	// local change, or false to keep the server's state.  It may prompt the user, but the
+	// sync cannot proceed until it returns.
+	Prompt func(StarConflict) bool
+
+	client Client
+	path   string
+	now    func() time.Time
+
+	// syncMu serializes syncs, so no change is pushed twice
+	syncMu sync.Mutex
+
+	mu      sync.Mutex
+	synced  map[StarItem]time.Time
+	pending map[StarItem]StarChange
+}
+
+// starSyncState is the state of a StarSync which is saved to its file
+type starSyncState struct {
+	// Synced are the items starred on the server as of the previous sync
+	Synced []StarChange
+
+	// Pending are the local changes which have not been synced
+	Pending []StarChange
+}
+
+// NewStarSync creates a new StarSync.  If path is set, its state is saved to the file at
+// that path, and any state saved by a previous StarSync is loaded from it.  The local set is
+// empty until the first sync.
+func (s Client) NewStarSync(path string) (*StarSync, error) {
+	ss := &StarSync{
+		client:  s,
+		path:    path,
+		now:     time.Now,
+		synced:  make(map[StarItem]time.Time),
+		pending: make(map[StarItem]StarChange),
+	}
+
+	if path == "" {
+		return ss, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ss, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state starSyncState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to load starred items: %s", err.Error())
+	}
+
+	for _, c := range state.Synced {
+		ss.synced[c.Item] = c.Time
+	}
+	for _, c := range state.Pending {
+		ss.pending[c.Item] = c
+	}
+
+	return ss, nil
+}
+
+// Star stars an item locally, until the next sync
+func (ss *StarSync) Star(item StarItem) error {
+	return ss.change(item, true)
+}
+
+// Unstar removes the star from an item locally, until the next sync
+func (ss *StarSync) Unstar(item StarItem) error {
+	return ss.change(item, false)
+}
+
+// change records a local change, replacing any earlier change to the same item
+func (ss *StarSync) change(item StarItem, starred bool) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.pending[item] = StarChange{Item: item, Starred: starred, Time: ss.now()}
+	return ss.save()
+}
+
+// IsStarred determines if an item is starred locally
+func (ss *StarSync) IsStarred(item StarItem) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if c, ok := ss.pending[item]; ok {
+		return c.Starred
+	}
+
+	_, ok := ss.synced[item]
+	return ok
+}
+
+// Starred returns the items which are starred locally, ordered by kind and ID
+func (ss *StarSync) Starred() []StarItem {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var items []StarItem
+	for item := range ss.synced {
+		if c, ok := ss.pending[item]; !ok || c.Starred {
+			items = append(items, item)
+		}
+	}
+	for item, c := range ss.pending {
+		if _, ok := ss.synced[item]; !ok && c.Starred {
+			items = append(items, item)
+		}
+	}
+
+	sortStarItems(items)
+	return items
+}
+
+// Pending returns the local changes which have not been synced, in the order they were made
+func (ss *StarSync) Pending() []StarChange {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	changes := make([]StarChange, 0, len(ss.pending))
+	for _, c := range ss.pending {
+		changes = append(changes, c)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes
+}
+
+// Sync retrieves the starred items from the server, applies the changes made on the server
+// since the previous sync locally, and pushes local changes to the server.  If the server
+// cannot be reached, or local changes cannot be pushed, no changes are made, and local
+// changes remain pending.  Local changes made during a sync are kept for the next sync.
+func (ss *StarSync) Sync(ctx context.Context) (*StarSyncResult, error) {
+	ss.syncMu.Lock()
+	defer ss.syncMu.Unlock()
+
+	remote, err := ss.client.starredTimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.mu.Lock()
+	synced := make(map[StarItem]time.Time, len(ss.synced))
+	for item, t := range ss.synced {
+		synced[item] = t
+	}
+	pending := make(map[StarItem]StarChange, len(ss.pending))
+	for item, c := range ss.pending {
+		pending[item] = c
+	}
+	ss.mu.Unlock()
+
+	// Every item which is starred anywhere, or was changed locally
+	seen := make(map[StarItem]bool)
+	var items []StarItem
+	for _, m := range []map[StarItem]time.Time{remote, synced} {
+		for item := range m {
+			if !seen[item] {
+				seen[item] = true
+				items = append(items, item)
+			}
+		}
+	}
+	for item := range pending {
+		if !seen[item] {
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+	sortStarItems(items)
+
+	res := &StarSyncResult{}
+	var push []StarChange
+	for _, item := range items {
+		remoteTime, inRemote := remote[item]
+		syncedTime, inSynced := synced[item]
+		change, changed := pending[item]
+
+		// Items which are starred again on the server have a later starred time
+		remoteChanged := inRemote != inSynced || remoteTime.After(syncedTime)
+
+		switch {
+		case !changed:
+			// Apply changes made on the server
+			if inRemote && !inSynced {
+				res.Starred = append(res.Starred, item)
+			} else if !inRemote && inSynced {
+				res.Unstarred = append(res.Unstarred, item)
+			}
+
+			continue
+		case change.Starred == inRemote:
+			// The server already agrees with the local change
+			continue
+		case remoteChanged:
+			c := StarConflict{Local: change, Remote: inRemote, RemoteTime: remoteTime}
+			res.Conflicts = append(res.Conflicts, c)
+
+			if !ss.resolve(c) {
+				if inRemote {
+					res.Starred = append(res.Starred, item)
+				} else {
+					res.Unstarred = append(res.Unstarred, item)
+				}
+
+				continue
+			}
+		}
+
+		push = append(push, change)
+	}
+
+	res.Pushed, res.Rejected, err = ss.push(ctx, push)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server's state is now the synced state, along with the pushed changes
+	updated := remote
+	for _, c := range res.Pushed {
+		if c.Starred {
+			updated[c.Item] = c.Time
+		} else {
+			delete(updated, c.Item)
+		}
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.synced = updated
+	for item, c := range pending {
+		// Only discard changes which were not replaced during the sync
+		if p := ss.pending[item]; p.Starred == c.Starred && p.Time.Equal(c.Time) {
+			delete(ss.pending, item)
+		}
+	}
+
+	if err := ss.save(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// resolve determines if a local change should win a conflict
+func (ss *StarSync) resolve(c StarConflict) bool {
+	if ss.Prompt != nil {
+		return ss.Prompt(c)
+	}
+
+	// The server's starred time is only known for starred items
+	return !c.Remote || c.RemoteTime.IsZero() || c.Local.Time.After(c.RemoteTime)
+}
+
+// push applies local changes to the server, using a request to star items and another to
+// unstar them.  If the server rejects a request, its changes are applied individually, so
+// only those which are rejected themselves are discarded.
+func (ss *StarSync) push(ctx context.Context, changes []StarChange) ([]StarChange, []StarChange, error) {
+	var stars, unstars []StarChange
+	for _, c := range changes {
+		if c.Starred {
+			stars = append(stars, c)
+		} else {
+			unstars = append(unstars, c)
+		}
+	}
+
+	var pushed, rejected []StarChange
+	for _, batch := range [][]StarChange{stars, unstars} {
+		if len(batch) == 0 {
+			continue
+		}
+
+		err := ss.apply(ctx, batch)
+		if err == nil {
+			pushed = append(pushed, batch...)
+			continue
+		}
+
+		// Only requests rejected by the server are retried
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || IsAuthError(err) {
+			return nil, nil, err
+		}
+		if len(batch) == 1 {
+			rejected = append(rejected, batch...)
+			continue
+		}
+
+		for _, c := range batch {
+			err := ss.apply(ctx, []StarChange{c})
+			switch {
+			case err == nil:
+				pushed = append(pushed, c)
+			case errors.As(err, &apiErr) && !IsAuthError(err):
+				rejected = append(rejected, c)
+			default:
+				return nil, nil, err
+			}
+		}
+	}
+
+	return pushed, rejected, nil
+}
+
+// apply stars or unstars the items of a batch of changes, which must be either all stars or
+// all unstars
+func (ss *StarSync) apply(ctx context.Context, batch []StarChange) error {
+	var options StarOptions
+	for _, c := range batch {
+		switch c.Item.Kind {
+		case StarSong:
+			options.IDs = append(options.IDs, c.Item.ID)
+		case StarAlbum:
+			options.AlbumIDs = append(options.AlbumIDs, c.Item.ID)
+		case StarArtist:
+			options.ArtistIDs = append(options.ArtistIDs, c.Item.ID)
+		}
+	}
+
+	if batch[0].Starred {
+		return ss.client.Star(ctx, options)
+	}
+
+	return ss.client.Unstar(ctx, options)
+}
+
+// Run syncs immediately, and then at each interval, until ctx is canceled
+func (ss *StarSync) Run(ctx context.Context) {
+	interval := ss.Interval
+	if interval <= 0 {
+		interval = starSyncDefaultInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if _, err := ss.Sync(ctx); err != nil && ctx.Err() == nil && ss.Errors != nil {
+			ss.Errors(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// save writes the state to its file, if set.  The caller must hold ss.mu.
+func (ss *StarSync) save() error {
+	if ss.path == "" {
+		return nil
+	}
+
+	var state starSyncState
+	for item, t := range ss.synced {
+		state.Synced = append(state.Synced, StarChange{Item: item, Starred: true, Time: t})
+	}
+	for _, c := range ss.pending {
+		state.Pending = append(state.Pending, c)
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(ss.path, b); err != nil {
+		return fmt.Errorf("gosubsonic: failed to save starred items: %s", err.Error())
+	}
+
+	return nil
+}
+
+// starredTimes retrieves the songs, albums, and artists starred on the server, organized by
+// ID3 tags, along with the time each was starred, if reported
+func (s Client) starredTimes(ctx context.Context) (map[StarItem]time.Time, error) {
+	raw, err := s.Raw(ctx, "getStarred2", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The starred time is not part of the decoded items, so only it and the ID are decoded
+	type starredRaw struct {
+		ID      ID
+		Starred flexString
+	}
+
+	var res struct {
+		Starred2 json.RawMessage
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to parse starred items: %s", err.Error())
+	}
+
+	var starred struct {
+		Artist oneOrMany[starredRaw]
+		Album  oneOrMany[starredRaw]
+		Song   oneOrMany[starredRaw]
+	}
+	if len(res.Starred2) > 0 && !isEmptyJSON(res.Starred2) {
+		if err := json.Unmarshal(res.Starred2, &starred); err != nil {
+			return nil, fmt.Errorf("gosubsonic: failed to parse starred items: %s", err.Error())
+		}
+	}
+
+	out := make(map[StarItem]time.Time)
+	for _, kind := range []struct {
+		kind  StarKind
+		items []starredRaw
+	}{
+		{StarSong, starred.Song},
+		{StarAlbum, starred.Album},
+		{StarArtist, starred.Artist},
+	} {
+		for _, r := range kind.items {
+			// An unrecognized time is treated as unknown
+			t, _ := parseSubsonicTime(string(r.Starred))
+			out[StarItem{Kind: kind.kind, ID: r.ID}] = t
+		}
+	}
+
+	return out, nil
+}
+
+// sortStarItems sorts items by kind and ID
+func sortStarItems(items []StarItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+
+		return items[i].ID < items[j].ID
+	})
+}
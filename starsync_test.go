@@ -0,0 +1,158 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestStarSync verifies that a StarSync applies changes made on the server locally, pushes
+// local changes to the server, and resolves conflicts between them
+func TestStarSync(t *testing.T) {
+	log.Println("TestStarSync()")
+
+	f := NewFakeServer()
+	defer f.Close()
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	path := filepath.Join(t.TempDir(), "starred.json")
+	ss, err := s.NewStarSync(path)
+	if err != nil {
+		t.Fatalf("NewStarSync returned error: %s", err.Error())
+	}
+
+	song := func(id ID) StarItem { return StarItem{Kind: StarSong, ID: id} }
+	album := StarItem{Kind: StarAlbum, ID: "10"}
+	artist := StarItem{Kind: StarArtist, ID: "20"}
+
+	// The first sync applies all starred items locally
+	f.SetFixture("getStarred2", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"starred2": {"song": {"id": "1", "starred": "2020-01-01T00:00:00Z"},
+		"album": [{"id": 10, "starred": "2020-01-01T00:00:00Z"}],
+		"artist": [{"id": "20", "starred": "2020-01-01T00:00:00Z"}]}}}`))
+
+	ctx := context.Background()
+	res, err := ss.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync returned error: %s", err.Error())
+	}
+
+	if want := []StarItem{song("1"), album, artist}; !reflect.DeepEqual(res.Starred, want) || !reflect.DeepEqual(ss.Starred(), want) {
+		t.Fatalf("Sync returned invalid starred items: %+v, %+v", res.Starred, ss.Starred())
+	}
+
+	// Local changes are made while the server's starred items also change: song 1 is
+	// unstarred, and artist 20 is unstarred and starred again after the local change
+	local := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ss.now = func() time.Time { return local }
+
+	for _, c := range []StarChange{
+		{Item: song("2"), Starred: true},
+		{Item: song("404"), Starred: true},
+		{Item: album},
+		{Item: artist},
+	} {
+		if c.Starred {
+			err = ss.Star(c.Item)
+		} else {
+			err = ss.Unstar(c.Item)
+		}
+		if err != nil {
+			t.Fatalf("Star returned error: %s", err.Error())
+		}
+	}
+
+	if ss.IsStarred(album) || !ss.IsStarred(song("2")) || len(ss.Pending()) != 4 {
+		t.Fatalf("StarSync did not apply local changes: %+v", ss.Pending())
+	}
+
+	f.SetFixture("getStarred2", nil, []byte(`{"subsonic-response": {"status": "ok", "version": "1.9.0",
+		"starred2": {"album": {"id": "10", "starred": "2020-01-01T00:00:00Z"},
+		"artist": {"id": "20", "starred": "2022-01-01T00:00:00Z"}}}}`))
+	f.SetFixture("star", url.Values{"id": {"2"}}, mockTable[0].data)
+	f.SetFixture("unstar", url.Values{"albumId": {"10"}}, mockTable[0].data)
+
+	// Syncs are not applied while the server is unavailable
+	f.SetStatus("getStarred2", http.StatusServiceUnavailable)
+	if _, err := ss.Sync(ctx); err == nil || len(ss.Pending()) != 4 {
+		t.Fatalf("Sync did not fail while server was unavailable: %v", err)
+	}
+	f.ClearErrors()
+
+	res, err = ss.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync returned error: %s", err.Error())
+	}
+
+	// The song which does not exist is rejected, and the artist starred again on the server
+	// wins its conflict, since it was starred after the local change
+	want := &StarSyncResult{
+		Pushed:    []StarChange{{Item: song("2"), Starred: true, Time: local}, {Item: album, Time: local}},
+		Unstarred: []StarItem{song("1")},
+		Starred:   []StarItem{artist},
+		Conflicts: []StarConflict{{Local: StarChange{Item: artist, Time: local}, Remote: true, RemoteTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		Rejected:  []StarChange{{Item: song("404"), Starred: true, Time: local}},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("Sync returned invalid result:\n- got: %+v\n- want: %+v", res, want)
+	}
+
+	if want := []StarItem{song("2"), artist}; !reflect.DeepEqual(ss.Starred(), want) || len(ss.Pending()) != 0 {
+		t.Fatalf("Sync left invalid state: %+v, %+v", ss.Starred(), ss.Pending())
+	}
+
+	// The state is loaded by a new StarSync
+	loaded, err := s.NewStarSync(path)
+	if err != nil {
+		t.Fatalf("NewStarSync returned error: %s", err.Error())
+	}
+	if !reflect.DeepEqual(loaded.Starred(), ss.Starred()) {
+		t.Fatalf("NewStarSync loaded invalid state: %+v", loaded.Starred())
+	}
+}
+
+// TestStarSyncResolve verifies that conflicts are resolved by the most recent change, unless
+// a Prompt is set
+func TestStarSyncResolve(t *testing.T) {
+	log.Println("TestStarSyncResolve()")
+
+	local := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		conflict StarConflict
+		keep     bool
+	}{
+		// Unstarred on the server, at an unknown time
+		{StarConflict{Local: StarChange{Starred: true, Time: local}}, true},
+		// Starred on the server before and after the local change
+		{StarConflict{Local: StarChange{Time: local}, Remote: true, RemoteTime: local.Add(-time.Hour)}, true},
+		{StarConflict{Local: StarChange{Time: local}, Remote: true, RemoteTime: local.Add(time.Hour)}, false},
+		// Starred on the server at an unknown time
+		{StarConflict{Local: StarChange{Time: local}, Remote: true}, true},
+	}
+
+	ss := &StarSync{}
+	for i, test := range tests {
+		if keep := ss.resolve(test.conflict); keep != test.keep {
+			t.Fatalf("[%02d] resolve returned %v, expected %v", i, keep, test.keep)
+		}
+	}
+
+	var prompted int
+	ss.Prompt = func(StarConflict) bool {
+		prompted++
+		return false
+	}
+	if ss.resolve(tests[0].conflict) || prompted != 1 {
+		t.Fatalf("resolve did not use Prompt")
+	}
+}
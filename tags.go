@@ -0,0 +1,267 @@
+package gosubsonic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Tags are written to downloaded files using the song's metadata from the server, so files
+// are tagged consistently with the library, even if the server's copies are not.  MP3 files
+// receive an ID3v2.4 tag, replacing any existing ID3v2 tag, and FLAC files receive Vorbis
+// comments, replacing any existing comments.  Other formats are copied unchanged.
+
+// Maximum sizes imposed by the ID3v2 and FLAC formats
+const (
+	id3MaxSize       = 1<<28 - 1
+	flacMaxBlockSize = 1<<24 - 1
+)
+
+// FLAC metadata block types
+const (
+	flacVorbisComment = 4
+	flacPicture       = 6
+)
+
+// tagPicture is cover art embedded in a file's tags
+type tagPicture struct {
+	contentType string
+	data        []byte
+}
+
+// writeTags copies an audio file from r to w, replacing its tags with the song's metadata,
+// and embedding cover art if set
+func writeTags(w io.Writer, r io.Reader, song *Audio, pic *tagPicture) error {
+	switch strings.ToLower(song.Suffix) {
+	case "mp3":
+		return writeID3(w, r, song, pic)
+	case "flac":
+		return writeFLACTags(w, r, song, pic)
+	default:
+		_, err := io.Copy(w, r)
+		return err
+	}
+}
+
+// writeID3 copies an MP3 file from r to w, replacing any ID3v2 tag at its start with a new
+// ID3v2.4 tag
+func writeID3(w io.Writer, r io.Reader, song *Audio, pic *tagPicture) error {
+	br := bufio.NewReader(r)
+
+	// Skip an existing tag, including its footer, if present
+	if head, err := br.Peek(10); err == nil && string(head[:3]) == "ID3" {
+		size := int64(syncsafe(head[6:10])) + 10
+		if head[5]&0x10 != 0 {
+			size += 10
+		}
+
+		if _, err := io.CopyN(io.Discard, br, size); err != nil {
+			return fmt.Errorf("gosubsonic: invalid ID3 tag: %s", err.Error())
+		}
+	}
+
+	var frames bytes.Buffer
+	text := func(id string, value string) {
+		if value != "" {
+			id3Frame(&frames, id, append([]byte{3}, value...))
+		}
+	}
+
+	// Text frames use UTF-8 encoding
+	text("TIT2", song.Title)
+	text("TPE1", song.Artist)
+	text("TALB", song.Album)
+	text("TCON", song.Genre)
+	text("TRCK", tagNumber(song.Track))
+	text("TPOS", tagNumber(song.DiscNumber))
+	text("TDRC", tagNumber(song.Year))
+
+	if pic != nil {
+		// Front cover, with no description
+		var b bytes.Buffer
+		b.WriteByte(3)
+		b.WriteString(pic.contentType)
+		b.Write([]byte{0, 3, 0})
+		b.Write(pic.data)
+		id3Frame(&frames, "APIC", b.Bytes())
+	}
+
+	if frames.Len() > id3MaxSize {
+		return errors.New("gosubsonic: ID3 tag is too large")
+	}
+
+	header := []byte{'I', 'D', '3', 4, 0, 0}
+	header = append(header, syncsafeBytes(frames.Len())...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := frames.WriteTo(w); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(w, br)
+	return err
+}
+
+// id3Frame appends an ID3v2.4 frame to b
+func id3Frame(b *bytes.Buffer, id string, data []byte) {
+	b.WriteString(id)
+	b.Write(syncsafeBytes(len(data)))
+	b.Write([]byte{0, 0})
+	b.Write(data)
+}
+
+// syncsafe decodes a 28-bit ID3v2 syncsafe integer, which uses 7 bits of each byte
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// syncsafeBytes encodes a 28-bit ID3v2 syncsafe integer
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n>>21) & 0x7f, byte(n>>14) & 0x7f, byte(n>>7) & 0x7f, byte(n) & 0x7f}
+}
+
+// writeFLACTags copies a FLAC file from r to w, replacing its Vorbis comments, and its
+// pictures if a new picture is set.  Other metadata blocks are preserved.
+func writeFLACTags(w io.Writer, r io.Reader, song *Audio, pic *tagPicture) error {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != "fLaC" {
+		return errors.New("gosubsonic: invalid FLAC file")
+	}
+
+	type block struct {
+		typ  byte
+		data []byte
+	}
+
+	var blocks []block
+	for {
+		var h [4]byte
+		if _, err := io.ReadFull(br, h[:]); err != nil {
+			return fmt.Errorf("gosubsonic: invalid FLAC metadata: %s", err.Error())
+		}
+
+		typ := h[0] & 0x7f
+		data := make([]byte, int(h[1])<<16|int(h[2])<<8|int(h[3]))
+		if _, err := io.ReadFull(br, data); err != nil {
+			return fmt.Errorf("gosubsonic: invalid FLAC metadata: %s", err.Error())
+		}
+
+		if typ != flacVorbisComment && (typ != flacPicture || pic == nil) {
+			blocks = append(blocks, block{typ, data})
+		}
+
+		// The last block is flagged
+		if h[0]&0x80 != 0 {
+			break
+		}
+	}
+
+	blocks = append(blocks, block{flacVorbisComment, flacComments(song)})
+	if pic != nil {
+		data, err := flacPictureBlock(pic)
+		if err != nil {
+			return err
+		}
+
+		blocks = append(blocks, block{flacPicture, data})
+	}
+
+	if _, err := w.Write(magic); err != nil {
+		return err
+	}
+
+	for i, b := range blocks {
+		if len(b.data) > flacMaxBlockSize {
+			return errors.New("gosubsonic: FLAC metadata block is too large")
+		}
+
+		typ := b.typ
+		if i == len(blocks)-1 {
+			typ |= 0x80
+		}
+
+		n := len(b.data)
+		if _, err := w.Write([]byte{typ, byte(n >> 16), byte(n >> 8), byte(n)}); err != nil {
+			return err
+		}
+		if _, err := w.Write(b.data); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.Copy(w, br)
+	return err
+}
+
+// flacComments generates a FLAC Vorbis comment block containing a song's metadata
+func flacComments(song *Audio) []byte {
+	var comments []string
+	add := func(name string, value string) {
+		if value != "" {
+			comments = append(comments, name+"="+value)
+		}
+	}
+
+	add("TITLE", song.Title)
+	add("ARTIST", song.Artist)
+	add("ALBUM", song.Album)
+	add("GENRE", song.Genre)
+	add("TRACKNUMBER", tagNumber(song.Track))
+	add("DISCNUMBER", tagNumber(song.DiscNumber))
+	add("DATE", tagNumber(song.Year))
+
+	// Vorbis comments use little endian lengths
+	var b bytes.Buffer
+	vorbisString(&b, DefaultClientName)
+	binary.Write(&b, binary.LittleEndian, uint32(len(comments)))
+	for _, c := range comments {
+		vorbisString(&b, c)
+	}
+
+	return b.Bytes()
+}
+
+// vorbisString appends a length-prefixed string to a Vorbis comment block
+func vorbisString(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.LittleEndian, uint32(len(s)))
+	b.WriteString(s)
+}
+
+// flacPictureBlock generates a FLAC picture block containing a front cover
+func flacPictureBlock(pic *tagPicture) ([]byte, error) {
+	// The dimensions are informational, so images which cannot be decoded are still embedded
+	cfg, _, _ := image.DecodeConfig(bytes.NewReader(pic.data))
+
+	// FLAC pictures use big endian lengths
+	var b bytes.Buffer
+	for _, v := range []interface{}{
+		uint32(3), uint32(len(pic.contentType)), []byte(pic.contentType),
+		uint32(0),
+		uint32(cfg.Width), uint32(cfg.Height), uint32(24), uint32(0),
+		uint32(len(pic.data)), pic.data,
+	} {
+		if err := binary.Write(&b, binary.BigEndian, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// tagNumber formats a positive number for a tag, or returns an empty string otherwise
+func tagNumber(n int64) string {
+	if n <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(n, 10)
+}
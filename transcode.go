@@ -0,0 +1,118 @@
+package gosubsonic
+
+import (
+	"context"
+	"strings"
+)
+
+// formatRaw is the stream format which disables transcoding
+const formatRaw = "raw"
+
+// contentTypeFormats maps an audio content type to the transcoding format which produces it
+var contentTypeFormats = map[string]string{
+	"audio/aac":  "aac",
+	"audio/flac": "flac",
+	"audio/mp4":  "m4a",
+	"audio/mpeg": "mp3",
+	"audio/ogg":  "ogg",
+	"audio/wav":  "wav",
+}
+
+// PlaybackCapabilities describes the media a player is able to play, which is used to decide
+// whether a song can be streamed in its original format, or must be transcoded
+type PlaybackCapabilities struct {
+	// Formats are the formats the player can play, in order of preference.  Each is either
+	// a file suffix, such as "opus" or "mp3", or a content type, such as "audio/flac".
+	Formats []string
+
+	// MaxBitRate is the maximum bit rate the player accepts in Kbps, such as on a metered
+	// connection, or 0 for no limit
+	MaxBitRate int64
+}
+
+// StreamDecision describes how a song should be streamed to a player
+type StreamDecision struct {
+	// Transcode is set if the song must be transcoded, because the player cannot play its
+	// original format, or its bit rate is too high
+	Transcode bool
+
+	// Options are the StreamOptions which produce the stream
+	Options StreamOptions
+
+	// ContentType is the expected content type of the stream
+	ContentType string
+}
+
+// Decide determines how a song should be streamed to a player.  Songs are streamed in their
+// original format when the player can play it within its maximum bit rate.  Otherwise, they
+// are transcoded into the server's configured format if the player can play it, or else the
+// player's most preferred format.
+func (c PlaybackCapabilities) Decide(song *Audio) StreamDecision {
+	if c.supports(song.Suffix, song.ContentType) && (c.MaxBitRate <= 0 || song.BitRate <= c.MaxBitRate) {
+		return StreamDecision{
+			Options:     StreamOptions{Format: formatRaw},
+			ContentType: song.ContentType,
+		}
+	}
+
+	d := StreamDecision{
+		Transcode: true,
+		Options:   StreamOptions{MaxBitRate: c.MaxBitRate},
+	}
+
+	// The server transcodes to its configured format when no format is requested
+	if song.TranscodedSuffix != "" && c.supports(song.TranscodedSuffix, song.TranscodedContentType) {
+		d.ContentType = song.TranscodedContentType
+		if d.ContentType == "" {
+			d.ContentType = formatContentType(strings.ToLower(song.TranscodedSuffix))
+		}
+
+		return d
+	}
+
+	format := c.preferredFormat()
+	d.Options.Format = format
+	d.ContentType = formatContentType(format)
+	return d
+}
+
+// supports determines if the player can play media with the specified suffix or content type
+func (c PlaybackCapabilities) supports(suffix string, contentType string) bool {
+	for _, f := range c.Formats {
+		if (suffix != "" && strings.EqualFold(f, suffix)) || (contentType != "" && strings.EqualFold(f, contentType)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preferredFormat returns the player's most preferred format which may be requested from
+// the server, or mp3 if none are known
+func (c PlaybackCapabilities) preferredFormat() string {
+	for _, f := range c.Formats {
+		f = strings.ToLower(f)
+		if _, ok := formatContentTypes[f]; ok {
+			return f
+		}
+
+		if format, ok := contentTypeFormats[f]; ok {
+			return format
+		}
+	}
+
+	return castDefaultFormat
+}
+
+// StreamFor streams a song in a format the player can play, as decided by
+// PlaybackCapabilities.Decide.  The decision is returned along with the stream.
+func (s Client) StreamFor(ctx context.Context, song *Audio, caps PlaybackCapabilities) (*StreamResponse, StreamDecision, error) {
+	d := caps.Decide(song)
+
+	stream, err := s.Stream(ctx, song.ID, &d.Options)
+	if err != nil {
+		return nil, d, err
+	}
+
+	return stream, d, nil
+}
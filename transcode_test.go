@@ -0,0 +1,71 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"testing"
+)
+
+// TestPlaybackCapabilitiesDecide verifies that songs are only transcoded when required
+func TestPlaybackCapabilitiesDecide(t *testing.T) {
+	log.Println("TestPlaybackCapabilitiesDecide()")
+
+	flac := &Audio{
+		Suffix:                "flac",
+		ContentType:           "audio/flac",
+		BitRate:               1000,
+		TranscodedSuffix:      "mp3",
+		TranscodedContentType: "audio/mpeg",
+	}
+
+	var tests = []struct {
+		caps        PlaybackCapabilities
+		transcode   bool
+		format      string
+		maxBitRate  int64
+		contentType string
+	}{
+		// Original format is supported
+		{PlaybackCapabilities{Formats: []string{"FLAC"}}, false, "raw", 0, "audio/flac"},
+		{PlaybackCapabilities{Formats: []string{"audio/flac"}, MaxBitRate: 1411}, false, "raw", 0, "audio/flac"},
+		// Bit rate is too high, so the server's configured format is used
+		{PlaybackCapabilities{Formats: []string{"flac", "mp3"}, MaxBitRate: 320}, true, "", 320, "audio/mpeg"},
+		// Neither format is supported, so the preferred format is requested
+		{PlaybackCapabilities{Formats: []string{"audio/ogg", "opus"}}, true, "ogg", 0, "audio/ogg"},
+		{PlaybackCapabilities{Formats: []string{"opus"}, MaxBitRate: 96}, true, "opus", 96, "audio/ogg"},
+		{PlaybackCapabilities{}, true, "mp3", 0, "audio/mpeg"},
+	}
+
+	for i, test := range tests {
+		d := test.caps.Decide(flac)
+		if d.Transcode != test.transcode || d.Options.Format != test.format ||
+			d.Options.MaxBitRate != test.maxBitRate || d.ContentType != test.contentType {
+			t.Fatalf("[%02d] Decide returned invalid decision: %+v", i, d)
+		}
+	}
+}
+
+// TestStreamFor verifies that client.StreamFor() requests the decided format
+func TestStreamFor(t *testing.T) {
+	log.Println("TestStreamFor()")
+
+	f := NewFakeServer()
+	defer f.Close()
+	f.SetMedia("1", "audio/mpeg", []byte("0123456789"))
+
+	s, err := f.Client()
+	if err != nil {
+		t.Fatalf("Client returned error: %s", err.Error())
+	}
+
+	song := &Audio{ID: "1", Suffix: "mp3", ContentType: "audio/mpeg"}
+	stream, d, err := s.StreamFor(context.Background(), song, PlaybackCapabilities{Formats: []string{"mp3"}})
+	if err != nil {
+		t.Fatalf("StreamFor returned error: %s", err.Error())
+	}
+	defer stream.Close()
+
+	if d.Transcode || stream.ContentType != d.ContentType {
+		t.Fatalf("StreamFor returned invalid decision: %+v, content type %s", d, stream.ContentType)
+	}
+}
@@ -0,0 +1,235 @@
+package gosubsonic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Subsonic XML responses use the same structure as JSON responses, with values stored in
+// attributes, nested objects and lists stored as repeated child elements, and a small number
+// of values (such as lyrics, genres, and artist biographies) stored as element text.  Older
+// servers produce XML far more reliably than JSON, so XML responses are converted into the
+// equivalent JSON and decoded into the same types, using the tolerant decoding in decode.go.
+
+// xmlSniffLen is the number of bytes read from the start of a response to determine whether
+// it contains XML
+const xmlSniffLen = 512
+
+// xmlNode represents a single element of a Subsonic XML response
+type xmlNode struct {
+	attrs    []xml.Attr
+	names    []string
+	children map[string][]*xmlNode
+	text     strings.Builder
+}
+
+// processResponse parses a raw JSON or XML response body into an apiContainer
+func processResponse(body []byte) (*apiContainer, error) {
+	if !isXML(body) {
+		return processJSON(bytes.NewReader(body))
+	}
+
+	out, err := xmlToJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to parse response XML: %s", err.Error())
+	}
+
+	return processJSON(bytes.NewReader(out))
+}
+
+// decodeResponse decodes a JSON or XML response into an apiContainer as it is read.  XML
+// responses are read entirely, since they are converted into JSON before being decoded.
+func decodeResponse(r io.Reader) (*apiContainer, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(xmlSniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("gosubsonic: failed to read response: %w", err)
+	}
+
+	if !isXML(head) {
+		return processJSON(br)
+	}
+
+	body, err := io.ReadAll(br)
+	if err != nil {
+		return nil, fmt.Errorf("gosubsonic: failed to read response: %w", err)
+	}
+
+	return processResponse(body)
+}
+
+// isXML determines if a response body contains XML, rather than JSON
+func isXML(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n\ufeff")
+	return len(body) > 0 && body[0] == '<'
+}
+
+// isXMLContentType determines if a Content-Type header indicates a Subsonic XML response,
+// rather than binary data which happens to be XML, such as an SVG image
+func isXMLContentType(contentType string) bool {
+	return strings.Contains(contentType, "text/xml") || strings.Contains(contentType, "application/xml")
+}
+
+// xmlToJSON converts a Subsonic XML response into the equivalent JSON response
+func xmlToJSON(body []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+
+	var root *xmlNode
+	var rootName string
+	var stack []*xmlNode
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{children: make(map[string][]*xmlNode)}
+			for _, a := range tok.Attr {
+				// Namespace declarations are not part of the response
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+
+				n.attrs = append(n.attrs, a)
+			}
+
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("multiple root elements")
+				}
+
+				root = n
+				rootName = tok.Name.Local
+			} else {
+				parent := stack[len(stack)-1]
+				name := tok.Name.Local
+				if _, ok := parent.children[name]; !ok {
+					parent.names = append(parent.names, name)
+				}
+
+				parent.children[name] = append(parent.children[name], n)
+			}
+
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tok)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if err := writeJSONString(&buf, rootName); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte(':')
+	if err := root.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeJSON writes the JSON equivalent of an element.  Elements without attributes or
+// children become strings, and all others become objects, with any text stored as "value".
+func (n *xmlNode) writeJSON(buf *bytes.Buffer) error {
+	text := n.text.String()
+	if len(n.attrs) == 0 && len(n.names) == 0 {
+		return writeJSONString(buf, text)
+	}
+
+	buf.WriteByte('{')
+	first := true
+	field := func(name string) error {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		if err := writeJSONString(buf, name); err != nil {
+			return err
+		}
+
+		buf.WriteByte(':')
+		return nil
+	}
+
+	for _, a := range n.attrs {
+		if err := field(a.Name.Local); err != nil {
+			return err
+		}
+		if err := writeJSONString(buf, a.Value); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range n.names {
+		if err := field(name); err != nil {
+			return err
+		}
+
+		// A single child becomes an object, and repeated children become a list
+		children := n.children[name]
+		if len(children) == 1 {
+			if err := children[0].writeJSON(buf); err != nil {
+				return err
+			}
+			continue
+		}
+
+		buf.WriteByte('[')
+		for i, c := range children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := c.writeJSON(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	}
+
+	// Whitespace between child elements is not a value
+	if strings.TrimSpace(text) != "" {
+		if err := field("value"); err != nil {
+			return err
+		}
+		if err := writeJSONString(buf, text); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeJSONString writes a string as a JSON string
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(b)
+	return nil
+}
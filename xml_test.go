@@ -0,0 +1,132 @@
+package gosubsonic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestXMLToJSON verifies that XML responses are converted into equivalent JSON responses
+func TestXMLToJSON(t *testing.T) {
+	log.Println("TestXMLToJSON()")
+
+	var tests = []struct {
+		input  string
+		output string
+	}{
+		{
+			`<subsonic-response xmlns="http://subsonic.org/restapi" status="ok" version="1.10.2"/>`,
+			`{"subsonic-response":{"status":"ok","version":"1.10.2"}}`,
+		},
+		{
+			`<?xml version="1.0" encoding="UTF-8"?>
+			<subsonic-response status="ok" version="1.10.2">
+				<musicFolders><musicFolder id="1" name="Music"/></musicFolders>
+			</subsonic-response>`,
+			`{"subsonic-response":{"status":"ok","version":"1.10.2","musicFolders":{"musicFolder":{"id":"1","name":"Music"}}}}`,
+		},
+		{
+			`<subsonic-response status="ok" version="1.10.2">
+				<genres><genre songCount="1">Rock &amp; Roll</genre><genre songCount="2">Jazz</genre></genres>
+				<nowPlaying/>
+			</subsonic-response>`,
+			`{"subsonic-response":{"status":"ok","version":"1.10.2","genres":{"genre":[{"songCount":"1","value":"Rock \u0026 Roll"},{"songCount":"2","value":"Jazz"}]},"nowPlaying":""}}`,
+		},
+	}
+
+	for _, test := range tests {
+		out, err := xmlToJSON([]byte(test.input))
+		if err != nil {
+			t.Fatalf("xmlToJSON: unexpected error: %s", err.Error())
+		}
+
+		if string(out) != test.output {
+			t.Fatalf("xmlToJSON: unexpected output:\n- want: %s\n-  got: %s", test.output, out)
+		}
+	}
+
+	// Malformed XML is an error
+	if _, err := processResponse([]byte(`<subsonic-response status="ok">`)); err == nil {
+		t.Fatalf("processResponse: expected error for malformed XML")
+	}
+}
+
+// TestClientXML verifies that a Client configured for XML responses requests them, and
+// decodes them into the same types as JSON responses
+func TestClientXML(t *testing.T) {
+	log.Println("TestClientXML()")
+
+	responses := map[string]string{
+		"ping": `<subsonic-response status="ok" version="1.10.2"/>`,
+		"getMusicDirectory": `<subsonic-response status="ok" version="1.10.2">
+			<directory id="1" name="311">
+				<child id="2" parent="1" title="Amber" isDir="false" isVideo="false" track="1" duration="208" created="2014-03-20T21:55:32"/>
+			</directory>
+		</subsonic-response>`,
+		"getUser": `<subsonic-response status="ok" version="1.10.2">
+			<user username="mock" adminRole="true" streamRole="false" maxBitRate="320">
+				<folder>1</folder>
+				<folder>3</folder>
+			</user>
+		</subsonic-response>`,
+		"getScanStatus": `<subsonic-response status="ok" version="1.10.2">
+			<scanStatus scanning="true" count="25"/>
+		</subsonic-response>`,
+		"getSong": `<subsonic-response status="failed" version="1.10.2">
+			<error code="70" message="Song not found"/>
+		</subsonic-response>`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("f") != "xml" && !strings.HasSuffix(r.URL.Path, "/ping.view") {
+			t.Errorf("request did not specify XML format: %s", r.URL.String())
+		}
+
+		method := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/"), ".view")
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(responses[method]))
+	}))
+	defer srv.Close()
+
+	s, err := New(strings.TrimPrefix(srv.URL, "http://"), WithPassword("mock", "mock"))
+	if err != nil {
+		t.Fatalf("Could not generate client: %s", err.Error())
+	}
+	s.XML = true
+
+	ctx := context.Background()
+
+	content, err := s.GetMusicDirectory(ctx, "1")
+	if err != nil {
+		t.Fatalf("GetMusicDirectory: unexpected error: %s", err.Error())
+	}
+
+	if len(content.Audio) != 1 || content.Audio[0].Title != "Amber" || content.Audio[0].Duration.Seconds() != 208 {
+		t.Fatalf("GetMusicDirectory: unexpected content: %+v", content)
+	}
+
+	user, err := s.GetUser(ctx, "mock")
+	if err != nil {
+		t.Fatalf("GetUser: unexpected error: %s", err.Error())
+	}
+
+	if !user.AdminRole || user.StreamRole || user.MaxBitRate != 320 || len(user.Folder) != 2 || user.Folder[1] != 3 {
+		t.Fatalf("GetUser: unexpected user: %+v", user)
+	}
+
+	status, err := s.GetScanStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetScanStatus: unexpected error: %s", err.Error())
+	}
+
+	if !status.Scanning || status.Count != 25 {
+		t.Fatalf("GetScanStatus: unexpected status: %+v", status)
+	}
+
+	if _, err := s.GetSong(ctx, "2"); !IsNotFound(err) {
+		t.Fatalf("GetSong: unexpected error: %v", err)
+	}
+}